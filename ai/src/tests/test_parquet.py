@@ -0,0 +1,52 @@
+import math
+import unittest
+
+import pandas as pd
+import pyarrow as pa
+import pyarrow.parquet as pq
+
+# Written by `spice observations export --format parquet`, kept up to date by pkg/observations/parquet_test.go
+OBSERVATIONS_PARQUET_PATH = "../../test/assets/data/parquet/observations.parquet"
+
+
+class ObservationsParquetTestCase(unittest.TestCase):
+    def test_schema(self):
+        schema = pq.read_schema(OBSERVATIONS_PARQUET_PATH)
+        self.assertEqual(
+            schema.names,
+            ["time", "event.id", "coinbase.btcusd.close", "coinbase.btcusd.volume", "event.color", "tags"])
+        self.assertEqual(schema.field("time").type, pa.int64())
+        self.assertFalse(schema.field("time").nullable)
+        self.assertEqual(schema.field("event.id").type, pa.string())
+        self.assertEqual(schema.field("coinbase.btcusd.close").type, pa.float64())
+        self.assertEqual(schema.field("coinbase.btcusd.volume").type, pa.float64())
+        self.assertEqual(schema.field("event.color").type, pa.string())
+        self.assertEqual(schema.field("tags").type, pa.string())
+
+        metadata = pq.read_metadata(OBSERVATIONS_PARQUET_PATH)
+        self.assertEqual(metadata.num_rows, 4)
+        self.assertEqual(metadata.num_row_groups, 1)
+        self.assertEqual(metadata.created_by, "spiceai")
+
+    def test_read_with_pandas(self):
+        observations = pd.read_parquet(OBSERVATIONS_PARQUET_PATH, engine="pyarrow")
+
+        self.assertEqual(observations["time"].tolist(), [1605312000, 1605312060, 1605312120, 1605312180])
+        self.assertEqual(observations["event.id"].tolist(), ["a", None, "b", ""])
+        self.assertEqual(observations["event.color"].tolist(), [None, "red", "grün", None])
+        self.assertEqual(observations["tags"].tolist(), ["tagA tagB", None, "tagA", None])
+
+        close = observations["coinbase.btcusd.close"].tolist()
+        self.assertEqual(close[0], 16339.56)
+        self.assertTrue(math.isnan(close[1]))
+        self.assertEqual(close[2:], [-0.25, 16350.0])
+
+        volume = observations["coinbase.btcusd.volume"].tolist()
+        self.assertEqual(volume[0], 1.5)
+        self.assertTrue(math.isnan(volume[1]))
+        self.assertEqual(volume[2], 0.0)
+        self.assertTrue(math.isnan(volume[3]))
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -2,21 +2,26 @@ package main
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/config"
 	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/loggers"
 	"github.com/spiceai/spiceai/pkg/runtime"
 	"github.com/spiceai/spiceai/pkg/version"
+	"go.uber.org/zap"
 )
 
 var (
+	zaplog *zap.Logger = loggers.ZapLogger()
+
 	contextFlag     string
 	developmentMode bool
+	validateFlag    bool
 )
 
 func main() {
@@ -27,6 +32,7 @@ func main() {
 		os.Exit(-1)
 	}
 	defer loggers.ZapLoggerSync()
+	loggers.CaptureStdLog()
 
 	if err := RootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -51,8 +57,6 @@ var RootCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		context.SetContext(rtcontext)
-
 		var manifestPath string
 		if len(args) > 0 {
 			manifestPath = args[0]
@@ -60,25 +64,86 @@ var RootCmd = &cobra.Command{
 
 		isSingleRun := manifestPath != ""
 
-		runtime := runtime.GetSpiceRuntime()
+		runtime, err := runtime.NewRuntime(runtime.WithContext(rtcontext), runtime.WithConfigHook(func(rtConfig *config.SpiceConfiguration) error {
+			return configureLogger(rtcontext, rtConfig)
+		}))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		err = runtime.BindFlags(cmd.Flags())
+		if err != nil {
+			fmt.Printf("error initializing: %s", err)
+			os.Exit(1)
+		}
+
+		if validateFlag {
+			var manifestPaths []string
+			if manifestPath != "" {
+				manifestPaths = append(manifestPaths, manifestPath)
+			}
+			err = runtime.Validate(os.Stdout, manifestPaths...)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		if isSingleRun {
 			err = runtime.SingleRun(manifestPath)
 		} else {
 			err = runtime.Run()
 		}
 		if err != nil {
-			log.Fatalln(err)
+			zaplog.Fatal("error starting runtime", zap.Error(err))
 		}
 		defer runtime.Shutdown()
 
 		if !isSingleRun {
 			stop := make(chan os.Signal, 1)
 			signal.Notify(stop, syscall.SIGTERM, os.Interrupt)
-			<-stop
+
+			reload := make(chan os.Signal, 1)
+			signal.Notify(reload, syscall.SIGHUP)
+
+			for {
+				select {
+				case <-stop:
+					return
+				case <-reload:
+					err := runtime.ReloadConfig()
+					if err != nil {
+						zaplog.Error("error reloading configuration", zap.Error(err))
+						continue
+					}
+					zaplog.Info("reloaded configuration")
+				}
+			}
 		}
 	},
 }
 
+// The logger is shared by the whole process, so it is configured here rather than by the runtime
+func configureLogger(rtcontext context.RuntimeContext, rtConfig *config.SpiceConfiguration) error {
+	err := loggers.ConfigureZapLogger(rtConfig.LogLevel, rtConfig.LogFormat)
+	if err != nil {
+		return err
+	}
+
+	var podLogsDir string
+	if rtConfig.Logs.PerPod {
+		podLogsDir = filepath.Join(rtcontext.SpiceRuntimeDir(), "log", "pods")
+	}
+
+	return loggers.ConfigureFileLogs(loggers.FileRotation{
+		MaxSizeMB:  rtConfig.Logs.MaxSizeMB,
+		MaxAgeDays: rtConfig.Logs.MaxAgeDays,
+		MaxBackups: rtConfig.Logs.MaxBackups,
+	}, podLogsDir)
+}
+
 var VersionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Version information",
@@ -88,13 +153,9 @@ var VersionCmd = &cobra.Command{
 }
 
 func init() {
-	runtime := runtime.GetSpiceRuntime()
 	RootCmd.Flags().StringVar(&contextFlag, "context", "metal", "Runs Spice.ai in the given context, either 'docker' or 'metal'")
 	RootCmd.Flags().BoolVarP(&developmentMode, "development", "d", false, "Runs Spice.ai in development mode.")
-	err := runtime.BindFlags(RootCmd.Flags().Lookup("development"))
-	if err != nil {
-		fmt.Printf("error initializing: %s", err)
-		return
-	}
+	RootCmd.Flags().BoolVar(&validateFlag, "validate", false, "Validates the configuration and pods without reading data or training, then exits")
+	config.AddFlags(RootCmd.Flags())
 	RootCmd.AddCommand(VersionCmd)
 }
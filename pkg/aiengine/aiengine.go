@@ -4,18 +4,19 @@ import (
 	"bufio"
 	"context"
 	"errors"
-	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/logrusorgru/aurora"
+	"github.com/spiceai/spiceai/pkg/config"
 	spice_context "github.com/spiceai/spiceai/pkg/context"
+	"github.com/spiceai/spiceai/pkg/flights"
 	"github.com/spiceai/spiceai/pkg/loggers"
 	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
+	"github.com/spiceai/spiceai/pkg/scheduler"
 	"go.uber.org/zap"
 )
 
@@ -25,47 +26,118 @@ type AIEngineResponse struct {
 }
 
 const (
-	aiServerUrl          = "localhost:8004"
-	pythonServerFilename = "main.py"
+	defaultAIServerUrl        = "localhost:8004"
+	defaultHealthCheckRetries = 30
+	pythonServerFilename      = "main.py"
 )
 
 var (
-	execCommand         = exec.Command
-	aiengineClient      AIEngineClient
-	getClient           = NewAIEngineClient
-	aiServerCmd         *exec.Cmd
-	aiServerRunning     chan bool
-	aiServerReady       bool        = false
-	aiSingleTrainingRun bool        = false
-	zaplog              *zap.Logger = loggers.ZapLogger()
+	zaplog *zap.Logger = loggers.ZapLogger()
 )
 
-func StartServer(ready chan bool, isSingleRun bool) error {
-	if aiServerRunning != nil {
+// AIEngine manages the AI engine process and the gRPC client used to talk to it
+type AIEngine struct {
+	rtcontext   spice_context.RuntimeContext
+	execCommand func(name string, arg ...string) *exec.Cmd
+	getClient   func(target string) (AIEngineClient, error)
+
+	serverUrl          string
+	pythonPath         string
+	healthCheckRetries int
+
+	// Guards the fields below, which are written by the server goroutines and read by handlers
+	serverMutex   sync.RWMutex
+	client        AIEngineClient
+	serverCmd     *exec.Cmd
+	serverRunning chan bool
+	serverReady   bool
+
+	singleTrainingRun bool
+
+	activeFlightsMutex sync.Mutex
+	activeFlights      []*flights.Flight
+
+	scheduler *scheduler.Scheduler
+
+	podInitMutex sync.RWMutex
+	podInitMap   map[string]*aiengine_pb.InitRequest
+
+	algorithms    []*LearningAlgorithm
+	algorithmsMap map[string]*LearningAlgorithm
+}
+
+func NewAIEngine(rtcontext spice_context.RuntimeContext) *AIEngine {
+	return &AIEngine{
+		rtcontext:          rtcontext,
+		execCommand:        exec.Command,
+		getClient:          NewAIEngineClient,
+		serverUrl:          defaultAIServerUrl,
+		healthCheckRetries: defaultHealthCheckRetries,
+		podInitMap:         make(map[string]*aiengine_pb.InitRequest),
+		algorithmsMap:      make(map[string]*LearningAlgorithm),
+		scheduler:          scheduler.NewScheduler(0),
+	}
+}
+
+// Returns the scheduler limiting concurrent trainings across pods
+func (e *AIEngine) Scheduler() *scheduler.Scheduler {
+	return e.scheduler
+}
+
+// Applies the AI engine settings from the runtime configuration. Must be called before StartServer.
+func (e *AIEngine) Configure(aiEngineConfig config.AIEngineConfiguration) {
+	if aiEngineConfig.Address != "" {
+		e.serverUrl = aiEngineConfig.Address
+	}
+	e.pythonPath = aiEngineConfig.PythonPath
+	if aiEngineConfig.HealthCheckRetries > 0 {
+		e.healthCheckRetries = aiEngineConfig.HealthCheckRetries
+	}
+}
+
+func (e *AIEngine) StartServer(ready chan bool, isSingleRun bool) error {
+	e.serverMutex.Lock()
+	defer e.serverMutex.Unlock()
+
+	if e.serverRunning != nil {
 		return errors.New("ai engine already started")
 	}
 
-	aiSingleTrainingRun = isSingleRun
+	e.singleTrainingRun = isSingleRun
 
-	outputFormatter := func(line string) {
-		if strings.Contains(line, "completed with score of") {
-			log.Println(aurora.BrightCyan(line))
-		} else {
-			log.Println(line)
+	// Named so the AI engine's output isn't also recorded as runtime logs
+	outputLogger := zaplog.Named(loggers.ComponentAIEngine)
+	logOutput := func(line string) {
+		pod, message := loggers.SplitAIEngineLine(line)
+		if strings.Contains(message, "completed with score of") {
+			message = loggers.Color().BrightCyan(message).String()
+		}
+		if pod == "" {
+			outputLogger.Info(message)
+			return
 		}
+		outputLogger.Info(message, loggers.Pod(pod))
 	}
 
-	rtcontext := spice_context.CurrentContext()
+	rtcontext := e.rtcontext
 	aiServerPath := filepath.Join(rtcontext.AIEngineDir(), pythonServerFilename)
-	aiServerCmd = execCommand(rtcontext.AIEnginePythonCmdPath(), aiServerPath)
-	aiServerRunning := make(chan bool, 1)
+	pythonPath := e.pythonPath
+	if pythonPath == "" {
+		pythonPath = rtcontext.AIEnginePythonCmdPath()
+	}
+	aiServerCmd := e.execCommand(pythonPath, aiServerPath)
 
-	var err error
-	aiengineClient, err = getClient(aiServerUrl)
+	client, err := e.getClient(e.serverUrl)
 	if err != nil {
 		return err
 	}
 
+	// Receives whether the server started, then false when it exits
+	aiServerRunning := make(chan bool, 1)
+	e.serverCmd = aiServerCmd
+	e.serverRunning = aiServerRunning
+	e.client = client
+
 	go func() {
 		if aiServerCmd == nil {
 			aiServerRunning <- true
@@ -74,14 +146,14 @@ func StartServer(ready chan bool, isSingleRun bool) error {
 
 		stdOutPipe, pipeErr := aiServerCmd.StdoutPipe()
 		if pipeErr != nil {
-			log.Printf("Error creating stdout for App: %s\n", pipeErr.Error())
+			zaplog.Error("error creating stdout for the AI engine", zap.Error(pipeErr))
 			aiServerRunning <- false
 			return
 		}
 
 		stdErrPipe, pipeErr := aiServerCmd.StderrPipe()
 		if pipeErr != nil {
-			log.Printf("Error creating stderr for App: %s\n", pipeErr.Error())
+			zaplog.Error("error creating stderr for the AI engine", zap.Error(pipeErr))
 			aiServerRunning <- false
 			return
 		}
@@ -91,19 +163,16 @@ func StartServer(ready chan bool, isSingleRun bool) error {
 
 		fileLogger, err := loggers.NewFileLogger("aiengine", rtcontext.SpiceRuntimeDir())
 		if err != nil {
-			zaplog.Sugar().Errorf("error creating file logger: %w", err)
+			zaplog.Error("error creating AI engine file logger", zap.Error(err))
 			fileLogger = nil
 		}
 
 		go func() {
 			for outScanner.Scan() {
 				line := outScanner.Text()
-				if outputFormatter != nil {
-					outputFormatter(line)
-				} else {
-					log.Println(line)
-				}
+				logOutput(line)
 
+				loggers.WriteAIEngineLine(line)
 				if fileLogger != nil {
 					fileLogger.Info(line)
 				}
@@ -114,6 +183,7 @@ func StartServer(ready chan bool, isSingleRun bool) error {
 			for errScanner.Scan() {
 				line := errScanner.Text()
 
+				loggers.WriteAIEngineLine(line)
 				if fileLogger != nil {
 					fileLogger.Info(line)
 				}
@@ -122,7 +192,7 @@ func StartServer(ready chan bool, isSingleRun bool) error {
 
 		err = aiServerCmd.Start()
 		if err != nil {
-			log.Println(fmt.Errorf("error starting %s: %w", aiServerCmd.Path, err))
+			zaplog.Error("error starting the AI engine", zap.String("path", aiServerCmd.Path), zap.Error(err))
 			if fileLogger != nil {
 				_ = fileLogger.Sync()
 			}
@@ -131,19 +201,22 @@ func StartServer(ready chan bool, isSingleRun bool) error {
 		}
 
 		go func() {
-			waitForServerHealthy(30)
-			aiServerReady = true
+			e.waitForServerHealthy(e.healthCheckRetries)
+			e.setServerReady(true)
 			ready <- true
 			appErr := aiServerCmd.Wait()
+			e.abortActiveFlights(errors.New("ai engine exited"))
+			stopped := e.getServerCmd() != aiServerCmd
+			aiServerRunning <- false
 
 			if appErr != nil {
-				log.Println(fmt.Errorf("process %s exited with error: %w", aiServerCmd.Path, appErr))
+				zaplog.Error("the AI engine exited with an error", zap.String("path", aiServerCmd.Path), zap.Error(appErr))
 			}
 			if fileLogger != nil {
 				_ = fileLogger.Sync()
 			}
 
-			if appErr != nil && !aiServerCmd.ProcessState.Success() && !isTestEnvironment() {
+			if !stopped && appErr != nil && !aiServerCmd.ProcessState.Success() && !isTestEnvironment(aiServerCmd) {
 				// If the AI engine crashes, pass on its exit status
 				os.Exit(aiServerCmd.ProcessState.ExitCode())
 			}
@@ -154,36 +227,43 @@ func StartServer(ready chan bool, isSingleRun bool) error {
 
 	appRunStatus := <-aiServerRunning
 	if !appRunStatus {
-		zaplog.Sugar().Error("AI Engine failed to run")
+		zaplog.Error("the AI engine failed to run")
 	}
 
 	return nil
 }
 
-func StopServer() error {
-	aiServerReady = false
-	if aiServerCmd != nil {
-		err := aiServerCmd.Process.Kill()
-		aiServerCmd = nil
-		if err != nil {
+func (e *AIEngine) StopServer() error {
+	e.serverMutex.Lock()
+	serverCmd := e.serverCmd
+	serverRunning := e.serverRunning
+	client := e.client
+	e.serverCmd = nil
+	e.serverRunning = nil
+	e.client = nil
+	e.serverReady = false
+	e.serverMutex.Unlock()
+
+	e.abortActiveFlights(errors.New("ai engine stopped"))
+	if serverCmd != nil && serverCmd.Process != nil {
+		err := serverCmd.Process.Kill()
+		if err != nil && !errors.Is(err, os.ErrProcessDone) {
 			return err
 		}
-		if aiServerRunning != nil {
-			<-aiServerRunning
-		}
+		// Wait for the process to exit
+		<-serverRunning
 	}
-	if aiengineClient != nil {
-		err := aiengineClient.Close()
+	if client != nil {
+		err := client.Close()
 		if err != nil {
 			return err
 		}
-		aiengineClient = nil
 	}
 	return nil
 }
 
-func ServerReady() bool {
-	err := IsAIEngineHealthy()
+func (e *AIEngine) ServerReady() bool {
+	err := e.IsAIEngineHealthy()
 	if err != nil {
 		zaplog.Sugar().Debugf("aiengine not healthy: %s", err)
 		return false
@@ -192,29 +272,33 @@ func ServerReady() bool {
 	return true
 }
 
-func IsAIEngineHealthy() error {
-	if !aiServerReady {
+func (e *AIEngine) IsAIEngineHealthy() error {
+	e.serverMutex.RLock()
+	serverReady := e.serverReady
+	e.serverMutex.RUnlock()
+
+	if !serverReady {
 		return errors.New("aiengine not yet ready")
 	}
 
-	return isAIEngineServerHealthy()
+	return e.isAIEngineServerHealthy()
 }
 
-func waitForServerHealthy(maxAttempts int) int {
+func (e *AIEngine) waitForServerHealthy(maxAttempts int) int {
 	attemptCount := 0
 	for {
 		time.Sleep(time.Millisecond * 250)
 
-		if aiServerCmd == nil {
+		if e.getServerCmd() == nil {
 			break
 		}
 
 		if attemptCount++; attemptCount > 4*maxAttempts {
-			log.Fatalf("Error: Failed to verify health of %s after %d attempts\n", aiServerUrl, attemptCount)
+			zaplog.Fatal("failed to verify the AI engine is healthy", zap.String("url", e.serverUrl), zap.Int("attempts", attemptCount))
 			break
 		}
 
-		err := isAIEngineServerHealthy()
+		err := e.isAIEngineServerHealthy()
 		if err != nil {
 			zaplog.Debug(err.Error())
 			continue
@@ -226,10 +310,10 @@ func waitForServerHealthy(maxAttempts int) int {
 	return attemptCount
 }
 
-func isAIEngineServerHealthy() error {
+func (e *AIEngine) isAIEngineServerHealthy() error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	resp, err := aiengineClient.GetHealth(ctx, &aiengine_pb.HealthRequest{})
+	resp, err := e.aiClient().GetHealth(ctx, &aiengine_pb.HealthRequest{})
 	if err != nil {
 		return err
 	}
@@ -245,8 +329,8 @@ func isAIEngineServerHealthy() error {
 	return nil
 }
 
-func isTestEnvironment() bool {
-	for _, envVar := range aiServerCmd.Env {
+func isTestEnvironment(cmd *exec.Cmd) bool {
+	for _, envVar := range cmd.Env {
 		if envVar == "GO_WANT_HELPER_PROCESS=1" {
 			return true
 		}
@@ -255,7 +339,31 @@ func isTestEnvironment() bool {
 	return false
 }
 
-func SetAIEngineClient(newClient AIEngineClient) {
-	aiengineClient = newClient
-	aiServerReady = newClient != nil
+func (e *AIEngine) SetAIEngineClient(newClient AIEngineClient) {
+	e.serverMutex.Lock()
+	defer e.serverMutex.Unlock()
+
+	e.client = newClient
+	e.serverReady = newClient != nil
+}
+
+func (e *AIEngine) aiClient() AIEngineClient {
+	e.serverMutex.RLock()
+	defer e.serverMutex.RUnlock()
+
+	return e.client
+}
+
+func (e *AIEngine) getServerCmd() *exec.Cmd {
+	e.serverMutex.RLock()
+	defer e.serverMutex.RUnlock()
+
+	return e.serverCmd
+}
+
+func (e *AIEngine) setServerReady(ready bool) {
+	e.serverMutex.Lock()
+	defer e.serverMutex.Unlock()
+
+	e.serverReady = ready
 }
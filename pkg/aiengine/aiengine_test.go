@@ -4,7 +4,6 @@ import (
 	go_context "context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"testing"
 
@@ -19,32 +18,11 @@ import (
 var snapshotter = testutils.NewSnapshotter("../../test/assets/snapshots/aiengine")
 
 func TestAIEngineGetPythonCmd(t *testing.T) {
-	origContext := context.CurrentContext()
-	t.Cleanup(func() { context.SetContext(origContext) })
-
-	algorithmsMap = map[string]*LearningAlgorithm{
-		"dql": {
-			Id:   "dql",
-			Name: "Deep Q-Learning",
-		},
-	}
-
 	t.Run("getPythonCmd() -- Docker Context", testPythonCmdDockerContextFunc())
 	t.Run("getPythonCmd() -- BareMetal Context", testPythonCmdBareMetalContextFunc())
 }
 
 func TestAIEngineStartServer(t *testing.T) {
-	origContext := context.CurrentContext()
-	t.Cleanup(func() { context.SetContext(origContext) })
-	t.Cleanup(func() { execCommand = exec.Command })
-
-	algorithmsMap = map[string]*LearningAlgorithm{
-		"dql": {
-			Id:   "dql",
-			Name: "Deep Q-Learning",
-		},
-	}
-
 	t.Run("StartServer() -- Happy Path", testStartServerFunc())
 	t.Run("StartServer() -- Python server takes a few tries to return healthy", testStartServerHealthyLaterFunc())
 }
@@ -52,23 +30,13 @@ func TestAIEngineStartServer(t *testing.T) {
 func TestInfer(t *testing.T) {
 	t.Run("Infer() -- Server not ready", testInferServerNotReadyFunc())
 	t.Run("Infer() -- Expected url is called", testInferServerFunc())
+}
 
-	algorithmsMap = map[string]*LearningAlgorithm{
-		"dql": {
-			Id:   "dql",
-			Name: "Deep Q-Learning",
-		},
-	}
+func TestPodSyncState(t *testing.T) {
+	t.Run("PodSyncState() - Reports whether the engine has the loaded pod", testPodSyncStateFunc())
 }
 
 func TestPod(t *testing.T) {
-	algorithmsMap = map[string]*LearningAlgorithm{
-		"dql": {
-			Id:   "dql",
-			Name: "Deep Q-Learning",
-		},
-	}
-
 	manifestsToTest := []string{"trader.yaml", "trader-infer.yaml", "event-tags.yaml", "event-categories.yaml"}
 
 	for _, manifestToTest := range manifestsToTest {
@@ -96,9 +64,7 @@ func TestPod(t *testing.T) {
 
 func testInitializePod(pod *pods.Pod) func(t *testing.T) {
 	return func(t *testing.T) {
-		t.Cleanup(func() {
-			aiengineClient = nil
-		})
+		aiEngine := newTestAIEngine(t)
 
 		// Go is not deterministic with array ordering, so we account for that by sending a specified order
 		// in the initialize request. However this makes tests unstable since the order will change on each run.
@@ -135,9 +101,9 @@ func testInitializePod(pod *pods.Pod) func(t *testing.T) {
 			},
 		}
 
-		aiengineClient = mockAIEngineClient
+		aiEngine.SetAIEngineClient(mockAIEngineClient)
 
-		err := InitializePod(pod)
+		err := aiEngine.InitializePod(pod)
 		if pod.Name == "trader-infer" {
 			switch err.Error() {
 			case "action 'sell' references undefined 'args.price'\n":
@@ -153,6 +119,37 @@ func testInitializePod(pod *pods.Pod) func(t *testing.T) {
 	}
 }
 
+func testPodSyncStateFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		aiEngine := newTestAIEngine(t)
+		aiEngine.SetAIEngineClient(&MockAIEngineClient{
+			InitHandler: func(c go_context.Context, ir *aiengine_pb.InitRequest, co ...grpc.CallOption) (*aiengine_pb.Response, error) {
+				return &aiengine_pb.Response{Result: "ok"}, nil
+			},
+		})
+
+		pod, err := pods.LoadPodFromManifest("../../test/assets/pods/manifests/trader.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, PodNotInitialized, aiEngine.PodSyncState(pod))
+
+		assert.NoError(t, aiEngine.InitializePod(pod))
+		assert.Equal(t, PodSynced, aiEngine.PodSyncState(pod))
+
+		defaultParams := pods.NewPodParams()
+		defaultParams.Interpolation = false
+		changedPod, err := pods.LoadPodFromManifestWithDefaults("../../test/assets/pods/manifests/trader.yaml", defaultParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, PodOutOfSync, aiEngine.PodSyncState(changedPod))
+
+		aiEngine.RemovePod(pod.Name)
+		assert.Equal(t, PodNotInitialized, aiEngine.PodSyncState(pod))
+	}
+}
+
 func testStartTrainingFunc(pod *pods.Pod, response string) func(t *testing.T) {
 	return func(t *testing.T) {
 		expectedTrainRequest := &aiengine_pb.StartTrainingRequest{
@@ -164,9 +161,7 @@ func testStartTrainingFunc(pod *pods.Pod, response string) func(t *testing.T) {
 			TrainingLoggers:   nil,
 		}
 
-		t.Cleanup(func() {
-			aiengineClient = nil
-		})
+		aiEngine := newTestAIEngine(t)
 
 		mockAIEngineClient := &MockAIEngineClient{
 			StartTrainingHandler: func(c go_context.Context, actualTrainRequest *aiengine_pb.StartTrainingRequest, co ...grpc.CallOption) (*aiengine_pb.Response, error) {
@@ -181,9 +176,9 @@ func testStartTrainingFunc(pod *pods.Pod, response string) func(t *testing.T) {
 			},
 		}
 
-		aiengineClient = mockAIEngineClient
+		aiEngine.SetAIEngineClient(mockAIEngineClient)
 
-		err := StartTraining(pod, nil)
+		err := aiEngine.StartTraining(pod, nil)
 		switch response {
 		case "already_training":
 			assert.EqualError(t, err, fmt.Sprintf("%s -> training is already in progress", pod.Name))
@@ -204,8 +199,8 @@ func testStartTrainingFunc(pod *pods.Pod, response string) func(t *testing.T) {
 
 func testInferServerNotReadyFunc() func(*testing.T) {
 	return func(t *testing.T) {
-		aiServerReady = false
-		_, err := Infer("pod_foo", 0, "tag_bar")
+		aiEngine := newTestAIEngine(t)
+		_, err := aiEngine.Infer("pod_foo", 0, "tag_bar")
 		if assert.Error(t, err) {
 			assert.Equal(t, "not ready", err.Error())
 		}
@@ -214,11 +209,8 @@ func testInferServerNotReadyFunc() func(*testing.T) {
 
 func testInferServerFunc() func(*testing.T) {
 	return func(t *testing.T) {
-		aiServerReady = true
-		t.Cleanup(func() {
-			aiServerReady = false
-			aiengineClient = nil
-		})
+		aiEngine := newTestAIEngine(t)
+		aiEngine.serverReady = true
 
 		podName := "pod_foo"
 		tagName := "tag_bar"
@@ -241,9 +233,9 @@ func testInferServerFunc() func(*testing.T) {
 			},
 		}
 
-		SetAIEngineClient(mockAIEngineClient)
+		aiEngine.SetAIEngineClient(mockAIEngineClient)
 
-		resp, err := Infer("pod_foo", 0, "tag_bar")
+		resp, err := aiEngine.Infer("pod_foo", 0, "tag_bar")
 		if assert.NoError(t, err) {
 			assert.Equal(t, "ok", resp.Response.Result)
 		}
@@ -278,15 +270,8 @@ func testPythonCmdBareMetalContextFunc() func(*testing.T) {
 
 func testStartServerFunc() func(*testing.T) {
 	return func(t *testing.T) {
-		execCommand = testutils.GetScenarioExecCommand("HAPPY_PATH")
-		t.Cleanup(func() {
-			getClient = NewAIEngineClient
-			aiengineClient = nil
-			aiServerCmd = nil
-		})
-
-		aiengineClient = nil
-		aiServerCmd = nil
+		aiEngine := newTestAIEngine(t)
+		aiEngine.execCommand = testutils.GetScenarioExecCommand("HAPPY_PATH")
 
 		mockAIEngineClient := &MockAIEngineClient{
 			GetHealthHandler: func(c go_context.Context, healthRequest *aiengine_pb.HealthRequest, co ...grpc.CallOption) (*aiengine_pb.Response, error) {
@@ -296,29 +281,31 @@ func testStartServerFunc() func(*testing.T) {
 			},
 		}
 
-		getClient = func(target string) (AIEngineClient, error) {
+		aiEngine.getClient = func(target string) (AIEngineClient, error) {
 			return mockAIEngineClient, nil
 		}
 
 		ready := make(chan bool)
-		err := StartServer(ready, false)
+		err := aiEngine.StartServer(ready, false)
 		assert.NoError(t, err)
 		<-ready
-		assert.NotNil(t, aiServerCmd)
-		actualPythonCmd := aiServerCmd.Args[3]
+		assert.NotNil(t, aiEngine.serverCmd)
+		actualPythonCmd := aiEngine.serverCmd.Args[3]
 		assert.Equal(t, filepath.Join(os.Getenv("HOME"), ".spice/bin/ai/venv/bin/python3"), actualPythonCmd)
-		actualArg := aiServerCmd.Args[4]
+		actualArg := aiEngine.serverCmd.Args[4]
 		assert.Equal(t, filepath.Join(os.Getenv("HOME"), ".spice/bin/ai/main.py"), actualArg)
+
+		assert.Error(t, aiEngine.StartServer(ready, false), "expected second start to fail")
+		assert.NoError(t, aiEngine.StopServer())
+		assert.Nil(t, aiEngine.serverRunning)
+		assert.False(t, aiEngine.ServerReady())
 	}
 }
 
 func testStartServerHealthyLaterFunc() func(*testing.T) {
 	return func(t *testing.T) {
-		execCommand = testutils.GetScenarioExecCommand("HAPPY_PATH")
-		t.Cleanup(func() {
-			getClient = NewAIEngineClient
-			aiengineClient = nil
-		})
+		aiEngine := newTestAIEngine(t)
+		aiEngine.execCommand = testutils.GetScenarioExecCommand("HAPPY_PATH")
 
 		healthyRequests := 0
 		mockAIEngineClient := &MockAIEngineClient{
@@ -335,12 +322,12 @@ func testStartServerHealthyLaterFunc() func(*testing.T) {
 			},
 		}
 
-		getClient = func(target string) (AIEngineClient, error) {
+		aiEngine.getClient = func(target string) (AIEngineClient, error) {
 			return mockAIEngineClient, nil
 		}
 
 		ready := make(chan bool)
-		err := StartServer(ready, false)
+		err := aiEngine.StartServer(ready, false)
 		assert.NoError(t, err)
 		<-ready
 	}
@@ -357,3 +344,29 @@ func testGetPodInitForTrainingFunc(pod *pods.Pod) func(*testing.T) {
 		snapshotter.SnapshotTJson(t, podInitSpec)
 	}
 }
+
+func newTestAIEngine(t *testing.T) *AIEngine {
+	rtcontext, err := context.NewContext("metal")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = rtcontext.Init(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aiEngine := NewAIEngine(rtcontext)
+	aiEngine.algorithmsMap = map[string]*LearningAlgorithm{
+		"dql": {
+			Id:   "dql",
+			Name: "Deep Q-Learning",
+		},
+	}
+
+	t.Cleanup(func() {
+		aiEngine.StopServer() //nolint
+	})
+
+	return aiEngine
+}
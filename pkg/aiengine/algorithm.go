@@ -7,8 +7,6 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
-
-	"github.com/spiceai/spiceai/pkg/context"
 )
 
 type LearningAlgorithm struct {
@@ -17,19 +15,15 @@ type LearningAlgorithm struct {
 	DocsLink string `json:"docs_link"`
 }
 
-var (
-	algorithms    []*LearningAlgorithm
-	algorithmsMap map[string]*LearningAlgorithm
-)
-
-func Algorithms() []*LearningAlgorithm {
-	return algorithms
+func (e *AIEngine) Algorithms() []*LearningAlgorithm {
+	return e.algorithms
 }
 
-func LoadAlgorithms() error {
-	algorithmsMap = make(map[string]*LearningAlgorithm)
+func (e *AIEngine) LoadAlgorithms() error {
+	var algorithms []*LearningAlgorithm
+	algorithmsMap := make(map[string]*LearningAlgorithm)
 
-	algorithmsDir := filepath.Join(context.CurrentContext().AIEngineDir(), "algorithms")
+	algorithmsDir := filepath.Join(e.rtcontext.AIEngineDir(), "algorithms")
 
 	entries, err := os.ReadDir(algorithmsDir)
 	if err != nil {
@@ -61,9 +55,12 @@ func LoadAlgorithms() error {
 		return strings.Compare(algorithms[i].Name, algorithms[j].Name) < 0
 	})
 
+	e.algorithms = algorithms
+	e.algorithmsMap = algorithmsMap
+
 	return nil
 }
 
-func GetAlgorithm(id string) *LearningAlgorithm {
-	return algorithmsMap[id]
+func (e *AIEngine) GetAlgorithm(id string) *LearningAlgorithm {
+	return e.algorithmsMap[id]
 }
@@ -7,22 +7,23 @@ import (
 	"strings"
 	"time"
 
-	"github.com/logrusorgru/aurora"
 	"github.com/spiceai/spiceai/pkg/dataspace"
+	"github.com/spiceai/spiceai/pkg/loggers"
 	"github.com/spiceai/spiceai/pkg/observations"
 	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
 	"github.com/spiceai/spiceai/pkg/state"
 	spice_time "github.com/spiceai/spiceai/pkg/time"
+	"go.uber.org/zap"
 )
 
-func SendData(pod *pods.Pod, podState ...*state.State) error {
+func (e *AIEngine) SendData(pod *pods.Pod, podState ...*state.State) error {
 	if len(podState) == 0 {
 		// Nothing to do
 		return nil
 	}
 
-	err := IsAIEngineHealthy()
+	err := e.IsAIEngineHealthy()
 	if err != nil {
 		return err
 	}
@@ -34,11 +35,11 @@ func SendData(pod *pods.Pod, podState ...*state.State) error {
 			continue
 		}
 
-		zaplog.Sugar().Debug(aurora.BrightMagenta(fmt.Sprintf("Sending data %d", len(addDataRequest.CsvData))))
+		zaplog.Debug("sending data to AI engine", loggers.Pod(pod.Name), loggers.Dataspace(s.Path()), zap.Int("bytes", len(addDataRequest.CsvData)))
 
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 		defer cancel()
-		response, err := aiengineClient.AddData(ctx, addDataRequest)
+		response, err := e.aiClient().AddData(ctx, addDataRequest)
 		if err != nil {
 			return fmt.Errorf("failed to post new data to pod %s: %w", pod.Name, err)
 		}
@@ -101,7 +102,7 @@ func getAddDataRequest(pod *pods.Pod, s *state.State) *aiengine_pb.AddDataReques
 
 	csvPreview := getData(&csv, pod.Epoch(), pod.TimeCategoryNames(), timeCategories, s.MeasurementsNames(), categories, ds.Tags(), observationData, 5)
 
-	zaplog.Sugar().Debugf("Posting data to AI engine:\n%s", aurora.BrightYellow(fmt.Sprintf("%s%s...\n%d observations posted", csv.String(), csvPreview, len(observationData))))
+	zaplog.Debug("posting data to AI engine", loggers.Pod(pod.Name), loggers.Dataspace(s.Path()), zap.Int("observations", len(observationData)), zap.String("preview", csv.String()+csvPreview+"..."))
 
 	addDataRequest := &aiengine_pb.AddDataRequest{
 		Pod:     pod.Name,
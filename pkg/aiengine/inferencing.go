@@ -8,8 +8,8 @@ import (
 	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
 )
 
-func Infer(pod string, inferenceTime int64, tag string) (*aiengine_pb.InferenceResult, error) {
-	if !ServerReady() {
+func (e *AIEngine) Infer(pod string, inferenceTime int64, tag string) (*aiengine_pb.InferenceResult, error) {
+	if !e.ServerReady() {
 		return nil, fmt.Errorf("not ready")
 	}
 
@@ -21,7 +21,7 @@ func Infer(pod string, inferenceTime int64, tag string) (*aiengine_pb.InferenceR
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	response, err := aiengineClient.GetInference(ctx, request)
+	response, err := e.aiClient().GetInference(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,6 @@
 package aiengine
 
 // Initializes the AI Engine
-func Init() error {
-	return LoadAlgorithms()
+func (e *AIEngine) Init() error {
+	return e.LoadAlgorithms()
 }
@@ -7,21 +7,22 @@ import (
 	"os"
 	"time"
 
-	"github.com/logrusorgru/aurora"
 	"github.com/spiceai/spiceai/pkg/api"
+	"github.com/spiceai/spiceai/pkg/loggers"
 	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
 	"github.com/spiceai/spiceai/pkg/proto/common_pb"
+	"go.uber.org/zap"
 )
 
-func sendInterpretations(pod *pods.Pod, indexedInterpretations *common_pb.IndexedInterpretations) error {
-	zaplog.Sugar().Debugf("Sending %d interpretations to AI engine\n", aurora.BrightYellow(len(indexedInterpretations.Interpretations)))
+func (e *AIEngine) sendInterpretations(pod *pods.Pod, indexedInterpretations *common_pb.IndexedInterpretations) error {
+	zaplog.Debug("sending interpretations to AI engine", loggers.Pod(pod.Name), zap.Int("interpretations", len(indexedInterpretations.Interpretations)))
 	if len(indexedInterpretations.Interpretations) == 0 {
 		// Nothing to do
 		return nil
 	}
 
-	err := IsAIEngineHealthy()
+	err := e.IsAIEngineHealthy()
 	if err != nil {
 		return err
 	}
@@ -34,7 +35,7 @@ func sendInterpretations(pod *pods.Pod, indexedInterpretations *common_pb.Indexe
 	startTime := time.Now()
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	response, err := aiengineClient.AddInterpretations(ctx, addInterpretationsRequest)
+	response, err := e.aiClient().AddInterpretations(ctx, addInterpretationsRequest)
 	if err != nil {
 		return fmt.Errorf("failed to post new interpretations to pod %s: %w", pod.Name, err)
 	}
@@ -44,7 +45,7 @@ func sendInterpretations(pod *pods.Pod, indexedInterpretations *common_pb.Indexe
 	}
 
 	duration := time.Since(startTime)
-	zaplog.Sugar().Debugf("Sent %d interpretations to AI engine in %.2f seconds\n", len(indexedInterpretations.Interpretations), duration.Seconds())
+	zaplog.Debug("sent interpretations to AI engine", loggers.Pod(pod.Name), zap.Int("interpretations", len(indexedInterpretations.Interpretations)), zap.Duration("duration", duration))
 
 	return nil
 }
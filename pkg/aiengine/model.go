@@ -8,12 +8,36 @@ import (
 
 	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
+	"github.com/spiceai/spiceai/pkg/tempdir"
+	"github.com/spiceai/spiceai/pkg/util"
 )
 
-func importModel(pod *pods.Pod, tag string) error {
+func (e *AIEngine) importModel(pod *pods.Pod, tag string) error {
+	return e.importModelFromDir(pod, filepath.Dir(pod.ManifestPath()), tag)
+}
+
+// Imports the model from an archive created by ExportPod, without re-initializing the pod
+func (e *AIEngine) ImportModelArchive(pod *pods.Pod, archivePath string, tag string) error {
+	if !e.ServerReady() {
+		return fmt.Errorf("not ready")
+	}
+
+	modelDir, err := tempdir.CreateTempDir("import")
+	if err != nil {
+		return err
+	}
+
+	err = util.ExtractZipFileToDir(archivePath, modelDir)
+	if err != nil {
+		return err
+	}
+
+	return e.importModelFromDir(pod, modelDir, tag)
+}
+
+func (e *AIEngine) importModelFromDir(pod *pods.Pod, dir string, tag string) error {
 	modelName := fmt.Sprintf("%s_train", pod.Name)
-	podDir := filepath.Dir(pod.ManifestPath())
-	modelPath := filepath.Join(podDir, modelName)
+	modelPath := filepath.Join(dir, modelName)
 
 	importRequest := &aiengine_pb.ImportModelRequest{
 		Pod:        pod.Name,
@@ -23,7 +47,7 @@ func importModel(pod *pods.Pod, tag string) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
-	response, err := aiengineClient.ImportModel(ctx, importRequest)
+	response, err := e.aiClient().ImportModel(ctx, importRequest)
 	if err != nil {
 		return err
 	}
@@ -22,9 +22,13 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-var podInitMap map[string]*aiengine_pb.InitRequest
+const (
+	PodSynced         = "synced"
+	PodOutOfSync      = "out_of_sync"
+	PodNotInitialized = "not_initialized"
+)
 
-func InitializePod(pod *pods.Pod) error {
+func (e *AIEngine) InitializePod(pod *pods.Pod) error {
 	err := pod.ValidateForTraining()
 	if err != nil {
 		return err
@@ -32,20 +36,50 @@ func InitializePod(pod *pods.Pod) error {
 
 	podInit := getPodInitForTraining(pod)
 
-	err = sendInit(podInit)
+	err = e.sendInit(podInit)
 	if err != nil {
 		return err
 	}
 
-	podInitMap[pod.Name] = podInit
+	e.podInitMutex.Lock()
+	e.podInitMap[pod.Name] = podInit
+	e.podInitMutex.Unlock()
 
 	return nil
 }
 
-func sendInit(podInit *aiengine_pb.InitRequest) error {
+// Forgets the initialization of a pod that has been removed
+func (e *AIEngine) RemovePod(podName string) {
+	e.podInitMutex.Lock()
+	defer e.podInitMutex.Unlock()
+
+	delete(e.podInitMap, podName)
+}
+
+// Returns whether the AI engine was initialized with the pod as currently loaded
+func (e *AIEngine) PodSyncState(pod *pods.Pod) string {
+	e.podInitMutex.RLock()
+	sentInit, ok := e.podInitMap[pod.Name]
+	e.podInitMutex.RUnlock()
+
+	if !ok {
+		return PodNotInitialized
+	}
+
+	podInit := getPodInitForTraining(pod)
+	// Pods without an epoch start a period before now, so the epoch moves on every call
+	podInit.EpochTime = sentInit.EpochTime
+	if !proto.Equal(podInit, sentInit) {
+		return PodOutOfSync
+	}
+
+	return PodSynced
+}
+
+func (e *AIEngine) sendInit(podInit *aiengine_pb.InitRequest) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	response, err := aiengineClient.Init(ctx, podInit)
+	response, err := e.aiClient().Init(ctx, podInit)
 	if err != nil {
 		return err
 	}
@@ -57,8 +91,9 @@ func sendInit(podInit *aiengine_pb.InitRequest) error {
 	return nil
 }
 
-func init() {
-	podInitMap = make(map[string]*aiengine_pb.InitRequest)
+// Returns the request InitializePod sends to the AI engine, without sending it
+func PodInitForTraining(pod *pods.Pod) *aiengine_pb.InitRequest {
+	return getPodInitForTraining(pod)
 }
 
 func getPodInitForTraining(pod *pods.Pod) *aiengine_pb.InitRequest {
@@ -185,19 +220,19 @@ func replaceDotNotatedFieldNames(content string, fieldNames []string) string {
 	return newContent
 }
 
-func ExportPod(podName string, tag string, request *runtime_pb.ExportModel) error {
-	if !ServerReady() {
+func (e *AIEngine) ExportPod(pod *pods.Pod, tag string, request *runtime_pb.ExportModel) error {
+	if !e.ServerReady() {
 		return fmt.Errorf("not ready")
 	}
 
 	aiRequest := &aiengine_pb.ExportModelRequest{
-		Pod: podName,
+		Pod: pod.Name,
 		Tag: tag,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	result, err := aiengineClient.ExportModel(ctx, aiRequest)
+	result, err := e.aiClient().ExportModel(ctx, aiRequest)
 	if err != nil {
 		return err
 	}
@@ -240,17 +275,14 @@ func ExportPod(podName string, tag string, request *runtime_pb.ExportModel) erro
 		}
 	}
 
-	init := podInitMap[podName]
+	e.podInitMutex.RLock()
+	init := e.podInitMap[pod.Name]
+	e.podInitMutex.RUnlock()
 	initBytes, err := proto.Marshal(init)
 	if err != nil {
 		return err
 	}
 
-	pod := pods.GetPod(podName)
-	if pod == nil {
-		return fmt.Errorf("pod not loaded")
-	}
-
 	manifestBytes, err := os.ReadFile(pod.ManifestPath())
 	if err != nil {
 		return err
@@ -272,7 +304,7 @@ func ExportPod(podName string, tag string, request *runtime_pb.ExportModel) erro
 	if err != nil {
 		return err
 	}
-	err = addBytesAsFileToZip(zipWriter, manifestBytes, fmt.Sprintf("%s.yaml", podName))
+	err = addBytesAsFileToZip(zipWriter, manifestBytes, fmt.Sprintf("%s.yaml", pod.Name))
 	if err != nil {
 		return err
 	}
@@ -280,8 +312,8 @@ func ExportPod(podName string, tag string, request *runtime_pb.ExportModel) erro
 	return nil
 }
 
-func ImportPod(pod *pods.Pod, request *runtime_pb.ImportModel) error {
-	if !ServerReady() {
+func (e *AIEngine) ImportPod(pod *pods.Pod, request *runtime_pb.ImportModel) error {
+	if !e.ServerReady() {
 		return fmt.Errorf("not ready")
 	}
 
@@ -297,7 +329,7 @@ func ImportPod(pod *pods.Pod, request *runtime_pb.ImportModel) error {
 		return err
 	}
 
-	err = sendInit(&init)
+	err = e.sendInit(&init)
 	if err != nil {
 		return err
 	}
@@ -311,11 +343,11 @@ func ImportPod(pod *pods.Pod, request *runtime_pb.ImportModel) error {
 
 	errGroup.Go(func() error {
 		podState := pod.CachedState()
-		return SendData(pod, podState...)
+		return e.SendData(pod, podState...)
 	})
 
 	errGroup.Go(func() error {
-		return importModel(pod, request.Tag)
+		return e.importModel(pod, request.Tag)
 	})
 
 	return errGroup.Wait()
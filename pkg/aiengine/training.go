@@ -3,17 +3,77 @@ package aiengine
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
-	"github.com/logrusorgru/aurora"
 	"github.com/spiceai/spiceai/pkg/flights"
+	"github.com/spiceai/spiceai/pkg/loggers"
 	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
 	"github.com/spiceai/spiceai/pkg/proto/runtime_pb"
+	"github.com/spiceai/spiceai/pkg/scheduler"
+	"go.uber.org/zap"
 )
 
-func StartTraining(pod *pods.Pod, trainModel *runtime_pb.TrainModel) error {
+// Waits for a slot from the training scheduler, then starts training
+func (e *AIEngine) StartTraining(pod *pods.Pod, trainModel *runtime_pb.TrainModel) error {
+	ticket, err := e.requestTraining(pod)
+	if err != nil {
+		return err
+	}
+
+	<-ticket.Granted()
+
+	return e.startTraining(pod, trainModel, ticket)
+}
+
+// Starts training if the scheduler has a free slot. Otherwise the training is queued to
+// start in the background and true is returned.
+func (e *AIEngine) QueueTraining(pod *pods.Pod, trainModel *runtime_pb.TrainModel) (bool, error) {
+	ticket, err := e.requestTraining(pod)
+	if err != nil {
+		return false, err
+	}
+
+	select {
+	case <-ticket.Granted():
+		return false, e.startTraining(pod, trainModel, ticket)
+	default:
+	}
+
+	zaplog.Info(loggers.Color().BrightCyan("training queued").String(), loggers.Pod(pod.Name))
+
+	go func() {
+		<-ticket.Granted()
+		err := e.startTraining(pod, trainModel, ticket)
+		if err != nil {
+			zaplog.Error("error starting training", loggers.Pod(pod.Name), zap.Error(err))
+		}
+	}()
+
+	return true, nil
+}
+
+func (e *AIEngine) requestTraining(pod *pods.Pod) (*scheduler.Ticket, error) {
+	// Hold a training slot until the flight is added so concurrent requests can't exceed the quota
+	err := pod.ReserveTraining()
+	if err != nil {
+		return nil, fmt.Errorf("%s -> %w", pod.Name, err)
+	}
+
+	return e.scheduler.Request(pod.Name, pod.TrainingPriority()), nil
+}
+
+// Starts training in the slot granted to ticket, which is released when the flight completes
+func (e *AIEngine) startTraining(pod *pods.Pod, trainModel *runtime_pb.TrainModel, ticket *scheduler.Ticket) error {
+	defer pod.ReleaseTraining()
+
+	started := false
+	defer func() {
+		if !started {
+			ticket.Release()
+		}
+	}()
+
 	if trainModel == nil {
 		// Use pod defaults
 		trainModel = &runtime_pb.TrainModel{
@@ -32,7 +92,7 @@ func StartTraining(pod *pods.Pod, trainModel *runtime_pb.TrainModel) error {
 		algorithmId = pod.LearningAlgorithm()
 	}
 
-	algorithm := GetAlgorithm(algorithmId)
+	algorithm := e.GetAlgorithm(algorithmId)
 	if algorithm == nil {
 		return fmt.Errorf("Learning algorithm %s not found", algorithmId)
 	}
@@ -42,7 +102,7 @@ func StartTraining(pod *pods.Pod, trainModel *runtime_pb.TrainModel) error {
 	}
 
 	// Once we have an AI engine -> spiced gRPC channel, this should be done on demand
-	err := sendInterpretations(pod, pod.Interpretations().IndexedInterpretations())
+	err := e.sendInterpretations(pod, pod.Interpretations().IndexedInterpretations())
 	if err != nil {
 		return err
 	}
@@ -52,18 +112,18 @@ func StartTraining(pod *pods.Pod, trainModel *runtime_pb.TrainModel) error {
 		return err
 	}
 
-	flightId := fmt.Sprintf("%d", len(*pod.Flights())+1)
+	flightId := fmt.Sprintf("%d", len(pod.Flights())+1)
 	flight, err := flights.NewFlight(flightId, trainModel.NumberEpisodes, algorithm.Id, trainModel.Loggers, podLogDir)
 	if err != nil {
 		return err
 	}
 
 	for _, loggerId := range trainModel.Loggers {
-		logger, err := flight.LoadLogger(loggerId)
+		logger, err := flight.LoadLogger(e.rtcontext, loggerId)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("%s -> Training logging to %s\n", pod.Name, logger.Name())
+		zaplog.Info("training logging to "+logger.Name(), loggers.Pod(pod.Name), loggers.Flight(flightId))
 	}
 
 	trainRequest := &aiengine_pb.StartTrainingRequest{
@@ -84,7 +144,7 @@ func StartTraining(pod *pods.Pod, trainModel *runtime_pb.TrainModel) error {
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	response, err := aiengineClient.StartTraining(ctx, trainRequest)
+	response, err := e.aiClient().StartTraining(ctx, trainRequest)
 	if err != nil {
 		return fmt.Errorf("%s -> failed to verify training has started: %w", pod.Name, err)
 	}
@@ -98,12 +158,19 @@ func StartTraining(pod *pods.Pod, trainModel *runtime_pb.TrainModel) error {
 		return fmt.Errorf("%s -> epoch time %d invalid: %s", pod.Name, pod.Epoch().Unix(), response.Message)
 	case "started_training":
 		pod.AddFlight(flightId, flight)
-		log.Println(fmt.Sprintf("%s -> %s", pod.Name, aurora.BrightCyan("Starting training...")))
+		e.addActiveFlight(flight)
+		ticket.SetFlight(flightId)
+		started = true
+		go func() {
+			<-flight.Done()
+			ticket.Release()
+		}()
+		zaplog.Info(loggers.Color().BrightCyan("starting training").String(), loggers.Pod(pod.Name), loggers.Flight(flightId))
 	default:
 		return fmt.Errorf("%s -> failed to verify training has started: %s", pod.Name, response.Result)
 	}
 
-	if !aiSingleTrainingRun {
+	if !e.singleTrainingRun {
 		return nil
 	}
 
@@ -111,3 +178,23 @@ func StartTraining(pod *pods.Pod, trainModel *runtime_pb.TrainModel) error {
 
 	return nil
 }
+
+func (e *AIEngine) addActiveFlight(flight *flights.Flight) {
+	e.activeFlightsMutex.Lock()
+	defer e.activeFlightsMutex.Unlock()
+
+	e.activeFlights = append(e.activeFlights, flight)
+}
+
+// Aborts the flights that were training when the AI engine exited, so they no longer count as active
+func (e *AIEngine) abortActiveFlights(err error) {
+	e.activeFlightsMutex.Lock()
+	defer e.activeFlightsMutex.Unlock()
+
+	for _, flight := range e.activeFlights {
+		if !flight.IsComplete() {
+			flight.Abort(err)
+		}
+	}
+	e.activeFlights = nil
+}
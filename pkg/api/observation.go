@@ -1,6 +1,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/spiceai/spiceai/pkg/observations"
 	"github.com/spiceai/spiceai/pkg/proto/common_pb"
 	"github.com/spiceai/spiceai/pkg/state"
@@ -26,12 +28,17 @@ func NewObservation(o *observations.Observation) *common_pb.Observation {
 }
 
 func NewObservationsFromState(s *state.State) []*common_pb.Observation {
+	return NewObservationsFromStateBetween(s, time.Time{}, time.Time{})
+}
+
+// Returns the state's observations from start up to but excluding end. Zero times leave that side unbounded.
+func NewObservationsFromStateBetween(s *state.State, start time.Time, end time.Time) []*common_pb.Observation {
 	identifiersNamesMap := s.IdentifiersNamesMap()
 	measurementsNameMap := s.MeasurementsNamesMap()
 	categoryNameMap := s.CategoryNamesMap()
 
 	apiObservations := []*common_pb.Observation{}
-	for _, o := range s.Observations() {
+	for _, o := range observations.Between(s.Observations(), start, end) {
 		apiIdentifiers := make(map[string]string, len(o.Identifiers))
 		for identifierName, i := range identifiersNamesMap {
 			apiIdentifiers[i] = o.Identifiers[identifierName]
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"time"
+
 	"github.com/spiceai/spiceai/pkg/pods"
 )
 
@@ -13,6 +15,49 @@ type Pod struct {
 	Categories   []string `json:"categories,omitempty" csv:"-"`
 }
 
+type PodParams struct {
+	Epoch             int64  `json:"epoch"`
+	Period            string `json:"period"`
+	Interval          string `json:"interval"`
+	Granularity       string `json:"granularity"`
+	Interpolation     bool   `json:"interpolation"`
+	LearningAlgorithm string `json:"learning_algorithm"`
+	TrainingPriority  int    `json:"training_priority"`
+}
+
+type PodDataspace struct {
+	From         string            `json:"from"`
+	Name         string            `json:"name"`
+	Identifiers  []string          `json:"identifiers,omitempty"`
+	Measurements []string          `json:"measurements,omitempty"`
+	Categories   []string          `json:"categories,omitempty"`
+	Actions      map[string]string `json:"actions,omitempty"`
+	Observations int               `json:"observations"`
+}
+
+// A pod with everything needed to inspect it, returned when getting a single pod
+type PodDetails struct {
+	*Pod
+	Lifecycle    string            `json:"lifecycle"`
+	Params       *PodParams        `json:"params"`
+	Dataspaces   []*PodDataspace   `json:"dataspaces"`
+	Actions      map[string]string `json:"actions"`
+	Rewards      map[string]string `json:"rewards"`
+	LatestFlight *Flight           `json:"latest_flight,omitempty"`
+}
+
+type PodStatus struct {
+	Name              string `json:"name" csv:"name"`
+	Lifecycle         string `json:"lifecycle" csv:"lifecycle"`
+	Observations      int    `json:"observations" csv:"observations"`
+	LatestObservation int64  `json:"latest_observation,omitempty" csv:"-"`
+	// Age of the latest observation, empty if there are none
+	Staleness string `json:"staleness,omitempty" csv:"staleness"`
+	// Whether the latest observation is older than the pod's interval
+	Stale      bool   `json:"stale" csv:"stale"`
+	EngineSync string `json:"engine_sync" csv:"engine_sync"`
+}
+
 func NewPod(f *pods.Pod) *Pod {
 	return &Pod{
 		Name:         f.Name,
@@ -23,3 +68,62 @@ func NewPod(f *pods.Pod) *Pod {
 		Categories:   f.CategoryNames(),
 	}
 }
+
+func NewPodDetails(f *pods.Pod) *PodDetails {
+	dataspaces := make([]*PodDataspace, 0, len(f.Dataspaces()))
+	for _, ds := range f.Dataspaces() {
+		dataspaces = append(dataspaces, &PodDataspace{
+			From:         ds.From,
+			Name:         ds.DataspaceSpec.Name,
+			Identifiers:  ds.IdentifiersNames(),
+			Measurements: ds.MeasurementNames(),
+			Categories:   ds.CategoryNames(),
+			Actions:      ds.Actions(),
+			Observations: ds.CachedObservations(),
+		})
+	}
+
+	details := &PodDetails{
+		Pod:       NewPod(f),
+		Lifecycle: f.Lifecycle(),
+		Params: &PodParams{
+			Epoch:             f.Epoch().Unix(),
+			Period:            f.Period().String(),
+			Interval:          f.Interval().String(),
+			Granularity:       f.Granularity().String(),
+			Interpolation:     f.Interpolation(),
+			LearningAlgorithm: f.LearningAlgorithm(),
+			TrainingPriority:  f.TrainingPriority(),
+		},
+		Dataspaces: dataspaces,
+		Actions:    f.Actions(),
+		Rewards:    f.Rewards(),
+	}
+
+	if latestFlight := f.LatestFlight(); latestFlight != nil {
+		details.LatestFlight = NewFlight(latestFlight)
+	}
+
+	return details
+}
+
+// engineSync is the pod's aiengine sync state, staleness is measured from now
+func NewPodStatus(f *pods.Pod, engineSync string, now time.Time) *PodStatus {
+	status := &PodStatus{
+		Name:         f.Name,
+		Lifecycle:    f.Lifecycle(),
+		Observations: f.CachedObservations(),
+		Stale:        true,
+		EngineSync:   engineSync,
+	}
+
+	latest := f.LatestObservationTime()
+	if !latest.IsZero() {
+		staleness := now.Sub(latest).Truncate(time.Second)
+		status.LatestObservation = latest.Unix()
+		status.Staleness = staleness.String()
+		status.Stale = staleness > f.Interval()
+	}
+
+	return status
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spiceai/spiceai/pkg/pods"
+	"github.com/spiceai/spiceai/pkg/quotas"
+	"github.com/spiceai/spiceai/pkg/spec"
+)
+
+type QuotaUsage struct {
+	CachedObservations int `json:"cached_observations"`
+	Interpretations    int `json:"interpretations,omitempty"`
+	ActiveTrainings    int `json:"active_trainings,omitempty"`
+}
+
+type Quotas struct {
+	Limits     spec.QuotasSpec   `json:"limits"`
+	Usage      QuotaUsage        `json:"usage"`
+	Rejections map[string]uint64 `json:"rejections"`
+}
+
+type PodQuotas struct {
+	Quotas
+	Dataspaces map[string]*Quotas `json:"dataspaces,omitempty"`
+}
+
+func NewPodQuotas(pod *pods.Pod) *PodQuotas {
+	podQuotas := &PodQuotas{
+		Quotas: newQuotas(pod.Quotas(), QuotaUsage{
+			CachedObservations: pod.CachedObservations(),
+			Interpretations:    len(pod.Interpretations().All()),
+			ActiveTrainings:    pod.ActiveTrainings(),
+		}),
+		Dataspaces: make(map[string]*Quotas),
+	}
+
+	for _, ds := range pod.Dataspaces() {
+		dsQuotas := newQuotas(ds.Quotas(), QuotaUsage{
+			CachedObservations: ds.CachedObservations(),
+		})
+		podQuotas.Dataspaces[ds.Path()] = &dsQuotas
+	}
+
+	return podQuotas
+}
+
+func newQuotas(q *quotas.Quotas, usage QuotaUsage) Quotas {
+	return Quotas{
+		Limits:     q.Limits(),
+		Usage:      usage,
+		Rejections: q.Rejections(),
+	}
+}
+
+// Writes quota usage and rejections of every pod in the Prometheus text format
+func WriteQuotaMetrics(w io.Writer, loadedPods map[string]*pods.Pod) error {
+	podNames := make([]string, 0, len(loadedPods))
+	for name := range loadedPods {
+		podNames = append(podNames, name)
+	}
+	sort.Strings(podNames)
+
+	cached := []string{"# TYPE spice_quota_cached_observations gauge\n"}
+	rejections := []string{"# TYPE spice_quota_rejections_total counter\n"}
+	addMetrics := func(labels string, q *Quotas) {
+		cached = append(cached, fmt.Sprintf("spice_quota_cached_observations{%s} %d\n", labels, q.Usage.CachedObservations))
+		for _, quota := range sortedKeys(q.Rejections) {
+			rejections = append(rejections, fmt.Sprintf("spice_quota_rejections_total{%s,quota=%q} %d\n", labels, quota, q.Rejections[quota]))
+		}
+	}
+
+	for _, name := range podNames {
+		podQuotas := NewPodQuotas(loadedPods[name])
+		addMetrics(fmt.Sprintf("pod=%q", name), &podQuotas.Quotas)
+
+		dataspacePaths := make([]string, 0, len(podQuotas.Dataspaces))
+		for path := range podQuotas.Dataspaces {
+			dataspacePaths = append(dataspacePaths, path)
+		}
+		sort.Strings(dataspacePaths)
+		for _, path := range dataspacePaths {
+			addMetrics(fmt.Sprintf("pod=%q,dataspace=%q", name, path), podQuotas.Dataspaces[path])
+		}
+	}
+
+	for _, metric := range append(cached, rejections...) {
+		if _, err := io.WriteString(w, metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/spiceai/spiceai/pkg/scheduler"
+)
+
+type TrainingSlot struct {
+	Pod         string `json:"pod"`
+	Priority    int    `json:"priority"`
+	Flight      string `json:"flight,omitempty"`
+	RequestedAt int64  `json:"requested_at"`
+	StartedAt   int64  `json:"started_at,omitempty"`
+}
+
+type TrainingQueue struct {
+	MaxConcurrent int             `json:"max_concurrent"`
+	Active        []*TrainingSlot `json:"active"`
+	Queued        []*TrainingSlot `json:"queued"`
+}
+
+func NewTrainingQueue(status scheduler.Status) *TrainingQueue {
+	queue := &TrainingQueue{
+		MaxConcurrent: status.MaxConcurrent,
+		Active:        make([]*TrainingSlot, 0, len(status.Active)),
+		Queued:        make([]*TrainingSlot, 0, len(status.Queued)),
+	}
+	for _, t := range status.Active {
+		queue.Active = append(queue.Active, newTrainingSlot(t))
+	}
+	for _, t := range status.Queued {
+		queue.Queued = append(queue.Queued, newTrainingSlot(t))
+	}
+	return queue
+}
+
+func newTrainingSlot(t scheduler.TicketStatus) *TrainingSlot {
+	slot := &TrainingSlot{
+		Pod:         t.Pod,
+		Priority:    t.Priority,
+		Flight:      t.Flight,
+		RequestedAt: t.RequestedAt.Unix(),
+	}
+	if !t.GrantedAt.IsZero() {
+		slot.StartedAt = t.GrantedAt.Unix()
+	}
+	return slot
+}
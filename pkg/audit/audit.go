@@ -0,0 +1,197 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spiceai/spiceai/pkg/constants"
+)
+
+const (
+	FileName = "audit.jsonl"
+
+	SourceAPI     = "api"
+	SourceCLI     = "cli"
+	SourceWatcher = "watcher"
+
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Who made a change. API callers are identified by address, user agent and a fingerprint of their bearer
+// token, CLI callers by their OS user.
+type Caller struct {
+	User      string `json:"user,omitempty"`
+	Address   string `json:"address,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	Token     string `json:"token,omitempty"`
+}
+
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Caller  *Caller   `json:"caller,omitempty"`
+	Action  string    `json:"action"`
+	Route   string    `json:"route,omitempty"`
+	Pod     string    `json:"pod,omitempty"`
+	Status  int       `json:"status,omitempty"`
+	Outcome string    `json:"outcome"`
+	Error   string    `json:"error,omitempty"`
+	// Set for manifest changes
+	Manifest string `json:"manifest,omitempty"`
+	OldHash  string `json:"old_hash,omitempty"`
+	NewHash  string `json:"new_hash,omitempty"`
+}
+
+type Query struct {
+	// Only entries at or after Since and before Until, if set
+	Since time.Time
+	Until time.Time
+	// Only entries about this pod, if set
+	Pod string
+}
+
+// Log appends entries to a JSON lines file, which is only ever appended to. A nil *Log records nothing.
+type Log struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// Returns the path of the audit log of the app in appDir
+func LogPath(appDir string) string {
+	return filepath.Join(appDir, constants.DotSpice, FileName)
+}
+
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+func (l *Log) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// Appends the entry, setting its time if it isn't set
+func (l *Log) Record(entry *Entry) error {
+	if l == nil {
+		return nil
+	}
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	if entry.Outcome == "" {
+		entry.Outcome = OutcomeSuccess
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	err = os.MkdirAll(filepath.Dir(l.path), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	// The CLI and runtime append to the same file, so it is opened for each entry rather than held open
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(data)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the entries matching the query, oldest first
+func (l *Log) Query(query *Query) ([]*Entry, error) {
+	entries := make([]*Entry, 0)
+	if l == nil {
+		return entries, nil
+	}
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A partially written last line is skipped rather than failing the query
+			continue
+		}
+		if query.matches(&entry) {
+			entries = append(entries, &entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (query *Query) matches(entry *Entry) bool {
+	if !query.Since.IsZero() && entry.Time.Before(query.Since) {
+		return false
+	}
+	if !query.Until.IsZero() && !entry.Time.Before(query.Until) {
+		return false
+	}
+	return query.Pod == "" || entry.Pod == query.Pod
+}
+
+// Returns a fingerprint identifying a bearer token without revealing it
+func TokenFingerprint(token string) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// Returns the caller for changes made by the current OS user
+func CurrentUser() *Caller {
+	caller := &Caller{}
+	if u, err := user.Current(); err == nil {
+		caller.User = u.Username
+	}
+	return caller
+}
+
+// Marks the entry as failed with err, if set
+func (entry *Entry) WithError(err error) *Entry {
+	if err != nil {
+		entry.Outcome = OutcomeFailure
+		entry.Error = err.Error()
+	}
+	return entry
+}
@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	t.Run("Record() - Entries are appended as JSON lines", testRecordFunc())
+	t.Run("Query() - Entries are filtered by time and pod", testQueryFunc())
+	t.Run("Query() - A missing log has no entries", testQueryMissingFunc())
+	t.Run("TokenFingerprint() - Tokens are identified without being revealed", testTokenFingerprintFunc())
+}
+
+func testRecordFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".spice", FileName)
+		log := NewLog(path)
+
+		assert.NoError(t, log.Record(&Entry{Source: SourceCLI, Action: "action add", Pod: "trader"}))
+		assert.NoError(t, log.Record((&Entry{Source: SourceAPI, Action: "POST", Pod: "trader"}).WithError(errors.New("pod not found"))))
+
+		// A line cut off by a crash mid-write doesn't hide the entries around it
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		assert.NoError(t, err)
+		_, err = f.WriteString(`{"time":"2021-`)
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+
+		entries, err := log.Query(&Query{})
+		assert.NoError(t, err)
+		if assert.Len(t, entries, 2) {
+			assert.False(t, entries[0].Time.IsZero())
+			assert.Equal(t, OutcomeSuccess, entries[0].Outcome)
+			assert.Equal(t, OutcomeFailure, entries[1].Outcome)
+			assert.Equal(t, "pod not found", entries[1].Error)
+		}
+
+		var nilLog *Log
+		assert.NoError(t, nilLog.Record(&Entry{}))
+		entries, err = nilLog.Query(&Query{})
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	}
+}
+
+func testQueryFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		log := NewLog(filepath.Join(t.TempDir(), FileName))
+
+		start := time.Date(2021, 10, 16, 0, 0, 0, 0, time.UTC)
+		for i, pod := range []string{"trader", "cartpole", "trader", ""} {
+			entry := &Entry{Time: start.Add(time.Duration(i) * time.Hour), Source: SourceAPI, Action: "POST", Pod: pod}
+			assert.NoError(t, log.Record(entry))
+		}
+
+		count := func(query *Query) int {
+			entries, err := log.Query(query)
+			assert.NoError(t, err)
+			return len(entries)
+		}
+
+		assert.Equal(t, 4, count(&Query{}))
+		assert.Equal(t, 2, count(&Query{Pod: "trader"}))
+		assert.Equal(t, 3, count(&Query{Since: start.Add(time.Hour)}))
+		assert.Equal(t, 1, count(&Query{Until: start.Add(time.Hour)}))
+		assert.Equal(t, 1, count(&Query{Since: start.Add(time.Hour), Until: start.Add(3 * time.Hour), Pod: "trader"}))
+	}
+}
+
+func testQueryMissingFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		entries, err := NewLog(filepath.Join(t.TempDir(), FileName)).Query(&Query{})
+		assert.NoError(t, err)
+		assert.NotNil(t, entries)
+		assert.Empty(t, entries)
+	}
+}
+
+func testTokenFingerprintFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		assert.Equal(t, "", TokenFingerprint(""))
+		assert.Equal(t, TokenFingerprint("secret"), TokenFingerprint("secret"))
+		assert.NotEqual(t, TokenFingerprint("secret"), TokenFingerprint("other"))
+		assert.NotContains(t, TokenFingerprint("secret"), "secret")
+		assert.Regexp(t, `^sha256:[0-9a-f]{12}$`, TokenFingerprint("secret"))
+	}
+}
@@ -7,8 +7,14 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/spec"
-	"github.com/spiceai/spiceai/pkg/util"
-	"gopkg.in/yaml.v2"
+)
+
+var (
+	actionPod       string
+	actionDo        string
+	actionArgs      map[string]string
+	actionDataspace string
+	actionScript    string
 )
 
 var actionCmd = &cobra.Command{
@@ -23,48 +29,67 @@ spice action add jump
 var actionAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add Action - adds an action to the pod",
+	Long: `Adds an action to the pod. Comments and formatting in the pod manifest are kept.
+
+With --do the action runs a dataspace action, passing it --arg values. With --dataspace the action is
+added to the dataspace's actions with the given --script, and is available to the pod under its own name.`,
 	Example: `
 spice action add <>
 spice action add jump
+spice action add hold --pod trader
+spice action add buy --pod trader --do local.portfolio.buy --arg price=coinbase.btcusd.close
+spice action add buy --pod trader --dataspace local/portfolio --script "usd_balance -= args.price"
 `,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		cmdActionName := args[0]
 
-		manifests := pods.FindAllManifestPaths()
-		if len(manifests) == 0 {
-			cmd.Println("No pods detected!")
+		err := validateActionFlags()
+		if err != nil {
+			cmd.Println(err.Error())
 			return
 		}
 
-		podPath := manifests[0]
-
-		pod, err := pods.LoadPodFromManifest(podPath)
+		pod, err := selectPod(actionPod)
 		if err != nil {
 			cmd.Println(err.Error())
 			return
 		}
 
-		actions := pod.Actions()
+		manifest, err := pods.LoadManifest(pod.ManifestPath())
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
 
-		if _, ok := actions[cmdActionName]; ok {
-			cmd.Printf("Action %s already exists in %s. Overwrite? (y/n)\n", cmdActionName, pod.Name)
-			var confirm string
-			fmt.Scanf("%s", &confirm)
-			if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		if actionDataspace != "" {
+			from, name := splitDataspacePath(actionDataspace)
+			if manifest.HasDataspaceAction(from, name, cmdActionName) && !confirmOverwrite(cmd, fmt.Sprintf("Action %s already exists in dataspace %s of %s.", cmdActionName, actionDataspace, pod.Name)) {
+				return
+			}
+			script := actionScript
+			if strings.Contains(script, "\n") && !strings.HasSuffix(script, "\n") {
+				// Kept as a plain literal block like hand-written multi-line actions
+				script += "\n"
+			}
+			err = manifest.SetDataspaceAction(from, name, cmdActionName, script)
+		} else {
+			if manifest.HasAction(cmdActionName) && !confirmOverwrite(cmd, fmt.Sprintf("Action %s already exists in %s.", cmdActionName, pod.Name)) {
 				return
 			}
+			action := spec.PodActionSpec{Name: cmdActionName}
+			if actionDo != "" {
+				action.Do = &spec.DoSpec{Name: actionDo, Args: actionArgs}
+			}
+			err = manifest.SetAction(action)
 		}
-
-		pod.PodSpec.Actions = append(pod.PodSpec.Actions, spec.PodActionSpec{Name: cmdActionName})
-
-		marshalledPod, err := yaml.Marshal(pod.PodSpec)
 		if err != nil {
 			cmd.Println(err.Error())
 			return
 		}
 
-		err = util.WriteToExistingFile(podPath, marshalledPod)
+		err = manifest.Save()
+		recordManifestEdit(cmd, "action add", pod, err)
 		if err != nil {
 			cmd.Println(err.Error())
 			return
@@ -74,7 +99,47 @@ spice action add jump
 	},
 }
 
+func validateActionFlags() error {
+	if actionDo != "" && actionDataspace != "" {
+		return fmt.Errorf("--do and --dataspace cannot be used together")
+	}
+	if len(actionArgs) > 0 && actionDo == "" {
+		return fmt.Errorf("--arg requires --do")
+	}
+	if actionDataspace != "" {
+		if from, name := splitDataspacePath(actionDataspace); from == "" || name == "" {
+			return fmt.Errorf("invalid --dataspace %s, expected <from>/<name>", actionDataspace)
+		}
+		if strings.TrimSpace(actionScript) == "" {
+			return fmt.Errorf("--dataspace requires the action's --script")
+		}
+	} else if actionScript != "" {
+		return fmt.Errorf("--script requires --dataspace")
+	}
+	return nil
+}
+
+func splitDataspacePath(path string) (string, string) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+func confirmOverwrite(cmd *cobra.Command, message string) bool {
+	cmd.Printf("%s Overwrite? (y/n)\n", message)
+	var confirm string
+	fmt.Scanf("%s", &confirm)
+	return strings.ToLower(strings.TrimSpace(confirm)) == "y"
+}
+
 func init() {
+	actionAddCmd.Flags().StringVar(&actionPod, "pod", "", "Pod to add the action to. Required when the app has more than one pod.")
+	actionAddCmd.Flags().StringVar(&actionDo, "do", "", "Dataspace action the action runs, e.g. local.portfolio.buy")
+	actionAddCmd.Flags().StringToStringVar(&actionArgs, "arg", nil, "Argument passed to the --do action as <name>=<value>. May be repeated.")
+	actionAddCmd.Flags().StringVar(&actionDataspace, "dataspace", "", "Add the action to the dataspace <from>/<name> instead of the pod")
+	actionAddCmd.Flags().StringVar(&actionScript, "script", "", "Script the --dataspace action runs")
 	actionCmd.AddCommand(actionAddCmd)
 	actionCmd.Flags().BoolP("help", "h", false, "Print this help message")
 	RootCmd.AddCommand(actionCmd)
@@ -2,40 +2,80 @@ package cmd
 
 import (
 	"errors"
+	"os"
+	"sort"
 
 	"github.com/spf13/cobra"
-	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/registry"
 	"github.com/spiceai/spiceai/pkg/util"
 )
 
+var (
+	addFrozen bool
+)
+
 var addCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add Pod - adds a pod to the project",
-	Args:  cobra.MinimumNArgs(1),
+	Args:  cobra.MaximumNArgs(1),
+	Long: `Adds a pod from the pod registry, a local directory, a local .zip or .tar.gz archive of a pod directory, or a
+git repository. A registry pod's latest version is added unless a version is given with @<version>.
+
+Git repositories are given as git+<file:// or https:// URL>, followed by #<path> if the pod isn't at the root of
+the repository. A git ref, such as a tag, branch or commit, can be given with @<ref>.
+
+The source, version and content hash of each added pod are recorded in spicepods.lock. With --frozen, pods are
+added at the version in spicepods.lock instead, and rejected if their content doesn't match it. Without a pod,
+--frozen adds every pod in spicepods.lock.`,
 	Example: `
 spice add samples/LogPruner
+spice add samples/LogPruner@0.2.0
+spice add samples/LogPruner --registry-url https://pods.example.com/api
+spice add ./trader
+spice add ./trader.tar.gz
+spice add git+https://github.com/example/pods.git#trader@v1.0.0
+spice add --frozen
+spice add samples/LogPruner --frozen
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		podPath := args[0]
+		lockfile, err := loadLockfile()
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(1)
+		}
 
-		cmd.Printf("Getting Pod %s ...\n", podPath)
+		if addFrozen {
+			addFrozenPods(cmd, lockfile, args)
+			return
+		}
+
+		if len(args) == 0 {
+			cmd.Println("Specify a pod to add, or --frozen to add the pods in spicepods.lock.")
+			return
+		}
 
-		r := registry.GetRegistry(podPath)
-		downloadPath, err := r.GetPod(podPath)
+		podPath, podVersion := registry.ParsePodReference(args[0])
+
+		cmd.Printf("Getting Pod %s ...\n", args[0])
+
+		fetched, err := registry.Install(rtcontext.PodsDir(), podPath, podVersion, nil, registryOptions("")...)
 		if err != nil {
 			var itemNotFound *registry.RegistryItemNotFound
 			if errors.As(err, &itemNotFound) {
-				cmd.Printf("No pod found with the name '%s'.\n", podPath)
+				cmd.Printf("No pod found with the name '%s'.\n", args[0])
 			} else {
 				cmd.Println(err)
 			}
 			return
 		}
 
-		relativePath := context.CurrentContext().GetSpiceAppRelativePath(downloadPath)
+		lockfile.Set(podPath, fetched)
+		err = lockfile.Save()
+		if err != nil {
+			cmd.Printf("failed to update %s: %s\n", registry.LockfileName, err.Error())
+		}
 
-		cmd.Printf("Added %s\n", relativePath)
+		printAdded(cmd, fetched)
 
 		err = checkLatestCliReleaseVersion()
 		if err != nil && util.IsDebug() {
@@ -44,7 +84,61 @@ spice add samples/LogPruner
 	},
 }
 
+// Adds the locked pods, or only the pod in args, at their locked version, exiting if any doesn't match the lock
+func addFrozenPods(cmd *cobra.Command, lockfile *registry.Lockfile, args []string) {
+	var podPaths []string
+	if len(args) > 0 {
+		podPath, podVersion := registry.ParsePodReference(args[0])
+		locked := lockfile.Get(podPath)
+		if locked == nil {
+			cmd.Printf("Pod %s isn't in %s. Add it without --frozen to lock it.\n", podPath, registry.LockfileName)
+			os.Exit(1)
+		}
+		if podVersion != "" && podVersion != locked.Version {
+			cmd.Printf("Pod %s is locked at version %s, not %s.\n", podPath, locked.Version, podVersion)
+			os.Exit(1)
+		}
+		podPaths = append(podPaths, podPath)
+	} else {
+		for podPath := range lockfile.Pods {
+			podPaths = append(podPaths, podPath)
+		}
+		sort.Strings(podPaths)
+	}
+
+	if len(podPaths) == 0 {
+		cmd.Printf("No pods in %s.\n", registry.LockfileName)
+		return
+	}
+
+	for _, podPath := range podPaths {
+		locked := lockfile.Get(podPath)
+
+		cmd.Printf("Getting Pod %s ...\n", podPath)
+
+		fetched, err := registry.Install(rtcontext.PodsDir(), podPath, locked.Version, func(staged *registry.FetchedPod) error {
+			return locked.Verify(podPath, staged)
+		}, registryOptions(locked.Source)...)
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(1)
+		}
+
+		printAdded(cmd, fetched)
+	}
+}
+
+func printAdded(cmd *cobra.Command, fetched *registry.FetchedPod) {
+	relativePath := rtcontext.GetSpiceAppRelativePath(fetched.ManifestPath)
+	if fetched.Version != "" {
+		cmd.Printf("Added %s at version %s\n", relativePath, fetched.Version)
+		return
+	}
+	cmd.Printf("Added %s\n", relativePath)
+}
+
 func init() {
+	addCmd.Flags().BoolVar(&addFrozen, "frozen", false, "Adds pods at the version in spicepods.lock, failing if their content doesn't match it")
 	addCmd.Flags().BoolP("help", "h", false, "Print this help message")
 	RootCmd.AddCommand(addCmd)
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiceai/spiceai/pkg/config"
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Runtime configuration",
+	Example: `
+spice config show
+`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows the effective runtime configuration and where each value came from",
+	Example: `
+spice config show
+spice config show --http-port 8001
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		v := viper.New()
+		err := config.BindFlags(v, cmd.Flags())
+		if err != nil {
+			cmd.Printf("failed to load runtime configuration: %s\n", err.Error())
+			return
+		}
+
+		runtimeConfig, err := config.LoadRuntimeConfiguration(v, rtcontext.AppDir())
+		if err != nil {
+			cmd.Printf("failed to load runtime configuration: %s\n", err.Error())
+			return
+		}
+
+		values := config.DescribeConfiguration(v, cmd.Flags(), runtimeConfig)
+		err = printOutput(cmd, values, func() error {
+			return util.MarshalAndPrintTable(cmd.OutOrStdout(), values)
+		})
+		if err != nil {
+			cmd.Printf("failed to print runtime configuration: %s\n", err.Error())
+			return
+		}
+	},
+}
+
+func init() {
+	config.AddFlags(configShowCmd.Flags())
+	configCmd.AddCommand(configShowCmd)
+	configCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(configCmd)
+}
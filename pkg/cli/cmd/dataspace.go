@@ -0,0 +1,650 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/dataspace"
+	"github.com/spiceai/spiceai/pkg/pods"
+	"github.com/spiceai/spiceai/pkg/spec"
+	"github.com/spiceai/spiceai/pkg/validator"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	dataspacePod             string
+	dataspaceFrom            string
+	dataspaceName            string
+	dataspaceConnector       string
+	dataspaceConnectorParams []string
+	dataspaceProcessor       string
+	dataspaceProcessorParams []string
+	dataspaceMeasurements    []string
+	dataspaceFills           []string
+	dataspaceCategories      []string
+	dataspaceCategoryValues  []string
+	dataspaceIdentifiers     []string
+	dataspaceTagSelectors    []string
+	dataspaceInteractive     bool
+)
+
+var dataspaceCmd = &cobra.Command{
+	Use:     "dataspace",
+	Aliases: []string{"dataspaces"},
+	Short:   "Maintains dataspaces",
+	Example: `
+spice dataspace add
+spice dataspace components
+`,
+}
+
+var dataspaceAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add Dataspace - adds a dataspace to the pod",
+	Long: `Adds a dataspace to the pod. Comments and formatting in the pod manifest are kept.
+
+Without --from and --name, or with --interactive, the dataspace is built from prompts that use any other
+flags as defaults. Otherwise it is built from flags alone. Fields are given as <name> or <name>=<selector>.
+Run 'spice dataspace components' for the data connectors and processors and their params.`,
+	Example: `
+spice dataspace add
+spice dataspace add --pod trader --interactive
+spice dataspace add --pod trader --from coinbase --name btcusd \
+  --connector coinbase --connector-param product_ids=BTC-USD --processor json \
+  --measurement close=price --fill close=previous
+spice dataspace add --from event --name stream --connector file --connector-param path=data/events.csv \
+  --processor csv --measurement height --category target --category-value target=local \
+  --category-value target=remote --tag-selector tags
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pod, err := selectPod(dataspacePod)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		manifest, err := pods.LoadManifest(pod.ManifestPath())
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		dsSpec, err := dataspaceSpecFromFlags()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		var p *prompter
+		if dataspaceInteractive || (dataspaceFrom == "" && dataspaceName == "") {
+			if !dataspaceInteractive && !isTerminal(os.Stdin) {
+				cmd.Println("--from and --name are required when not run interactively")
+				return
+			}
+			p = newPrompter(cmd)
+			err = promptDataspaceSpec(p, manifest, dsSpec)
+			if err != nil {
+				cmd.Println(err.Error())
+				return
+			}
+		}
+
+		err = validateDataspaceSpec(dsSpec)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if manifest.HasDataspace(dsSpec.From, dsSpec.Name) {
+			cmd.Printf("Dataspace %s/%s already exists in pod %s.\n", dsSpec.From, dsSpec.Name, pod.Name)
+			return
+		}
+
+		if p != nil {
+			data, err := marshalDataspaceSpec(dsSpec)
+			if err != nil {
+				cmd.Println(err.Error())
+				return
+			}
+			cmd.Printf("\n%s\n", data)
+			confirm, err := p.ask(fmt.Sprintf("Add this dataspace to pod %s? (y/n)", pod.Name), "y")
+			if err != nil || strings.ToLower(confirm) != "y" {
+				return
+			}
+		}
+
+		err = manifest.AddDataspace(*dsSpec)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = manifest.Save()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		cmd.Printf("Dataspace %s/%s added to pod %s.\n", dsSpec.From, dsSpec.Name, pod.Name)
+	},
+}
+
+var dataspaceComponentsCmd = &cobra.Command{
+	Use:   "components",
+	Short: "Lists the data connectors and processors dataspaces can use, with their params",
+	Example: `
+spice dataspace components
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		components := map[string][]dataspace.Component{
+			"connectors": dataspace.DataConnectors(),
+			"processors": dataspace.DataProcessors(),
+		}
+		err := printOutput(cmd, components, func() error {
+			printComponents(cmd.OutOrStdout(), "Data connectors", dataspace.DataConnectors())
+			cmd.Println()
+			printComponents(cmd.OutOrStdout(), "Data processors", dataspace.DataProcessors())
+			return nil
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+		}
+	},
+}
+
+// Builds a dataspace from the add flags without validating it, so prompts can use it as defaults
+func dataspaceSpecFromFlags() (*spec.DataspaceSpec, error) {
+	dsSpec := &spec.DataspaceSpec{From: dataspaceFrom, Name: dataspaceName}
+
+	connectorParams, err := parseKeyValues("--connector-param", dataspaceConnectorParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(connectorParams) > 0 && dataspaceConnector == "" {
+		return nil, fmt.Errorf("--connector-param requires --connector")
+	}
+	processorParams, err := parseKeyValues("--processor-param", dataspaceProcessorParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(processorParams) > 0 && dataspaceProcessor == "" {
+		return nil, fmt.Errorf("--processor-param requires --processor")
+	}
+	if dataspaceConnector != "" || dataspaceProcessor != "" {
+		dsSpec.Data = &spec.DataSpec{
+			Connector: spec.DataConnectorSpec{Name: dataspaceConnector, Params: connectorParams},
+			Processor: spec.DataProcessorSpec{Name: dataspaceProcessor, Params: processorParams},
+		}
+	}
+
+	for _, field := range dataspaceMeasurements {
+		name, selector := splitFieldSelector(field)
+		dsSpec.Measurements = append(dsSpec.Measurements, spec.MeasurementSpec{Name: name, Selector: selector})
+	}
+	for _, fill := range dataspaceFills {
+		name, value, err := parseKeyValue("--fill", fill)
+		if err != nil {
+			return nil, err
+		}
+		i := findMeasurement(dsSpec.Measurements, name)
+		if i < 0 {
+			return nil, fmt.Errorf("--fill %s: measurement %s is not added with --measurement", fill, name)
+		}
+		dsSpec.Measurements[i].Fill = value
+	}
+
+	for _, field := range dataspaceCategories {
+		name, selector := splitFieldSelector(field)
+		dsSpec.Categories = append(dsSpec.Categories, spec.CategorySpec{Name: name, Selector: selector})
+	}
+	for _, categoryValue := range dataspaceCategoryValues {
+		name, value, err := parseKeyValue("--category-value", categoryValue)
+		if err != nil {
+			return nil, err
+		}
+		i := findCategory(dsSpec.Categories, name)
+		if i < 0 {
+			return nil, fmt.Errorf("--category-value %s: category %s is not added with --category", categoryValue, name)
+		}
+		dsSpec.Categories[i].Values = append(dsSpec.Categories[i].Values, value)
+	}
+
+	for _, field := range dataspaceIdentifiers {
+		name, selector := splitFieldSelector(field)
+		dsSpec.Identifiers = append(dsSpec.Identifiers, spec.IdentifiersSpec{Name: name, Selector: selector})
+	}
+
+	if len(dataspaceTagSelectors) > 0 {
+		dsSpec.Tags = &spec.TagsSpec{Selectors: dataspaceTagSelectors}
+	}
+
+	return dsSpec, nil
+}
+
+// Prompts for each part of the dataspace, keeping what is already set as defaults
+func promptDataspaceSpec(p *prompter, manifest *pods.Manifest, dsSpec *spec.DataspaceSpec) error {
+	var err error
+
+	dsSpec.From, err = p.askValid("From", dsSpec.From, func(value string) error {
+		return validateDataspaceNamePart("from", value)
+	})
+	if err != nil {
+		return err
+	}
+	dsSpec.Name, err = p.askValid("Name", dsSpec.Name, func(value string) error {
+		if err := validateDataspaceNamePart("name", value); err != nil {
+			return err
+		}
+		if manifest.HasDataspace(dsSpec.From, value) {
+			return fmt.Errorf("dataspace %s/%s already exists", dsSpec.From, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	data := dsSpec.Data
+	if data == nil {
+		data = &spec.DataSpec{}
+	}
+
+	p.cmd.Println()
+	printComponents(p.cmd.OutOrStdout(), "Data connectors", dataspace.DataConnectors())
+	data.Connector.Name, err = p.askValid("Data connector (empty for none)", data.Connector.Name, func(value string) error {
+		if value == "" {
+			return nil
+		}
+		_, err := dataspace.FindDataConnector(value)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if data.Connector.Name != "" {
+		connector, _ := dataspace.FindDataConnector(data.Connector.Name)
+		data.Connector.Params, err = promptParams(p, connector, data.Connector.Params)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.cmd.Println()
+	printComponents(p.cmd.OutOrStdout(), "Data processors", dataspace.DataProcessors())
+	processorLabel := "Data processor"
+	if data.Connector.Name == "" {
+		processorLabel = "Data processor (empty for none)"
+	}
+	data.Processor.Name, err = p.askValid(processorLabel, data.Processor.Name, func(value string) error {
+		if value == "" && data.Connector.Name == "" {
+			return nil
+		}
+		_, err := dataspace.FindDataProcessor(value)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if data.Processor.Name != "" {
+		processor, _ := dataspace.FindDataProcessor(data.Processor.Name)
+		data.Processor.Params, err = promptParams(p, processor, data.Processor.Params)
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.Connector.Name != "" || data.Processor.Name != "" {
+		dsSpec.Data = data
+	} else {
+		dsSpec.Data = nil
+	}
+
+	p.cmd.Println()
+	for {
+		name, selector, err := promptField(p, "Measurement", measurementNames(dsSpec.Measurements))
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			break
+		}
+		fill, err := p.askValid("  Fill: previous or none (empty for previous)", "", validateFill)
+		if err != nil {
+			return err
+		}
+		dsSpec.Measurements = append(dsSpec.Measurements, spec.MeasurementSpec{Name: name, Selector: selector, Fill: fill})
+	}
+
+	for {
+		name, selector, err := promptField(p, "Category", categoryNames(dsSpec.Categories))
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			break
+		}
+		values, err := p.askValid("  Values (comma-separated)", "", func(value string) error {
+			if len(splitList(value)) == 0 {
+				return fmt.Errorf("category %s requires at least one value", name)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		dsSpec.Categories = append(dsSpec.Categories, spec.CategorySpec{Name: name, Selector: selector, Values: splitList(values)})
+	}
+
+	for {
+		name, selector, err := promptField(p, "Identifier", identifierNames(dsSpec.Identifiers))
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			break
+		}
+		dsSpec.Identifiers = append(dsSpec.Identifiers, spec.IdentifiersSpec{Name: name, Selector: selector})
+	}
+
+	var tagSelectors []string
+	if dsSpec.Tags != nil {
+		tagSelectors = dsSpec.Tags.Selectors
+	}
+	selectors, err := p.ask("Tag selectors (comma-separated, empty for none)", strings.Join(tagSelectors, ","))
+	if err != nil {
+		return err
+	}
+	if tagSelectors = splitList(selectors); len(tagSelectors) > 0 {
+		dsSpec.Tags = &spec.TagsSpec{Selectors: tagSelectors}
+	} else {
+		dsSpec.Tags = nil
+	}
+
+	return nil
+}
+
+// Prompts for a field's name and selector. An empty name means no more fields.
+func promptField(p *prompter, kind string, existing []string) (string, string, error) {
+	label := fmt.Sprintf("%s name (empty to finish)", kind)
+	if len(existing) > 0 {
+		label = fmt.Sprintf("%s name, in addition to %s (empty to finish)", kind, strings.Join(existing, ", "))
+	}
+	name, err := p.askValid(label, "", func(value string) error {
+		if value == "" {
+			return nil
+		}
+		if containsString(existing, value) {
+			return fmt.Errorf("%s %s was already added", strings.ToLower(kind), value)
+		}
+		return validateFieldName(strings.ToLower(kind), value)
+	})
+	if err != nil || name == "" {
+		return "", "", err
+	}
+
+	selector, err := p.ask("  Selector", name)
+	if err != nil {
+		return "", "", err
+	}
+	if selector == name {
+		selector = ""
+	}
+
+	return name, selector, nil
+}
+
+func promptParams(p *prompter, component *dataspace.Component, defaults map[string]string) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, param := range component.Params {
+		label := fmt.Sprintf("  %s: %s", param.Name, param.Description)
+		if !param.Required {
+			label += " (optional)"
+		}
+		value, err := p.askValid(label, defaults[param.Name], func(value string) error {
+			if param.Required && value == "" {
+				return fmt.Errorf("%s requires the '%s' param", component.Name, param.Name)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			params[param.Name] = value
+		}
+	}
+	if len(params) == 0 {
+		return nil, nil
+	}
+	return params, nil
+}
+
+func validateDataspaceSpec(dsSpec *spec.DataspaceSpec) error {
+	if err := validateDataspaceNamePart("from", dsSpec.From); err != nil {
+		return err
+	}
+	if err := validateDataspaceNamePart("name", dsSpec.Name); err != nil {
+		return err
+	}
+
+	if dsSpec.Data != nil {
+		if dsSpec.Data.Connector.Name != "" {
+			connector, err := dataspace.FindDataConnector(dsSpec.Data.Connector.Name)
+			if err != nil {
+				return err
+			}
+			if err := connector.ValidateParams(dsSpec.Data.Connector.Params); err != nil {
+				return err
+			}
+			if dsSpec.Data.Processor.Name == "" {
+				return fmt.Errorf("the %s data connector requires a data processor", connector.Name)
+			}
+		}
+		processor, err := dataspace.FindDataProcessor(dsSpec.Data.Processor.Name)
+		if err != nil {
+			return err
+		}
+		if err := processor.ValidateParams(dsSpec.Data.Processor.Params); err != nil {
+			return err
+		}
+	}
+
+	if err := validateFieldNames("measurement", measurementNames(dsSpec.Measurements)); err != nil {
+		return err
+	}
+	for _, measurement := range dsSpec.Measurements {
+		if err := validateFill(measurement.Fill); err != nil {
+			return err
+		}
+	}
+
+	if err := validateFieldNames("category", categoryNames(dsSpec.Categories)); err != nil {
+		return err
+	}
+	for _, category := range dsSpec.Categories {
+		if len(category.Values) == 0 {
+			return fmt.Errorf("category %s requires at least one value", category.Name)
+		}
+	}
+
+	return validateFieldNames("identifier", identifierNames(dsSpec.Identifiers))
+}
+
+func validateDataspaceNamePart(part string, value string) error {
+	if !validator.ValidateDataspaceName(value) {
+		return fmt.Errorf("invalid dataspace \"%s\": '%s' should only contain A-Za-z0-9_", part, value)
+	}
+	return nil
+}
+
+func validateFieldNames(kind string, names []string) error {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if err := validateFieldName(kind, name); err != nil {
+			return err
+		}
+		if seen[name] {
+			return fmt.Errorf("%s %s is added more than once", kind, name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+func validateFieldName(kind string, name string) error {
+	if !validator.ValidateDataspaceName(name) {
+		return fmt.Errorf("invalid %s name '%s' should only contain A-Za-z0-9_", kind, name)
+	}
+	return nil
+}
+
+func validateFill(fill string) error {
+	switch fill {
+	case "", "previous", "none":
+		return nil
+	}
+	return fmt.Errorf("invalid measurement fill '%s': choose one of ['previous', 'none']", fill)
+}
+
+func marshalDataspaceSpec(dsSpec *spec.DataspaceSpec) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err := encoder.Encode([]spec.DataspaceSpec{*dsSpec})
+	if err != nil {
+		return "", err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func printComponents(w io.Writer, title string, components []dataspace.Component) {
+	fmt.Fprintf(w, "%s\n", aurora.Bold(title+":"))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range components {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.Name, c.Description)
+		for _, param := range c.Params {
+			required := ""
+			if param.Required {
+				required = " (required)"
+			}
+			fmt.Fprintf(tw, "\t  %s%s: %s\n", param.Name, required, param.Description)
+		}
+	}
+	tw.Flush()
+}
+
+// Splits a field given as <name> or <name>=<selector>
+func splitFieldSelector(field string) (string, string) {
+	parts := strings.SplitN(field, "=", 2)
+	if len(parts) == 1 || parts[1] == parts[0] {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func parseKeyValue(flag string, value string) (string, string, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("invalid %s %s, expected <name>=<value>", flag, value)
+	}
+	return parts[0], parts[1], nil
+}
+
+func parseKeyValues(flag string, values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string, len(values))
+	for _, value := range values {
+		k, v, err := parseKeyValue(flag, value)
+		if err != nil {
+			return nil, err
+		}
+		result[k] = v
+	}
+	return result, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func findMeasurement(measurements []spec.MeasurementSpec, name string) int {
+	for i, m := range measurements {
+		if m.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func findCategory(categories []spec.CategorySpec, name string) int {
+	for i, c := range categories {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func measurementNames(measurements []spec.MeasurementSpec) []string {
+	names := make([]string, len(measurements))
+	for i, m := range measurements {
+		names[i] = m.Name
+	}
+	return names
+}
+
+func categoryNames(categories []spec.CategorySpec) []string {
+	names := make([]string, len(categories))
+	for i, c := range categories {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func identifierNames(identifiers []spec.IdentifiersSpec) []string {
+	names := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		names[i] = identifier.Name
+	}
+	return names
+}
+
+func init() {
+	dataspaceAddCmd.Flags().StringVar(&dataspacePod, "pod", "", "Pod to add the dataspace to. Required when the app has more than one pod.")
+	dataspaceAddCmd.Flags().StringVar(&dataspaceFrom, "from", "", "Dataspace \"from\", e.g. coinbase")
+	dataspaceAddCmd.Flags().StringVar(&dataspaceName, "name", "", "Dataspace name, e.g. btcusd")
+	dataspaceAddCmd.Flags().StringVar(&dataspaceConnector, "connector", "", "Data connector the dataspace reads from")
+	dataspaceAddCmd.Flags().StringArrayVar(&dataspaceConnectorParams, "connector-param", nil, "Data connector param as <name>=<value>. May be repeated.")
+	dataspaceAddCmd.Flags().StringVar(&dataspaceProcessor, "processor", "", "Data processor that processes the connector's data")
+	dataspaceAddCmd.Flags().StringArrayVar(&dataspaceProcessorParams, "processor-param", nil, "Data processor param as <name>=<value>. May be repeated.")
+	dataspaceAddCmd.Flags().StringArrayVar(&dataspaceMeasurements, "measurement", nil, "Measurement as <name> or <name>=<selector>. May be repeated.")
+	dataspaceAddCmd.Flags().StringArrayVar(&dataspaceFills, "fill", nil, "Fill for a measurement as <name>=previous or <name>=none. May be repeated.")
+	dataspaceAddCmd.Flags().StringArrayVar(&dataspaceCategories, "category", nil, "Category as <name> or <name>=<selector>. May be repeated.")
+	dataspaceAddCmd.Flags().StringArrayVar(&dataspaceCategoryValues, "category-value", nil, "Value of a category as <name>=<value>. May be repeated.")
+	dataspaceAddCmd.Flags().StringArrayVar(&dataspaceIdentifiers, "identifier", nil, "Identifier as <name> or <name>=<selector>. May be repeated.")
+	dataspaceAddCmd.Flags().StringArrayVar(&dataspaceTagSelectors, "tag-selector", nil, "Field tags are read from. May be repeated.")
+	dataspaceAddCmd.Flags().BoolVarP(&dataspaceInteractive, "interactive", "i", false, "Prompt for the dataspace, using the other flags as defaults")
+	dataspaceCmd.AddCommand(dataspaceAddCmd)
+	dataspaceCmd.AddCommand(dataspaceComponentsCmd)
+	dataspaceCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(dataspaceCmd)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/diagnostics"
+	"github.com/spiceai/spiceai/pkg/version"
+)
+
+var (
+	diagnosticsFile      string
+	diagnosticsOverwrite bool
+)
+
+var diagnosticsCmd = &cobra.Command{
+	Use:   "diagnostics",
+	Short: "Collect diagnostics for support",
+	Example: `
+spice diagnostics bundle
+`,
+}
+
+var diagnosticsBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Collects config, manifests, logs, health, metrics and status into a zip to attach to a support ticket",
+	Long: `Collects a zip with the app's redacted config and pod manifests, manifest validation results,
+the AI engine log, recent runtime logs, health and metrics, pod and training run status, algorithms and versions.
+
+When the local runtime can't be reached, whatever can be read from the app and runtime directories is collected instead.
+Values of keys that look like credentials, such as tokens, secrets and keys, are replaced with REDACTED.`,
+	Example: `
+spice diagnostics bundle
+spice diagnostics bundle -f ./support/diagnostics.zip
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		bundlePath := diagnosticsFile
+		if bundlePath == "" {
+			bundlePath = fmt.Sprintf("spice-diagnostics-%s.zip", time.Now().Format("20060102T150405"))
+		}
+
+		if _, err := os.Stat(bundlePath); err == nil && !diagnosticsOverwrite {
+			cmd.Printf("%s: not overwriting the existing file at '%s', specify --overwrite to override this behavior\n", aurora.Red("error"), aurora.Blue(bundlePath))
+			return
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			cmd.Println(err.Error())
+			return
+		}
+
+		report, err := collectDiagnostics()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+		report.Versions.Cli = version.Version()
+
+		// Write to a temp file first so a failure doesn't leave a partial archive
+		tmpPath := bundlePath + ".tmp"
+		bundle, err := os.Create(tmpPath)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = diagnostics.WriteBundle(bundle, report)
+		closeErr := bundle.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(tmpPath)
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = os.Rename(tmpPath, bundlePath)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		result := &diagnosticsBundleResult{File: bundlePath, Errors: report.Errors}
+		err = printOutput(cmd, result, func() error {
+			cmd.Println(aurora.Green(fmt.Sprintf("Diagnostics bundle written to %s", bundlePath)))
+			for _, collectErr := range report.Errors {
+				cmd.Printf("%s: not collected: %s\n", aurora.Yellow("warning"), collectErr)
+			}
+			return nil
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+		}
+	},
+}
+
+type diagnosticsBundleResult struct {
+	File   string   `json:"file"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Gets the runtime's report, falling back to what can be collected locally if the local runtime can't be reached
+func collectDiagnostics() (*diagnostics.Report, error) {
+	runtimeClient, err := newRuntimeClient()
+	if err == nil {
+		var report *diagnostics.Report
+		report, err = runtimeClient.GetDiagnostics()
+		if err == nil {
+			return report, nil
+		}
+	}
+
+	if runtimeUrl != "" {
+		// The local app may have nothing to do with a remote runtime
+		return nil, err
+	}
+
+	report := diagnostics.Collect(rtcontext)
+	report.AddError("runtime", err)
+	return report, nil
+}
+
+func init() {
+	diagnosticsBundleCmd.Flags().StringVarP(&diagnosticsFile, "file", "f", "", "Path of the zip to write. Defaults to spice-diagnostics-<time>.zip in the current directory.")
+	diagnosticsBundleCmd.Flags().BoolVar(&diagnosticsOverwrite, "overwrite", false, "Overwrite a file that already exists")
+	diagnosticsCmd.AddCommand(diagnosticsBundleCmd)
+	diagnosticsCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(diagnosticsCmd)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/context"
+	"github.com/spiceai/spiceai/pkg/doctor"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Checks the app and environment have what the runtime needs",
+	Long: `Checks the app directory is writable, the config and pod manifests are valid, the runtime is installed
+for the given context, the AI engine and its Python environment work (metal) or Docker is running (docker),
+and the runtime's ports are free. Each problem is reported with a suggested fix.
+
+Exits with a non-zero status if any check fails.`,
+	Example: `
+spice doctor
+spice doctor --context metal
+spice doctor -o json
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runtimeContext, err := context.NewContext(contextFlag)
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(1)
+		}
+
+		err = runtimeContext.Init(false)
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(1)
+		}
+
+		// The app is always read from the current directory, whichever context runs it
+		checks := doctor.NewDoctor(rtcontext.AppDir(), rtcontext.PodsDir(), runtimeContext).Run()
+
+		err = printOutput(cmd, checks, func() error {
+			printChecks(cmd, checks)
+			return nil
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(1)
+		}
+
+		if doctor.Failed(checks) {
+			os.Exit(1)
+		}
+	},
+}
+
+func printChecks(cmd *cobra.Command, checks []*doctor.Check) {
+	counts := make(map[doctor.Status]int)
+	for _, check := range checks {
+		counts[check.Status]++
+
+		var status aurora.Value
+		switch check.Status {
+		case doctor.StatusPass:
+			status = aurora.Green("pass")
+		case doctor.StatusWarn:
+			status = aurora.Yellow("warn")
+		default:
+			status = aurora.Red("fail")
+		}
+
+		cmd.Printf("%s  %s: %s\n", status, aurora.Bold(check.Name), check.Message)
+		if check.Fix != "" {
+			cmd.Printf("      fix: %s\n", check.Fix)
+		}
+	}
+
+	cmd.Printf("\n%d passed, %d warned, %d failed\n", counts[doctor.StatusPass], counts[doctor.StatusWarn], counts[doctor.StatusFail])
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&contextFlag, "context", "docker", "Checks the runtime for the given context, either 'docker' or 'metal'")
+	doctorCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(doctorCmd)
+}
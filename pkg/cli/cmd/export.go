@@ -9,7 +9,6 @@ import (
 
 	"github.com/logrusorgru/aurora"
 	"github.com/spf13/cobra"
-	"github.com/spiceai/spiceai/pkg/cli/runtime"
 	"github.com/spiceai/spiceai/pkg/constants"
 )
 
@@ -36,7 +35,7 @@ spice export trader -o ./models
 			return
 		}
 
-		runtimeClient, err := runtime.NewRuntimeClient(podName)
+		runtimeClient, err := newRuntimeClient()
 		if err != nil {
 			cmd.Println(err.Error())
 			return
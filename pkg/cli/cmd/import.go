@@ -3,7 +3,6 @@ package cmd
 import (
 	"github.com/logrusorgru/aurora"
 	"github.com/spf13/cobra"
-	"github.com/spiceai/spiceai/pkg/cli/runtime"
 	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
 	"github.com/spiceai/spiceai/pkg/util"
 	"google.golang.org/protobuf/proto"
@@ -56,7 +55,7 @@ spice import --tag latest ./models/trader.spicepod
 			return
 		}
 
-		runtimeClient, err := runtime.NewRuntimeClient(init.Pod)
+		runtimeClient, err := newRuntimeClient()
 		if err != nil {
 			cmd.Println(err.Error())
 			return
@@ -7,25 +7,56 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/spec"
+	"github.com/spiceai/spiceai/pkg/templates"
 	"github.com/spiceai/spiceai/pkg/util"
 	"gopkg.in/yaml.v2"
 )
 
+var (
+	initTemplate      string
+	initVars          []string
+	initListTemplates bool
+)
+
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize Pod - initializes a new pod in the project",
+	Long: `Initializes a new pod manifest in the project.
+
+With --template, scaffolds a complete app from a built-in template or a local template directory: pods,
+seed data, reward functions and spice.config.yaml. A template directory has a template.yaml describing its
+variables. Files ending in .tmpl are rendered with Go templates, e.g. {{ .name }}, and {<variable>} in a
+file path is replaced with the variable's value. Variables not passed with --var are prompted for, with the
+template's defaults. A pod name argument sets the 'name' variable.`,
 	Example: `
 spice init <pod name>
 spice init trader
+spice init --list-templates
+spice init --template trader
+spice init --template trader mytrader --var starting_balance=50000
+spice init --template ./templates/house-pod --var owner=platform
 `,
-	Args: cobra.MinimumNArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if initTemplate != "" || initListTemplates {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
+		if initListTemplates {
+			listTemplates(cmd)
+			return
+		}
+
+		if initTemplate != "" {
+			initFromTemplate(cmd, args)
+			return
+		}
+
 		podName := args[0]
 		podManifestFileName := fmt.Sprintf("%s.yaml", strings.ToLower(podName))
 
-		rtcontext := context.CurrentContext()
 		podsPath := rtcontext.PodsDir()
 		podManifestPath := filepath.Join(podsPath, podManifestFileName)
 		appRelativeManifestPath := rtcontext.GetSpiceAppRelativePath(podManifestPath)
@@ -81,7 +112,117 @@ spice init trader
 	},
 }
 
+func listTemplates(cmd *cobra.Command) {
+	builtins, err := templates.Builtin()
+	if err != nil {
+		cmd.Println(err.Error())
+		return
+	}
+
+	err = printOutput(cmd, builtins, func() error {
+		cmd.Println("Built-in templates:")
+		for _, t := range builtins {
+			cmd.Printf("  %-14s %s\n", t.Name, t.Description)
+			for _, v := range t.Variables {
+				cmd.Printf("  %-14s   %s: %s (default %s)\n", "", v.Name, v.Description, v.Default)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		cmd.Println(err.Error())
+	}
+}
+
+func initFromTemplate(cmd *cobra.Command, args []string) {
+	t, err := templates.Load(initTemplate)
+	if err != nil {
+		cmd.Println(err.Error())
+		return
+	}
+
+	given, err := parseKeyValues("--var", initVars)
+	if err != nil {
+		cmd.Println(err.Error())
+		return
+	}
+	if given == nil {
+		given = make(map[string]string)
+	}
+	if len(args) > 0 {
+		given["name"] = args[0]
+	}
+
+	if isTerminal(os.Stdin) {
+		p := newPrompter(cmd)
+		for _, v := range t.Variables {
+			if _, ok := given[v.Name]; ok {
+				continue
+			}
+			label := v.Name
+			if v.Description != "" {
+				label = fmt.Sprintf("%s (%s)", v.Name, v.Description)
+			}
+			value, err := p.askValid(label, v.Default, func(value string) error {
+				if value == "" {
+					return fmt.Errorf("%s requires a value", v.Name)
+				}
+				return nil
+			})
+			if err != nil {
+				cmd.Println(err.Error())
+				return
+			}
+			given[v.Name] = value
+		}
+	}
+
+	vars, err := t.Vars(given)
+	if err != nil {
+		cmd.Println(err.Error())
+		return
+	}
+
+	files, err := t.Render(vars)
+	if err != nil {
+		cmd.Println(err.Error())
+		return
+	}
+
+	appDir := rtcontext.AppDir()
+
+	var existing []string
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(appDir, filepath.FromSlash(file.Path))); !os.IsNotExist(err) {
+			existing = append(existing, file.Path)
+		}
+	}
+	if len(existing) > 0 && !confirmOverwrite(cmd, fmt.Sprintf("%s already exist.", strings.Join(existing, ", "))) {
+		return
+	}
+
+	for _, file := range files {
+		filePath := filepath.Join(appDir, filepath.FromSlash(file.Path))
+		err = os.MkdirAll(filepath.Dir(filePath), 0766)
+		if err != nil {
+			cmd.Println(err)
+			return
+		}
+		err = os.WriteFile(filePath, file.Data, 0766)
+		if err != nil {
+			cmd.Println(err)
+			return
+		}
+		cmd.Printf("  %s\n", file.Path)
+	}
+
+	cmd.Printf("Spice app initialized from template %s! Run 'spice run' to start it.\n", t.Name)
+}
+
 func init() {
+	initCmd.Flags().StringVar(&initTemplate, "template", "", "Built-in template or template directory to scaffold the app from")
+	initCmd.Flags().StringArrayVar(&initVars, "var", nil, "Template variable as <name>=<value>. May be repeated.")
+	initCmd.Flags().BoolVar(&initListTemplates, "list-templates", false, "List the built-in templates and their variables")
 	initCmd.Flags().BoolP("help", "h", false, "Print this help message")
 	RootCmd.AddCommand(initCmd)
 }
@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/api"
+	spice_time "github.com/spiceai/spiceai/pkg/time"
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+var (
+	interpretationsStart   string
+	interpretationsEnd     string
+	interpretationsName    string
+	interpretationsTag     string
+	interpretationsActions []string
+	interpretationsTags    []string
+	interpretationsAll     bool
+)
+
+const interpretationsTimeHelp = "date, offset from now like -2h or 3d ago, unix or RFC3339 time"
+
+var interpretationsCmd = &cobra.Command{
+	Use:   "interpretations",
+	Short: "Retrieve and manage a pod's interpretations",
+	Example: `
+spice interpretations list trader --start yesterday
+spice interpretations add trader --start "2021-11-14 09:00" --end "2021-11-14 10:00" --name "news spike" --action buy --tag news
+spice interpretations delete trader --tag news
+spice interpretations import trader interpretations.json
+`,
+}
+
+var interpretationsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists a pod's interpretations",
+	Example: `
+spice interpretations list trader
+spice interpretations list trader --start -1d --tag news
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName := args[0]
+
+		start, end, err := parseInterpretationsRange()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		apiInterpretations, err := runtimeClient.GetInterpretations(podName, start, end)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if interpretationsTag != "" {
+			tagged := make([]*api.Interpretation, 0, len(apiInterpretations))
+			for _, i := range apiInterpretations {
+				if hasTag(i, interpretationsTag) {
+					tagged = append(tagged, i)
+				}
+			}
+			apiInterpretations = tagged
+		}
+
+		if apiInterpretations == nil {
+			apiInterpretations = []*api.Interpretation{}
+		}
+
+		err = printOutput(cmd, apiInterpretations, func() error {
+			if len(apiInterpretations) == 0 {
+				cmd.Printf("No interpretations found for pod %s\n", podName)
+				return nil
+			}
+			return printInterpretations(cmd, apiInterpretations)
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+	},
+}
+
+var interpretationsAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Adds an interpretation to a pod",
+	Example: `
+spice interpretations add trader --start -2h --end now --name "news spike"
+spice interpretations add trader --start 2021-11-14 --end 2021-11-15 --name rally --action buy --tag news,twitter
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName := args[0]
+
+		if interpretationsStart == "" || interpretationsEnd == "" {
+			cmd.Println("--start and --end are required")
+			return
+		}
+
+		start, end, err := parseInterpretationsRange()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		apiInterpretation := &api.Interpretation{
+			Start:   start.Unix(),
+			End:     end.Unix(),
+			Name:    interpretationsName,
+			Actions: interpretationsActions,
+			Tags:    interpretationsTags,
+		}
+
+		// Validates the interpretation before sending it
+		_, err = api.NewInterpretationFromApi(apiInterpretation)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = runtimeClient.AddInterpretations(podName, []*api.Interpretation{apiInterpretation})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		added := []*api.Interpretation{apiInterpretation}
+		err = printOutput(cmd, added, func() error {
+			cmd.Println(aurora.Green(fmt.Sprintf("Added interpretation to pod %s", podName)))
+			return printInterpretations(cmd, added)
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+	},
+}
+
+var interpretationsDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Deletes a pod's interpretations that lie within --start and --end and match --name and --tag",
+	Example: `
+spice interpretations delete trader --name "news spike"
+spice interpretations delete trader --start 2021-11-14 --end 2021-11-15 --tag news
+spice interpretations delete trader --all
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName := args[0]
+
+		hasFilter := interpretationsStart != "" || interpretationsEnd != "" || interpretationsName != "" || interpretationsTag != ""
+		if !hasFilter && !interpretationsAll {
+			cmd.Println("specify --start, --end, --name or --tag to select interpretations, or --all to delete all of them")
+			return
+		}
+
+		start, end, err := parseInterpretationsRange()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		removed, err := runtimeClient.DeleteInterpretations(podName, start, end, interpretationsName, interpretationsTag)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if removed == nil {
+			removed = []*api.Interpretation{}
+		}
+
+		err = printOutput(cmd, removed, func() error {
+			if len(removed) == 0 {
+				cmd.Printf("No matching interpretations found for pod %s\n", podName)
+				return nil
+			}
+			cmd.Println(aurora.Green(fmt.Sprintf("Deleted %d interpretations from pod %s", len(removed), podName)))
+			return printInterpretations(cmd, removed)
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+	},
+}
+
+var interpretationsImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Adds the interpretations in a JSON file to a pod",
+	Long: `Adds the interpretations in a JSON file to a pod. The file holds an array of interpretations
+with unix start and end times, as returned by the interpretations API:
+
+[{"Start": 1605312000, "End": 1605315600, "Name": "news spike", "Actions": ["buy"], "Tags": ["news"]}]`,
+	Example: `
+spice interpretations import trader interpretations.json
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName := args[0]
+
+		apiInterpretations, err := readInterpretationsFile(args[1])
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = runtimeClient.AddInterpretations(podName, apiInterpretations)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		cmd.Println(aurora.Green(fmt.Sprintf("Imported %d interpretations into pod %s", len(apiInterpretations), podName)))
+	},
+}
+
+// Rows of the interpretations table, as MarshalAndPrintTable splits on commas
+type interpretationRow struct {
+	Start   string `csv:"start"`
+	End     string `csv:"end"`
+	Name    string `csv:"name"`
+	Actions string `csv:"actions"`
+	Tags    string `csv:"tags"`
+}
+
+func printInterpretations(cmd *cobra.Command, apiInterpretations []*api.Interpretation) error {
+	sort.SliceStable(apiInterpretations, func(i, j int) bool {
+		return apiInterpretations[i].Start < apiInterpretations[j].Start
+	})
+
+	rows := make([]*interpretationRow, 0, len(apiInterpretations))
+	for _, i := range apiInterpretations {
+		rows = append(rows, &interpretationRow{
+			Start:   time.Unix(i.Start, 0).UTC().Format(time.RFC3339),
+			End:     time.Unix(i.End, 0).UTC().Format(time.RFC3339),
+			Name:    i.Name,
+			Actions: strings.Join(i.Actions, " "),
+			Tags:    strings.Join(i.Tags, " "),
+		})
+	}
+
+	return util.MarshalAndPrintTable(cmd.OutOrStdout(), rows)
+}
+
+func readInterpretationsFile(path string) ([]*api.Interpretation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiInterpretations []*api.Interpretation
+	err = json.Unmarshal(data, &apiInterpretations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read interpretations from %s: %w", path, err)
+	}
+	if len(apiInterpretations) == 0 {
+		return nil, fmt.Errorf("no interpretations found in %s", path)
+	}
+
+	for n, i := range apiInterpretations {
+		_, err := api.NewInterpretationFromApi(i)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interpretation %d in %s: %w", n+1, path, err)
+		}
+	}
+
+	return apiInterpretations, nil
+}
+
+// Parses --start and --end. Unset times are zero, which the runtime treats as the pod's period.
+func parseInterpretationsRange() (time.Time, time.Time, error) {
+	now := time.Now()
+	var start, end time.Time
+	var err error
+
+	if interpretationsStart != "" {
+		start, err = spice_time.ParseHumanTime(interpretationsStart, now)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid --start: %w", err)
+		}
+	}
+	if interpretationsEnd != "" {
+		end, err = spice_time.ParseHumanTime(interpretationsEnd, now)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid --end: %w", err)
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return start, end, errors.New("--end cannot be before --start")
+	}
+
+	return start, end, nil
+}
+
+func hasTag(i *api.Interpretation, tag string) bool {
+	for _, t := range i.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	for _, c := range []*cobra.Command{interpretationsListCmd, interpretationsAddCmd, interpretationsDeleteCmd} {
+		c.Flags().StringVar(&interpretationsStart, "start", "", "Start time: "+interpretationsTimeHelp)
+		c.Flags().StringVar(&interpretationsEnd, "end", "", "End time: "+interpretationsTimeHelp)
+	}
+	interpretationsListCmd.Flags().StringVar(&interpretationsTag, "tag", "", "Only list interpretations with this tag")
+
+	interpretationsAddCmd.Flags().StringVar(&interpretationsName, "name", "", "Name of the interpretation")
+	interpretationsAddCmd.Flags().StringSliceVar(&interpretationsActions, "action", nil, "Action the interpretation suggests. May be repeated or comma-separated.")
+	interpretationsAddCmd.Flags().StringSliceVar(&interpretationsTags, "tag", nil, "Tag of the interpretation. May be repeated or comma-separated.")
+
+	interpretationsDeleteCmd.Flags().StringVar(&interpretationsName, "name", "", "Only delete interpretations with this name")
+	interpretationsDeleteCmd.Flags().StringVar(&interpretationsTag, "tag", "", "Only delete interpretations with this tag")
+	interpretationsDeleteCmd.Flags().BoolVar(&interpretationsAll, "all", false, "Delete all of the pod's interpretations")
+
+	interpretationsCmd.AddCommand(interpretationsListCmd)
+	interpretationsCmd.AddCommand(interpretationsAddCmd)
+	interpretationsCmd.AddCommand(interpretationsDeleteCmd)
+	interpretationsCmd.AddCommand(interpretationsImportCmd)
+	interpretationsCmd.Flags().BoolP("help", "h", false, "Prints this help message")
+	RootCmd.AddCommand(interpretationsCmd)
+}
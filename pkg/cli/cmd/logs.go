@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	spice_loggers "github.com/spiceai/spiceai/pkg/loggers"
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+var (
+	logsFollow    bool
+	logsComponent string
+	logsPod       string
+	logsSince     string
+	logsLines     int
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Prints the runtime's and AI engine's logs",
+	Long: `Prints the lines recently logged by the runtime and the AI engine, oldest first, and with --follow keeps
+printing new lines as they are logged.
+
+Lines about a pod can be selected with --pod. Runtime lines are attributed to a pod when they have a pod field,
+and AI engine lines when they start with "<pod> ->". With -o json, each line is printed as a JSON object.`,
+	Example: `
+spice logs
+spice logs -f
+spice logs --component aiengine --pod trader
+spice logs --since 10m -n 100
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if outputFormat == util.OutputYaml {
+			cmd.Println("logs only supports table or json output")
+			return
+		}
+
+		query := &spice_loggers.LogQuery{
+			Pod:  logsPod,
+			Tail: logsLines,
+		}
+
+		if logsComponent != "" {
+			err := spice_loggers.ValidateComponent(logsComponent)
+			if err != nil {
+				cmd.Println(err.Error())
+				return
+			}
+			query.Components = []string{logsComponent}
+		}
+
+		since, err := parseSince(logsSince, time.Now())
+		if err != nil {
+			cmd.Printf("invalid --since: %s\n", err.Error())
+			return
+		}
+		query.Since = since
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetEscapeHTML(false)
+
+		err = runtimeClient.GetLogs(query, logsFollow, func(logLine *spice_loggers.LogLine) error {
+			if outputFormat == util.OutputJson {
+				return encoder.Encode(logLine)
+			}
+
+			cmd.Println(formatLogLine(logLine))
+			return nil
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+	},
+}
+
+// Parses a duration before now, such as 10m, or a unix or RFC3339 time
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return parseOptionalTime(value)
+}
+
+func formatLogLine(logLine *spice_loggers.LogLine) string {
+	var b strings.Builder
+	b.WriteString(logLine.Time.Local().Format("2006-01-02 15:04:05.000"))
+	b.WriteString(" ")
+	b.WriteString(aurora.Cyan(logLine.Component).String())
+	if logLine.Pod != "" {
+		b.WriteString(" ")
+		b.WriteString(aurora.Blue(logLine.Pod).String())
+	}
+	b.WriteString(" ")
+
+	if logLine.Component == spice_loggers.ComponentRuntime {
+		b.WriteString(formatRuntimeEntry(logLine.Line))
+	} else {
+		b.WriteString(logLine.Line)
+	}
+
+	return b.String()
+}
+
+// Formats a runtime JSON entry as its level, message and fields. Lines that aren't JSON entries are returned as is.
+func formatRuntimeEntry(line string) string {
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return line
+	}
+
+	msg, ok := entry["msg"].(string)
+	if !ok {
+		return line
+	}
+
+	var b strings.Builder
+	if level, ok := entry["level"].(string); ok {
+		b.WriteString(strings.ToUpper(level))
+		b.WriteString(" ")
+	}
+	b.WriteString(msg)
+
+	keys := make([]string, 0, len(entry))
+	for key := range entry {
+		switch key {
+		case "level", "ts", "msg", "caller", "pod":
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b.WriteString(fmt.Sprintf(" %s=%v", key, entry[key]))
+	}
+
+	return b.String()
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep printing new lines as they are logged")
+	logsCmd.Flags().StringVar(&logsComponent, "component", "", "Only print lines from this component, either 'runtime' or 'aiengine'")
+	logsCmd.Flags().StringVar(&logsPod, "pod", "", "Only print lines about this pod")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Only print lines logged since this unix or RFC3339 time, or for this long, such as 10m")
+	logsCmd.Flags().IntVarP(&logsLines, "lines", "n", 0, "Only print this many of the lines already logged. 0 prints them all.")
+	logsCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(logsCmd)
+}
@@ -0,0 +1,516 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/observations"
+	"github.com/spiceai/spiceai/pkg/proto/common_pb"
+	spice_time "github.com/spiceai/spiceai/pkg/time"
+)
+
+const (
+	exportFormatCsv     = "csv"
+	exportFormatJson    = "json"
+	exportFormatParquet = "parquet"
+)
+
+var (
+	observationsDataspace  string
+	observationsChunkSize  int
+	observationsInterval   time.Duration
+	observationsTailLines  int
+	observationsStart      string
+	observationsEnd        string
+	observationsFormat     string
+	observationsOutputFile string
+)
+
+var observationsCmd = &cobra.Command{
+	Use:   "observations",
+	Short: "Push, follow and export pod observations",
+	Example: `
+spice observations push trader ./data.csv
+spice observations tail trader
+spice observations export trader --format parquet --output-file trader.parquet
+`,
+}
+
+var observationsPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Sends observations from a CSV or JSON file to a pod or one of its dataspaces",
+	Long: `Sends observations from a CSV or JSON file to a pod or one of its dataspaces.
+
+Observations pushed to a pod must use fully-qualified field names, e.g. coinbase.btcusd.close.
+CSV files need a header row starting with the time column. JSON files are an array of
+observations as returned by "spice observations export --format json".
+
+Observations pushed to a dataspace are read by the dataspace's processor, so the file must
+be in the format it expects. Files are sent in chunks of --chunk-size rows.`,
+	Example: `
+spice observations push trader ./data.csv
+spice observations push trader ./observations.json --chunk-size 500
+spice observations push trader ./btcusd.csv --dataspace coinbase/btcusd
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName := args[0]
+		filePath := args[1]
+
+		if observationsChunkSize <= 0 {
+			cmd.Println("--chunk-size must be greater than 0")
+			return
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = runtimeClient.CheckHealth()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		var chunks [][]byte
+		var chunkSizes []int
+		contentType := "text/csv"
+		ext := strings.ToLower(filepath.Ext(filePath))
+		switch {
+		case ext == ".csv":
+			chunks, chunkSizes, err = chunkCsv(data, observationsChunkSize)
+		case ext == ".json" && observationsDataspace != "":
+			contentType = "application/json"
+			chunks, chunkSizes, err = chunkJson(data, observationsChunkSize)
+		case ext == ".json":
+			var csvData []byte
+			csvData, err = jsonObservationsToCsv(data, cmd.ErrOrStderr())
+			if err == nil {
+				chunks, chunkSizes, err = chunkCsv(csvData, observationsChunkSize)
+			}
+		default:
+			err = fmt.Errorf("unsupported file type '%s', expected .csv or .json", ext)
+		}
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		send := func(chunk []byte) error {
+			return runtimeClient.PostObservations(podName, chunk)
+		}
+		if observationsDataspace != "" {
+			parts := strings.Split(observationsDataspace, "/")
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				cmd.Printf("invalid dataspace '%s', expected <from>/<name>\n", observationsDataspace)
+				return
+			}
+			send = func(chunk []byte) error {
+				return runtimeClient.PostDataspaceData(podName, parts[0], parts[1], contentType, chunk)
+			}
+		}
+
+		total := 0
+		for _, size := range chunkSizes {
+			total += size
+		}
+
+		pushed := 0
+		for i, chunk := range chunks {
+			err = send(chunk)
+			if err != nil {
+				cmd.Println()
+				cmd.Printf("%s after pushing %d of %d observations: %s\n", aurora.Red("error"), pushed, total, err.Error())
+				return
+			}
+			pushed += chunkSizes[i]
+			cmd.Printf("\rPushed %d of %d observations (%d%%)", pushed, total, pushed*100/total)
+		}
+		cmd.Println()
+
+		cmd.Println(aurora.Green(fmt.Sprintf("Pushed %d observations to %s", total, podName)))
+	},
+}
+
+var observationsTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Prints a pod's latest observations, then follows new ones as they arrive",
+	Example: `
+spice observations tail trader
+spice observations tail trader -n 50 --interval 1s
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName := args[0]
+
+		err := requireTableOutput("observations tail")
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		podObservations, err := runtimeClient.GetObservations(podName, time.Time{}, time.Time{})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+		sortObservations(podObservations)
+		if len(podObservations) > observationsTailLines {
+			podObservations = podObservations[len(podObservations)-observationsTailLines:]
+		}
+
+		var lastTime int64
+		for {
+			for _, o := range podObservations {
+				cmd.Println(formatObservation(o))
+				if o.Time > lastTime {
+					lastTime = o.Time
+				}
+			}
+
+			time.Sleep(observationsInterval)
+
+			// Observations are polled by time, so late arriving observations older than the latest are not shown
+			podObservations, err = runtimeClient.GetObservations(podName, time.Unix(lastTime+1, 0), time.Time{})
+			if err != nil {
+				cmd.Println(err.Error())
+				return
+			}
+			sortObservations(podObservations)
+		}
+	},
+}
+
+var observationsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports a pod's observations as CSV, JSON or Parquet",
+	Example: `
+spice observations export trader
+spice observations export trader --start 2021-11-14T00:00:00Z --end 1636934400 --format json
+spice observations export trader --format parquet --output-file trader.parquet
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName := args[0]
+
+		switch observationsFormat {
+		case exportFormatCsv, exportFormatJson, exportFormatParquet:
+		default:
+			cmd.Printf("invalid format '%s', must be one of %s, %s or %s\n", observationsFormat, exportFormatCsv, exportFormatJson, exportFormatParquet)
+			return
+		}
+
+		start, err := parseOptionalTime(observationsStart)
+		if err != nil {
+			cmd.Printf("invalid --start: %s\n", err.Error())
+			return
+		}
+		end, err := parseOptionalTime(observationsEnd)
+		if err != nil {
+			cmd.Printf("invalid --end: %s\n", err.Error())
+			return
+		}
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		pod, err := runtimeClient.GetPod(podName)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+		if pod == nil {
+			cmd.Printf("pod %s is not loaded\n", podName)
+			return
+		}
+
+		podObservations, err := runtimeClient.GetObservations(podName, start, end)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+		sortObservations(podObservations)
+
+		var out bytes.Buffer
+		switch observationsFormat {
+		case exportFormatCsv:
+			err = writeObservationsCsv(&out, pod.Identifiers, pod.Measurements, pod.Categories, podObservations)
+		case exportFormatJson:
+			var data []byte
+			data, err = json.MarshalIndent(podObservations, "", "  ")
+			out.Write(data)
+			out.WriteString("\n")
+		case exportFormatParquet:
+			err = observations.WriteParquet(&out, pod.Identifiers, pod.Measurements, pod.Categories, newObservations(podObservations))
+		}
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if observationsOutputFile == "" {
+			_, err = cmd.OutOrStdout().Write(out.Bytes())
+			if err != nil {
+				cmd.Println(err.Error())
+			}
+			return
+		}
+
+		err = os.WriteFile(observationsOutputFile, out.Bytes(), 0644)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		cmd.Println(aurora.Green(fmt.Sprintf("Exported %d observations to %s", len(podObservations), observationsOutputFile)))
+	},
+}
+
+// Splits CSV data into chunks of at most chunkSize rows, each with the header row. Returns the number of rows in each chunk.
+func chunkCsv(data []byte, chunkSize int) ([][]byte, []int, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, errors.New("failed to read csv: no header row")
+	}
+
+	header := records[0]
+	rows := records[1:]
+
+	var chunks [][]byte
+	var chunkSizes []int
+	for start := 0; start < len(rows); start += chunkSize {
+		end := start + chunkSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		var chunk bytes.Buffer
+		writer := csv.NewWriter(&chunk)
+		err = writer.Write(header)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = writer.WriteAll(rows[start:end])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		chunks = append(chunks, chunk.Bytes())
+		chunkSizes = append(chunkSizes, end-start)
+	}
+
+	return chunks, chunkSizes, nil
+}
+
+// Splits a JSON array into arrays of at most chunkSize items. Any other JSON value is sent as one chunk.
+func chunkJson(data []byte, chunkSize int) ([][]byte, []int, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		if !json.Valid(data) {
+			return nil, nil, fmt.Errorf("failed to read json: %w", err)
+		}
+		return [][]byte{data}, []int{1}, nil
+	}
+
+	var chunks [][]byte
+	var chunkSizes []int
+	for start := 0; start < len(items); start += chunkSize {
+		end := start + chunkSize
+		if end > len(items) {
+			end = len(items)
+		}
+
+		chunk, err := json.Marshal(items[start:end])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		chunks = append(chunks, chunk)
+		chunkSizes = append(chunkSizes, end-start)
+	}
+
+	return chunks, chunkSizes, nil
+}
+
+// Converts a JSON array of observations with fully-qualified field names to CSV the pod observations endpoint accepts
+func jsonObservationsToCsv(data []byte, warnings io.Writer) ([]byte, error) {
+	var jsonObservations []*common_pb.Observation
+	err := json.Unmarshal(data, &jsonObservations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read json observations: %w", err)
+	}
+
+	identifierNames := make(map[string]bool)
+	measurementNames := make(map[string]bool)
+	categoryNames := make(map[string]bool)
+	hasTags := false
+	for _, o := range jsonObservations {
+		for name := range o.Identifiers {
+			identifierNames[name] = true
+		}
+		for name := range o.Measurements {
+			measurementNames[name] = true
+		}
+		for name := range o.Categories {
+			categoryNames[name] = true
+		}
+		hasTags = hasTags || len(o.Tags) > 0
+	}
+
+	if hasTags {
+		fmt.Fprintf(warnings, "%s tags can't be pushed to a pod and are ignored\n", aurora.Yellow("warning:"))
+	}
+
+	var out bytes.Buffer
+	err = writeObservationsCsv(&out, sortedKeys(identifierNames), sortedKeys(measurementNames), sortedKeys(categoryNames), jsonObservations)
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func writeObservationsCsv(w io.Writer, identifierNames []string, measurementNames []string, categoryNames []string, podObservations []*common_pb.Observation) error {
+	writer := csv.NewWriter(w)
+
+	header := append([]string{"time"}, identifierNames...)
+	header = append(header, measurementNames...)
+	header = append(header, categoryNames...)
+	err := writer.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range podObservations {
+		record := make([]string, 0, len(header))
+		record = append(record, strconv.FormatInt(o.Time, 10))
+		for _, name := range identifierNames {
+			record = append(record, o.Identifiers[name])
+		}
+		for _, name := range measurementNames {
+			value, ok := o.Measurements[name]
+			if ok {
+				record = append(record, strconv.FormatFloat(value, 'f', -1, 64))
+			} else {
+				record = append(record, "")
+			}
+		}
+		for _, name := range categoryNames {
+			record = append(record, o.Categories[name])
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func newObservations(podObservations []*common_pb.Observation) []observations.Observation {
+	result := make([]observations.Observation, 0, len(podObservations))
+	for _, o := range podObservations {
+		result = append(result, observations.Observation{
+			Time:         o.Time,
+			Identifiers:  o.Identifiers,
+			Measurements: o.Measurements,
+			Categories:   o.Categories,
+			Tags:         o.Tags,
+		})
+	}
+	return result
+}
+
+func formatObservation(o *common_pb.Observation) string {
+	fields := make([]string, 0, len(o.Identifiers)+len(o.Measurements)+len(o.Categories))
+	for name, value := range o.Identifiers {
+		fields = append(fields, fmt.Sprintf("%s=%s", name, value))
+	}
+	for name, value := range o.Measurements {
+		fields = append(fields, fmt.Sprintf("%s=%s", name, strconv.FormatFloat(value, 'f', -1, 64)))
+	}
+	for name, value := range o.Categories {
+		fields = append(fields, fmt.Sprintf("%s=%s", name, value))
+	}
+	sort.Strings(fields)
+
+	line := fmt.Sprintf("%s %s", aurora.Gray(12, time.Unix(o.Time, 0).UTC().Format(time.RFC3339)), strings.Join(fields, " "))
+	if len(o.Tags) > 0 {
+		line += fmt.Sprintf(" [%s]", strings.Join(o.Tags, " "))
+	}
+	return line
+}
+
+func sortObservations(podObservations []*common_pb.Observation) {
+	sort.SliceStable(podObservations, func(i, j int) bool {
+		return podObservations[i].Time < podObservations[j].Time
+	})
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func parseOptionalTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return spice_time.ParseTime(value, "")
+}
+
+func init() {
+	observationsPushCmd.Flags().StringVar(&observationsDataspace, "dataspace", "", "Send to the dataspace <from>/<name> instead of the pod")
+	observationsPushCmd.Flags().IntVar(&observationsChunkSize, "chunk-size", 1000, "Number of observations sent per request")
+	observationsTailCmd.Flags().DurationVar(&observationsInterval, "interval", 5*time.Second, "How often to check for new observations")
+	observationsTailCmd.Flags().IntVarP(&observationsTailLines, "lines", "n", 10, "Number of existing observations to print first")
+	observationsExportCmd.Flags().StringVar(&observationsStart, "start", "", "Only export observations from this unix or RFC3339 time")
+	observationsExportCmd.Flags().StringVar(&observationsEnd, "end", "", "Only export observations before this unix or RFC3339 time")
+	observationsExportCmd.Flags().StringVar(&observationsFormat, "format", exportFormatCsv, "Export format: csv, json or parquet")
+	observationsExportCmd.Flags().StringVar(&observationsOutputFile, "output-file", "", "Write to this file instead of stdout")
+
+	observationsCmd.AddCommand(observationsPushCmd)
+	observationsCmd.AddCommand(observationsTailCmd)
+	observationsCmd.AddCommand(observationsExportCmd)
+	observationsCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(observationsCmd)
+}
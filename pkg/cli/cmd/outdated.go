@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/registry"
+	"github.com/spiceai/spiceai/pkg/util"
+	"golang.org/x/mod/semver"
+)
+
+// A locked pod with a newer version in its registry
+type outdatedPod struct {
+	Pod     string `json:"pod" csv:"pod"`
+	Current string `json:"current" csv:"current"`
+	Latest  string `json:"latest" csv:"latest"`
+	Source  string `json:"source" csv:"source"`
+}
+
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated [pod...]",
+	Short: "Lists pods with newer versions in their registry",
+	Long: `Lists the pods in spicepods.lock, or only the given pods, that have a newer version in the registry they were
+added from. Local and git pods don't have versions to compare, so are never outdated.
+Update them with 'spice update'.`,
+	Example: `
+spice outdated
+spice outdated samples/LogPruner
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		lockfile, err := loadLockfile()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		outdated, err := findOutdatedPods(lockfile, args)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = printOutput(cmd, outdated, func() error {
+			if len(outdated) == 0 {
+				cmd.Println("All pods are up to date.")
+				return nil
+			}
+			return util.MarshalAndPrintTable(cmd.OutOrStdout(), outdated)
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+		}
+	},
+}
+
+// Returns the locked pods, or only those at podPaths, with a newer version in their registry
+func findOutdatedPods(lockfile *registry.Lockfile, podPaths []string) ([]*outdatedPod, error) {
+	if len(podPaths) == 0 {
+		for podPath := range lockfile.Pods {
+			podPaths = append(podPaths, podPath)
+		}
+		sort.Strings(podPaths)
+	}
+
+	outdated := make([]*outdatedPod, 0)
+	for _, podPath := range podPaths {
+		locked := lockfile.Get(podPath)
+		if locked == nil {
+			return nil, fmt.Errorf("pod %s isn't in %s, add it with 'spice add %s'", podPath, registry.LockfileName, podPath)
+		}
+
+		if filepath.IsAbs(locked.Source) {
+			continue
+		}
+
+		versions, err := registry.GetRegistry(rtcontext.PodsDir(), podPath, registryOptions(locked.Source)...).ListVersions(podPath)
+		if err != nil {
+			// Such as git pods, which are pinned to a commit rather than a version
+			var notSupported *registry.RegistryOperationNotSupported
+			if errors.As(err, &notSupported) {
+				continue
+			}
+			return nil, err
+		}
+
+		latest := locked.Version
+		for _, version := range versions {
+			if latest == "" || semver.Compare("v"+version.Version, "v"+latest) > 0 {
+				latest = version.Version
+			}
+		}
+
+		if latest != locked.Version {
+			outdated = append(outdated, &outdatedPod{
+				Pod:     podPath,
+				Current: locked.Version,
+				Latest:  latest,
+				Source:  locked.Source,
+			})
+		}
+	}
+
+	return outdated, nil
+}
+
+func init() {
+	outdatedCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(outdatedCmd)
+}
@@ -1,27 +1,32 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"io"
+	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/logrusorgru/aurora"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/spiceai/spiceai/pkg/api"
-	"github.com/spiceai/spiceai/pkg/config"
-	"github.com/spiceai/spiceai/pkg/context"
+	"github.com/spiceai/spiceai/pkg/audit"
+	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/util"
 )
 
 var podsCmd = &cobra.Command{
 	Use:     "pods",
 	Aliases: []string{"pods"},
-	Short:   "Retrieve pods",
+	Short:   "Retrieve and manage pods",
 	Example: `
 spice pods list
+spice pods get trader
+spice pods reload trader
+spice pods remove trader
+spice pods status
 `,
 }
 
@@ -30,67 +35,296 @@ var podsListCmd = &cobra.Command{
 	Short: "Lists currently loaded pods from the runtime",
 	Example: `
 spice pods list
+spice pods list -o json
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		v := viper.New()
-		appDir := context.CurrentContext().AppDir()
-		runtimeConfig, err := config.LoadRuntimeConfiguration(v, appDir)
+		runtimeClient, err := newRuntimeClient()
 		if err != nil {
-			cmd.Println("failed to load runtime configuration")
+			cmd.Println(err.Error())
 			return
 		}
 
-		serverBaseUrl := runtimeConfig.ServerBaseUrl()
-
-		err = util.IsRuntimeServerHealthy(serverBaseUrl, http.DefaultClient)
+		pods, err := runtimeClient.GetPods()
 		if err != nil {
-			cmd.Printf("failed to reach %s. is the spice runtime running?\n", serverBaseUrl)
+			cmd.Printf("failed to get currently loaded pods from runtime: %s\n", err.Error())
 			return
 		}
 
-		listUrl := fmt.Sprintf("%s/api/v0.1/pods", serverBaseUrl)
+		sort.SliceStable(pods, func(i, j int) bool {
+			return strings.Compare(pods[i].Name, pods[j].Name) == -1
+		})
 
-		response, err := http.DefaultClient.Get(listUrl)
+		err = util.MarshalAndPrint(cmd.OutOrStdout(), outputFormat, pods, func(w io.Writer) error {
+			return util.MarshalAndPrintTable(w, pods)
+		})
 		if err != nil {
 			cmd.Printf("failed to get currently loaded pods from runtime: %s\n", err.Error())
 			return
 		}
+	},
+}
+
+var podsGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Shows a pod's dataspaces, actions, rewards, params, lifecycle and latest training run",
+	Example: `
+spice pods get trader
+spice pods get trader -o yaml
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName := args[0]
 
-		if response.StatusCode != 200 {
-			cmd.Printf("failed to get currently loaded pods from runtime: %s\n", response.Status)
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
 			return
 		}
 
-		body, _ := ioutil.ReadAll(response.Body)
+		pod, err := runtimeClient.GetPod(podName)
 		if err != nil {
-			cmd.Printf("failed to get currently loaded pods from runtime: %s\n", err.Error())
+			cmd.Println(err.Error())
+			return
+		}
+		if pod == nil {
+			cmd.Printf("pod %s is not loaded\n", podName)
 			return
 		}
 
-		pods := make([]*api.Pod, 0)
-		err = json.Unmarshal(body, &pods)
+		err = util.MarshalAndPrint(cmd.OutOrStdout(), outputFormat, pod, func(w io.Writer) error {
+			printPodDetails(w, pod)
+			return nil
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+	},
+}
 
+var podsReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reloads a pod from its manifest and reinitializes it in the runtime",
+	Example: `
+spice pods reload trader
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runtimeClient, err := newRuntimeClient()
 		if err != nil {
-			cmd.Printf("failed to get currently loaded pods from runtime: %s\n", err.Error())
+			cmd.Println(err.Error())
 			return
 		}
 
-		defer response.Body.Close()
+		pod, err := runtimeClient.ReloadPod(args[0])
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
 
-		sort.SliceStable(pods, func(i, j int) bool {
-			return strings.Compare(pods[i].Name, pods[j].Name) == -1
+		err = util.MarshalAndPrint(cmd.OutOrStdout(), outputFormat, pod, func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, aurora.Green(fmt.Sprintf("Reloaded pod %s from %s", pod.Name, pod.ManifestPath)))
+			return err
 		})
-		err = util.MarshalAndPrintTable(cmd.OutOrStdout(), pods)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+	},
+}
 
+var podsRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Unloads a pod from the runtime. Its manifest is left in place and loaded again on the next start.",
+	Example: `
+spice pods remove trader
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runtimeClient, err := newRuntimeClient()
 		if err != nil {
-			cmd.Printf("failed to get currently loaded pods from runtime: %s\n", err.Error())
+			cmd.Println(err.Error())
 			return
 		}
+
+		err = runtimeClient.RemovePod(args[0])
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		cmd.Println(aurora.Green(fmt.Sprintf("Removed pod %s", args[0])))
 	},
 }
 
+var podsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Shows the lifecycle, data staleness and AI engine sync state of loaded pods",
+	Example: `
+spice pods status
+spice pods status trader -o json
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		status, err := runtimeClient.GetPodsStatus()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if len(args) == 1 {
+			podStatus := make([]*api.PodStatus, 0, 1)
+			for _, s := range status {
+				if s.Name == args[0] {
+					podStatus = append(podStatus, s)
+				}
+			}
+			if len(podStatus) == 0 {
+				cmd.Printf("pod %s is not loaded\n", args[0])
+				return
+			}
+			status = podStatus
+		}
+
+		sort.SliceStable(status, func(i, j int) bool {
+			return strings.Compare(status[i].Name, status[j].Name) == -1
+		})
+
+		err = util.MarshalAndPrint(cmd.OutOrStdout(), outputFormat, status, func(w io.Writer) error {
+			return util.MarshalAndPrintTable(w, status)
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+	},
+}
+
+// Loads the app's pod named podName, or its only pod if podName is empty, for commands that edit manifests
+func selectPod(podName string) (*pods.Pod, error) {
+	manifests := pods.FindAllManifestPaths(rtcontext.PodsDir())
+	if len(manifests) == 0 {
+		return nil, errors.New("no pods detected")
+	}
+
+	if podName == "" && len(manifests) > 1 {
+		names := make([]string, 0, len(manifests))
+		for _, manifestPath := range manifests {
+			names = append(names, strings.TrimSuffix(filepath.Base(manifestPath), filepath.Ext(manifestPath)))
+		}
+		return nil, fmt.Errorf("the app has %d pods (%s). Select one with --pod", len(manifests), strings.Join(names, ", "))
+	}
+
+	// Manifests that fail to load only matter if they could be the selected pod
+	var loadErr error
+	for _, manifestPath := range manifests {
+		pod, err := pods.LoadPodFromManifest(manifestPath)
+		if err != nil {
+			loadErr = fmt.Errorf("error loading pod %s: %w", manifestPath, err)
+			continue
+		}
+		if podName == "" || pod.Name == podName {
+			return pod, nil
+		}
+	}
+
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return nil, fmt.Errorf("pod %s not found in %s", podName, rtcontext.PodsDir())
+}
+
+// Records an edit of pod's manifest, which err is the result of saving, in the app's audit log
+func recordManifestEdit(cmd *cobra.Command, action string, pod *pods.Pod, err error) {
+	entry := &audit.Entry{
+		Source:   audit.SourceCLI,
+		Caller:   audit.CurrentUser(),
+		Action:   action,
+		Pod:      pod.Name,
+		Manifest: rtcontext.GetSpiceAppRelativePath(pod.ManifestPath()),
+		OldHash:  pod.Hash(),
+	}
+	if err == nil {
+		entry.NewHash, _ = util.ComputeFileHash(pod.ManifestPath())
+	}
+
+	auditErr := audit.NewLog(audit.LogPath(rtcontext.AppDir())).Record(entry.WithError(err))
+	if auditErr != nil {
+		cmd.Printf("failed to record the change in the audit log: %s\n", auditErr.Error())
+	}
+}
+
+func printPodDetails(w io.Writer, pod *api.PodDetails) {
+	fmt.Fprintf(w, "%s %s\n", aurora.Bold("Pod:"), pod.Name)
+	fmt.Fprintf(w, "%s %s\n", aurora.Bold("Manifest:"), pod.ManifestPath)
+	fmt.Fprintf(w, "%s %s\n", aurora.Bold("Lifecycle:"), pod.Lifecycle)
+
+	fmt.Fprintf(w, "\n%s\n", aurora.Bold("Params"))
+	fmt.Fprintf(w, "  epoch: %s\n", time.Unix(pod.Params.Epoch, 0).UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "  period: %s\n", pod.Params.Period)
+	fmt.Fprintf(w, "  interval: %s\n", pod.Params.Interval)
+	fmt.Fprintf(w, "  granularity: %s\n", pod.Params.Granularity)
+	fmt.Fprintf(w, "  interpolation: %t\n", pod.Params.Interpolation)
+	fmt.Fprintf(w, "  learning_algorithm: %s\n", pod.Params.LearningAlgorithm)
+	fmt.Fprintf(w, "  training_priority: %d\n", pod.Params.TrainingPriority)
+
+	fmt.Fprintf(w, "\n%s\n", aurora.Bold("Dataspaces"))
+	for _, ds := range pod.Dataspaces {
+		fmt.Fprintf(w, "  %s/%s (%d observations)\n", ds.From, ds.Name, ds.Observations)
+		printPodDetailsList(w, "identifiers", ds.Identifiers)
+		printPodDetailsList(w, "measurements", ds.Measurements)
+		printPodDetailsList(w, "categories", ds.Categories)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", aurora.Bold("Actions"))
+	printPodDetailsMap(w, pod.Actions)
+
+	fmt.Fprintf(w, "\n%s\n", aurora.Bold("Rewards"))
+	printPodDetailsMap(w, pod.Rewards)
+
+	fmt.Fprintf(w, "\n%s\n", aurora.Bold("Latest training run"))
+	if pod.LatestFlight == nil {
+		fmt.Fprintln(w, "  none")
+		return
+	}
+	fmt.Fprintf(w, "  id: %s\n", pod.LatestFlight.Id)
+	fmt.Fprintf(w, "  algorithm: %s\n", pod.LatestFlight.Algorithm)
+	fmt.Fprintf(w, "  started: %s\n", time.Unix(pod.LatestFlight.Start, 0).Format(time.RFC1123))
+	fmt.Fprintf(w, "  episodes: %d\n", len(pod.LatestFlight.Episodes))
+}
+
+func printPodDetailsList(w io.Writer, name string, items []string) {
+	if len(items) > 0 {
+		fmt.Fprintf(w, "    %s: %s\n", name, strings.Join(items, ", "))
+	}
+}
+
+func printPodDetailsMap(w io.Writer, items map[string]string) {
+	names := make([]string, 0, len(items))
+	for name := range items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		// Actions and rewards may be multi-line scripts
+		value := strings.ReplaceAll(strings.TrimSpace(items[name]), "\n", "\n      ")
+		fmt.Fprintf(w, "  %s:\n      %s\n", name, value)
+	}
+}
+
 func init() {
 	podsCmd.AddCommand(podsListCmd)
+	podsCmd.AddCommand(podsGetCmd)
+	podsCmd.AddCommand(podsReloadCmd)
+	podsCmd.AddCommand(podsRemoveCmd)
+	podsCmd.AddCommand(podsStatusCmd)
 	podsCmd.Flags().BoolP("help", "h", false, "Prints this help message")
 	podsListCmd.Flags().BoolP("help", "h", false, "Prints this help message")
 	RootCmd.AddCommand(podsCmd)
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/spiceai/spiceai/pkg/cli/profiles"
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+var profileUseNone bool
+
+var profileCmd = &cobra.Command{
+	Use:     "profile",
+	Aliases: []string{"profiles"},
+	Short:   "Manage connection profiles for runtimes",
+	Long: `Manage named connection profiles, so one CLI can manage several runtimes.
+
+Profiles are kept in the user's config directory and hold a runtime url, token, timeout and output format.
+The current profile is used unless --profile selects another. Global flags and SPICE_* env vars override
+the profile's values.`,
+	Example: `
+spice profile add prod --runtime-url https://spice.example.com --token $SPICE_PROD_TOKEN --timeout 30s
+spice profile use prod
+spice profile list
+spice pods list --profile dev
+spice profile show
+spice profile use --none
+spice profile remove prod
+`,
+	// Profiles must be manageable even when the current profile is missing
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		outputFormat = firstNonEmpty(viper.GetString(outputFlag), util.OutputTable)
+		return util.ValidateOutputFormat(outputFormat)
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the connection profiles",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := loadProfiles()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		rows := make([]*profileRow, 0, len(p.Profiles))
+		for _, profile := range p.Profiles {
+			rows = append(rows, newProfileRow(profile, profile.Name == p.Current))
+		}
+
+		err = printOutput(cmd, rows, func() error {
+			if len(rows) == 0 {
+				cmd.Println("No profiles found. Add one with 'spice profile add <name> --runtime-url <url>'")
+				return nil
+			}
+			return util.MarshalAndPrintTable(cmd.OutOrStdout(), rows)
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+		}
+	},
+}
+
+var profileAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Adds a connection profile, or replaces the profile with the same name",
+	Long: `Adds a connection profile with the given --runtime-url, --token, --timeout and --output,
+or replaces the profile with the same name. The first profile added becomes the current profile.`,
+	Example: `
+spice profile add prod --runtime-url https://spice.example.com --token $SPICE_PROD_TOKEN
+spice profile add dev --runtime-url http://localhost:8000 --timeout 10s -o json
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := loadProfiles()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		profile := &profiles.Profile{Name: args[0]}
+		profile.RuntimeUrl, _ = cmd.Flags().GetString(runtimeUrlFlag)
+		profile.Token, _ = cmd.Flags().GetString(tokenFlag)
+		profile.Output, _ = cmd.Flags().GetString(outputFlag)
+		if timeout, _ := cmd.Flags().GetString(timeoutFlag); timeout != "" {
+			profile.Timeout, err = time.ParseDuration(timeout)
+			if err != nil {
+				cmd.Printf("invalid --timeout '%s', expected a duration like 30s\n", timeout)
+				return
+			}
+		}
+
+		replaced := p.Get(profile.Name) != nil
+		err = p.Set(profile)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+		if len(p.Profiles) == 1 {
+			_ = p.Use(profile.Name)
+		}
+
+		err = p.Save()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if replaced {
+			cmd.Println(aurora.Green(fmt.Sprintf("Profile %s replaced", profile.Name)))
+		} else {
+			cmd.Println(aurora.Green(fmt.Sprintf("Profile %s added", profile.Name)))
+		}
+		if p.Current == profile.Name {
+			cmd.Printf("Profile %s is the current profile\n", profile.Name)
+		}
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use",
+	Short: "Makes a profile the current profile",
+	Example: `
+spice profile use prod
+spice profile use --none
+`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if profileUseNone {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := loadProfiles()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+
+		err = p.Use(name)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = p.Save()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if name == "" {
+			cmd.Println("No profile is current. Commands connect to the local app's runtime.")
+			return
+		}
+		cmd.Printf("Profile %s is the current profile\n", name)
+	},
+}
+
+var profileRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Removes a profile",
+	Example: `
+spice profile remove prod
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := loadProfiles()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if !p.Remove(args[0]) {
+			cmd.Printf("profile %s not found\n", args[0])
+			return
+		}
+
+		err = p.Save()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		cmd.Println(aurora.Green(fmt.Sprintf("Profile %s removed", args[0])))
+	},
+}
+
+var profileShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows the connection commands use, after applying the profile, env vars and flags",
+	Example: `
+spice profile show
+spice profile show --profile prod
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := resolveGlobalFlags(cmd, args)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		profile, err := selectedProfile()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		connection := &profileRow{
+			RuntimeUrl: runtimeClient.ServerBaseUrl(),
+			Token:      runtimeToken != "",
+			Timeout:    durationOrNone(runtimeTimeout),
+			Output:     outputFormat,
+		}
+		if profile != nil {
+			connection.Name = profile.Name
+		}
+
+		err = printOutput(cmd, connection, func() error {
+			return util.MarshalAndPrintTable(cmd.OutOrStdout(), []*profileRow{connection})
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+		}
+	},
+}
+
+// Profiles as printed, without their tokens
+type profileRow struct {
+	Name       string `json:"name" csv:"name"`
+	Current    bool   `json:"current" csv:"current"`
+	RuntimeUrl string `json:"runtime_url" csv:"runtime_url"`
+	Token      bool   `json:"token" csv:"token"`
+	Timeout    string `json:"timeout" csv:"timeout"`
+	Output     string `json:"output" csv:"output"`
+}
+
+func newProfileRow(profile *profiles.Profile, current bool) *profileRow {
+	return &profileRow{
+		Name:       profile.Name,
+		Current:    current,
+		RuntimeUrl: profile.RuntimeUrl,
+		Token:      profile.Token != "",
+		Timeout:    durationOrNone(profile.Timeout),
+		Output:     profile.Output,
+	}
+}
+
+func loadProfiles() (*profiles.Profiles, error) {
+	profilesPath, err := profiles.DefaultPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the user config directory: %w", err)
+	}
+	return profiles.Load(profilesPath)
+}
+
+func durationOrNone(d time.Duration) string {
+	if d == 0 {
+		return "none"
+	}
+	return d.String()
+}
+
+func init() {
+	profileUseCmd.Flags().BoolVar(&profileUseNone, "none", false, "Stop using a profile, connecting to the local app's runtime")
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileAddCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileRemoveCmd)
+	profileCmd.AddCommand(profileShowCmd)
+	profileCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(profileCmd)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+)
+
+// Reads answers to prompts a line at a time
+type prompter struct {
+	cmd    *cobra.Command
+	reader *bufio.Reader
+	eof    bool
+}
+
+func newPrompter(cmd *cobra.Command) *prompter {
+	return &prompter{cmd: cmd, reader: bufio.NewReader(cmd.InOrStdin())}
+}
+
+// Returns the trimmed answer, or defaultValue if the answer is empty or input has ended
+func (p *prompter) ask(label string, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		p.cmd.Printf("%s [%s]: ", label, defaultValue)
+	} else {
+		p.cmd.Printf("%s: ", label)
+	}
+
+	line, err := p.reader.ReadString('\n')
+	if err == io.EOF {
+		p.eof = true
+		p.cmd.Println()
+	} else if err != nil {
+		return "", err
+	}
+
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return defaultValue, nil
+}
+
+// Asks until validate accepts the answer, or fails if input ends without one
+func (p *prompter) askValid(label string, defaultValue string, validate func(value string) error) (string, error) {
+	for {
+		answer, err := p.ask(label, defaultValue)
+		if err != nil {
+			return "", err
+		}
+		if err := validate(answer); err != nil {
+			if p.eof {
+				return "", fmt.Errorf("no valid answer for %s: %w", strings.TrimSpace(label), err)
+			}
+			p.cmd.Println(aurora.Red(err.Error()))
+			continue
+		}
+		return answer, nil
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
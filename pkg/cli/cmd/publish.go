@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/pods"
+	"github.com/spiceai/spiceai/pkg/registry"
+	"golang.org/x/mod/semver"
+)
+
+var (
+	publishManifest string
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish <pod>@<version>",
+	Short: "Publishes a pod to the pod registry",
+	Long: `Validates a pod and publishes it to the pod registry as the given version, which must be a semantic
+version such as 1.0.0. The package has the pod's manifest, the reward functions and data files it refers to,
+and the README.md next to the manifest, with a checksum of each file.
+
+The pod is read from spicepods/<name>.yaml, or from --manifest, where <name> is the last part of its registry
+path. Files the manifest refers to must be within the manifest's directory.
+
+Publishes to the registry at --registry-url with --registry-token, or to a registry in a local directory with
+a file:// URL.`,
+	Example: `
+spice publish samples/trader@1.0.0
+spice publish samples/trader@1.1.0 --manifest ./trader/trader.yaml
+spice publish samples/trader@1.0.0 --registry-url file:///mnt/shared/spicepods
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		podPath, podVersion := registry.ParsePodReference(args[0])
+		podName := filepath.Base(podPath)
+
+		if podVersion == "" {
+			cmd.Printf("A version is required, such as %s@1.0.0\n", podPath)
+			os.Exit(1)
+		}
+		if !semver.IsValid("v" + podVersion) {
+			cmd.Printf("invalid version '%s': must be a semantic version such as 1.0.0\n", podVersion)
+			os.Exit(1)
+		}
+
+		manifestPath := publishManifest
+		if manifestPath == "" {
+			manifestPath = filepath.Join(rtcontext.PodsDir(), fmt.Sprintf("%s.yaml", strings.ToLower(podName)))
+		}
+
+		pod, err := pods.LoadPodFromManifest(manifestPath)
+		if err != nil {
+			cmd.Printf("invalid pod %s: %s\n", manifestPath, err.Error())
+			os.Exit(1)
+		}
+
+		err = pod.ValidateForTraining()
+		if err != nil {
+			cmd.Printf("invalid pod %s: %s\n", manifestPath, err.Error())
+			os.Exit(1)
+		}
+
+		pkg, metadata, err := registry.Package(podName, podVersion, manifestPath, pod.ReferencedFiles())
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(1)
+		}
+
+		cmd.Printf("Publishing %s@%s with %d files ...\n", podPath, podVersion, len(metadata.Files))
+
+		published, err := getRegistry("").Publish(podPath, podVersion, bytes.NewReader(pkg))
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(1)
+		}
+
+		err = printOutput(cmd, published, func() error {
+			cmd.Printf("Published %s@%s (%s)\n", podPath, published.Version, published.Checksum)
+			return nil
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	publishCmd.Flags().StringVar(&publishManifest, "manifest", "", "Publishes the pod with this manifest instead of the one in spicepods")
+	publishCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(publishCmd)
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/cli/runtime"
+	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
+)
+
+const (
+	// The runtime couldn't be reached or the pod isn't loaded
+	recommendExitError = 1
+	// The runtime was reached but couldn't make a recommendation, e.g. the pod hasn't trained
+	recommendExitNoRecommendation = 2
+
+	// --watch without a value re-queries every pod interval
+	watchPodInterval = "interval"
+)
+
+var (
+	recommendTag   string
+	recommendTime  string
+	recommendWatch string
+)
+
+var recommendCmd = &cobra.Command{
+	Use:   "recommend",
+	Short: "Gets a pod's recommended action",
+	Long: `Gets a pod's recommended action, with its confidence and the time range of the data it was based on.
+
+With --watch the recommendation is fetched again every pod interval, or every given duration,
+and changes from the previous recommendation are highlighted.
+
+Exits with 1 if the runtime can't be reached or the pod isn't loaded, and 2 if the runtime
+couldn't make a recommendation, e.g. because the pod hasn't been trained yet.`,
+	Example: `
+spice recommend trader
+spice recommend trader --tag v1 --time 2021-11-14T00:00:00Z
+spice recommend trader --watch
+spice recommend trader --watch=30s
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName := args[0]
+
+		inferenceTime, err := parseOptionalTime(recommendTime)
+		if err != nil {
+			cmd.Printf("invalid --time: %s\n", err.Error())
+			os.Exit(recommendExitError)
+		}
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(recommendExitError)
+		}
+
+		if recommendWatch == "" {
+			inference, err := runtimeClient.GetRecommendation(podName, recommendTag, inferenceTime)
+			if err != nil {
+				cmd.Println(err.Error())
+				os.Exit(recommendExitCode(inference, err))
+			}
+			err = printOutput(cmd, inference, func() error {
+				printRecommendation(cmd, podName, inference)
+				return nil
+			})
+			if err != nil {
+				cmd.Println(err.Error())
+				os.Exit(recommendExitError)
+			}
+			return
+		}
+
+		err = requireTableOutput("--watch")
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(recommendExitError)
+		}
+
+		interval, err := getWatchInterval(recommendWatch, runtimeClient, podName)
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(recommendExitError)
+		}
+
+		cmd.Printf("Watching recommendations for %s every %s\n", aurora.Bold(podName), interval)
+
+		// Errors while watching are printed and retried at the next interval
+		var previous *aiengine_pb.InferenceResult
+		for {
+			inference, err := runtimeClient.GetRecommendation(podName, recommendTag, inferenceTime)
+			if err != nil {
+				cmd.Printf("%s %s\n", aurora.Gray(12, time.Now().Format(time.Kitchen)), aurora.Red(err.Error()))
+			} else {
+				cmd.Println(formatRecommendationChange(previous, inference))
+				previous = inference
+			}
+
+			time.Sleep(interval)
+		}
+	},
+}
+
+// Maps the result of GetRecommendation to an exit code. A recommendation returned along with
+// its error is one the runtime was reached for but couldn't make.
+func recommendExitCode(inference *aiengine_pb.InferenceResult, err error) int {
+	if err == nil {
+		return 0
+	}
+	if inference != nil {
+		return recommendExitNoRecommendation
+	}
+	return recommendExitError
+}
+
+// Parses the --watch value, getting the pod's interval from the runtime for watchPodInterval
+func getWatchInterval(watch string, runtimeClient *runtime.RuntimeClient, podName string) (time.Duration, error) {
+	if watch != watchPodInterval {
+		interval, err := time.ParseDuration(watch)
+		if err != nil {
+			return 0, fmt.Errorf("invalid --watch: %w", err)
+		}
+		if interval <= 0 {
+			return 0, fmt.Errorf("invalid --watch: %s must be greater than 0", watch)
+		}
+		return interval, nil
+	}
+
+	pod, err := runtimeClient.GetPod(podName)
+	if err != nil {
+		return 0, err
+	}
+	if pod == nil {
+		return 0, fmt.Errorf("pod %s is not loaded", podName)
+	}
+
+	return time.ParseDuration(pod.Params.Interval)
+}
+
+func printRecommendation(cmd *cobra.Command, podName string, inference *aiengine_pb.InferenceResult) {
+	cmd.Printf("Recommendation for %s (model %s)\n", aurora.Bold(podName), inference.Tag)
+	cmd.Printf("  action:      %s\n", aurora.BrightCyan(inference.Action))
+	cmd.Printf("  confidence:  %.1f%%\n", inference.Confidence*100)
+	cmd.Printf("  data range:  %s\n", formatInferenceRange(inference))
+}
+
+// Formats a watched recommendation as one line, highlighting what changed since previous
+func formatRecommendationChange(previous *aiengine_pb.InferenceResult, inference *aiengine_pb.InferenceResult) string {
+	action := aurora.BrightCyan(inference.Action)
+	change := ""
+	if previous != nil {
+		if previous.Action != inference.Action {
+			action = aurora.Yellow(inference.Action).Bold()
+			change = aurora.Yellow(fmt.Sprintf(" (was %s)", previous.Action)).String()
+		} else if delta := (inference.Confidence - previous.Confidence) * 100; delta >= 0.1 {
+			change = aurora.Green(fmt.Sprintf(" (+%.1f%%)", delta)).String()
+		} else if delta <= -0.1 {
+			change = aurora.Red(fmt.Sprintf(" (%.1f%%)", delta)).String()
+		}
+	}
+
+	return fmt.Sprintf("%s %s %.1f%%%s  data %s", aurora.Gray(12, time.Now().Format(time.Kitchen)), action, inference.Confidence*100, change, formatInferenceRange(inference))
+}
+
+func formatInferenceRange(inference *aiengine_pb.InferenceResult) string {
+	return fmt.Sprintf("%s to %s", time.Unix(inference.Start, 0).UTC().Format(time.RFC3339), time.Unix(inference.End, 0).UTC().Format(time.RFC3339))
+}
+
+func init() {
+	recommendCmd.Flags().StringVar(&recommendTag, "tag", "latest", "Model tag to get the recommendation from")
+	recommendCmd.Flags().StringVar(&recommendTime, "time", "", "Get the recommendation at this unix or RFC3339 time instead of the latest")
+	recommendCmd.Flags().StringVar(&recommendWatch, "watch", "", "Keep fetching the recommendation every pod interval, or every given duration with --watch=<duration>")
+	recommendCmd.Flags().Lookup("watch").NoOptDefVal = watchPodInterval
+	recommendCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(recommendCmd)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spiceai/spiceai/pkg/api"
+	"github.com/spiceai/spiceai/pkg/cli/runtime"
+	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecommend(t *testing.T) {
+	t.Run("formatRecommendationChange() - Changes from the previous recommendation are highlighted", testFormatRecommendationChangeFunc())
+	t.Run("getWatchInterval() - Durations are parsed and interval uses the pod's", testGetWatchIntervalFunc())
+	t.Run("recommendExitCode() - Runtime errors exit 1 and missing recommendations exit 2", testRecommendExitCodeFunc())
+}
+
+func testFormatRecommendationChangeFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		inference := func(action string, confidence float32) *aiengine_pb.InferenceResult {
+			return &aiengine_pb.InferenceResult{Action: action, Confidence: confidence, Start: 1636848000, End: 1636851600}
+		}
+
+		tests := []struct {
+			name     string
+			previous *aiengine_pb.InferenceResult
+			current  *aiengine_pb.InferenceResult
+			contains []string
+			excludes []string
+		}{
+			{
+				name:     "first recommendation",
+				current:  inference("buy", 0.75),
+				contains: []string{"buy", "75.0%", "data 2021-11-14T00:00:00Z to 2021-11-14T01:00:00Z"},
+				excludes: []string{"(was", "(+", "(-"},
+			},
+			{
+				name:     "action changed",
+				previous: inference("sell", 0.75),
+				current:  inference("buy", 0.5),
+				contains: []string{"buy", "50.0%", " (was sell)"},
+				excludes: []string{"(+", "(-"},
+			},
+			{
+				name:     "confidence increased",
+				previous: inference("buy", 0.5),
+				current:  inference("buy", 0.6),
+				contains: []string{"60.0%", " (+10.0%)"},
+				excludes: []string{"(was"},
+			},
+			{
+				name:     "confidence decreased",
+				previous: inference("buy", 0.6),
+				current:  inference("buy", 0.5),
+				contains: []string{"50.0%", " (-10.0%)"},
+				excludes: []string{"(was"},
+			},
+			{
+				name:     "confidence changed less than 0.1%",
+				previous: inference("buy", 0.5),
+				current:  inference("buy", 0.5004),
+				contains: []string{"50.0%"},
+				excludes: []string{"(was", "(+", "(-"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				line := formatRecommendationChange(tt.previous, tt.current)
+				assert.NotContains(t, line, "\n")
+				for _, s := range tt.contains {
+					assert.Contains(t, line, s)
+				}
+				for _, s := range tt.excludes {
+					assert.NotContains(t, line, s)
+				}
+			})
+		}
+	}
+}
+
+func testGetWatchIntervalFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		runtimeClient := newStubRuntimeClient(t)
+
+		tests := []struct {
+			name     string
+			watch    string
+			podName  string
+			expected time.Duration
+			err      string
+		}{
+			{name: "duration", watch: "30s", podName: "trader", expected: 30 * time.Second},
+			{name: "pod interval", watch: watchPodInterval, podName: "trader", expected: 15 * time.Minute},
+			{name: "invalid duration", watch: "soon", podName: "trader", err: "invalid --watch"},
+			{name: "zero duration", watch: "0s", podName: "trader", err: "invalid --watch: 0s must be greater than 0"},
+			{name: "negative duration", watch: "-1m", podName: "trader", err: "invalid --watch: -1m must be greater than 0"},
+			{name: "pod not loaded", watch: watchPodInterval, podName: "missing", err: "pod missing is not loaded"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				interval, err := getWatchInterval(tt.watch, runtimeClient, tt.podName)
+				if tt.err != "" {
+					assert.Error(t, err)
+					assert.Contains(t, err.Error(), tt.err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, interval)
+			})
+		}
+	}
+}
+
+func testRecommendExitCodeFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		tests := []struct {
+			name      string
+			inference *aiengine_pb.InferenceResult
+			err       error
+			expected  int
+		}{
+			{name: "recommendation", inference: &aiengine_pb.InferenceResult{Action: "buy"}, expected: 0},
+			{name: "runtime error", err: errors.New("failed to reach runtime"), expected: recommendExitError},
+			{name: "no recommendation", inference: &aiengine_pb.InferenceResult{Response: &aiengine_pb.Response{Error: true}}, err: errors.New("not trained"), expected: recommendExitNoRecommendation},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, recommendExitCode(tt.inference, tt.err))
+			})
+		}
+
+		assert.Equal(t, 1, recommendExitError)
+		assert.Equal(t, 2, recommendExitNoRecommendation)
+
+		// The same mapping applied to what the runtime client returns
+		runtimeClient := newStubRuntimeClient(t)
+		inference, err := runtimeClient.GetRecommendation("trader", "latest", time.Time{})
+		assert.Equal(t, 0, recommendExitCode(inference, err))
+		inference, err = runtimeClient.GetRecommendation("untrained", "latest", time.Time{})
+		assert.Equal(t, recommendExitNoRecommendation, recommendExitCode(inference, err))
+
+		unreachable, err := runtime.NewRuntimeClient(nil, runtime.WithServerBaseUrl("http://127.0.0.1:1"), runtime.WithTimeout(time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		inference, err = unreachable.GetRecommendation("trader", "latest", time.Time{})
+		assert.Equal(t, recommendExitError, recommendExitCode(inference, err))
+	}
+}
+
+// Serves a runtime with a trained "trader" pod with a 15m interval and an "untrained" pod
+func newStubRuntimeClient(t *testing.T) *runtime.RuntimeClient {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/api/v0.1/pods/", func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v0.1/pods/"), "/")
+		switch {
+		case len(parts) == 1 && (parts[0] == "trader" || parts[0] == "untrained"):
+			writeStubJson(w, http.StatusOK, &api.PodDetails{Pod: &api.Pod{Name: parts[0]}, Params: &api.PodParams{Interval: "15m0s"}})
+		case len(parts) == 4 && parts[0] == "trader" && parts[3] == "recommendation":
+			writeStubJson(w, http.StatusOK, &aiengine_pb.InferenceResult{Action: "buy", Confidence: 0.75, Tag: parts[2]})
+		case len(parts) == 4 && parts[0] == "untrained" && parts[3] == "recommendation":
+			writeStubJson(w, http.StatusBadRequest, &aiengine_pb.InferenceResult{Response: &aiengine_pb.Response{Result: "not_trained", Message: "pod untrained has not been trained", Error: true}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	runtimeClient, err := runtime.NewRuntimeClient(nil, runtime.WithServerBaseUrl(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runtimeClient
+}
+
+func writeStubJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
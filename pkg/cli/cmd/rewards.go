@@ -2,13 +2,24 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/spec"
-	"github.com/spiceai/spiceai/pkg/util"
-	"gopkg.in/yaml.v2"
+)
+
+const (
+	rewardTemplateUniform  = "uniform"
+	rewardTemplateIncrease = "increase"
+	rewardTemplateDecrease = "decrease"
+)
+
+var (
+	rewardPod         string
+	rewardTemplate    string
+	rewardMeasurement string
 )
 
 var rewardCmd = &cobra.Command{
@@ -23,44 +34,42 @@ spice reward add
 var rewardAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add Reward - adds a reward to your Spice pod",
+	Long: `Adds a reward from a template for each of the pod's actions that doesn't have one.
+Comments and formatting in the pod manifest are kept.
+
+Templates:
+  uniform   every action is rewarded with 1
+  increase  actions are rewarded by how much --measurement increases
+  decrease  actions are rewarded by how much --measurement decreases`,
 	Example: `
 spice reward add
+spice reward add --pod trader
+spice reward add --pod trader --template increase --measurement local.portfolio.usd_balance
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		manifests := pods.FindAllManifestPaths()
-		if len(manifests) == 0 {
-			cmd.Println("No pods detected!")
+		pod, err := selectPod(rewardPod)
+		if err != nil {
+			cmd.Println(err.Error())
 			return
 		}
 
-		podPath := manifests[0]
+		rewardWith, err := rewardFromTemplate(pod, rewardTemplate, rewardMeasurement)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
 
-		pod, err := pods.LoadPodFromManifest(podPath)
+		manifest, err := pods.LoadManifest(pod.ManifestPath())
 		if err != nil {
-			cmd.Println(fmt.Errorf("error loading Pod %s: %w", podPath, err))
+			cmd.Println(err.Error())
 			return
 		}
 
-		// Check for existing rewards.  If they exist or are malformed, warn and do nothing.
-		if pod.Training != nil {
-			rewardsType := fmt.Sprintf("%T", pod.Training.Rewards)
-			switch rewardsType {
-			case "string":
-				if pod.Training.Rewards.(string) != "uniform" {
-					cmd.Println("Rewards section malformed!  'rewards' must be either 'uniform' or an array of rewards.")
-					return
-				}
-			case "[]interface {}":
-				var rewards []spec.RewardSpec
-				err := viper.UnmarshalKey("training.rewards", &rewards)
-				if err != nil {
-					cmd.Println("Rewards section malformed!  'rewards' must be either 'uniform' or an array of rewards.")
-					return
-				} else if len(rewards) > 0 {
-					cmd.Println("Pod already has rewards defined!")
-					return
-				}
-			}
+		// Check for existing rewards.  If they are malformed, warn and do nothing.
+		existingRewards, err := manifest.RewardNames()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
 		}
 
 		actions := pod.Actions()
@@ -70,35 +79,77 @@ spice reward add
 			return
 		}
 
-		if pod.PodSpec.Training == nil {
-			pod.PodSpec.Training = &spec.TrainingSpec{}
+		actionNames := make([]string, 0, len(actions))
+		for actionName := range actions {
+			actionNames = append(actionNames, actionName)
 		}
+		sort.Strings(actionNames)
 
-		defaultRewards := []spec.RewardSpec{}
-		for _, action := range pod.PodSpec.Actions {
-			reward := spec.RewardSpec{Reward: action.Name, With: "reward = 1"}
-			defaultRewards = append(defaultRewards, reward)
+		var newRewards []spec.RewardSpec
+		for _, actionName := range actionNames {
+			if !containsString(existingRewards, actionName) {
+				newRewards = append(newRewards, spec.RewardSpec{Reward: actionName, With: rewardWith})
+			}
 		}
 
-		pod.Training.Rewards = defaultRewards
+		if len(newRewards) == 0 {
+			cmd.Printf("Pod %s already has rewards for all of its actions!\n", pod.Name)
+			return
+		}
 
-		marshalledPod, err := yaml.Marshal(pod.PodSpec)
+		err = manifest.AddRewards(newRewards...)
 		if err != nil {
-			cmd.Println(fmt.Errorf(err.Error()))
+			cmd.Println(err.Error())
 			return
 		}
 
-		err = util.WriteToExistingFile(podPath, marshalledPod)
+		err = manifest.Save()
+		recordManifestEdit(cmd, "reward add", pod, err)
 		if err != nil {
-			cmd.Println(fmt.Errorf(err.Error()))
+			cmd.Println(err.Error())
 			return
 		}
 
-		fmt.Printf("Uniform rewards added to pod %s.\n", pod.Name)
+		cmd.Printf("Added %s rewards to pod %s for %d actions.\n", rewardTemplate, pod.Name, len(newRewards))
 	},
 }
 
+// Returns the reward script for a template. Measurements are fully-qualified, e.g. local.portfolio.usd_balance.
+func rewardFromTemplate(pod *pods.Pod, template string, measurement string) (string, error) {
+	switch template {
+	case rewardTemplateUniform:
+		return "reward = 1", nil
+	case rewardTemplateIncrease, rewardTemplateDecrease:
+		if measurement == "" {
+			return "", fmt.Errorf("the %s template requires --measurement", template)
+		}
+		if !containsString(pod.MeasurementNames(), measurement) {
+			return "", fmt.Errorf("measurement %s not found in pod %s. Available measurements: %s", measurement, pod.Name, strings.Join(pod.MeasurementNames(), ", "))
+		}
+		// Reward functions see state with underscores in place of dots
+		stateKey := strings.ReplaceAll(measurement, ".", "_")
+		if template == rewardTemplateIncrease {
+			return fmt.Sprintf("reward = next_state[\"%s\"] - current_state[\"%s\"]", stateKey, stateKey), nil
+		}
+		return fmt.Sprintf("reward = current_state[\"%s\"] - next_state[\"%s\"]", stateKey, stateKey), nil
+	}
+
+	return "", fmt.Errorf("unknown reward template %s, expected %s, %s or %s", template, rewardTemplateUniform, rewardTemplateIncrease, rewardTemplateDecrease)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
+	rewardAddCmd.Flags().StringVar(&rewardPod, "pod", "", "Pod to add rewards to. Required when the app has more than one pod.")
+	rewardAddCmd.Flags().StringVar(&rewardTemplate, "template", rewardTemplateUniform, "Reward template: uniform, increase or decrease")
+	rewardAddCmd.Flags().StringVar(&rewardMeasurement, "measurement", "", "Fully-qualified measurement the increase and decrease templates reward changes in")
 	rewardCmd.AddCommand(rewardAddCmd)
 	rewardCmd.Flags().BoolP("help", "h", false, "Print this help message")
 	RootCmd.AddCommand(rewardCmd)
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Searches the pod registry",
+	Long: `Lists the pods in the pod registry matching the query, or every pod without one.
+Add a pod with 'spice add <name>'.`,
+	Example: `
+spice search
+spice search trader
+spice search trader --registry-url https://pods.example.com/api
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		query := ""
+		if len(args) > 0 {
+			query = args[0]
+		}
+
+		summaries, err := getRegistry("").Search(query)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		rows := make([]*searchRow, 0, len(summaries))
+		for _, summary := range summaries {
+			rows = append(rows, &searchRow{
+				Name:          summary.Name,
+				LatestVersion: summary.LatestVersion,
+				Description:   summary.Description,
+			})
+		}
+
+		err = printOutput(cmd, summaries, func() error {
+			if len(rows) == 0 {
+				cmd.Println("No pods found.")
+				return nil
+			}
+			return util.MarshalAndPrintTable(cmd.OutOrStdout(), rows)
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+		}
+	},
+}
+
+type searchRow struct {
+	Name          string `csv:"name"`
+	LatestVersion string `csv:"latest_version"`
+	Description   string `csv:"description"`
+}
+
+func init() {
+	searchCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(searchCmd)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/snapshot"
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+var (
+	snapshotOutput    string
+	snapshotOverwrite bool
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Snapshot and restore runtime state",
+	Example: `
+spice snapshot create
+spice snapshot restore ./runtime.spicesnapshot
+`,
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Saves every pod's manifest, observations, interpretations, training runs and models from a running runtime",
+	Example: `
+spice snapshot create
+spice snapshot create -o ./backups/runtime.spicesnapshot
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		outputPath := snapshotOutput
+		if outputPath == "" {
+			outputPath = fmt.Sprintf("runtime-%s%s", time.Now().Format("20060102T150405"), snapshot.FileExtension)
+		}
+
+		if _, err := os.Stat(outputPath); err == nil && !snapshotOverwrite {
+			cmd.Printf("%s: not overwriting the existing snapshot at '%s', specify --overwrite to override this behavior\n", aurora.Red("error"), aurora.Blue(outputPath))
+			return
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			cmd.Println(err.Error())
+			return
+		}
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		// Write to a temp file first so a failed snapshot doesn't leave a partial archive
+		tmpPath := outputPath + ".tmp"
+		archive, err := os.Create(tmpPath)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = runtimeClient.CreateSnapshot(archive)
+		closeErr := archive.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(tmpPath)
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = os.Rename(tmpPath, outputPath)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		cmd.Println(aurora.Green(fmt.Sprintf("Snapshot saved to %s", outputPath)))
+	},
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restores the pods in a snapshot into a running runtime",
+	Example: `
+spice snapshot restore ./runtime.spicesnapshot
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		manifest, err := runtimeClient.RestoreSnapshot(args[0])
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		err = printOutput(cmd, manifest, func() error {
+			err := util.MarshalAndPrintTable(cmd.OutOrStdout(), manifest.Pods)
+			if err != nil {
+				return err
+			}
+			cmd.Println(aurora.Green(fmt.Sprintf("Restored %d pods from a snapshot taken %s", len(manifest.Pods), time.Unix(manifest.CreatedAt, 0).Format(time.RFC1123))))
+			return nil
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+	},
+}
+
+func init() {
+	snapshotCreateCmd.Flags().StringVarP(&snapshotOutput, "output", "o", "", "Path of the snapshot archive, defaults to runtime-<time>.spicesnapshot")
+	snapshotCreateCmd.Flags().BoolVar(&snapshotOverwrite, "overwrite", false, "Overwrite a snapshot that already exists")
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(snapshotCmd)
+}
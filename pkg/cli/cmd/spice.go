@@ -1,12 +1,30 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/spiceai/spiceai/pkg/cli/profiles"
+	"github.com/spiceai/spiceai/pkg/cli/runtime"
 	"github.com/spiceai/spiceai/pkg/context"
+	"github.com/spiceai/spiceai/pkg/registry"
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+const (
+	outputFlag     = "output"
+	runtimeUrlFlag = "runtime-url"
+	tokenFlag      = "token"
+	timeoutFlag    = "timeout"
+	profileFlag    = "profile"
+
+	registryUrlFlag   = "registry-url"
+	registryTokenFlag = "registry-token"
 )
 
 var (
@@ -14,11 +32,27 @@ var (
 	algorithmFlag      string
 	numberEpisodesFlag int64
 	loggers            []string
+	rtcontext          context.RuntimeContext
+
+	// Resolved from the global flags, SPICE_* env vars and the selected profile before each command runs
+	outputFormat   string
+	runtimeUrl     string
+	runtimeToken   string
+	runtimeTimeout time.Duration
 )
 
 var RootCmd = &cobra.Command{
 	Use:   "spice",
 	Short: "Spice.ai CLI",
+	Long: `Spice.ai CLI
+
+Commands that talk to a runtime connect to the local app's runtime, unless --runtime-url is given or the
+selected profile has a runtime url. Global flags can also be set with SPICE_OUTPUT, SPICE_RUNTIME_URL,
+SPICE_TOKEN, SPICE_TIMEOUT and SPICE_PROFILE, and override the profile's values.
+
+Pods are added from and published to spicerack.org, unless --registry-url or SPICE_REGISTRY_URL names another
+registry.`,
+	PersistentPreRunE: resolveGlobalFlags,
 }
 
 // Execute adds all child commands to the root command.
@@ -26,7 +60,8 @@ func Execute() {
 	cobra.OnInitialize(initConfig)
 
 	// All CLI commands run in the "metal" context
-	err := context.SetDefaultContext()
+	var err error
+	rtcontext, err = context.NewDefaultContext()
 	if err != nil {
 		RootCmd.Println(err.Error())
 		os.Exit(1)
@@ -43,3 +78,132 @@ func initConfig() {
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.AutomaticEnv()
 }
+
+// Applies flags, then env vars, then the selected profile, then the defaults
+func resolveGlobalFlags(cmd *cobra.Command, args []string) error {
+	profile, err := selectedProfile()
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		profile = &profiles.Profile{}
+	}
+
+	outputFormat = firstNonEmpty(viper.GetString(outputFlag), profile.Output, util.OutputTable)
+	err = util.ValidateOutputFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	runtimeUrl = firstNonEmpty(viper.GetString(runtimeUrlFlag), profile.RuntimeUrl)
+	if runtimeUrl != "" {
+		err = profiles.ValidateRuntimeUrl(runtimeUrl)
+		if err != nil {
+			return err
+		}
+	}
+
+	runtimeToken = firstNonEmpty(viper.GetString(tokenFlag), profile.Token)
+
+	runtimeTimeout = profile.Timeout
+	if timeout := viper.GetString(timeoutFlag); timeout != "" {
+		runtimeTimeout, err = time.ParseDuration(timeout)
+		if err != nil || runtimeTimeout < 0 {
+			return fmt.Errorf("invalid --timeout '%s', expected a duration like 30s", timeout)
+		}
+	}
+
+	return nil
+}
+
+// Returns the registry pods at path are fetched from, or published to if path is empty
+func getRegistry(path string) registry.SpiceRegistry {
+	return registry.GetRegistry(rtcontext.PodsDir(), path, registryOptions("")...)
+}
+
+// Returns the options for the registry at --registry-url, or at source if set, such as a locked pod's source.
+// Local pods' sources are paths rather than registries, so are ignored.
+func registryOptions(source string) []registry.Option {
+	url := viper.GetString(registryUrlFlag)
+	if source != "" && !filepath.IsAbs(source) {
+		url = source
+	}
+	return []registry.Option{registry.WithUrl(url), registry.WithToken(viper.GetString(registryTokenFlag))}
+}
+
+func loadLockfile() (*registry.Lockfile, error) {
+	return registry.LoadLockfile(filepath.Join(rtcontext.AppDir(), registry.LockfileName))
+}
+
+// Returns the profile selected with --profile, or the current profile. Returns nil if none is selected.
+func selectedProfile() (*profiles.Profile, error) {
+	profilesPath, err := profiles.DefaultPath()
+	if err != nil {
+		if name := viper.GetString(profileFlag); name != "" {
+			return nil, fmt.Errorf("failed to find profile %s: %w", name, err)
+		}
+		return nil, nil
+	}
+
+	p, err := profiles.Load(profilesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	name := viper.GetString(profileFlag)
+	if name == "" {
+		name = p.Current
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	profile := p.Get(name)
+	if profile == nil {
+		return nil, fmt.Errorf("profile %s not found in %s. Add it with 'spice profile add %s'", name, profilesPath, name)
+	}
+
+	return profile, nil
+}
+
+func newRuntimeClient() (*runtime.RuntimeClient, error) {
+	return runtime.NewRuntimeClient(rtcontext, runtime.WithServerBaseUrl(runtimeUrl), runtime.WithToken(runtimeToken), runtime.WithTimeout(runtimeTimeout))
+}
+
+// Prints in using the global output format, or as a table using printTable
+func printOutput(cmd *cobra.Command, in interface{}, printTable func() error) error {
+	if outputFormat == util.OutputTable {
+		return printTable()
+	}
+	return util.MarshalAndPrint(cmd.OutOrStdout(), outputFormat, in, nil)
+}
+
+// For commands that stream updates, which are only printed as tables
+func requireTableOutput(feature string) error {
+	if outputFormat != util.OutputTable {
+		return fmt.Errorf("%s only supports table output", feature)
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func init() {
+	RootCmd.PersistentFlags().StringP(outputFlag, "o", "", "Output format: table, json or yaml. Defaults to the profile's output or table.")
+	RootCmd.PersistentFlags().String(runtimeUrlFlag, "", "URL of the runtime to connect to, e.g. https://spice.example.com. Defaults to the local app's runtime.")
+	RootCmd.PersistentFlags().String(tokenFlag, "", "Bearer token sent to the runtime")
+	RootCmd.PersistentFlags().String(timeoutFlag, "", "Timeout for each request to the runtime, e.g. 30s. Defaults to no timeout.")
+	RootCmd.PersistentFlags().String(profileFlag, "", "Connection profile to use instead of the current profile")
+	RootCmd.PersistentFlags().String(registryUrlFlag, "", "Base URL of the pod registry, e.g. https://pods.example.com/api. Defaults to spicerack.org.")
+	RootCmd.PersistentFlags().String(registryTokenFlag, "", "Bearer token sent to the pod registry")
+	for _, name := range []string{outputFlag, runtimeUrlFlag, tokenFlag, timeoutFlag, profileFlag, registryUrlFlag, registryTokenFlag} {
+		_ = viper.BindPFlag(name, RootCmd.PersistentFlags().Lookup(name))
+	}
+}
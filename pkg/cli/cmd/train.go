@@ -1,22 +1,13 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
 	"os"
 
 	"github.com/logrusorgru/aurora"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/spiceai/spiceai/pkg/cli/runtime"
-	"github.com/spiceai/spiceai/pkg/config"
-	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/proto/runtime_pb"
-	"github.com/spiceai/spiceai/pkg/util"
 )
 
 var trainCmd = &cobra.Command{
@@ -36,7 +27,7 @@ spice train LogPruner --loggers=tensorboard
 		podName := podNameOrPath
 		_, err := os.Stat(podNameOrPath)
 		if err != nil {
-			manifests = pods.FindAllManifestPaths()
+			manifests = pods.FindAllManifestPaths(rtcontext.PodsDir())
 		} else {
 			err := runtime.Run(contextFlag, podPath)
 			if err != nil {
@@ -46,83 +37,52 @@ spice train LogPruner --loggers=tensorboard
 			return
 		}
 
-		if len(manifests) == 0 || podName == "" || podPath == "" {
-			cmd.Println("pod not found")
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
 			return
 		}
 
-		var selectedPod *pods.Pod
-		for _, podPath := range manifests {
-			pod, err := pods.LoadPodFromManifest(podPath)
-			if err != nil {
-				cmd.Println(err.Error())
+		// A remote runtime's pods aren't in the local app, so the runtime checks the pod exists
+		if runtimeUrl == "" {
+			if len(manifests) == 0 || podName == "" || podPath == "" {
+				cmd.Println("pod not found")
 				return
 			}
 
-			if pod.Name == podName {
-				selectedPod = pod
+			var selectedPod *pods.Pod
+			for _, podPath := range manifests {
+				pod, err := pods.LoadPodFromManifest(podPath)
+				if err != nil {
+					cmd.Println(err.Error())
+					return
+				}
+
+				if pod.Name == podName {
+					selectedPod = pod
+				}
 			}
-		}
 
-		if selectedPod == nil {
-			cmd.Printf("the pod '%s' does not exist\n", podNameOrPath)
-			return
-		}
-
-		v := viper.New()
-		appDir := context.CurrentContext().AppDir()
-		runtimeConfig, err := config.LoadRuntimeConfiguration(v, appDir)
-		if err != nil {
-			cmd.Println("failed to load runtime configuration")
-			return
-		}
-
-		serverBaseUrl := runtimeConfig.ServerBaseUrl()
-
-		err = util.IsRuntimeServerHealthy(serverBaseUrl, http.DefaultClient)
-		if err != nil {
-			cmd.Printf("failed to reach %s. is the spice runtime running?\n", serverBaseUrl)
-			return
+			if selectedPod == nil {
+				cmd.Printf("the pod '%s' does not exist\n", podNameOrPath)
+				return
+			}
 		}
 
-		trainUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/train", serverBaseUrl, selectedPod.Name)
-
 		trainRequest := &runtime_pb.TrainModel{
 			LearningAlgorithm: algorithmFlag,
 			NumberEpisodes:    numberEpisodesFlag,
 			Loggers:           loggers,
 		}
-		trainRequestBytes, err := json.Marshal(&trainRequest)
-		if err != nil {
-			return
-		}
 
-		response, err := http.DefaultClient.Post(trainUrl, "application/json", bytes.NewReader(trainRequestBytes))
+		queued, err := runtimeClient.StartTraining(podName, trainRequest)
 		if err != nil {
-			cmd.Printf("failed to start training: %s\n", err.Error())
+			cmd.Println(err.Error())
 			return
 		}
 
-		if response.StatusCode != 200 {
-			if response.StatusCode == 404 {
-				cmd.Printf("Failed to start training. The pod '%s' cannot be found. Has it been added?", podNameOrPath)
-				return
-			}
-
-			body, err := ioutil.ReadAll(response.Body)
-			defer response.Body.Close()
-
-			if err != nil {
-				cmd.Printf("failed to start training: %s\n", err.Error())
-				return
-			}
-
-			if len(body) > 0 {
-				cmd.Printf("failed to start training: %s\n", body)
-			} else {
-				cmd.Printf("failed to start training: %s\n", response.Status)
-			}
-
+		if queued {
+			cmd.Println(aurora.Yellow("training queued, it will start when the runtime has a free training slot"))
 			return
 		}
 
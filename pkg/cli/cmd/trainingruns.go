@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/api"
+	"github.com/spiceai/spiceai/pkg/cli/runtime"
+	"github.com/spiceai/spiceai/pkg/proto/runtime_pb"
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+const (
+	flightStatusRunning  = "running"
+	flightStatusComplete = "complete"
+	flightStatusError    = "error"
+
+	actionHistogramWidth = 40
+)
+
+var (
+	trainingRunsWatch     bool
+	trainingRunsInterval  time.Duration
+	trainingRunsLogger    string
+	trainingRunsNoBrowser bool
+)
+
+var trainingRunsCmd = &cobra.Command{
+	Use:     "training-runs",
+	Aliases: []string{"flights"},
+	Short:   "Inspect a pod's training runs",
+	Example: `
+spice training-runs list trader
+spice training-runs show trader 1
+spice training-runs show trader 2 --watch
+spice training-runs logs trader 1
+`,
+}
+
+var trainingRunsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists a pod's training runs",
+	Example: `
+spice training-runs list trader
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		flights, err := runtimeClient.GetFlights(args[0])
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if flights == nil {
+			flights = []*api.Flight{}
+		}
+
+		sort.SliceStable(flights, func(i, j int) bool {
+			return flights[i].Start < flights[j].Start
+		})
+
+		err = printOutput(cmd, flights, func() error {
+			if len(flights) == 0 {
+				cmd.Printf("No training runs found for pod %s\n", args[0])
+				return nil
+			}
+
+			rows := make([]*flightRow, 0, len(flights))
+			for _, f := range flights {
+				rows = append(rows, newFlightRow(f))
+			}
+			return util.MarshalAndPrintTable(cmd.OutOrStdout(), rows)
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+	},
+}
+
+var trainingRunsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows a training run's episodes with a score sparkline and a histogram of actions taken",
+	Example: `
+spice training-runs show trader 1
+spice training-runs show trader 2 --watch
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName, flightId := args[0], args[1]
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		flight, err := getFlight(runtimeClient, podName, flightId)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if !trainingRunsWatch || flightStatus(flight) != flightStatusRunning {
+			err = printOutput(cmd, flight, func() error {
+				return printFlight(cmd.OutOrStdout(), podName, flight)
+			})
+			if err != nil {
+				cmd.Println(err.Error())
+			}
+			return
+		}
+
+		err = requireTableOutput("--watch")
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		cmd.Printf("Watching training run %s of pod %s\n", aurora.Bold(flight.Id), aurora.Bold(podName))
+
+		// Errors while watching are printed and retried at the next interval
+		printed := 0
+		for {
+			for ; printed < len(flight.Episodes); printed++ {
+				cmd.Println(formatEpisodeProgress(flight.Episodes[:printed+1]))
+			}
+
+			if flightStatus(flight) != flightStatusRunning {
+				break
+			}
+
+			time.Sleep(trainingRunsInterval)
+
+			latest, err := getFlight(runtimeClient, podName, flightId)
+			if err != nil {
+				cmd.Println(aurora.Red(err.Error()))
+				continue
+			}
+			flight = latest
+		}
+
+		cmd.Println()
+		err = printFlight(cmd.OutOrStdout(), podName, flight)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+	},
+}
+
+var trainingRunsLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Opens a training run's logger, such as TensorBoard, in the browser",
+	Long: `Opens a training run's logger, such as TensorBoard, in the browser.
+The pod must have been trained with the logger, e.g. with spice train trader --loggers=tensorboard.`,
+	Example: `
+spice training-runs logs trader 1
+spice training-runs logs trader 1 --no-browser
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		podName, flightId := args[0], args[1]
+
+		runtimeClient, err := newRuntimeClient()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		flight, err := getFlight(runtimeClient, podName, flightId)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		loggerId := trainingRunsLogger
+		if loggerId == "" {
+			if len(flight.Loggers) == 0 {
+				cmd.Printf("training run %s was not trained with any loggers. Train with --loggers=tensorboard to record logs.\n", flightId)
+				return
+			}
+			loggerId = flight.Loggers[0]
+		}
+
+		address, err := runtimeClient.OpenFlightLogger(podName, flightId, loggerId)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		logger := map[string]string{"logger": loggerId, "url": address}
+		err = printOutput(cmd, logger, func() error {
+			cmd.Printf("%s logs for training run %s are at %s\n", loggerId, flightId, aurora.BrightCyan(address))
+			return nil
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+		if trainingRunsNoBrowser || outputFormat != util.OutputTable {
+			return
+		}
+
+		err = util.OpenBrowser(address)
+		if err != nil {
+			cmd.Printf("failed to open browser: %s\n", err.Error())
+			return
+		}
+	},
+}
+
+type flightRow struct {
+	Id        string `csv:"id"`
+	Algorithm string `csv:"algorithm"`
+	Started   string `csv:"started"`
+	Duration  string `csv:"duration"`
+	Episodes  int    `csv:"episodes"`
+	BestScore string `csv:"best_score"`
+	Status    string `csv:"status"`
+}
+
+func newFlightRow(flight *api.Flight) *flightRow {
+	bestScore := ""
+	if scores := episodeScores(flight.Episodes); len(scores) > 0 {
+		bestScore = fmt.Sprintf("%.2f", maxScore(scores))
+	}
+
+	return &flightRow{
+		Id:        flight.Id,
+		Algorithm: flight.Algorithm,
+		Started:   time.Unix(flight.Start, 0).Format(time.RFC1123),
+		Duration:  flightDuration(flight).String(),
+		Episodes:  len(flight.Episodes),
+		BestScore: bestScore,
+		Status:    flightStatus(flight),
+	}
+}
+
+type episodeRow struct {
+	Episode  int64  `csv:"episode"`
+	Score    string `csv:"score"`
+	Actions  uint64 `csv:"actions"`
+	Duration string `csv:"duration"`
+	Error    string `csv:"error"`
+}
+
+func printFlight(w io.Writer, podName string, flight *api.Flight) error {
+	fmt.Fprintf(w, "%s %s\n", aurora.Bold("Training run:"), flight.Id)
+	fmt.Fprintf(w, "%s %s\n", aurora.Bold("Pod:"), podName)
+	fmt.Fprintf(w, "%s %s\n", aurora.Bold("Algorithm:"), flight.Algorithm)
+	fmt.Fprintf(w, "%s %s\n", aurora.Bold("Started:"), time.Unix(flight.Start, 0).Format(time.RFC1123))
+	fmt.Fprintf(w, "%s %s\n", aurora.Bold("Duration:"), flightDuration(flight))
+	fmt.Fprintf(w, "%s %s\n", aurora.Bold("Status:"), flightStatus(flight))
+	if len(flight.Loggers) > 0 {
+		fmt.Fprintf(w, "%s %s\n", aurora.Bold("Loggers:"), strings.Join(flight.Loggers, ", "))
+	}
+
+	fmt.Fprintf(w, "\n%s\n", aurora.Bold("Episodes"))
+	if len(flight.Episodes) == 0 {
+		fmt.Fprintln(w, "  none yet")
+		return nil
+	}
+
+	rows := make([]*episodeRow, 0, len(flight.Episodes))
+	for _, e := range flight.Episodes {
+		var actions uint64
+		for _, count := range e.ActionsTaken {
+			actions += count
+		}
+		rows = append(rows, &episodeRow{
+			Episode:  e.Episode,
+			Score:    fmt.Sprintf("%.2f", e.Score),
+			Actions:  actions,
+			Duration: (time.Duration(e.End-e.Start) * time.Second).String(),
+			// The table is split on commas
+			Error: strings.ReplaceAll(strings.TrimSpace(e.Error+" "+e.ErrorMessage), ",", ";"),
+		})
+	}
+	err := util.MarshalAndPrintTable(w, rows)
+	if err != nil {
+		return err
+	}
+
+	scores := episodeScores(flight.Episodes)
+	fmt.Fprintf(w, "\n%s %s  (%.2f to %.2f)\n", aurora.Bold("Score"), util.Sparkline(scores), minScore(scores), maxScore(scores))
+
+	fmt.Fprintf(w, "\n%s\n", aurora.Bold("Actions taken"))
+	printActionHistogram(w, flight.Episodes)
+
+	return nil
+}
+
+// Prints the actions taken across all episodes, most taken first
+func printActionHistogram(w io.Writer, episodes []*runtime_pb.Episode) {
+	totals := make(map[string]uint64)
+	var total uint64
+	for _, e := range episodes {
+		for action, count := range e.ActionsTaken {
+			totals[action] += count
+			total += count
+		}
+	}
+
+	if total == 0 {
+		fmt.Fprintln(w, "  none")
+		return
+	}
+
+	actions := make([]string, 0, len(totals))
+	nameWidth := 0
+	for action := range totals {
+		actions = append(actions, action)
+		if len(action) > nameWidth {
+			nameWidth = len(action)
+		}
+	}
+	sort.SliceStable(actions, func(i, j int) bool {
+		if totals[actions[i]] == totals[actions[j]] {
+			return actions[i] < actions[j]
+		}
+		return totals[actions[i]] > totals[actions[j]]
+	})
+
+	most := float64(totals[actions[0]])
+	for _, action := range actions {
+		count := totals[action]
+		bar := util.Bar(float64(count), most, actionHistogramWidth)
+		fmt.Fprintf(w, "  %-*s %s %d (%.1f%%)\n", nameWidth, action, aurora.BrightCyan(bar), count, float64(count)/float64(total)*100)
+	}
+}
+
+// Formats the latest of episodes as one line, with a sparkline of the scores so far
+func formatEpisodeProgress(episodes []*runtime_pb.Episode) string {
+	e := episodes[len(episodes)-1]
+	if e.Error != "" {
+		return fmt.Sprintf("episode %d %s", e.Episode, aurora.Red(strings.TrimSpace(e.Error+": "+e.ErrorMessage)))
+	}
+	return fmt.Sprintf("episode %d  score %.2f  %s", e.Episode, e.Score, util.Sparkline(episodeScores(episodes)))
+}
+
+func getFlight(runtimeClient *runtime.RuntimeClient, podName string, flightId string) (*api.Flight, error) {
+	flight, err := runtimeClient.GetFlight(podName, flightId)
+	if err != nil {
+		return nil, err
+	}
+	if flight == nil {
+		return nil, fmt.Errorf("training run %s of pod %s not found", flightId, podName)
+	}
+	return flight, nil
+}
+
+// Incomplete flights have a zero end time, which is before the unix epoch
+func flightStatus(flight *api.Flight) string {
+	if flight.End <= 0 {
+		return flightStatusRunning
+	}
+	for _, e := range flight.Episodes {
+		if e.Error != "" {
+			return flightStatusError
+		}
+	}
+	return flightStatusComplete
+}
+
+func flightDuration(flight *api.Flight) time.Duration {
+	if flight.End <= 0 {
+		return time.Since(time.Unix(flight.Start, 0)).Round(time.Second)
+	}
+	return time.Duration(flight.End-flight.Start) * time.Second
+}
+
+func episodeScores(episodes []*runtime_pb.Episode) []float64 {
+	scores := make([]float64, 0, len(episodes))
+	for _, e := range episodes {
+		if e.Error == "" {
+			scores = append(scores, e.Score)
+		}
+	}
+	return scores
+}
+
+func minScore(scores []float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	min := scores[0]
+	for _, s := range scores {
+		if s < min {
+			min = s
+		}
+	}
+	return min
+}
+
+func maxScore(scores []float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	max := scores[0]
+	for _, s := range scores {
+		if s > max {
+			max = s
+		}
+	}
+	return max
+}
+
+func init() {
+	trainingRunsShowCmd.Flags().BoolVarP(&trainingRunsWatch, "watch", "w", false, "Print episodes as they complete until the training run ends")
+	trainingRunsShowCmd.Flags().DurationVar(&trainingRunsInterval, "interval", 2*time.Second, "How often to check for new episodes with --watch")
+	trainingRunsLogsCmd.Flags().StringVar(&trainingRunsLogger, "logger", "", "Logger to open. Defaults to the first logger the training run was trained with.")
+	trainingRunsLogsCmd.Flags().BoolVar(&trainingRunsNoBrowser, "no-browser", false, "Print the logger's address without opening a browser")
+
+	trainingRunsCmd.AddCommand(trainingRunsListCmd)
+	trainingRunsCmd.AddCommand(trainingRunsShowCmd)
+	trainingRunsCmd.AddCommand(trainingRunsLogsCmd)
+	trainingRunsCmd.Flags().BoolP("help", "h", false, "Prints this help message")
+	RootCmd.AddCommand(trainingRunsCmd)
+}
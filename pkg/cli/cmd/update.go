@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"github.com/spiceai/spiceai/pkg/registry"
+)
+
+var (
+	updateDryRun bool
+
+	errUpdateDryRun = errors.New("dry run")
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update [pod...]",
+	Short: "Updates pods to the latest version in their registry",
+	Long: `Updates the pods in spicepods.lock, or only the given pods, to the latest version in the registry they were
+added from, showing the changes to each pod's manifest. spicepods.lock is updated with the new versions.
+
+With --dry-run the manifest changes are shown without updating anything.`,
+	Example: `
+spice update
+spice update samples/LogPruner
+spice update --dry-run
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		lockfile, err := loadLockfile()
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		outdated, err := findOutdatedPods(lockfile, args)
+		if err != nil {
+			cmd.Println(err.Error())
+			return
+		}
+
+		if len(outdated) == 0 {
+			cmd.Println("All pods are up to date.")
+			return
+		}
+
+		for _, pod := range outdated {
+			cmd.Printf("Updating %s from %s to %s ...\n", pod.Pod, firstNonEmpty(pod.Current, "an unknown version"), pod.Latest)
+
+			fetched, err := registry.Install(rtcontext.PodsDir(), pod.Pod, pod.Latest, func(staged *registry.FetchedPod) error {
+				err := printManifestDiff(cmd, pod, staged)
+				if err != nil {
+					return err
+				}
+				if updateDryRun {
+					return errUpdateDryRun
+				}
+				return nil
+			}, registryOptions(pod.Source)...)
+			if errors.Is(err, errUpdateDryRun) {
+				continue
+			}
+			if err != nil {
+				cmd.Println(err.Error())
+				return
+			}
+
+			// Saved after each pod so the pods already updated stay locked if a later one fails
+			lockfile.Set(pod.Pod, fetched)
+			err = lockfile.Save()
+			if err != nil {
+				cmd.Printf("failed to update %s: %s\n", registry.LockfileName, err.Error())
+				return
+			}
+
+			cmd.Printf("Updated %s to %s\n", rtcontext.GetSpiceAppRelativePath(fetched.ManifestPath), fetched.Version)
+		}
+	},
+}
+
+// Prints the changes between the installed manifest of the pod and the staged new version's manifest
+func printManifestDiff(cmd *cobra.Command, pod *outdatedPod, staged *registry.FetchedPod) error {
+	manifestPath := filepath.Join(rtcontext.PodsDir(), filepath.Base(staged.ManifestPath))
+	relativePath := rtcontext.GetSpiceAppRelativePath(manifestPath)
+
+	current, err := os.ReadFile(manifestPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	latest, err := os.ReadFile(staged.ManifestPath)
+	if err != nil {
+		return err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(current)),
+		B:        difflib.SplitLines(string(latest)),
+		FromFile: fmt.Sprintf("%s (%s)", relativePath, firstNonEmpty(pod.Current, "installed")),
+		ToFile:   fmt.Sprintf("%s (%s)", relativePath, pod.Latest),
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+
+	if diff == "" {
+		cmd.Println("No manifest changes.")
+		return nil
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			cmd.Println(aurora.Bold(line))
+		case strings.HasPrefix(line, "+"):
+			cmd.Println(aurora.Green(line))
+		case strings.HasPrefix(line, "-"):
+			cmd.Println(aurora.Red(line))
+		case strings.HasPrefix(line, "@@"):
+			cmd.Println(aurora.Cyan(line))
+		default:
+			cmd.Println(line)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	updateCmd.Flags().BoolVar(&updateDryRun, "dry-run", false, "Shows the manifest changes without updating")
+	updateCmd.Flags().BoolP("help", "h", false, "Print this help message")
+	RootCmd.AddCommand(updateCmd)
+}
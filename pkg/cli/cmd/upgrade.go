@@ -8,7 +8,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spiceai/spiceai/pkg/constants"
-	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/github"
 	"github.com/spiceai/spiceai/pkg/util"
 	"github.com/spiceai/spiceai/pkg/version"
@@ -28,7 +27,6 @@ spice upgrade
 			return
 		}
 
-		rtcontext := context.CurrentContext()
 		cliVersion := version.Version()
 
 		if cliVersion == release.TagName {
@@ -23,8 +23,6 @@ var versionCmd = &cobra.Command{
 spice version
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		cmd.Printf("CLI version:     %s\n", version.Version())
-
 		var rtversion string
 		var err error
 
@@ -50,7 +48,20 @@ spice version
 			}
 		}
 
-		cmd.Printf("Runtime version: %s\n", rtversion)
+		versions := &versionInfo{CliVersion: version.Version(), RuntimeVersion: rtversion}
+		err = printOutput(cmd, versions, func() error {
+			cmd.Printf("CLI version:     %s\n", versions.CliVersion)
+			cmd.Printf("Runtime version: %s\n", versions.RuntimeVersion)
+			return nil
+		})
+		if err != nil {
+			cmd.Println(err.Error())
+			os.Exit(1)
+		}
+
+		if outputFormat != util.OutputTable {
+			return
+		}
 
 		err = checkLatestCliReleaseVersion()
 		if err != nil && util.IsDebug() {
@@ -59,6 +70,11 @@ spice version
 	},
 }
 
+type versionInfo struct {
+	CliVersion     string `json:"cli_version"`
+	RuntimeVersion string `json:"runtime_version"`
+}
+
 func checkLatestCliReleaseVersion() error {
 	rtcontext, err := context.NewContext("metal") // CLI is always metal context
 	if err != nil {
@@ -0,0 +1,167 @@
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spiceai/spiceai/pkg/util"
+	"github.com/spiceai/spiceai/pkg/validator"
+	"gopkg.in/yaml.v3"
+)
+
+const FileName = "profiles.yaml"
+
+// A named runtime connection. Empty fields fall back to the CLI's defaults.
+type Profile struct {
+	Name       string        `json:"name" yaml:"name"`
+	RuntimeUrl string        `json:"runtime_url,omitempty" yaml:"runtime_url,omitempty"`
+	Token      string        `json:"token,omitempty" yaml:"token,omitempty"`
+	Timeout    time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Output     string        `json:"output,omitempty" yaml:"output,omitempty"`
+}
+
+// The profiles in the user's config directory and the one in use when none is selected
+type Profiles struct {
+	Current  string     `yaml:"current,omitempty"`
+	Profiles []*Profile `yaml:"profiles,omitempty"`
+
+	path string
+}
+
+// Returns <user config dir>/spice/profiles.yaml
+func DefaultPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "spice", FileName), nil
+}
+
+// Loads the profiles at path. A missing file has no profiles.
+func Load(path string) (*Profiles, error) {
+	p := &Profiles{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// Writes the profiles back to their file, which is only readable by the user as it may hold tokens
+func (p *Profiles) Save() error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(p.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(p.path, data, 0600)
+}
+
+func (p *Profiles) Path() string {
+	return p.path
+}
+
+func (p *Profiles) Get(name string) *Profile {
+	for _, profile := range p.Profiles {
+		if profile.Name == name {
+			return profile
+		}
+	}
+	return nil
+}
+
+// Adds the profile, replacing any existing profile with the same name
+func (p *Profiles) Set(profile *Profile) error {
+	err := profile.Validate()
+	if err != nil {
+		return err
+	}
+
+	for i, existing := range p.Profiles {
+		if existing.Name == profile.Name {
+			p.Profiles[i] = profile
+			return nil
+		}
+	}
+
+	p.Profiles = append(p.Profiles, profile)
+	sort.SliceStable(p.Profiles, func(i, j int) bool {
+		return p.Profiles[i].Name < p.Profiles[j].Name
+	})
+
+	return nil
+}
+
+// Removes the profile, and stops using it if it was current. Returns false if it wasn't found.
+func (p *Profiles) Remove(name string) bool {
+	for i, profile := range p.Profiles {
+		if profile.Name == name {
+			p.Profiles = append(p.Profiles[:i], p.Profiles[i+1:]...)
+			if p.Current == name {
+				p.Current = ""
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Makes the profile current. An empty name stops using a profile.
+func (p *Profiles) Use(name string) error {
+	if name != "" && p.Get(name) == nil {
+		return fmt.Errorf("profile %s not found", name)
+	}
+	p.Current = name
+	return nil
+}
+
+func (profile *Profile) Validate() error {
+	if !validator.ValidateDataspaceName(profile.Name) {
+		return fmt.Errorf("invalid profile name '%s' should only contain A-Za-z0-9_", profile.Name)
+	}
+
+	if profile.RuntimeUrl != "" {
+		err := ValidateRuntimeUrl(profile.RuntimeUrl)
+		if err != nil {
+			return err
+		}
+	}
+
+	if profile.Timeout < 0 {
+		return fmt.Errorf("invalid timeout %s: must not be negative", profile.Timeout)
+	}
+
+	if profile.Output != "" {
+		return util.ValidateOutputFormat(profile.Output)
+	}
+
+	return nil
+}
+
+func ValidateRuntimeUrl(runtimeUrl string) error {
+	u, err := url.Parse(runtimeUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid runtime url '%s', expected e.g. http://localhost:8000", runtimeUrl)
+	}
+	return nil
+}
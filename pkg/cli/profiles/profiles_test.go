@@ -0,0 +1,88 @@
+package profiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiles(t *testing.T) {
+	t.Run("Load() - Missing file has no profiles", testLoadMissingFunc())
+	t.Run("Save() - Profiles are written and read back", testSaveFunc())
+	t.Run("Set() - Invalid profiles are rejected", testSetInvalidFunc())
+	t.Run("Remove() - Removing the current profile stops using it", testRemoveFunc())
+}
+
+func testLoadMissingFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		p, err := Load(filepath.Join(t.TempDir(), FileName))
+		assert.NoError(t, err)
+		assert.Empty(t, p.Profiles)
+		assert.Nil(t, p.Get("prod"))
+	}
+}
+
+func testSaveFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "spice", FileName)
+		p, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.NoError(t, p.Set(&Profile{Name: "prod", RuntimeUrl: "https://spice.example.com", Token: "secret", Timeout: 30 * time.Second, Output: "json"}))
+		assert.NoError(t, p.Set(&Profile{Name: "dev", RuntimeUrl: "http://localhost:8000"}))
+		assert.NoError(t, p.Use("prod"))
+		assert.EqualError(t, p.Use("staging"), "profile staging not found")
+		assert.NoError(t, p.Save())
+
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "prod", loaded.Current)
+		assert.Equal(t, []*Profile{
+			{Name: "dev", RuntimeUrl: "http://localhost:8000"},
+			{Name: "prod", RuntimeUrl: "https://spice.example.com", Token: "secret", Timeout: 30 * time.Second, Output: "json"},
+		}, loaded.Profiles)
+	}
+}
+
+func testSetInvalidFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		p, err := Load(filepath.Join(t.TempDir(), FileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Error(t, p.Set(&Profile{Name: "my prod"}))
+		assert.EqualError(t, p.Set(&Profile{Name: "prod", RuntimeUrl: "localhost:8000"}), "invalid runtime url 'localhost:8000', expected e.g. http://localhost:8000")
+		assert.Error(t, p.Set(&Profile{Name: "prod", Output: "xml"}))
+		assert.Error(t, p.Set(&Profile{Name: "prod", Timeout: -time.Second}))
+		assert.Empty(t, p.Profiles)
+	}
+}
+
+func testRemoveFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		p, err := Load(filepath.Join(t.TempDir(), FileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.NoError(t, p.Set(&Profile{Name: "prod"}))
+		assert.NoError(t, p.Use("prod"))
+
+		assert.False(t, p.Remove("dev"))
+		assert.True(t, p.Remove("prod"))
+		assert.Empty(t, p.Current)
+		assert.Empty(t, p.Profiles)
+	}
+}
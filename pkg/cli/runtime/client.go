@@ -1,42 +1,92 @@
 package runtime
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
+	"github.com/spiceai/spiceai/pkg/api"
 	"github.com/spiceai/spiceai/pkg/config"
 	"github.com/spiceai/spiceai/pkg/context"
+	"github.com/spiceai/spiceai/pkg/diagnostics"
+	"github.com/spiceai/spiceai/pkg/loggers"
+	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
+	"github.com/spiceai/spiceai/pkg/proto/common_pb"
 	"github.com/spiceai/spiceai/pkg/proto/runtime_pb"
+	"github.com/spiceai/spiceai/pkg/snapshot"
 	"github.com/spiceai/spiceai/pkg/util"
 )
 
 type RuntimeClient struct {
-	runtimeConfig *config.SpiceConfiguration
 	serverBaseUrl string
+	token         string
+	timeout       time.Duration
+	httpClient    *http.Client
 }
 
-func NewRuntimeClient(podName string) (*RuntimeClient, error) {
-	v := viper.New()
-	appDir := context.CurrentContext().AppDir()
-	runtimeConfig, err := config.LoadRuntimeConfiguration(v, appDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load runtime configuration: %w", err)
+type Option func(r *RuntimeClient)
+
+// Connects to the runtime at serverBaseUrl instead of the one configured by the local app
+func WithServerBaseUrl(serverBaseUrl string) Option {
+	return func(r *RuntimeClient) {
+		r.serverBaseUrl = strings.TrimSuffix(serverBaseUrl, "/")
 	}
+}
 
-	serverBaseUrl := runtimeConfig.ServerBaseUrl()
+// Sends token as a bearer token with every request
+func WithToken(token string) Option {
+	return func(r *RuntimeClient) {
+		r.token = token
+	}
+}
 
-	return &RuntimeClient{
-		runtimeConfig: runtimeConfig,
-		serverBaseUrl: serverBaseUrl,
-	}, nil
+// Limits how long each request may take. 0 is no limit.
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *RuntimeClient) {
+		r.timeout = timeout
+	}
+}
+
+func NewRuntimeClient(rtcontext context.RuntimeContext, opts ...Option) (*RuntimeClient, error) {
+	r := &RuntimeClient{}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.serverBaseUrl == "" {
+		v := viper.New()
+		appDir := rtcontext.AppDir()
+		runtimeConfig, err := config.LoadRuntimeConfiguration(v, appDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load runtime configuration: %w", err)
+		}
+		r.serverBaseUrl = runtimeConfig.ServerBaseUrl()
+	}
+
+	r.httpClient = &http.Client{
+		Timeout:   r.timeout,
+		Transport: &tokenTransport{token: r.token, base: http.DefaultTransport},
+	}
+
+	return r, nil
+}
+
+func (r *RuntimeClient) ServerBaseUrl() string {
+	return r.serverBaseUrl
 }
 
 func (r *RuntimeClient) ExportModel(podName string, directory string, filename string, tag string) error {
-	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, http.DefaultClient)
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
 	if err != nil {
 		return fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
 	}
@@ -52,7 +102,7 @@ func (r *RuntimeClient) ExportModel(podName string, directory string, filename s
 	}
 
 	exportModelUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/models/%s/export", r.serverBaseUrl, podName, tag)
-	response, err := http.DefaultClient.Post(exportModelUrl, "application/json", bytes.NewReader(exportRequestBytes))
+	response, err := r.httpClient.Post(exportModelUrl, "application/json", bytes.NewReader(exportRequestBytes))
 	if err != nil {
 		return nil
 	}
@@ -69,7 +119,7 @@ func (r *RuntimeClient) ExportModel(podName string, directory string, filename s
 }
 
 func (r *RuntimeClient) ImportModel(podName string, archivePath string, tag string) error {
-	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, http.DefaultClient)
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
 	if err != nil {
 		return fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
 	}
@@ -86,7 +136,7 @@ func (r *RuntimeClient) ImportModel(podName string, archivePath string, tag stri
 	}
 
 	importModelUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/models/%s/import", r.serverBaseUrl, podName, tag)
-	response, err := http.DefaultClient.Post(importModelUrl, "application/json", bytes.NewReader(importRequestBytes))
+	response, err := r.httpClient.Post(importModelUrl, "application/json", bytes.NewReader(importRequestBytes))
 	if err != nil {
 		return nil
 	}
@@ -102,21 +152,626 @@ func (r *RuntimeClient) ImportModel(podName string, archivePath string, tag stri
 	return nil
 }
 
-func (r *RuntimeClient) StartTraining(podName string) error {
-	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, http.DefaultClient)
+// Starts training the pod. Returns true if the training is queued behind other pods.
+func (r *RuntimeClient) StartTraining(podName string, trainRequest *runtime_pb.TrainModel) (bool, error) {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
 	if err != nil {
-		return fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+		return false, fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	trainRequestBytes, err := json.Marshal(trainRequest)
+	if err != nil {
+		return false, err
 	}
 
 	trainUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/train", r.serverBaseUrl, podName)
-	response, err := http.DefaultClient.Post(trainUrl, "application/json", nil)
+	response, err := r.httpClient.Post(trainUrl, "application/json", bytes.NewReader(trainRequestBytes))
+	if err != nil {
+		return false, fmt.Errorf("failed to start training: %w", err)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return false, nil
+	case http.StatusAccepted:
+		return true, nil
+	case http.StatusNotFound:
+		return false, fmt.Errorf("failed to start training. The pod '%s' cannot be found. Has it been added?", podName)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil || len(body) == 0 {
+		return false, fmt.Errorf("failed to start training: %s", response.Status)
+	}
+	return false, fmt.Errorf("failed to start training: %s", body)
+}
+
+// Downloads a snapshot of the runtime's pods and writes it to w
+func (r *RuntimeClient) CreateSnapshot(w io.Writer) error {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	snapshotUrl := fmt.Sprintf("%s/api/v0.1/admin/snapshot", r.serverBaseUrl)
+	response, err := r.httpClient.Post(snapshotUrl, "application/json", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != 200 {
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to create snapshot: %s", string(body))
+	}
+
+	_, err = io.Copy(w, response.Body)
+	return err
+}
+
+// Uploads a snapshot archive for the runtime to restore and returns its manifest
+func (r *RuntimeClient) RestoreSnapshot(archivePath string) (*snapshot.Manifest, error) {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	restoreUrl := fmt.Sprintf("%s/api/v0.1/admin/restore", r.serverBaseUrl)
+	response, err := r.httpClient.Post(restoreUrl, "application/zip", archive)
 	if err != nil {
-		return fmt.Errorf("failed to start training: %w", err)
+		return nil, fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
 	}
 
 	if response.StatusCode != 200 {
-		return fmt.Errorf("failed to start training: %s", response.Status)
+		return nil, fmt.Errorf("failed to restore snapshot: %s", string(body))
+	}
+
+	var manifest snapshot.Manifest
+	err = json.Unmarshal(body, &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+func (r *RuntimeClient) GetPods() ([]*api.Pod, error) {
+	var loadedPods []*api.Pod
+	err := r.getJson("/api/v0.1/pods", &loadedPods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods: %w", err)
+	}
+	return loadedPods, nil
+}
+
+// Returns the pod, or nil if the runtime hasn't loaded it
+func (r *RuntimeClient) GetPod(podName string) (*api.PodDetails, error) {
+	var pod api.PodDetails
+	err := r.getJson(fmt.Sprintf("/api/v0.1/pods/%s", url.PathEscape(podName)), &pod)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod %s: %w", podName, err)
+	}
+	return &pod, nil
+}
+
+func (r *RuntimeClient) GetPodsStatus() ([]*api.PodStatus, error) {
+	var status []*api.PodStatus
+	err := r.getJson("/api/v0.1/pods_status", &status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods status: %w", err)
+	}
+	return status, nil
+}
+
+// Reloads the pod from its manifest and returns it as reloaded
+func (r *RuntimeClient) ReloadPod(podName string) (*api.PodDetails, error) {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	reloadUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/reload", r.serverBaseUrl, url.PathEscape(podName))
+	response, err := r.httpClient.Post(reloadUrl, "application/json", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload pod %s: %w", podName, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("pod %s is not loaded", podName)
+	}
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to reload pod %s: %s", podName, string(body))
+	}
+
+	var pod api.PodDetails
+	err = json.Unmarshal(body, &pod)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pod, nil
+}
+
+// Unloads the pod from the runtime, leaving its manifest in place
+func (r *RuntimeClient) RemovePod(podName string) error {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	removeUrl := fmt.Sprintf("%s/api/v0.1/pods/%s", r.serverBaseUrl, url.PathEscape(podName))
+	request, err := http.NewRequest(http.MethodDelete, removeUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to remove pod %s: %w", podName, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("pod %s is not loaded", podName)
+	}
+	if response.StatusCode != 200 {
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to remove pod %s: %s", podName, string(body))
+	}
+
+	return nil
+}
+
+var errNotFound = errors.New("not found")
+
+// Gets path from the runtime and decodes its JSON response into v
+func (r *RuntimeClient) getJson(path string, v interface{}) error {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	response, err := r.httpClient.Get(r.serverBaseUrl + path)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if response.StatusCode != 200 {
+		return fmt.Errorf("%s: %s", response.Status, string(body))
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// Returns the pod's cached observations from start up to but excluding end. Zero times leave that side unbounded.
+func (r *RuntimeClient) GetObservations(podName string, start time.Time, end time.Time) ([]*common_pb.Observation, error) {
+	query := url.Values{}
+	if !start.IsZero() {
+		query.Set("start", strconv.FormatInt(start.Unix(), 10))
+	}
+	if !end.IsZero() {
+		query.Set("end", strconv.FormatInt(end.Unix(), 10))
+	}
+
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	observationsUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/observations?%s", r.serverBaseUrl, url.PathEscape(podName), query.Encode())
+	request, err := http.NewRequest(http.MethodGet, observationsUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get observations: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("pod %s is not loaded", podName)
+	}
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to get observations: %s", string(body))
+	}
+
+	var observations []*common_pb.Observation
+	err = json.Unmarshal(body, &observations)
+	if err != nil {
+		return nil, err
+	}
+
+	return observations, nil
+}
+
+// Adds CSV observations with fully-qualified headers to the pod
+func (r *RuntimeClient) PostObservations(podName string, csv []byte) error {
+	observationsUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/observations", r.serverBaseUrl, url.PathEscape(podName))
+	return r.postData(observationsUrl, "text/csv", csv)
+}
+
+// Sends data to a dataspace, to be read by its processor
+func (r *RuntimeClient) PostDataspaceData(podName string, dataspaceFrom string, dataspaceName string, contentType string, data []byte) error {
+	dataspaceUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/dataspaces/%s/%s/data", r.serverBaseUrl, url.PathEscape(podName), url.PathEscape(dataspaceFrom), url.PathEscape(dataspaceName))
+	return r.postData(dataspaceUrl, contentType, data)
+}
+
+func (r *RuntimeClient) postData(postUrl string, contentType string, data []byte) error {
+	response, err := r.httpClient.Post(postUrl, contentType, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to send data: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return errors.New("failed to send data: pod or dataspace is not loaded")
+	}
+	if response.StatusCode != http.StatusCreated {
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to send data: %s: %s", response.Status, string(body))
+	}
+
+	return nil
+}
+
+// Returns an error if the runtime isn't reachable
+func (r *RuntimeClient) CheckHealth() error {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+	return nil
+}
+
+// Gets the pod's recommendation at inferenceTime, or the latest if it is zero. A recommendation
+// the AI engine couldn't make is returned along with an error describing why.
+func (r *RuntimeClient) GetRecommendation(podName string, tag string, inferenceTime time.Time) (*aiengine_pb.InferenceResult, error) {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	recommendationUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/models/%s/recommendation", r.serverBaseUrl, url.PathEscape(podName), url.PathEscape(tag))
+	if !inferenceTime.IsZero() {
+		recommendationUrl += fmt.Sprintf("?time=%d", inferenceTime.Unix())
+	}
+
+	response, err := r.httpClient.Get(recommendationUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendation: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != 200 && response.StatusCode != http.StatusBadRequest {
+		return nil, fmt.Errorf("failed to get recommendation: %s", string(body))
+	}
+
+	var inference aiengine_pb.InferenceResult
+	err = json.Unmarshal(body, &inference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendation: %w", err)
+	}
+
+	if inference.Response != nil && inference.Response.Error {
+		return &inference, fmt.Errorf("failed to get recommendation: %s", inference.Response.Message)
+	}
+
+	return &inference, nil
+}
+
+// Returns the pod's interpretations overlapping start to end. Zero times default to the pod's period.
+func (r *RuntimeClient) GetInterpretations(podName string, start time.Time, end time.Time) ([]*api.Interpretation, error) {
+	var apiInterpretations []*api.Interpretation
+	err := r.getJson(fmt.Sprintf("/api/v0.1/pods/%s/interpretations?%s", url.PathEscape(podName), interpretationsQuery(start, end).Encode()), &apiInterpretations)
+	if errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("pod %s is not loaded", podName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interpretations: %w", err)
+	}
+	return apiInterpretations, nil
+}
+
+func (r *RuntimeClient) AddInterpretations(podName string, apiInterpretations []*api.Interpretation) error {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	data, err := json.Marshal(apiInterpretations)
+	if err != nil {
+		return err
+	}
+
+	interpretationsUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/interpretations", r.serverBaseUrl, url.PathEscape(podName))
+	response, err := r.httpClient.Post(interpretationsUrl, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to add interpretations: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("pod %s is not loaded", podName)
+	}
+	if response.StatusCode != http.StatusCreated {
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to add interpretations: %s", string(body))
 	}
 
 	return nil
 }
+
+// Removes the pod's interpretations within start to end that match name and tag, when given, and returns them
+func (r *RuntimeClient) DeleteInterpretations(podName string, start time.Time, end time.Time, name string, tag string) ([]*api.Interpretation, error) {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	query := interpretationsQuery(start, end)
+	if name != "" {
+		query.Set("name", name)
+	}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+
+	interpretationsUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/interpretations?%s", r.serverBaseUrl, url.PathEscape(podName), query.Encode())
+	request, err := http.NewRequest(http.MethodDelete, interpretationsUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete interpretations: %w", err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("pod %s is not loaded", podName)
+	}
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to delete interpretations: %s", string(body))
+	}
+
+	var removed []*api.Interpretation
+	err = json.Unmarshal(body, &removed)
+	if err != nil {
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+func interpretationsQuery(start time.Time, end time.Time) url.Values {
+	query := url.Values{}
+	if !start.IsZero() {
+		query.Set("start", strconv.FormatInt(start.Unix(), 10))
+	}
+	if !end.IsZero() {
+		query.Set("end", strconv.FormatInt(end.Unix(), 10))
+	}
+	return query
+}
+
+func (r *RuntimeClient) GetFlights(podName string) ([]*api.Flight, error) {
+	var flights []*api.Flight
+	err := r.getJson(fmt.Sprintf("/api/v0.1/pods/%s/training_runs", url.PathEscape(podName)), &flights)
+	if errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("pod %s is not loaded", podName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get training runs: %w", err)
+	}
+	return flights, nil
+}
+
+// Returns the training run, or nil if the pod or training run can't be found
+func (r *RuntimeClient) GetFlight(podName string, flightId string) (*api.Flight, error) {
+	var flight api.Flight
+	err := r.getJson(fmt.Sprintf("/api/v0.1/pods/%s/training_runs/%s", url.PathEscape(podName), url.PathEscape(flightId)), &flight)
+	if errors.Is(err, errNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get training run %s: %w", flightId, err)
+	}
+	return &flight, nil
+}
+
+// Starts the training run's logger, such as tensorboard, and returns the address it can be viewed at
+func (r *RuntimeClient) OpenFlightLogger(podName string, flightId string, loggerId string) (string, error) {
+	err := util.IsRuntimeServerHealthy(r.serverBaseUrl, r.httpClient)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+
+	loggerUrl := fmt.Sprintf("%s/api/v0.1/pods/%s/training_runs/%s/loggers/%s", r.serverBaseUrl, url.PathEscape(podName), url.PathEscape(flightId), url.PathEscape(loggerId))
+	response, err := r.httpClient.Post(loggerUrl, "application/json", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s logger: %w", loggerId, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if response.StatusCode != 200 {
+		if len(body) == 0 {
+			return "", fmt.Errorf("failed to open %s logger: pod %s or training run %s not found", loggerId, podName, flightId)
+		}
+		return "", fmt.Errorf("failed to open %s logger: %s", loggerId, string(body))
+	}
+
+	return string(body), nil
+}
+
+// Returns the runtime's diagnostics report. Unlike other requests, this doesn't require the runtime to be healthy.
+func (r *RuntimeClient) GetDiagnostics() (*diagnostics.Report, error) {
+	response, err := r.httpClient.Get(r.serverBaseUrl + "/api/v0.1/diagnostics?format=json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to get diagnostics: %s: %s", response.Status, string(body))
+	}
+
+	var report diagnostics.Report
+	err = json.Unmarshal(body, &report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diagnostics: %w", err)
+	}
+
+	return &report, nil
+}
+
+// Calls handle with each line the runtime logged matching the query, oldest first. If follow is set, keeps calling
+// it with new lines until handle returns an error or the runtime stops. Like diagnostics, this doesn't require the
+// runtime to be healthy.
+func (r *RuntimeClient) GetLogs(query *loggers.LogQuery, follow bool, handle func(logLine *loggers.LogLine) error) error {
+	params := url.Values{}
+	if len(query.Components) == 1 {
+		params.Set("component", query.Components[0])
+	}
+	if query.Pod != "" {
+		params.Set("pod", query.Pod)
+	}
+	if !query.Since.IsZero() {
+		params.Set("since", query.Since.Format(time.RFC3339Nano))
+	}
+	if query.Tail > 0 {
+		params.Set("tail", strconv.Itoa(query.Tail))
+	}
+
+	httpClient := r.httpClient
+	if follow {
+		params.Set("follow", "true")
+		// The timeout would end the response while it's still being followed
+		httpClient = &http.Client{Transport: r.httpClient.Transport}
+	}
+
+	logsUrl := r.serverBaseUrl + "/api/v0.1/logs"
+	if len(params) > 0 {
+		logsUrl += "?" + params.Encode()
+	}
+
+	response, err := httpClient.Get(logsUrl)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s. is the spice runtime running? %w", r.serverBaseUrl, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != 200 {
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to get logs: %s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		// Followed logs are kept alive with empty lines
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var logLine loggers.LogLine
+		err := json.Unmarshal(scanner.Bytes(), &logLine)
+		if err != nil {
+			return fmt.Errorf("failed to get logs: %w", err)
+		}
+
+		err = handle(&logLine)
+		if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+type tokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if t.token != "" {
+		request = request.Clone(request.Context())
+		request.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return t.base.RoundTrip(request)
+}
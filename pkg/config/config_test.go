@@ -5,10 +5,13 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/spiceai/spiceai/pkg/config"
 	"github.com/spiceai/spiceai/pkg/context"
@@ -19,10 +22,15 @@ import (
 func TestConfig(t *testing.T) {
 	testConfigPath := "../../test/assets/config/config.yaml"
 	testConfigPathWithEnvVars := "../../test/assets/config/config_with_env_vars.yaml"
+	testConfigPathFull := "../../test/assets/config/config_full.yaml"
 	t.Cleanup(testutils.CleanupTestSpiceDirectory)
 	t.Run("LoadRuntimeConfiguration() - Config loads correctly", testRuntimeConfigLoads(testConfigPath))
 	testutils.CleanupTestSpiceDirectory()
 	t.Run("LoadRuntimeConfiguration() - Environment variables in config are replaced", testRuntimeConfigReplacesEnvironmentVariables(testConfigPathWithEnvVars))
+	testutils.CleanupTestSpiceDirectory()
+	t.Run("LoadRuntimeConfiguration() - Defaults, file, env and flags are merged", testRuntimeConfigMergesSources(testConfigPathFull))
+	t.Run("LoadRuntimeConfiguration() - Invalid values are rejected", testRuntimeConfigValidates())
+	t.Run("RestartRequiredChanges() - Only fields that can't be reloaded are reported", testRestartRequiredChanges())
 }
 
 // Tests configuration loads correctly
@@ -35,7 +43,9 @@ func testRuntimeConfigLoads(testConfigPath string) func(*testing.T) {
 		defer os.Remove(tempConfigPath)
 
 		viper := viper.New()
-		rtcontext := context.CurrentContext()
+		rtcontext, err := context.NewDefaultContext()
+		assert.NoError(t, err)
+
 		spiceConfiguration, err := config.LoadRuntimeConfiguration(viper, rtcontext.AppDir())
 		if err != nil {
 			t.Error(err)
@@ -70,7 +80,9 @@ func testRuntimeConfigReplacesEnvironmentVariables(testConfigPath string) func(*
 		defer os.Remove(tempConfigPath)
 
 		viper := viper.New()
-		rtcontext := context.CurrentContext()
+		rtcontext, err := context.NewDefaultContext()
+		assert.NoError(t, err)
+
 		spiceConfiguration, err := config.LoadRuntimeConfiguration(viper, rtcontext.AppDir())
 		if err != nil {
 			t.Error(err)
@@ -82,6 +94,106 @@ func testRuntimeConfigReplacesEnvironmentVariables(testConfigPath string) func(*
 	}
 }
 
+// Tests configuration values are taken from flags, then env vars, then the config file, then defaults
+func testRuntimeConfigMergesSources(testConfigPath string) func(*testing.T) {
+	return func(t *testing.T) {
+		testutils.EnsureTestSpiceDirectory(t)
+
+		tempConfigPath := "spice.config.yaml"
+		copyFile(testConfigPath, tempConfigPath)
+		defer os.Remove(tempConfigPath)
+
+		t.Setenv("SPICE_LOG_LEVEL", "debug")
+		t.Setenv("SPICE_INGESTION_MAX_OBSERVATIONS_PER_REQUEST", "100")
+		t.Setenv("SPICE_LOGS_PER_POD", "true")
+
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		config.AddFlags(flags)
+		err := flags.Parse([]string{"--http-port", "9000", "--log-level", "error"})
+		assert.NoError(t, err)
+
+		v := viper.New()
+		err = config.BindFlags(v, flags)
+		assert.NoError(t, err)
+
+		cwd, err := os.Getwd()
+		assert.NoError(t, err)
+
+		spiceConfiguration, err := config.LoadRuntimeConfiguration(v, cwd)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		assert.Equal(t, uint(9000), spiceConfiguration.HttpPort)
+		assert.Equal(t, "error", spiceConfiguration.LogLevel)
+		assert.Equal(t, 100, spiceConfiguration.Ingestion.MaxObservationsPerRequest)
+		assert.Equal(t, "127.0.0.1", spiceConfiguration.BindAddress)
+		assert.Equal(t, []string{"http://localhost:3000"}, spiceConfiguration.CorsOrigins)
+		assert.Equal(t, 10, spiceConfiguration.AIEngine.HealthCheckRetries)
+		assert.Equal(t, 24*time.Hour, spiceConfiguration.Retention.Period)
+		assert.Equal(t, 1024, spiceConfiguration.Ingestion.MaxBodyBytes)
+		assert.True(t, spiceConfiguration.Persistence.Enabled)
+		assert.Equal(t, "json", spiceConfiguration.LogFormat)
+		assert.Equal(t, "localhost:8004", spiceConfiguration.AIEngine.Address)
+		assert.Equal(t, time.Minute, spiceConfiguration.Retention.Interval)
+		assert.Equal(t, filepath.Join(cwd, ".spice", "state"), spiceConfiguration.StateDir)
+		assert.True(t, spiceConfiguration.Logs.PerPod)
+		assert.Equal(t, 100, spiceConfiguration.Logs.MaxSizeMB)
+		assert.Equal(t, 60, spiceConfiguration.Logs.MaxAgeDays)
+
+		sources := make(map[string]string)
+		for _, value := range config.DescribeConfiguration(v, flags, spiceConfiguration) {
+			sources[value.Key] = value.Source
+		}
+
+		assert.Equal(t, "flag (--http-port)", sources["http_port"])
+		assert.Equal(t, "flag (--log-level)", sources["log_level"])
+		assert.Equal(t, "env (SPICE_INGESTION_MAX_OBSERVATIONS_PER_REQUEST)", sources["ingestion.max_observations_per_request"])
+		assert.Equal(t, config.SourceFile, sources["bind_address"])
+		assert.Equal(t, config.SourceFile, sources["retention.period"])
+		assert.Equal(t, config.SourceDefault, sources["retention.interval"])
+	}
+}
+
+func testRuntimeConfigValidates() func(*testing.T) {
+	return func(t *testing.T) {
+		t.Setenv("SPICE_LOG_LEVEL", "verbose")
+
+		_, err := config.LoadRuntimeConfiguration(viper.New(), t.TempDir())
+		assert.EqualError(t, err, "invalid log_level 'verbose': must be one of debug, info, warn or error")
+
+		t.Setenv("SPICE_LOG_LEVEL", "info")
+		t.Setenv("SPICE_LOGS_MAX_SIZE_MB", "0")
+
+		_, err = config.LoadRuntimeConfiguration(viper.New(), t.TempDir())
+		assert.EqualError(t, err, "invalid logs.max_size_mb 0: must be positive")
+	}
+}
+
+func testRestartRequiredChanges() func(*testing.T) {
+	return func(t *testing.T) {
+		current := config.LoadDefaultConfiguration()
+
+		newConfig := config.LoadDefaultConfiguration()
+		newConfig.HttpPort = 9000
+		newConfig.LogLevel = "debug"
+		newConfig.CorsOrigins = []string{"*"}
+		newConfig.Ingestion.MaxBodyBytes = 10
+		newConfig.Retention.Period = time.Hour
+
+		assert.Equal(t, []string{"http_port", "retention.period"}, current.RestartRequiredChanges(newConfig))
+
+		reloaded := current.WithReloadableFields(newConfig)
+		assert.Equal(t, uint(8000), reloaded.HttpPort)
+		assert.Equal(t, 72*time.Hour, reloaded.Retention.Period)
+		assert.Equal(t, "debug", reloaded.LogLevel)
+		assert.Equal(t, []string{"*"}, reloaded.CorsOrigins)
+		assert.Equal(t, 10, reloaded.Ingestion.MaxBodyBytes)
+		assert.Equal(t, "info", current.LogLevel)
+	}
+}
+
 func copyFile(fromPath string, toPath string) {
 	from, err := os.Open(fromPath)
 	if err != nil {
@@ -2,24 +2,104 @@ package config
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/spiceai/spiceai/pkg/constants"
+	"github.com/spiceai/spiceai/pkg/spec"
 	"github.com/spiceai/spiceai/pkg/util"
 	"gopkg.in/yaml.v2"
 )
 
+type AIEngineConfiguration struct {
+	Address            string `json:"address,omitempty" mapstructure:"address,omitempty" yaml:"address,omitempty"`
+	PythonPath         string `json:"python_path,omitempty" mapstructure:"python_path,omitempty" yaml:"python_path,omitempty"`
+	HealthCheckRetries int    `json:"health_check_retries,omitempty" mapstructure:"health_check_retries,omitempty" yaml:"health_check_retries,omitempty"`
+}
+
+// Defaults applied to pods that do not set their own params
+type RetentionConfiguration struct {
+	Period      time.Duration `json:"period,omitempty" mapstructure:"period,omitempty" yaml:"period,omitempty"`
+	Interval    time.Duration `json:"interval,omitempty" mapstructure:"interval,omitempty" yaml:"interval,omitempty"`
+	Granularity time.Duration `json:"granularity,omitempty" mapstructure:"granularity,omitempty" yaml:"granularity,omitempty"`
+}
+
+type IngestionConfiguration struct {
+	// Maximum size of an observations request body. 0 is unlimited.
+	MaxBodyBytes int `json:"max_body_bytes,omitempty" mapstructure:"max_body_bytes,omitempty" yaml:"max_body_bytes,omitempty"`
+	// Maximum number of observations accepted in a single request. 0 is unlimited.
+	MaxObservationsPerRequest int `json:"max_observations_per_request,omitempty" mapstructure:"max_observations_per_request,omitempty" yaml:"max_observations_per_request,omitempty"`
+}
+
+type TrainingConfiguration struct {
+	// Maximum number of trainings running at once across all pods. 0 is one per CPU.
+	MaxConcurrent int `json:"max_concurrent,omitempty" mapstructure:"max_concurrent,omitempty" yaml:"max_concurrent,omitempty"`
+}
+
+// Rotation of the runtime's log files, and whether each pod also gets its own
+type LogsConfiguration struct {
+	// Size in megabytes a log file grows to before it is rotated
+	MaxSizeMB int `json:"max_size_mb,omitempty" mapstructure:"max_size_mb,omitempty" yaml:"max_size_mb,omitempty"`
+	// Days rotated log files, and the logs of previous runs, are kept. 0 keeps them regardless of age.
+	MaxAgeDays int `json:"max_age_days,omitempty" mapstructure:"max_age_days,omitempty" yaml:"max_age_days,omitempty"`
+	// Number of rotated files kept for each log. 0 keeps them all.
+	MaxBackups int `json:"max_backups,omitempty" mapstructure:"max_backups,omitempty" yaml:"max_backups,omitempty"`
+	// Also writes the entries about each pod to log/pods/<pod>.log
+	PerPod bool `json:"per_pod,omitempty" mapstructure:"per_pod,omitempty" yaml:"per_pod,omitempty"`
+}
+
+type PersistenceConfiguration struct {
+	Enabled bool `json:"enabled,omitempty" mapstructure:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
 type SpiceConfiguration struct {
-	HttpPort        uint `json:"http_port,omitempty" mapstructure:"http_port,omitempty" yaml:"http_port,omitempty"`
-	DevelopmentMode bool `json:"development_mode,omitempty" mapstructure:"development_mode,omitempty" yaml:"development_mode,omitempty"`
+	HttpPort        uint                     `json:"http_port,omitempty" mapstructure:"http_port,omitempty" yaml:"http_port,omitempty"`
+	BindAddress     string                   `json:"bind_address,omitempty" mapstructure:"bind_address,omitempty" yaml:"bind_address,omitempty"`
+	DevelopmentMode bool                     `json:"development_mode,omitempty" mapstructure:"development_mode,omitempty" yaml:"development_mode,omitempty"`
+	LogLevel        string                   `json:"log_level,omitempty" mapstructure:"log_level,omitempty" yaml:"log_level,omitempty"`
+	LogFormat       string                   `json:"log_format,omitempty" mapstructure:"log_format,omitempty" yaml:"log_format,omitempty"`
+	Logs            LogsConfiguration        `json:"logs,omitempty" mapstructure:"logs,omitempty" yaml:"logs,omitempty"`
+	DataDir         string                   `json:"data_dir,omitempty" mapstructure:"data_dir,omitempty" yaml:"data_dir,omitempty"`
+	StateDir        string                   `json:"state_dir,omitempty" mapstructure:"state_dir,omitempty" yaml:"state_dir,omitempty"`
+	AIEngine        AIEngineConfiguration    `json:"ai_engine,omitempty" mapstructure:"ai_engine,omitempty" yaml:"ai_engine,omitempty"`
+	CorsOrigins     []string                 `json:"cors_origins,omitempty" mapstructure:"cors_origins,omitempty" yaml:"cors_origins,omitempty"`
+	Retention       RetentionConfiguration   `json:"retention,omitempty" mapstructure:"retention,omitempty" yaml:"retention,omitempty"`
+	Ingestion       IngestionConfiguration   `json:"ingestion,omitempty" mapstructure:"ingestion,omitempty" yaml:"ingestion,omitempty"`
+	Persistence     PersistenceConfiguration `json:"persistence,omitempty" mapstructure:"persistence,omitempty" yaml:"persistence,omitempty"`
+	Training        TrainingConfiguration    `json:"training,omitempty" mapstructure:"training,omitempty" yaml:"training,omitempty"`
+	// Default quotas for pods and dataspaces that don't set their own. The default body size is ingestion.max_body_bytes.
+	Quotas spec.QuotasSpec `json:"quotas,omitempty" mapstructure:"quotas,omitempty" yaml:"quotas,omitempty"`
 }
 
 func LoadDefaultConfiguration() *SpiceConfiguration {
 	return &SpiceConfiguration{
 		HttpPort:        8000,
 		DevelopmentMode: false,
+		LogLevel:        "info",
+		LogFormat:       "json",
+		Logs: LogsConfiguration{
+			MaxSizeMB:  100,
+			MaxAgeDays: 60,
+			MaxBackups: 3,
+		},
+		AIEngine: AIEngineConfiguration{
+			Address:            "localhost:8004",
+			HealthCheckRetries: 30,
+		},
+		Retention: RetentionConfiguration{
+			Period:      time.Hour * 24 * 3,
+			Interval:    time.Minute * 1,
+			Granularity: time.Second * 10,
+		},
+		Ingestion: IngestionConfiguration{
+			MaxBodyBytes: 4 * 1024 * 1024,
+		},
 	}
 }
 
@@ -28,18 +108,14 @@ func LoadRuntimeConfiguration(v *viper.Viper, appDir string) (*SpiceConfiguratio
 	v.SetConfigName(constants.SpiceConfigBaseName)
 	v.SetConfigType("yaml")
 
-	var config *SpiceConfiguration
-	configPath := fmt.Sprintf("%s.yaml", constants.SpiceConfigBaseName)
+	setDefaults(v, appDir)
 
-	if _, err := os.Stat(configPath); err != nil {
-		configPath = fmt.Sprintf("%s.yml", constants.SpiceConfigBaseName)
-		if _, err := os.Stat(configPath); err != nil {
-			// No config file found, use defaults
-			config = LoadDefaultConfiguration()
-		}
-	}
+	v.SetEnvPrefix(strings.TrimSuffix(constants.SpiceEnvVarPrefix, "_"))
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
 
-	if config == nil {
+	configPath := ConfigFilePath(appDir)
+	if configPath != "" {
 		configBytes, err := util.ReplaceEnvVariablesFromPath(configPath, constants.SpiceEnvVarPrefix)
 		if err != nil {
 			return nil, err
@@ -51,18 +127,103 @@ func LoadRuntimeConfiguration(v *viper.Viper, appDir string) (*SpiceConfiguratio
 		}
 	}
 
+	var config *SpiceConfiguration
 	err := v.Unmarshal(&config)
 	if err != nil {
 		return nil, err
 	}
 
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	return config, err
 }
 
+// Returns the path of the configuration file in appDir, or an empty string if there is none
+func ConfigFilePath(appDir string) string {
+	for _, ext := range []string{"yaml", "yml"} {
+		configPath := filepath.Join(appDir, fmt.Sprintf("%s.%s", constants.SpiceConfigBaseName, ext))
+		if _, err := os.Stat(configPath); err == nil {
+			return configPath
+		}
+	}
+	return ""
+}
+
+func (rtConfig *SpiceConfiguration) Validate() error {
+	switch rtConfig.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("invalid log_level '%s': must be one of debug, info, warn or error", rtConfig.LogLevel)
+	}
+
+	switch rtConfig.LogFormat {
+	case "json", "console":
+	default:
+		return fmt.Errorf("invalid log_format '%s': must be one of json or console", rtConfig.LogFormat)
+	}
+
+	if rtConfig.Logs.MaxSizeMB <= 0 {
+		return fmt.Errorf("invalid logs.max_size_mb %d: must be positive", rtConfig.Logs.MaxSizeMB)
+	}
+
+	if rtConfig.Logs.MaxAgeDays < 0 {
+		return fmt.Errorf("invalid logs.max_age_days %d: must not be negative", rtConfig.Logs.MaxAgeDays)
+	}
+
+	if rtConfig.Logs.MaxBackups < 0 {
+		return fmt.Errorf("invalid logs.max_backups %d: must not be negative", rtConfig.Logs.MaxBackups)
+	}
+
+	if rtConfig.Ingestion.MaxBodyBytes < 0 {
+		return fmt.Errorf("invalid ingestion.max_body_bytes %d: must not be negative", rtConfig.Ingestion.MaxBodyBytes)
+	}
+
+	if rtConfig.Ingestion.MaxObservationsPerRequest < 0 {
+		return fmt.Errorf("invalid ingestion.max_observations_per_request %d: must not be negative", rtConfig.Ingestion.MaxObservationsPerRequest)
+	}
+
+	if rtConfig.Quotas.MaxBodyBytes != 0 {
+		return errors.New("quotas.max_body_bytes is not a runtime setting: use ingestion.max_body_bytes")
+	}
+
+	return nil
+}
+
+// Returns the keys of fields that differ between the two configurations and can't be changed without a restart
+func (rtConfig *SpiceConfiguration) RestartRequiredChanges(newConfig *SpiceConfiguration) []string {
+	changed := make([]string, 0)
+	for _, key := range configurationKeys {
+		if key.reloadable {
+			continue
+		}
+		if !reflect.DeepEqual(valueOf(rtConfig, key.name), valueOf(newConfig, key.name)) {
+			changed = append(changed, key.name)
+		}
+	}
+	return changed
+}
+
+// Returns a copy of the configuration with the fields that are safe to change at runtime taken from newConfig
+func (rtConfig *SpiceConfiguration) WithReloadableFields(newConfig *SpiceConfiguration) *SpiceConfiguration {
+	merged := *rtConfig
+	merged.LogLevel = newConfig.LogLevel
+	merged.CorsOrigins = newConfig.CorsOrigins
+	merged.Ingestion = newConfig.Ingestion
+	merged.Training = newConfig.Training
+	return &merged
+}
+
 func (rtConfig *SpiceConfiguration) ServerBaseUrl() string {
 	return fmt.Sprintf("http://localhost:%d", rtConfig.HttpPort)
 }
 
+func (rtConfig *SpiceConfiguration) ListenAddress() string {
+	return fmt.Sprintf("%s:%d", rtConfig.BindAddress, rtConfig.HttpPort)
+}
+
 func (rtConfig *SpiceConfiguration) WriteToFile() error {
 	configPath := fmt.Sprintf("%s.yaml", constants.SpiceConfigBaseName)
 	configFile, err := os.Create(configPath)
@@ -87,3 +248,13 @@ func (rtConfig *SpiceConfiguration) WriteToFile() error {
 
 	return nil
 }
+
+func setDefaults(v *viper.Viper, appDir string) {
+	defaults := LoadDefaultConfiguration()
+	defaults.DataDir = filepath.Join(appDir, constants.DotSpice, "data")
+	defaults.StateDir = filepath.Join(appDir, constants.DotSpice, "state")
+
+	for _, key := range configurationKeys {
+		v.SetDefault(key.name, valueOf(defaults, key.name))
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/spiceai/spiceai/pkg/constants"
+)
+
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+type configurationKey struct {
+	name  string
+	flag  string
+	usage string
+	// Whether a change can be applied to a running runtime
+	reloadable bool
+}
+
+// Every configuration key, in the order they are shown.
+// Keys are mapstructure paths into SpiceConfiguration.
+var configurationKeys = []configurationKey{
+	{name: "http_port", flag: "http-port", usage: "Port the runtime HTTP server listens on"},
+	{name: "bind_address", flag: "bind-address", usage: "Address the runtime HTTP server binds to"},
+	{name: "development_mode", flag: "development", usage: "Runs Spice.ai in development mode."},
+	{name: "log_level", flag: "log-level", usage: "Log level, one of debug, info, warn or error", reloadable: true},
+	{name: "log_format", flag: "log-format", usage: "Log format, either json or console"},
+	{name: "logs.max_size_mb", flag: "logs-max-size-mb", usage: "Size in megabytes a log file grows to before it is rotated"},
+	{name: "logs.max_age_days", flag: "logs-max-age-days", usage: "Days rotated log files and the logs of previous runs are kept, 0 keeps them regardless of age"},
+	{name: "logs.max_backups", flag: "logs-max-backups", usage: "Number of rotated files kept for each log, 0 keeps them all"},
+	{name: "logs.per_pod", flag: "logs-per-pod", usage: "Also writes the entries about each pod to its own log file"},
+	{name: "data_dir", flag: "data-dir", usage: "Directory for training logs when persistence is enabled, defaults to .spice/data in the app directory"},
+	{name: "state_dir", flag: "state-dir", usage: "Directory for pod interpretations when persistence is enabled, defaults to .spice/state in the app directory"},
+	{name: "ai_engine.address", flag: "ai-engine-address", usage: "Address of the AI engine gRPC server"},
+	{name: "ai_engine.python_path", flag: "ai-engine-python-path", usage: "Python interpreter used to run the AI engine"},
+	{name: "ai_engine.health_check_retries", flag: "ai-engine-health-check-retries", usage: "Number of health checks to wait for the AI engine to start"},
+	{name: "cors_origins", flag: "cors-origins", usage: "Origins allowed to make cross-origin requests", reloadable: true},
+	{name: "retention.period", flag: "retention-period", usage: "Default period of pods that don't set one"},
+	{name: "retention.interval", flag: "retention-interval", usage: "Default interval of pods that don't set one"},
+	{name: "retention.granularity", flag: "retention-granularity", usage: "Default granularity of pods that don't set one"},
+	{name: "ingestion.max_body_bytes", flag: "ingestion-max-body-bytes", usage: "Maximum size of an observations request body for pods without a max_body_bytes quota, 0 is unlimited", reloadable: true},
+	{name: "ingestion.max_observations_per_request", flag: "ingestion-max-observations-per-request", usage: "Maximum observations accepted in a single request, 0 is unlimited", reloadable: true},
+	{name: "training.max_concurrent", flag: "training-max-concurrent", usage: "Maximum trainings running at once across all pods, 0 is one per CPU", reloadable: true},
+	{name: "persistence.enabled", flag: "persistence", usage: "Keeps training logs and pod interpretations across runtime restarts"},
+	{name: "quotas.max_cached_observations", flag: "quotas-max-cached-observations", usage: "Default maximum observations cached per pod and dataspace, 0 is unlimited"},
+	{name: "quotas.max_ingest_rate", flag: "quotas-max-ingest-rate", usage: "Default maximum observation requests per second per pod and dataspace, 0 is unlimited"},
+	{name: "quotas.max_interpretations", flag: "quotas-max-interpretations", usage: "Default maximum interpretations per pod, 0 is unlimited"},
+	{name: "quotas.max_concurrent_trainings", flag: "quotas-max-concurrent-trainings", usage: "Default maximum concurrent trainings per pod, 0 is unlimited"},
+}
+
+type ConfigurationValue struct {
+	Key    string `json:"key" csv:"key" yaml:"key"`
+	Value  string `json:"value" csv:"value" yaml:"value"`
+	Source string `json:"source" csv:"source" yaml:"source"`
+}
+
+// Registers a flag for every configuration key not already defined in flags
+func AddFlags(flags *pflag.FlagSet) {
+	defaults := LoadDefaultConfiguration()
+	for _, key := range configurationKeys {
+		if flags.Lookup(key.flag) != nil {
+			continue
+		}
+
+		switch defaultValue := valueOf(defaults, key.name).(type) {
+		case uint:
+			flags.Uint(key.flag, defaultValue, key.usage)
+		case int:
+			flags.Int(key.flag, defaultValue, key.usage)
+		case float64:
+			flags.Float64(key.flag, defaultValue, key.usage)
+		case bool:
+			flags.Bool(key.flag, defaultValue, key.usage)
+		case time.Duration:
+			flags.Duration(key.flag, defaultValue, key.usage)
+		case []string:
+			flags.StringSlice(key.flag, defaultValue, key.usage)
+		case string:
+			flags.String(key.flag, defaultValue, key.usage)
+		}
+	}
+}
+
+// Binds the configuration flags in flags to their keys in v
+func BindFlags(v *viper.Viper, flags *pflag.FlagSet) error {
+	for _, key := range configurationKeys {
+		flag := flags.Lookup(key.flag)
+		if flag == nil {
+			continue
+		}
+
+		err := v.BindPFlag(key.name, flag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns every effective configuration value along with where it came from.
+// flags may be nil when no flags were bound.
+func DescribeConfiguration(v *viper.Viper, flags *pflag.FlagSet, rtConfig *SpiceConfiguration) []ConfigurationValue {
+	values := make([]ConfigurationValue, 0, len(configurationKeys))
+	for _, key := range configurationKeys {
+		values = append(values, ConfigurationValue{
+			Key:    key.name,
+			Value:  formatValue(valueOf(rtConfig, key.name)),
+			Source: valueSource(v, flags, key),
+		})
+	}
+	return values
+}
+
+func valueSource(v *viper.Viper, flags *pflag.FlagSet, key configurationKey) string {
+	if flags != nil {
+		if flag := flags.Lookup(key.flag); flag != nil && flag.Changed {
+			return fmt.Sprintf("%s (--%s)", SourceFlag, key.flag)
+		}
+	}
+
+	envName := EnvVarName(key.name)
+	if value, ok := os.LookupEnv(envName); ok && value != "" {
+		return fmt.Sprintf("%s (%s)", SourceEnv, envName)
+	}
+
+	if v.InConfig(key.name) {
+		return SourceFile
+	}
+
+	return SourceDefault
+}
+
+// Returns the environment variable that overrides the given configuration key
+func EnvVarName(key string) string {
+	return constants.SpiceEnvVarPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case []string:
+		return strings.Join(v, " ")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Returns the value of the field at the mapstructure path key
+func valueOf(rtConfig *SpiceConfiguration, key string) interface{} {
+	value := reflect.ValueOf(rtConfig).Elem()
+	for _, part := range strings.Split(key, ".") {
+		value = fieldByTag(value, part)
+		if !value.IsValid() {
+			return nil
+		}
+	}
+	return value.Interface()
+}
+
+func fieldByTag(value reflect.Value, name string) reflect.Value {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		tag := strings.Split(valueType.Field(i).Tag.Get("mapstructure"), ",")[0]
+		if tag == name {
+			return value.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
@@ -2,7 +2,6 @@ package context
 
 import (
 	"fmt"
-	"log"
 	"os/exec"
 	"strings"
 
@@ -27,10 +26,6 @@ type RuntimeContext interface {
 	GetSpiceAppRelativePath(absolutePath string) string
 }
 
-var (
-	currentContext RuntimeContext
-)
-
 func NewContext(context string) (RuntimeContext, error) {
 	context = strings.ToLower(context)
 
@@ -47,33 +42,17 @@ func NewContext(context string) (RuntimeContext, error) {
 	return contextToSet, nil
 }
 
-func SetDefaultContext() error {
+// Returns an initialized "metal" context, as used by the CLI
+func NewDefaultContext() (RuntimeContext, error) {
 	rtcontext, err := NewContext("metal")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	err = rtcontext.Init(false)
 	if err != nil {
-		return err
-	}
-
-	SetContext(rtcontext)
-
-	return nil
-}
-
-func SetContext(context RuntimeContext) {
-	currentContext = context
-}
-
-func CurrentContext() RuntimeContext {
-	if currentContext == nil {
-		err := SetDefaultContext()
-		if err != nil {
-			log.Fatal(err)
-		}
+		return nil, err
 	}
 
-	return currentContext
+	return rtcontext, nil
 }
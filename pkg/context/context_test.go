@@ -4,31 +4,39 @@ import (
 	"testing"
 
 	"github.com/spiceai/spiceai/pkg/context"
+	"github.com/spiceai/spiceai/pkg/context/docker"
 	"github.com/spiceai/spiceai/pkg/context/metal"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestContext(t *testing.T) {
-	t.Run("CurrentContext() - Context is set correctly", testCurrentContext())
-	t.Run("SetDefaultContext() - Context is set correctly", testSetDefaultContext())
+	t.Run("NewContext() - Context is created correctly", testNewContext())
+	t.Run("NewDefaultContext() - Context is created correctly", testNewDefaultContext())
 }
 
-// Tests CurrentContext() inferring the correct context from the environment
-func testCurrentContext() func(*testing.T) {
+// Tests NewContext() creating the requested context
+func testNewContext() func(*testing.T) {
 	return func(t *testing.T) {
-		assert.IsType(t, &metal.MetalContext{}, context.CurrentContext())
+		rtcontext, err := context.NewContext("docker")
+		assert.NoError(t, err)
+		assert.IsType(t, &docker.DockerContext{}, rtcontext)
+
+		rtcontext, err = context.NewContext("Metal")
+		assert.NoError(t, err)
+		assert.IsType(t, &metal.MetalContext{}, rtcontext)
+
+		_, err = context.NewContext("cloud")
+		assert.Error(t, err)
 	}
 }
 
-// Tests SetDefaultContext()
-func testSetDefaultContext() func(*testing.T) {
+// Tests NewDefaultContext()
+func testNewDefaultContext() func(*testing.T) {
 	return func(t *testing.T) {
-		// Ensure no context is set
-		context.SetContext(nil)
-
-		err := context.SetDefaultContext()
+		rtcontext, err := context.NewDefaultContext()
 		assert.NoError(t, err)
 
-		assert.IsType(t, &metal.MetalContext{}, context.CurrentContext())
+		assert.IsType(t, &metal.MetalContext{}, rtcontext)
+		assert.NotEmpty(t, rtcontext.AppDir())
 	}
 }
@@ -0,0 +1,137 @@
+package dataspace
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spiceai/data-components-contrib/dataconnectors/coinbase"
+	"github.com/spiceai/data-components-contrib/dataconnectors/file"
+	"github.com/spiceai/data-components-contrib/dataconnectors/influxdb"
+	"github.com/spiceai/data-components-contrib/dataconnectors/twitter"
+	"github.com/spiceai/data-components-contrib/dataprocessors/csv"
+	"github.com/spiceai/data-components-contrib/dataprocessors/flux"
+	"github.com/spiceai/data-components-contrib/dataprocessors/json"
+)
+
+type ComponentParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// A data connector or processor that dataspaces can use, with the params it reads
+type Component struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Params      []ComponentParam `json:"params,omitempty"`
+}
+
+var dataConnectors = []Component{
+	{
+		Name:        coinbase.CoinbaseConnectorName,
+		Description: "Streams ticker prices from Coinbase Pro",
+		Params: []ComponentParam{
+			{Name: "product_ids", Description: "Comma-separated products, e.g. BTC-USD,ETH-USD", Required: true},
+		},
+	},
+	{
+		Name:        file.FileConnectorName,
+		Description: "Reads a file relative to the app directory",
+		Params: []ComponentParam{
+			{Name: "path", Description: "Path of the file to read", Required: true},
+			{Name: "watch", Description: "Re-read the file when it changes: true or false"},
+		},
+	},
+	{
+		Name:        influxdb.InfluxDbConnectorName,
+		Description: "Queries an InfluxDB bucket",
+		Params: []ComponentParam{
+			{Name: "url", Description: "InfluxDB server URL", Required: true},
+			{Name: "token", Description: "InfluxDB API token", Required: true},
+			{Name: "org", Description: "Organization to query"},
+			{Name: "bucket", Description: "Bucket to query"},
+			{Name: "measurement", Description: "Measurement to query"},
+			{Name: "field", Description: "Field to query"},
+			{Name: "fn", Description: "Aggregate function applied to each interval"},
+			{Name: "refresh_interval", Description: "How often to query for new data, e.g. 10s"},
+		},
+	},
+	{
+		Name:        twitter.TwitterConnectorName,
+		Description: "Streams tweets matching a filter",
+		Params: []ComponentParam{
+			{Name: "consumer_key", Description: "Twitter API consumer key", Required: true},
+			{Name: "consumer_secret", Description: "Twitter API consumer secret", Required: true},
+			{Name: "access_token", Description: "Twitter API access token", Required: true},
+			{Name: "access_secret", Description: "Twitter API access secret", Required: true},
+			{Name: "filter", Description: "Terms tweets are filtered by", Required: true},
+		},
+	},
+}
+
+var dataProcessors = []Component{
+	{
+		Name:        csv.CsvProcessorName,
+		Description: "Processes CSV data with a header row",
+		Params: []ComponentParam{
+			{Name: "time_format", Description: "Go time layout of the time column"},
+			{Name: "time_selector", Description: "Column with the observation time"},
+		},
+	},
+	{
+		Name:        flux.FluxCsvProcessorName,
+		Description: "Processes annotated CSV returned by InfluxDB Flux queries",
+	},
+	{
+		Name:        json.JsonProcessorName,
+		Description: "Processes a JSON array of observations",
+		Params: []ComponentParam{
+			{Name: "time_format", Description: "Go time layout of the time field"},
+			{Name: "time_selector", Description: "Field with the observation time"},
+		},
+	},
+}
+
+func DataConnectors() []Component {
+	return dataConnectors
+}
+
+func DataProcessors() []Component {
+	return dataProcessors
+}
+
+func FindDataConnector(name string) (*Component, error) {
+	return findComponent(dataConnectors, "data connector", name)
+}
+
+func FindDataProcessor(name string) (*Component, error) {
+	return findComponent(dataProcessors, "data processor", name)
+}
+
+// Returns an error naming the first required param that is missing or an unknown param
+func (c *Component) ValidateParams(params map[string]string) error {
+	known := make(map[string]bool, len(c.Params))
+	for _, param := range c.Params {
+		known[param.Name] = true
+		if param.Required && strings.TrimSpace(params[param.Name]) == "" {
+			return fmt.Errorf("%s requires the '%s' param", c.Name, param.Name)
+		}
+	}
+	for name := range params {
+		if !known[name] {
+			return fmt.Errorf("%s does not have a '%s' param", c.Name, name)
+		}
+	}
+	return nil
+}
+
+func findComponent(components []Component, kind string, name string) (*Component, error) {
+	names := make([]string, len(components))
+	for i := range components {
+		if components[i].Name == name {
+			return &components[i], nil
+		}
+		names[i] = components[i].Name
+	}
+	return nil, fmt.Errorf("unknown %s '%s', expected one of: %s", kind, name, strings.Join(names, ", "))
+}
@@ -0,0 +1,64 @@
+package dataspace
+
+import (
+	"testing"
+
+	"github.com/spiceai/data-components-contrib/dataconnectors"
+	"github.com/spiceai/data-components-contrib/dataprocessors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponents(t *testing.T) {
+	t.Run("DataConnectors() - Every connector can be created", testDataConnectorsFunc())
+	t.Run("DataProcessors() - Every processor can be created", testDataProcessorsFunc())
+	t.Run("FindDataConnector() - Unknown connectors are rejected", testFindDataConnectorUnknownFunc())
+	t.Run("ValidateParams() - Required and unknown params are rejected", testValidateParamsFunc())
+}
+
+func testDataConnectorsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		for _, c := range DataConnectors() {
+			_, err := dataconnectors.NewDataConnector(c.Name)
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func testDataProcessorsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		for _, p := range DataProcessors() {
+			_, err := dataprocessors.NewDataProcessor(p.Name)
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func testFindDataConnectorUnknownFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		c, err := FindDataConnector("file")
+		assert.NoError(t, err)
+		assert.Equal(t, "file", c.Name)
+
+		_, err = FindDataConnector("does-not-exist")
+		assert.EqualError(t, err, "unknown data connector 'does-not-exist', expected one of: coinbase, file, influxdb, twitter")
+	}
+}
+
+func testValidateParamsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		c, err := FindDataConnector("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.NoError(t, c.ValidateParams(map[string]string{"path": "data.csv", "watch": "true"}))
+		assert.EqualError(t, c.ValidateParams(map[string]string{"watch": "true"}), "file requires the 'path' param")
+		assert.EqualError(t, c.ValidateParams(map[string]string{"path": "data.csv", "url": "x"}), "file does not have a 'url' param")
+
+		p, err := FindDataProcessor("flux-csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, p.ValidateParams(nil))
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/spiceai/data-components-contrib/dataconnectors"
 	"github.com/spiceai/data-components-contrib/dataprocessors"
+	"github.com/spiceai/spiceai/pkg/quotas"
 	"github.com/spiceai/spiceai/pkg/spec"
 	"github.com/spiceai/spiceai/pkg/state"
 	"golang.org/x/sync/errgroup"
@@ -55,9 +56,14 @@ type Dataspace struct {
 	tags   []string
 	fqTags []string
 
-	stateMutex    *sync.RWMutex
-	cachedState   []*state.State
-	stateHandlers []state.StateHandler
+	stateMutex         *sync.RWMutex
+	cachedState        []*state.State
+	cachedObservations int
+	stateHandlers      []state.StateHandler
+
+	quotas *quotas.Quotas
+	// Enforces limits shared with the rest of the pod, called before observations are cached
+	reserveObservations func(numObservations int) error
 }
 
 func NewDataspace(dsSpec spec.DataspaceSpec) (*Dataspace, error) {
@@ -110,8 +116,30 @@ func (ds *Dataspace) Path() string {
 	return fmt.Sprintf("%s.%s", ds.DataspaceSpec.From, ds.DataspaceSpec.Name)
 }
 
+// Returns a snapshot of the cached state, which is only ever appended to
 func (ds *Dataspace) CachedState() []*state.State {
-	return ds.cachedState
+	ds.stateMutex.RLock()
+	defer ds.stateMutex.RUnlock()
+
+	return ds.cachedState[:len(ds.cachedState):len(ds.cachedState)]
+}
+
+// Returns the number of observations in the cached state
+func (ds *Dataspace) CachedObservations() int {
+	ds.stateMutex.RLock()
+	defer ds.stateMutex.RUnlock()
+
+	return ds.cachedObservations
+}
+
+func (ds *Dataspace) Quotas() *quotas.Quotas {
+	return ds.quotas
+}
+
+// Sets the dataspace's own quotas and the pod-wide check for cached observations, which may be nil
+func (ds *Dataspace) SetQuotas(q *quotas.Quotas, reserveObservations func(numObservations int) error) {
+	ds.quotas = q
+	ds.reserveObservations = reserveObservations
 }
 
 func (ds *Dataspace) Actions() map[string]string {
@@ -222,7 +250,18 @@ func (ds *Dataspace) AddNewState(state *state.State, metadata map[string]string)
 	ds.stateMutex.Lock()
 	defer ds.stateMutex.Unlock()
 
+	numObservations := len(state.Observations())
+	if err := ds.quotas.CheckCachedObservations(ds.cachedObservations, numObservations); err != nil {
+		return err
+	}
+	if ds.reserveObservations != nil {
+		if err := ds.reserveObservations(numObservations); err != nil {
+			return err
+		}
+	}
+
 	ds.cachedState = append(ds.cachedState, state)
+	ds.cachedObservations += numObservations
 
 	errGroup, _ := errgroup.WithContext(context.Background())
 
@@ -0,0 +1,89 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+const ReportFileName = "report.json"
+
+// Writes the report to w as a zip archive. Config, manifests, logs and metrics are written as their own files,
+// and report.json has everything else.
+func WriteBundle(w io.Writer, report *Report) error {
+	zipWriter := zip.NewWriter(w)
+
+	summary := *report
+	var err error
+
+	if report.Config != nil {
+		summary.Config, err = addFile(zipWriter, "config", report.GeneratedAt, report.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	summary.Manifests = make([]*Manifest, 0, len(report.Manifests))
+	for _, manifest := range report.Manifests {
+		file, err := addFile(zipWriter, "manifests", report.GeneratedAt, &manifest.File)
+		if err != nil {
+			return err
+		}
+		summary.Manifests = append(summary.Manifests, &Manifest{File: *file, ValidationError: manifest.ValidationError})
+	}
+
+	summary.Logs = make([]*File, 0, len(report.Logs))
+	for _, log := range report.Logs {
+		file, err := addFile(zipWriter, "logs", report.GeneratedAt, log)
+		if err != nil {
+			return err
+		}
+		summary.Logs = append(summary.Logs, file)
+	}
+
+	if report.Metrics != "" {
+		err = addBytes(zipWriter, "metrics.txt", report.GeneratedAt, []byte(report.Metrics))
+		if err != nil {
+			return err
+		}
+		summary.Metrics = ""
+	}
+
+	data, err := json.MarshalIndent(&summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = addBytes(zipWriter, ReportFileName, report.GeneratedAt, data)
+	if err != nil {
+		return err
+	}
+
+	return zipWriter.Close()
+}
+
+// Adds the file's content to dir in the archive, and returns the file without its content
+func addFile(zipWriter *zip.Writer, dir string, modified time.Time, file *File) (*File, error) {
+	if file.Content == "" {
+		return file, nil
+	}
+
+	err := addBytes(zipWriter, path.Join(dir, filepath.Base(file.Path)), modified, []byte(file.Content))
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{Path: file.Path, Error: file.Error}, nil
+}
+
+func addBytes(zipWriter *zip.Writer, name string, modified time.Time, data []byte) error {
+	writer, err := zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
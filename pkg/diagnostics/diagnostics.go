@@ -8,20 +8,19 @@ import (
 	"github.com/spiceai/spiceai/pkg/context"
 )
 
-func GenerateReport() (string, error) {
+func GenerateReport(rtcontext context.RuntimeContext) (string, error) {
 	body := strings.Builder{}
 
 	body.WriteString("## Diagnostics Report\n\n")
 
 	body.WriteString("Runtime Context\n")
 	body.WriteString("---------------\n")
-	context := context.CurrentContext()
-	body.WriteString(fmt.Sprintf("name: %s\n", context.Name()))
-	body.WriteString(fmt.Sprintf("app_dir: %s\n", context.AppDir()))
-	body.WriteString(fmt.Sprintf("pods_dir: %s\n", context.PodsDir()))
+	body.WriteString(fmt.Sprintf("name: %s\n", rtcontext.Name()))
+	body.WriteString(fmt.Sprintf("app_dir: %s\n", rtcontext.AppDir()))
+	body.WriteString(fmt.Sprintf("pods_dir: %s\n", rtcontext.PodsDir()))
 	body.WriteString("\n\n")
 
-	podsDirEntries, err := os.ReadDir(context.PodsDir())
+	podsDirEntries, err := os.ReadDir(rtcontext.PodsDir())
 	if err != nil {
 		return "", err
 	}
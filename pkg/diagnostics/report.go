@@ -0,0 +1,273 @@
+package diagnostics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spiceai/spiceai/pkg/aiengine"
+	"github.com/spiceai/spiceai/pkg/api"
+	"github.com/spiceai/spiceai/pkg/config"
+	"github.com/spiceai/spiceai/pkg/context"
+	"github.com/spiceai/spiceai/pkg/loggers"
+	"github.com/spiceai/spiceai/pkg/pods"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Only the end of each log is included, so bundles stay small enough to attach to a ticket
+	maxLogBytes = 1024 * 1024
+
+	redactedValue = "REDACTED"
+)
+
+// Keys whose values are replaced when config files and manifests are included
+var sensitiveKeyRegex = regexp.MustCompile(`(?i)(token|secret|password|passwd|credential|key|auth)`)
+
+type Versions struct {
+	Cli     string `json:"cli,omitempty"`
+	Runtime string `json:"runtime,omitempty"`
+	// Version of the runtime installed in the context
+	Context string `json:"context,omitempty"`
+}
+
+type ContextInfo struct {
+	Name       string `json:"name"`
+	AppDir     string `json:"app_dir"`
+	PodsDir    string `json:"pods_dir"`
+	RuntimeDir string `json:"runtime_dir"`
+}
+
+// A config file, manifest or log included in the report
+type File struct {
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+	// Set when the file could not be read or redacted, in which case it has no content
+	Error string `json:"error,omitempty"`
+}
+
+type Manifest struct {
+	File
+	// Why the pod failed to load or validate for training, empty if it is valid
+	ValidationError string `json:"validation_error,omitempty"`
+}
+
+// Everything needed to diagnose an app, collected by the runtime or, when it can't be reached, by the CLI
+type Report struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	Versions    *Versions    `json:"versions"`
+	Context     *ContextInfo `json:"context"`
+	// Config and manifests have sensitive values redacted
+	Config    *File       `json:"config,omitempty"`
+	Manifests []*Manifest `json:"manifests"`
+	Logs      []*File     `json:"logs"`
+
+	// Only collected by the runtime
+	Health     string                        `json:"health,omitempty"`
+	Metrics    string                        `json:"metrics,omitempty"`
+	Pods       []*api.PodStatus              `json:"pods,omitempty"`
+	Flights    map[string][]*api.Flight      `json:"flights,omitempty"`
+	Algorithms []*aiengine.LearningAlgorithm `json:"algorithms,omitempty"`
+
+	// Parts of the report that could not be collected
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Collects the parts of the report that are read from the app and runtime directories
+func Collect(rtcontext context.RuntimeContext) *Report {
+	report := &Report{
+		GeneratedAt: time.Now().UTC(),
+		Versions:    &Versions{},
+		Context: &ContextInfo{
+			Name:       rtcontext.Name(),
+			AppDir:     rtcontext.AppDir(),
+			PodsDir:    rtcontext.PodsDir(),
+			RuntimeDir: rtcontext.SpiceRuntimeDir(),
+		},
+		Manifests: make([]*Manifest, 0),
+		Logs:      make([]*File, 0),
+	}
+
+	contextVersion, err := rtcontext.Version()
+	if err != nil {
+		report.AddError("context version", err)
+	}
+	report.Versions.Context = contextVersion
+
+	if configPath := config.ConfigFilePath(rtcontext.AppDir()); configPath != "" {
+		report.Config = readRedactedFile(configPath)
+	}
+
+	if _, err := os.Stat(rtcontext.PodsDir()); err == nil {
+		for _, manifestPath := range pods.FindAllManifestPaths(rtcontext.PodsDir()) {
+			report.Manifests = append(report.Manifests, readManifest(manifestPath))
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		report.AddError("manifests", err)
+	}
+
+	aiEngineLog, err := loggers.LatestLogFile(rtcontext.SpiceRuntimeDir(), "aiengine")
+	if err != nil {
+		report.AddError("ai engine log", err)
+	} else if aiEngineLog != "" {
+		report.Logs = append(report.Logs, readLogTail(aiEngineLog))
+	}
+
+	return report
+}
+
+func (report *Report) AddError(part string, err error) {
+	report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", part, err.Error()))
+}
+
+// Replaces the values of keys that look like they hold credentials, keeping the document's comments
+func Redact(data []byte) ([]byte, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+	if document.Kind == 0 {
+		return data, nil
+	}
+
+	redactNode(&document)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&document)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func redactNode(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if sensitiveKeyRegex.MatchString(key.Value) && !isEmptyNode(value) {
+				redactValue(value)
+				continue
+			}
+			redactNode(value)
+		}
+		return
+	}
+
+	for _, child := range node.Content {
+		redactNode(child)
+	}
+}
+
+// Replaces a value of any kind, including every value in a mapping or sequence, with a redacted scalar.
+// The node is changed in place so aliases of it are redacted too.
+func redactValue(node *yaml.Node) {
+	*node = yaml.Node{
+		Kind:        yaml.ScalarNode,
+		Tag:         "!!str",
+		Value:       redactedValue,
+		Anchor:      node.Anchor,
+		HeadComment: node.HeadComment,
+		LineComment: node.LineComment,
+		FootComment: node.FootComment,
+		Line:        node.Line,
+		Column:      node.Column,
+	}
+}
+
+// Empty values are kept, as they show a credential isn't set
+func isEmptyNode(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value == ""
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	}
+	return false
+}
+
+func readRedactedFile(path string) *File {
+	file := &File{Path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+
+	redacted, err := Redact(data)
+	if err != nil {
+		// Content that can't be parsed can't be redacted, so it is left out
+		file.Error = fmt.Sprintf("not included, failed to parse: %s", err.Error())
+		return file
+	}
+
+	file.Content = string(redacted)
+	return file
+}
+
+func readManifest(manifestPath string) *Manifest {
+	manifest := &Manifest{File: *readRedactedFile(manifestPath)}
+
+	pod, err := pods.LoadPodFromManifest(manifestPath)
+	if err == nil {
+		err = pod.ValidateForTraining()
+	}
+	if err != nil {
+		manifest.ValidationError = strings.TrimSpace(err.Error())
+	}
+
+	return manifest
+}
+
+// Reads up to the last maxLogBytes of the log, starting at a line boundary
+func readLogTail(path string) *File {
+	file := &File{Path: path}
+
+	f, err := os.Open(path)
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+
+	offset := info.Size() - maxLogBytes
+	if offset < 0 {
+		offset = 0
+	}
+
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+
+	if offset > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	file.Content = string(data)
+	return file
+}
@@ -0,0 +1,204 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiceai/spiceai/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	t.Run("Redact() - Credentials, including lists and mappings of them, are replaced and comments kept", testRedactFunc())
+	t.Run("Collect() - Config, manifests, validation and the latest AI engine log are collected", testCollectFunc())
+	t.Run("WriteBundle() - Files are written alongside report.json", testWriteBundleFunc())
+}
+
+// A context with just the directories Collect reads
+type testContext struct {
+	context.RuntimeContext
+	appDir     string
+	runtimeDir string
+}
+
+func (c *testContext) Name() string {
+	return "test"
+}
+
+func (c *testContext) AppDir() string {
+	return c.appDir
+}
+
+func (c *testContext) PodsDir() string {
+	return filepath.Join(c.appDir, "spicepods")
+}
+
+func (c *testContext) SpiceRuntimeDir() string {
+	return c.runtimeDir
+}
+
+func (c *testContext) Version() (string, error) {
+	return "", errors.New("runtime not installed")
+}
+
+func testRedactFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		redacted, err := Redact([]byte(`# Twitter stream
+connector:
+  name: twitter
+  params:
+    consumer_key: abc # the key
+    consumer_secret: def
+    filter: spice
+    api_token: ""
+`))
+		assert.NoError(t, err)
+		assert.Equal(t, `# Twitter stream
+connector:
+  name: twitter
+  params:
+    consumer_key: REDACTED # the key
+    consumer_secret: REDACTED
+    filter: spice
+    api_token: ""
+`, string(redacted))
+
+		// Every value under a matching key is redacted, whatever its kind
+		redacted, err = Redact([]byte(`tokens: [abc, def]
+api_keys:
+  - abc
+  - def
+credentials:
+  user: u
+  pass: p
+passwords: []
+auth: &auth
+  user: u
+connector:
+  login: *auth
+`))
+		assert.NoError(t, err)
+		assert.Equal(t, `tokens: REDACTED
+api_keys: REDACTED
+credentials: REDACTED
+passwords: []
+auth: &auth REDACTED
+connector:
+  login: *auth
+`, string(redacted))
+		assert.NotContains(t, string(redacted), "abc")
+
+		_, err = Redact([]byte("name: [unclosed"))
+		assert.Error(t, err)
+	}
+}
+
+func testCollectFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		rtcontext := &testContext{appDir: t.TempDir(), runtimeDir: t.TempDir()}
+
+		writeTestFile(t, filepath.Join(rtcontext.appDir, "spice.config.yaml"), "http_port: 8000\nai_engine:\n  token: secret\n")
+		for _, name := range []string{"trader.yaml", "event-tags-invalid.yaml"} {
+			data, err := os.ReadFile(filepath.Join("../../test/assets/pods/manifests", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeTestFile(t, filepath.Join(rtcontext.PodsDir(), name), string(data))
+		}
+
+		logDir := filepath.Join(rtcontext.runtimeDir, "log")
+		writeTestFile(t, filepath.Join(logDir, "aiengine-20211001T100000Z.log"), "old\n")
+		writeTestFile(t, filepath.Join(logDir, "aiengine-20211002T100000Z.log"), "new\n")
+
+		report := Collect(rtcontext)
+
+		assert.Equal(t, "test", report.Context.Name)
+		assert.Equal(t, []string{"context version: runtime not installed"}, report.Errors)
+		assert.Equal(t, "http_port: 8000\nai_engine:\n  token: REDACTED\n", report.Config.Content)
+
+		assert.Len(t, report.Manifests, 2)
+		assert.Equal(t, filepath.Join(rtcontext.PodsDir(), "event-tags-invalid.yaml"), report.Manifests[0].Path)
+		assert.Equal(t, "invalid dataspace \"name\": 'data-invalid' should only contain A-Za-z0-9_", report.Manifests[0].ValidationError)
+		assert.Equal(t, filepath.Join(rtcontext.PodsDir(), "trader.yaml"), report.Manifests[1].Path)
+		assert.Empty(t, report.Manifests[1].ValidationError)
+		assert.Contains(t, report.Manifests[1].Content, "name: trader")
+
+		assert.Equal(t, []*File{{Path: filepath.Join(logDir, "aiengine-20211002T100000Z.log"), Content: "new\n"}}, report.Logs)
+	}
+}
+
+func testWriteBundleFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		report := &Report{
+			Versions:  &Versions{Cli: "local", Runtime: "local"},
+			Context:   &ContextInfo{Name: "test"},
+			Config:    &File{Path: "/app/spice.config.yaml", Content: "http_port: 8000\n"},
+			Manifests: []*Manifest{{File: File{Path: "/app/spicepods/trader.yaml", Content: "name: trader\n"}, ValidationError: "at least one action is required for training"}},
+			Logs:      []*File{{Path: "runtime.log", Content: "{\"msg\":\"started\"}"}, {Path: "/spice/log/aiengine.log", Error: "permission denied"}},
+			Health:    "ok",
+			Metrics:   "spice_quota_rejections_total 0\n",
+		}
+
+		var buf bytes.Buffer
+		err := WriteBundle(&buf, report)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		zipReader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files := make(map[string]string)
+		for _, f := range zipReader.File {
+			r, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[f.Name] = string(data)
+		}
+
+		assert.Equal(t, "http_port: 8000\n", files["config/spice.config.yaml"])
+		assert.Equal(t, "name: trader\n", files["manifests/trader.yaml"])
+		assert.Equal(t, "{\"msg\":\"started\"}", files["logs/runtime.log"])
+		assert.Equal(t, "spice_quota_rejections_total 0\n", files["metrics.txt"])
+		assert.Len(t, files, 5)
+
+		var summary Report
+		err = json.Unmarshal([]byte(files[ReportFileName]), &summary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "ok", summary.Health)
+		assert.Empty(t, summary.Metrics)
+		assert.Equal(t, &File{Path: "/app/spice.config.yaml"}, summary.Config)
+		assert.Equal(t, "at least one action is required for training", summary.Manifests[0].ValidationError)
+		assert.Empty(t, summary.Manifests[0].Content)
+		assert.Equal(t, []*File{{Path: "runtime.log"}, {Path: "/spice/log/aiengine.log", Error: "permission denied"}}, summary.Logs)
+
+		// The report itself keeps its content
+		assert.Equal(t, "name: trader\n", report.Manifests[0].Content)
+	}
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
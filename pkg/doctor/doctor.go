@@ -0,0 +1,344 @@
+package doctor
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/spiceai/spiceai/pkg/config"
+	"github.com/spiceai/spiceai/pkg/constants"
+	"github.com/spiceai/spiceai/pkg/context"
+	"github.com/spiceai/spiceai/pkg/pods"
+	"github.com/spiceai/spiceai/pkg/version"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+type Check struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	// How to fix a warning or failure
+	Fix string `json:"fix,omitempty"`
+}
+
+// Checks that an app and the runtime context it runs in have what the runtime needs
+type Doctor struct {
+	appDir         string
+	podsDir        string
+	runtimeContext context.RuntimeContext
+
+	lookPath         func(file string) (string, error)
+	runCommand       func(name string, arg ...string) ([]byte, error)
+	isRuntimeRunning func(serverBaseUrl string) bool
+}
+
+// Checks the app in appDir and podsDir, run in runtimeContext
+func NewDoctor(appDir string, podsDir string, runtimeContext context.RuntimeContext) *Doctor {
+	return &Doctor{
+		appDir:           appDir,
+		podsDir:          podsDir,
+		runtimeContext:   runtimeContext,
+		lookPath:         exec.LookPath,
+		runCommand:       runCommand,
+		isRuntimeRunning: isRuntimeRunning,
+	}
+}
+
+// Runs every check, in the order problems should be fixed
+func (d *Doctor) Run() []*Check {
+	checks := []*Check{d.checkAppDir()}
+
+	rtConfig, configCheck := d.checkConfig()
+	checks = append(checks, configCheck)
+	checks = append(checks, d.checkManifests()...)
+
+	if d.runtimeContext.Name() == "docker" {
+		checks = append(checks, d.checkDocker()...)
+	} else {
+		checks = append(checks, d.checkMetal(rtConfig)...)
+	}
+
+	checks = append(checks, d.checkPorts(rtConfig)...)
+
+	return checks
+}
+
+// Returns true if any check failed
+func Failed(checks []*Check) bool {
+	for _, check := range checks {
+		if check.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Doctor) checkAppDir() *Check {
+	check := &Check{Name: "app directory"}
+
+	f, err := os.CreateTemp(d.appDir, ".spice-doctor-*")
+	if err != nil {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("%s is not writable: %s", d.appDir, err.Error())
+		check.Fix = fmt.Sprintf("Make %s writable by the current user, e.g. 'chmod u+w %s'", d.appDir, d.appDir)
+		return check
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	check.Status = StatusPass
+	check.Message = fmt.Sprintf("%s is writable", d.appDir)
+	return check
+}
+
+// Returns the app's configuration, or the defaults if it is invalid
+func (d *Doctor) checkConfig() (*config.SpiceConfiguration, *Check) {
+	check := &Check{Name: "config"}
+
+	configName := fmt.Sprintf("%s.yaml", constants.SpiceConfigBaseName)
+	if configPath := config.ConfigFilePath(d.appDir); configPath != "" {
+		configName = filepath.Base(configPath)
+	}
+
+	rtConfig, err := config.LoadRuntimeConfiguration(viper.New(), d.appDir)
+	if err != nil {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("%s is invalid: %s", configName, err.Error())
+		check.Fix = fmt.Sprintf("Fix %s or the SPICE_ env vars that override it. Checks below use the default configuration.", configName)
+		return config.LoadDefaultConfiguration(), check
+	}
+
+	check.Status = StatusPass
+	if config.ConfigFilePath(d.appDir) == "" {
+		check.Message = fmt.Sprintf("no %s, using the default configuration", configName)
+	} else {
+		check.Message = fmt.Sprintf("%s is valid", configName)
+	}
+	return rtConfig, check
+}
+
+func (d *Doctor) checkManifests() []*Check {
+	if _, err := os.Stat(d.podsDir); err != nil {
+		return []*Check{{
+			Name:    "manifests",
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("%s not found, so there are no pods to run", d.podsDir),
+			Fix:     "Add a pod with 'spice add <pod>' or create an app with 'spice init --template trader'",
+		}}
+	}
+
+	manifestPaths := pods.FindAllManifestPaths(d.podsDir)
+	if len(manifestPaths) == 0 {
+		return []*Check{{
+			Name:    "manifests",
+			Status:  StatusWarn,
+			Message: fmt.Sprintf("%s has no pod manifests", d.podsDir),
+			Fix:     "Add a pod with 'spice add <pod>'",
+		}}
+	}
+
+	checks := make([]*Check, 0, len(manifestPaths))
+	for _, manifestPath := range manifestPaths {
+		check := &Check{Name: fmt.Sprintf("manifest %s", filepath.Base(manifestPath))}
+
+		pod, err := pods.LoadPodFromManifest(manifestPath)
+		if err == nil {
+			err = pod.ValidateForTraining()
+		}
+		if err != nil {
+			check.Status = StatusFail
+			check.Message = strings.TrimSpace(err.Error())
+			check.Fix = fmt.Sprintf("Fix %s", manifestPath)
+		} else {
+			check.Status = StatusPass
+			check.Message = fmt.Sprintf("pod %s is valid", pod.Name)
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+func (d *Doctor) checkDocker() []*Check {
+	dockerCheck := &Check{Name: "docker"}
+
+	if _, err := d.lookPath("docker"); err != nil {
+		dockerCheck.Status = StatusFail
+		dockerCheck.Message = "docker was not found in PATH"
+		dockerCheck.Fix = "Install Docker from https://docs.docker.com/get-docker/, or run the runtime with --context metal"
+		return []*Check{dockerCheck}
+	}
+
+	output, err := d.runCommand("docker", "info", "--format", "{{.ServerVersion}}")
+	if err != nil {
+		dockerCheck.Status = StatusFail
+		dockerCheck.Message = fmt.Sprintf("the Docker daemon can't be reached: %s", commandError(output, err))
+		dockerCheck.Fix = "Start Docker, and check the current user can run 'docker info'"
+		return []*Check{dockerCheck}
+	}
+
+	dockerCheck.Status = StatusPass
+	dockerCheck.Message = fmt.Sprintf("Docker %s is running", strings.TrimSpace(string(output)))
+
+	runtimeCheck := &Check{Name: "runtime"}
+	if d.runtimeContext.IsRuntimeInstallRequired() {
+		runtimeCheck.Status = StatusWarn
+		runtimeCheck.Message = "the Spice.ai runtime image has not been pulled"
+		runtimeCheck.Fix = "Run 'spice run', which pulls the image for this CLI version"
+		return []*Check{dockerCheck, runtimeCheck}
+	}
+
+	imageVersion, err := d.runtimeContext.Version()
+	if err != nil {
+		runtimeCheck.Status = StatusFail
+		runtimeCheck.Message = fmt.Sprintf("failed to get the runtime image version: %s", err.Error())
+		runtimeCheck.Fix = "Check 'docker images ghcr.io/spiceai/spiceai' lists the runtime image"
+		return []*Check{dockerCheck, runtimeCheck}
+	}
+
+	upgradeVersion, err := d.runtimeContext.IsRuntimeUpgradeAvailable()
+	if err == nil && upgradeVersion != "" {
+		runtimeCheck.Status = StatusWarn
+		runtimeCheck.Message = fmt.Sprintf("the runtime image is %s but the CLI is %s", imageVersion, version.Version())
+		runtimeCheck.Fix = "Run 'spice run', which pulls the image matching the CLI version"
+		return []*Check{dockerCheck, runtimeCheck}
+	}
+
+	runtimeCheck.Status = StatusPass
+	runtimeCheck.Message = fmt.Sprintf("runtime image %s is installed", imageVersion)
+	return []*Check{dockerCheck, runtimeCheck}
+}
+
+func (d *Doctor) checkMetal(rtConfig *config.SpiceConfiguration) []*Check {
+	runtimeCheck := &Check{Name: "runtime", Status: StatusPass, Message: "the Spice.ai runtime is installed"}
+	if d.runtimeContext.IsRuntimeInstallRequired() {
+		runtimeCheck.Status = StatusWarn
+		runtimeCheck.Message = fmt.Sprintf("the Spice.ai runtime is not installed in %s", d.runtimeContext.SpiceRuntimeDir())
+		runtimeCheck.Fix = "Run 'spice run --context metal', which installs it"
+	}
+
+	aiEngineDir := d.runtimeContext.AIEngineDir()
+	aiEngineCheck := &Check{Name: "ai engine", Status: StatusPass, Message: fmt.Sprintf("the AI engine is in %s", aiEngineDir)}
+	if _, err := os.Stat(filepath.Join(aiEngineDir, "main.py")); err != nil {
+		aiEngineCheck.Status = StatusFail
+		aiEngineCheck.Message = fmt.Sprintf("the AI engine was not found in %s", aiEngineDir)
+		aiEngineCheck.Fix = "Reinstall the runtime with 'spice run --context metal'. Development builds link it from $SPICE_REPO_ROOT/ai/src."
+	}
+
+	return []*Check{runtimeCheck, aiEngineCheck, d.checkPython(rtConfig, aiEngineDir)}
+}
+
+func (d *Doctor) checkPython(rtConfig *config.SpiceConfiguration, aiEngineDir string) *Check {
+	check := &Check{Name: "python"}
+
+	pythonPath := rtConfig.AIEngine.PythonPath
+	fixPath := "Fix ai_engine.python_path in the config"
+	if pythonPath == "" {
+		pythonPath = d.runtimeContext.AIEnginePythonCmdPath()
+		fixPath = fmt.Sprintf("Create the AI engine's virtualenv with 'make venv' in %s", aiEngineDir)
+	}
+
+	if _, err := os.Stat(pythonPath); err != nil {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("python was not found at %s", pythonPath)
+		check.Fix = fixPath
+		return check
+	}
+
+	output, err := d.runCommand(pythonPath, "--version")
+	if err != nil {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("%s failed to run: %s", pythonPath, commandError(output, err))
+		check.Fix = fixPath
+		return check
+	}
+	pythonVersion := strings.TrimSpace(string(output))
+
+	// grpc is the first package the AI engine imports, so a broken virtualenv fails here
+	output, err = d.runCommand(pythonPath, "-c", "import grpc")
+	if err != nil {
+		check.Status = StatusFail
+		check.Message = fmt.Sprintf("the AI engine's packages are missing from %s: %s", pythonVersion, commandError(output, err))
+		check.Fix = fmt.Sprintf("Install them with '%s -m pip install -r %s'", pythonPath, filepath.Join(aiEngineDir, "requirements", "production.txt"))
+		return check
+	}
+
+	check.Status = StatusPass
+	check.Message = fmt.Sprintf("%s at %s has the AI engine's packages", pythonVersion, pythonPath)
+	return check
+}
+
+func (d *Doctor) checkPorts(rtConfig *config.SpiceConfiguration) []*Check {
+	running := d.isRuntimeRunning(rtConfig.ServerBaseUrl())
+
+	checks := []*Check{checkPort("http port", rtConfig.ListenAddress(), "http_port", running)}
+
+	// The docker context runs the AI engine inside the container, so only the http port is published
+	if d.runtimeContext.Name() != "docker" {
+		checks = append(checks, checkPort("ai engine port", rtConfig.AIEngine.Address, "ai_engine.address", running))
+	}
+
+	return checks
+}
+
+// Checks the runtime could listen on address. An address in use is expected if the runtime is running.
+func checkPort(name string, address string, configKey string, runtimeRunning bool) *Check {
+	check := &Check{Name: name}
+
+	listener, err := net.Listen("tcp", address)
+	if err == nil {
+		listener.Close()
+		check.Status = StatusPass
+		check.Message = fmt.Sprintf("%s is free", address)
+		return check
+	}
+
+	if runtimeRunning {
+		check.Status = StatusPass
+		check.Message = fmt.Sprintf("%s is used by the running Spice.ai runtime", address)
+		return check
+	}
+
+	check.Status = StatusFail
+	check.Message = fmt.Sprintf("%s is in use by another process", address)
+	check.Fix = fmt.Sprintf("Stop the process using %s, or change %s in the config", address, configKey)
+	return check
+}
+
+func runCommand(name string, arg ...string) ([]byte, error) {
+	return exec.Command(name, arg...).CombinedOutput()
+}
+
+// Returns the command's last line of output, or err if there was none
+func commandError(output []byte, err error) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return last
+	}
+	return err.Error()
+}
+
+// Returns true if a runtime answers health checks at serverBaseUrl, even if it isn't healthy
+func isRuntimeRunning(serverBaseUrl string) bool {
+	client := &http.Client{Timeout: 2 * time.Second}
+	response, err := client.Get(serverBaseUrl + "/health")
+	if err != nil {
+		return false
+	}
+	response.Body.Close()
+	return response.StatusCode == http.StatusOK
+}
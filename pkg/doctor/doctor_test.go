@@ -0,0 +1,222 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spiceai/spiceai/pkg/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoctor(t *testing.T) {
+	t.Run("Run() - A valid metal app passes every check", testRunPassFunc())
+	t.Run("Run() - Problems fail with a fix", testRunFailFunc())
+	t.Run("checkConfig() - An invalid config fails and the defaults are used", testCheckConfigInvalidFunc())
+	t.Run("checkPorts() - Ports used by a running runtime pass", testCheckPortsRunningFunc())
+	t.Run("checkDocker() - Missing docker fails", testCheckDockerMissingFunc())
+}
+
+// A metal context installed in a temp directory
+type testContext struct {
+	context.RuntimeContext
+	name            string
+	runtimeDir      string
+	installRequired bool
+}
+
+func (c *testContext) Name() string {
+	return c.name
+}
+
+func (c *testContext) SpiceRuntimeDir() string {
+	return c.runtimeDir
+}
+
+func (c *testContext) AIEngineDir() string {
+	return filepath.Join(c.runtimeDir, "bin", "ai")
+}
+
+func (c *testContext) AIEnginePythonCmdPath() string {
+	return filepath.Join(c.AIEngineDir(), "venv", "bin", "python3")
+}
+
+func (c *testContext) IsRuntimeInstallRequired() bool {
+	return c.installRequired
+}
+
+// Returns a doctor for an app with the trader pod and the given config, run in a metal context with the AI engine installed
+func newTestDoctor(t *testing.T, configYaml string) (*Doctor, *testContext) {
+	appDir := t.TempDir()
+	writeTestFile(t, filepath.Join(appDir, "spice.config.yaml"), configYaml)
+
+	manifest, err := os.ReadFile("../../test/assets/pods/manifests/trader.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(appDir, "spicepods", "trader.yaml"), string(manifest))
+
+	rtcontext := &testContext{name: "metal", runtimeDir: t.TempDir()}
+	writeTestFile(t, filepath.Join(rtcontext.AIEngineDir(), "main.py"), "")
+	writeTestFile(t, rtcontext.AIEnginePythonCmdPath(), "")
+
+	d := NewDoctor(appDir, filepath.Join(appDir, "spicepods"), rtcontext)
+	d.runCommand = func(name string, arg ...string) ([]byte, error) {
+		if strings.Join(arg, " ") == "--version" {
+			return []byte("Python 3.8.10\n"), nil
+		}
+		return nil, nil
+	}
+	d.isRuntimeRunning = func(serverBaseUrl string) bool {
+		return false
+	}
+
+	return d, rtcontext
+}
+
+// Returns a config using ports nothing is listening on
+func freePortsConfig(t *testing.T) string {
+	return fmt.Sprintf("http_port: %d\nai_engine:\n  address: 127.0.0.1:%d\n", freePort(t), freePort(t))
+}
+
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func testRunPassFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		d, _ := newTestDoctor(t, freePortsConfig(t))
+
+		checks := d.Run()
+		names := make([]string, 0, len(checks))
+		for _, check := range checks {
+			assert.Equal(t, StatusPass, check.Status, "%s: %s", check.Name, check.Message)
+			assert.Empty(t, check.Fix)
+			names = append(names, check.Name)
+		}
+		assert.Equal(t, []string{"app directory", "config", "manifest trader.yaml", "runtime", "ai engine", "python", "http port", "ai engine port"}, names)
+		assert.Equal(t, "Python 3.8.10 at "+d.runtimeContext.AIEnginePythonCmdPath()+" has the AI engine's packages", checks[5].Message)
+		assert.False(t, Failed(checks))
+	}
+}
+
+func testRunFailFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		occupied, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer occupied.Close()
+		occupiedPort := occupied.Addr().(*net.TCPAddr).Port
+
+		d, rtcontext := newTestDoctor(t, fmt.Sprintf("bind_address: 127.0.0.1\nhttp_port: %d\nai_engine:\n  address: 127.0.0.1:%d\n", occupiedPort, freePort(t)))
+		rtcontext.installRequired = true
+		assert.NoError(t, os.Remove(filepath.Join(rtcontext.AIEngineDir(), "main.py")))
+		d.runCommand = func(name string, arg ...string) ([]byte, error) {
+			if strings.Join(arg, " ") == "--version" {
+				return []byte("Python 3.8.10\n"), nil
+			}
+			return []byte("Traceback (most recent call last):\nModuleNotFoundError: No module named 'grpc'\n"), errors.New("exit status 1")
+		}
+
+		invalidManifest, err := os.ReadFile("../../test/assets/pods/manifests/event-tags-invalid.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTestFile(t, filepath.Join(d.podsDir, "event-tags-invalid.yaml"), string(invalidManifest))
+
+		checks := make(map[string]*Check)
+		for _, check := range d.Run() {
+			checks[check.Name] = check
+		}
+
+		assert.Equal(t, StatusFail, checks["manifest event-tags-invalid.yaml"].Status)
+		assert.Equal(t, "invalid dataspace \"name\": 'data-invalid' should only contain A-Za-z0-9_", checks["manifest event-tags-invalid.yaml"].Message)
+		assert.Equal(t, StatusPass, checks["manifest trader.yaml"].Status)
+
+		assert.Equal(t, StatusWarn, checks["runtime"].Status)
+		assert.Equal(t, "Run 'spice run --context metal', which installs it", checks["runtime"].Fix)
+
+		assert.Equal(t, StatusFail, checks["ai engine"].Status)
+
+		assert.Equal(t, StatusFail, checks["python"].Status)
+		assert.Equal(t, "the AI engine's packages are missing from Python 3.8.10: ModuleNotFoundError: No module named 'grpc'", checks["python"].Message)
+		assert.Equal(t, fmt.Sprintf("Install them with '%s -m pip install -r %s'", rtcontext.AIEnginePythonCmdPath(), filepath.Join(rtcontext.AIEngineDir(), "requirements", "production.txt")), checks["python"].Fix)
+
+		address := fmt.Sprintf("127.0.0.1:%d", occupiedPort)
+		assert.Equal(t, &Check{
+			Name:    "http port",
+			Status:  StatusFail,
+			Message: address + " is in use by another process",
+			Fix:     "Stop the process using " + address + ", or change http_port in the config",
+		}, checks["http port"])
+		assert.Equal(t, StatusPass, checks["ai engine port"].Status)
+	}
+}
+
+func testCheckConfigInvalidFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		d, _ := newTestDoctor(t, "log_level: loud\n")
+
+		rtConfig, check := d.checkConfig()
+		assert.Equal(t, StatusFail, check.Status)
+		assert.Equal(t, "spice.config.yaml is invalid: invalid log_level 'loud': must be one of debug, info, warn or error", check.Message)
+		assert.Equal(t, uint(8000), rtConfig.HttpPort)
+	}
+}
+
+func testCheckPortsRunningFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		occupied, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer occupied.Close()
+
+		d, _ := newTestDoctor(t, fmt.Sprintf("bind_address: 127.0.0.1\nhttp_port: %d\n", occupied.Addr().(*net.TCPAddr).Port))
+		d.isRuntimeRunning = func(serverBaseUrl string) bool {
+			return true
+		}
+
+		rtConfig, _ := d.checkConfig()
+		checks := d.checkPorts(rtConfig)
+		assert.Equal(t, StatusPass, checks[0].Status)
+		assert.Equal(t, rtConfig.ListenAddress()+" is used by the running Spice.ai runtime", checks[0].Message)
+	}
+}
+
+func testCheckDockerMissingFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		d, rtcontext := newTestDoctor(t, freePortsConfig(t))
+		rtcontext.name = "docker"
+		d.lookPath = func(file string) (string, error) {
+			return "", errors.New("executable file not found in $PATH")
+		}
+
+		checks := d.checkDocker()
+		assert.Len(t, checks, 1)
+		assert.Equal(t, StatusFail, checks[0].Status)
+		assert.Equal(t, "docker was not found in PATH", checks[0].Message)
+		assert.NotEmpty(t, checks[0].Fix)
+	}
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -9,30 +9,42 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
-var firstInitCompleted bool = false
+// Environment connects the data connectors of loaded pods to the AI engine
+type Environment struct {
+	aiEngine           *aiengine.AIEngine
+	podRegistry        *pods.Registry
+	firstInitCompleted bool
+}
+
+func NewEnvironment(aiEngine *aiengine.AIEngine, podRegistry *pods.Registry) *Environment {
+	return &Environment{
+		aiEngine:    aiEngine,
+		podRegistry: podRegistry,
+	}
+}
 
-func FirstInitializationCompleted() bool {
-	return firstInitCompleted
+func (e *Environment) FirstInitializationCompleted() bool {
+	return e.firstInitCompleted
 }
 
-func InitDataConnectors() error {
+func (e *Environment) InitDataConnectors() error {
 	errGroup, _ := errgroup.WithContext(context.Background())
-	for _, pod := range pods.Pods() {
+	for _, pod := range e.podRegistry.Pods() {
 		p := pod
 		errGroup.Go(func() error {
-			return InitPodDataConnector(p)
+			return e.InitPodDataConnector(p)
 		})
 	}
 	err := errGroup.Wait()
 	if err == nil {
-		firstInitCompleted = true
+		e.firstInitCompleted = true
 	}
 	return err
 }
 
-func InitPodDataConnector(pod *pods.Pod) error {
+func (e *Environment) InitPodDataConnector(pod *pods.Pod) error {
 	handler := func(state *state.State, metadata map[string]string) error {
-		return aiengine.SendData(pod, state)
+		return e.aiEngine.SendData(pod, state)
 	}
 	err := pod.InitDataConnectors(handler)
 	if err != nil {
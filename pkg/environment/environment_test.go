@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/spiceai/spiceai/pkg/aiengine"
+	spice_context "github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
 	"github.com/stretchr/testify/assert"
@@ -19,8 +20,15 @@ func TestEnvironment(t *testing.T) {
 
 func testRegisterStateHandlers() func(*testing.T) {
 	return func(t *testing.T) {
+		rtcontext, err := spice_context.NewContext("metal")
+		assert.NoError(t, err)
+
+		aiEngine := aiengine.NewAIEngine(rtcontext)
+		podRegistry := pods.NewRegistry()
+		env := NewEnvironment(aiEngine, podRegistry)
+
 		data_received := make(chan bool)
-		aiengine.SetAIEngineClient(&aiengine.MockAIEngineClient{
+		aiEngine.SetAIEngineClient(&aiengine.MockAIEngineClient{
 			GetHealthHandler: func(c context.Context, hr *aiengine_pb.HealthRequest, co ...grpc.CallOption) (*aiengine_pb.Response, error) {
 				return &aiengine_pb.Response{
 					Result: "ok",
@@ -42,17 +50,14 @@ func testRegisterStateHandlers() func(*testing.T) {
 
 		pod, err := pods.LoadPodFromManifest("../../test/assets/pods/manifests/trader.yaml")
 		assert.NoError(t, err)
-		pods.CreateOrUpdatePod(pod)
+		podRegistry.CreateOrUpdatePod(pod)
 
 		t.Cleanup(func() {
-			aiengine.SetAIEngineClient(nil)
-			aiengine.StopServer() //nolint
-			pods.RemovePod(pod.Name)
-			firstInitCompleted = false
+			aiEngine.StopServer() //nolint
 		})
 
 		go func() {
-			err := InitDataConnectors()
+			err := env.InitDataConnectors()
 			assert.NoError(t, err)
 		}()
 
@@ -7,8 +7,13 @@ import (
 	"sync"
 	"time"
 
-	"github.com/logrusorgru/aurora"
+	"github.com/spiceai/spiceai/pkg/loggers"
 	"github.com/spiceai/spiceai/pkg/util"
+	"go.uber.org/zap"
+)
+
+var (
+	zaplog *zap.Logger = loggers.ZapLogger()
 )
 
 type Flight struct {
@@ -19,12 +24,15 @@ type Flight struct {
 	logDir    string
 
 	start time.Time
-	end   time.Time
+
+	completeMutex sync.RWMutex
+	end           time.Time
 
 	episodesMutex sync.RWMutex
 	episodes      []*Episode
 
 	isDone chan bool
+	done   chan struct{}
 	err    error
 }
 
@@ -36,6 +44,7 @@ func NewFlight(id string, episodes int64, algorithm string, loggers []string, lo
 		start:     time.Now(),
 		episodes:  make([]*Episode, 0, episodes),
 		isDone:    make(chan bool, 1),
+		done:      make(chan struct{}),
 		err:       nil,
 	}
 
@@ -51,6 +60,22 @@ func NewFlight(id string, episodes int64, algorithm string, loggers []string, lo
 	return f, nil
 }
 
+// Recreates a flight that has already completed, such as one restored from a snapshot
+func RestoreFlight(id string, algorithm string, loggers []string, start time.Time, end time.Time, episodes []*Episode) *Flight {
+	f := &Flight{
+		id:        id,
+		algorithm: algorithm,
+		loggers:   loggers,
+		start:     start,
+		end:       end,
+		episodes:  episodes,
+		isDone:    make(chan bool, 1),
+		done:      make(chan struct{}),
+	}
+	close(f.done)
+	return f
+}
+
 func (f *Flight) Id() string {
 	return f.id
 }
@@ -71,6 +96,11 @@ func (f *Flight) WaitForDoneChan() *chan bool {
 	return &f.isDone
 }
 
+// Closed when the flight completes. Unlike WaitForDoneChan, any number of receivers can wait on it.
+func (f *Flight) Done() <-chan struct{} {
+	return f.done
+}
+
 func (f *Flight) RecordEpisode(e *Episode) {
 	f.episodesMutex.Lock()
 	defer f.episodesMutex.Unlock()
@@ -111,16 +141,20 @@ func (f *Flight) Start() time.Time {
 }
 
 func (f *Flight) End() time.Time {
+	f.completeMutex.RLock()
+	defer f.completeMutex.RUnlock()
+
 	return f.end
 }
 
 func (f *Flight) IsComplete() bool {
-	return !f.end.IsZero()
+	return !f.End().IsZero()
 }
 
 func (f *Flight) Duration() time.Duration {
-	if !f.end.IsZero() {
-		return f.end.Sub(f.start)
+	end := f.End()
+	if !end.IsZero() {
+		return end.Sub(f.start)
 	}
 
 	return time.Since(f.start)
@@ -130,11 +164,24 @@ func (f *Flight) Close() error {
 	return os.RemoveAll(f.logDir)
 }
 
+// Ends a flight that will not record any more episodes, such as when the AI engine exits
+func (f *Flight) Abort(err error) {
+	f.complete(err)
+}
+
 func (f *Flight) complete(err error) {
+	f.completeMutex.Lock()
+	if !f.end.IsZero() {
+		f.completeMutex.Unlock()
+		return
+	}
 	f.end = time.Now()
 	f.err = err
+	close(f.done)
+	f.completeMutex.Unlock()
+
 	if err != nil {
-		fmt.Printf("Training run '%s' stopped on episode %d with error: %s\n", f.id, len(f.Episodes())+1, aurora.Red(err))
+		zaplog.Error("training run stopped", loggers.Flight(f.id), zap.Int("episode", len(f.Episodes())+1), zap.Error(err))
 	}
 	f.isDone <- true
 }
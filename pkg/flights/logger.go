@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/flights/loggers"
 )
 
@@ -12,12 +13,13 @@ type TrainingLogger interface {
 	Open() (*url.URL, error)
 }
 
-func (f *Flight) LoadLogger(loggerId string) (TrainingLogger, error) {
+func (f *Flight) LoadLogger(rtcontext context.RuntimeContext, loggerId string) (TrainingLogger, error) {
 	switch loggerId {
 	case "tensorboard":
 		return &loggers.TensorboardLogger{
-			RunId:  f.Id(),
-			LogDir: f.LogDir(),
+			RunId:   f.Id(),
+			LogDir:  f.LogDir(),
+			Context: rtcontext,
 		}, nil
 	default:
 		return nil, fmt.Errorf("Invalid logger %s", loggerId)
@@ -11,9 +11,9 @@ import (
 	"sync"
 	"time"
 
-	"github.com/logrusorgru/aurora"
 	"github.com/spiceai/spiceai/pkg/context"
-	"github.com/spiceai/spiceai/pkg/util"
+	spice_loggers "github.com/spiceai/spiceai/pkg/loggers"
+	"go.uber.org/zap"
 )
 
 type TensorBoard struct {
@@ -24,11 +24,13 @@ type TensorBoard struct {
 var (
 	cmdMutex             sync.Mutex
 	tensorboardInstances map[string]*TensorBoard
+	zaplog               *zap.Logger = spice_loggers.ZapLogger()
 )
 
 type TensorboardLogger struct {
-	RunId  string
-	LogDir string
+	RunId   string
+	LogDir  string
+	Context context.RuntimeContext
 }
 
 func (t *TensorboardLogger) Name() string {
@@ -62,7 +64,7 @@ func (l *TensorboardLogger) Open() (*url.URL, error) {
 
 	args := []string{"--logdir", runsDir}
 
-	rtcontext := context.CurrentContext()
+	rtcontext := l.Context
 	var tensorboardCmd string
 	if rtcontext.Name() == "docker" {
 		tensorboardCmd = "tensorboard"
@@ -89,9 +91,7 @@ func (l *TensorboardLogger) Open() (*url.URL, error) {
 		for outScanner.Scan() {
 			line := outScanner.Text()
 			outBuilder.WriteString(line)
-			if util.IsDebug() {
-				fmt.Println(aurora.BrightYellow(line))
-			}
+			zaplog.Debug(line, zap.String("logger", "tensorboard"))
 			if strings.HasPrefix(line, "TensorBoard ") && strings.HasSuffix(line, "(Press CTRL+C to quit)") {
 				startedLineChan <- line
 				return
@@ -129,7 +129,7 @@ func (l *TensorboardLogger) Open() (*url.URL, error) {
 		url.Host = fmt.Sprintf("localhost:%s", url.Port())
 	}
 
-	fmt.Printf("Opening %s %s %s %s\n", parts[0], parts[1], parts[2], url.String())
+	zaplog.Info("opening TensorBoard", zap.String("version", parts[1]), zap.String("url", url.String()))
 
 	tries := 0
 
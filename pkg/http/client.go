@@ -2,6 +2,7 @@ package http
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	net_http "net/http"
@@ -31,6 +32,22 @@ func Get(url string, accept string) (*net_http.Response, error) {
 	return do(req, accept)
 }
 
+// Sends a request with the given headers, which may be nil, and body, which may be nil
+func Do(method string, url string, header net_http.Header, body io.Reader) (*net_http.Response, error) {
+	req, err := retryablehttp.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	return do(req, header.Get("Accept"))
+}
+
 func do(req *retryablehttp.Request, accept string) (*net_http.Response, error) {
 	req.Header.Set("User-Agent", userAgent())
 	if accept != "" {
@@ -1,77 +1,124 @@
 package http
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"math"
+	"net"
 	"net/http"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fasthttp/router"
 	"github.com/spiceai/spiceai/pkg/aiengine"
 	"github.com/spiceai/spiceai/pkg/api"
+	"github.com/spiceai/spiceai/pkg/audit"
+	"github.com/spiceai/spiceai/pkg/config"
+	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/dashboard"
 	"github.com/spiceai/spiceai/pkg/dataspace"
 	"github.com/spiceai/spiceai/pkg/diagnostics"
 	"github.com/spiceai/spiceai/pkg/environment"
 	"github.com/spiceai/spiceai/pkg/flights"
+	"github.com/spiceai/spiceai/pkg/interpretations"
 	"github.com/spiceai/spiceai/pkg/loggers"
 	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/proto/common_pb"
 	"github.com/spiceai/spiceai/pkg/proto/runtime_pb"
+	"github.com/spiceai/spiceai/pkg/quotas"
+	"github.com/spiceai/spiceai/pkg/snapshot"
 	"github.com/spiceai/spiceai/pkg/state"
 	spice_time "github.com/spiceai/spiceai/pkg/time"
+	"github.com/spiceai/spiceai/pkg/version"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 )
 
-type ServerConfig struct {
-	Port uint
+type Server struct {
+	configMutex sync.RWMutex
+	config      *config.SpiceConfiguration
+	rtcontext   context.RuntimeContext
+	pods        *pods.Registry
+	aiEngine    *aiengine.AIEngine
+	environment *environment.Environment
+	fastServer  *fasthttp.Server
+	auditLog    *audit.Log
+
+	// Closed on Shutdown to end the responses following logs, which would otherwise keep it waiting
+	stopping     chan struct{}
+	stoppingOnce sync.Once
 }
 
-type server struct {
-	config ServerConfig
-}
+const (
+	// How often a followed log without new lines is checked for a client that went away
+	logsKeepAliveInterval = 15 * time.Second
+	// How much of a failed call's response is kept as its audit log error
+	auditMaxErrorLength = 256
+	// Snapshots include every pod's cached observations and models, so are allowed to be much larger than other bodies
+	maxSnapshotBodySize = 1 << 30
+
+	apiPodsPath    = "/api/v0.1/pods/"
+	apiRestorePath = "/api/v0.1/admin/restore"
+)
 
 var (
 	zaplog *zap.Logger = loggers.ZapLogger()
 )
 
-func healthHandler(ctx *fasthttp.RequestCtx) {
-	if !aiengine.ServerReady() {
-		fmt.Fprintf(ctx, "ai engine initializing")
-		return
+func (server *Server) healthHandler(ctx *fasthttp.RequestCtx) {
+	fmt.Fprint(ctx, server.health())
+}
+
+// Returns "ok", or what is initializing or degraded
+func (server *Server) health() string {
+	if !server.aiEngine.ServerReady() {
+		return "ai engine initializing"
 	}
 
-	err := aiengine.IsAIEngineHealthy()
+	err := server.aiEngine.IsAIEngineHealthy()
 	if err != nil {
-		fmt.Fprintf(ctx, "degraded\n")
-		fmt.Fprintf(ctx, "ai: %s", err.Error())
-		return
+		return fmt.Sprintf("degraded\nai: %s", err.Error())
 	}
 
-	if !environment.FirstInitializationCompleted() {
-		fmt.Fprintf(ctx, "environment initializing")
-		return
+	if !server.environment.FirstInitializationCompleted() {
+		return "environment initializing"
 	}
 
-	fmt.Fprintf(ctx, "ok")
+	return "ok"
 }
 
-func apiGetObservationsHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiGetObservationsHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 
 	if pod == nil {
 		ctx.Response.SetStatusCode(404)
 		return
 	}
 
+	start, err := parseTimeArg(ctx, "start")
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusBadRequest)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+	end, err := parseTimeArg(ctx, "end")
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusBadRequest)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
 	if string(ctx.Request.Header.Peek("Accept")) == "application/json" {
 		observations := []*common_pb.Observation{}
 		for _, state := range pod.CachedState() {
-			obs := api.NewObservationsFromState(state)
+			obs := api.NewObservationsFromStateBetween(state, start, end)
 			observations = append(observations, obs...)
 		}
 		ctx.Response.Header.Add("Content-Type", "application/json")
@@ -86,13 +133,27 @@ func apiGetObservationsHandler(ctx *fasthttp.RequestCtx) {
 	}
 
 	ctx.Response.Header.Add("Content-Type", "text/csv")
-	csv := pod.CachedCsv()
+	csv := pod.CachedCsvBetween(start, end)
 	_, _ = ctx.WriteString(csv)
 }
 
-func apiPostObservationsHandler(ctx *fasthttp.RequestCtx) {
+// Parses an optional unix timestamp or RFC3339 query arg, returning the zero time if it isn't set
+func parseTimeArg(ctx *fasthttp.RequestCtx, name string) (time.Time, error) {
+	arg := ctx.QueryArgs().Peek(name)
+	if len(arg) == 0 {
+		return time.Time{}, nil
+	}
+
+	t, err := spice_time.ParseTime(string(arg), "")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return t, nil
+}
+
+func (server *Server) apiPostObservationsHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 
 	if pod == nil {
 		ctx.Response.SetStatusCode(404)
@@ -103,6 +164,11 @@ func apiPostObservationsHandler(ctx *fasthttp.RequestCtx) {
 	validMeasurementNames := pod.MeasurementNames()
 	validCategoryNames := pod.CategoryNames()
 
+	ingestionConfig := server.Config().Ingestion
+	if writeQuotaError(ctx, checkIngestQuotas(pod.Quotas(), ctx, ingestionConfig)) {
+		return
+	}
+
 	newState, err := state.GetStateFromCsv(validIdentifierNames, validMeasurementNames, validCategoryNames, ctx.Request.Body())
 	if err != nil {
 		ctx.Response.SetStatusCode(400)
@@ -110,14 +176,29 @@ func apiPostObservationsHandler(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	pod.AddLocalState(newState...)
+	if ingestionConfig.MaxObservationsPerRequest > 0 {
+		numObservations := 0
+		for _, s := range newState {
+			numObservations += len(s.Observations())
+		}
+		if numObservations > ingestionConfig.MaxObservationsPerRequest {
+			ctx.Response.SetStatusCode(http.StatusRequestEntityTooLarge)
+			fmt.Fprintf(ctx, "too many observations: %d exceeds the limit of %d", numObservations, ingestionConfig.MaxObservationsPerRequest)
+			return
+		}
+	}
+
+	err = pod.AddLocalState(newState...)
+	if writeQuotaError(ctx, err) {
+		return
+	}
 
 	ctx.Response.SetStatusCode(201)
 }
 
-func apiPostDataspaceHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiPostDataspaceHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 
 	if pod == nil {
 		ctx.Response.SetStatusCode(404)
@@ -140,7 +221,14 @@ func apiPostDataspaceHandler(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	if writeQuotaError(ctx, checkIngestQuotas(selectedDataspace.Quotas(), ctx, server.Config().Ingestion)) {
+		return
+	}
+
 	_, err := selectedDataspace.ReadData(ctx.Request.Body(), nil)
+	if writeQuotaError(ctx, err) {
+		return
+	}
 	if err != nil {
 		zaplog.Sugar().Error(err)
 		ctx.Response.SetStatusCode(500)
@@ -150,12 +238,12 @@ func apiPostDataspaceHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetStatusCode(201)
 }
 
-func apiGetPodsHandler(ctx *fasthttp.RequestCtx) {
-	pods := pods.Pods()
+func (server *Server) apiGetPodsHandler(ctx *fasthttp.RequestCtx) {
+	loadedPods := server.pods.Pods()
 
-	data := make([]*api.Pod, 0, len(pods))
+	data := make([]*api.Pod, 0, len(loadedPods))
 
-	for _, f := range pods {
+	for _, f := range loadedPods {
 		if f == nil {
 			continue
 		}
@@ -175,16 +263,16 @@ func apiGetPodsHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetBody(response)
 }
 
-func apiGetPodHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiGetPodHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 
 	if pod == nil {
 		ctx.Response.SetStatusCode(404)
 		return
 	}
 
-	data := api.NewPod(pod)
+	data := api.NewPodDetails(pod)
 
 	response, err := json.Marshal(data)
 	if err != nil {
@@ -197,9 +285,103 @@ func apiGetPodHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetBody(response)
 }
 
-func apiPodTrainHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiGetPodsStatusHandler(ctx *fasthttp.RequestCtx) {
+	loadedPods := server.pods.Pods()
+	now := time.Now()
+
+	data := make([]*api.PodStatus, 0, len(loadedPods))
+	for _, pod := range loadedPods {
+		engineSync := aiengine.PodNotInitialized
+		if server.aiEngine != nil {
+			engineSync = server.aiEngine.PodSyncState(pod)
+		}
+		data = append(data, api.NewPodStatus(pod, engineSync, now))
+	}
+
+	response, err := json.Marshal(data)
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	ctx.Response.Header.SetContentType("application/json")
+	ctx.Response.SetBody(response)
+}
+
+// Reloads the pod from its manifest and reinitializes it with the AI engine, even if the manifest is unchanged
+func (server *Server) apiPodReloadHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
+
+	if pod == nil {
+		ctx.Response.SetStatusCode(http.StatusNotFound)
+		return
+	}
+
+	newPod, err := server.pods.LoadPodFromManifest(pod.ManifestPath())
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusBadRequest)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	if newPod.Name != pod.Name {
+		ctx.Response.SetStatusCode(http.StatusConflict)
+		ctx.Response.SetBodyString(fmt.Sprintf("manifest '%s' now defines pod '%s'", pod.ManifestPath(), newPod.Name))
+		return
+	}
+
+	server.pods.CreateOrUpdatePod(newPod)
+
+	if server.aiEngine != nil && server.aiEngine.ServerReady() {
+		err = server.aiEngine.InitializePod(newPod)
+		if err == nil {
+			err = server.aiEngine.SendData(newPod, newPod.CachedState()...)
+		}
+		if err != nil {
+			ctx.Response.SetStatusCode(http.StatusInternalServerError)
+			ctx.Response.SetBodyString(err.Error())
+			return
+		}
+	}
+
+	// An unchanged manifest returns the loaded pod, whose connectors are already initialized
+	if newPod != pod && server.environment != nil {
+		err = server.environment.InitPodDataConnector(newPod)
+		if err != nil {
+			ctx.Response.SetStatusCode(http.StatusInternalServerError)
+			ctx.Response.SetBodyString(err.Error())
+			return
+		}
+	}
+
+	response, err := json.Marshal(api.NewPodDetails(newPod))
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	ctx.Response.Header.SetContentType("application/json")
+	ctx.Response.SetBody(response)
+}
+
+// Unloads the pod from the runtime. Its manifest is left in place.
+func (server *Server) apiDeletePodHandler(ctx *fasthttp.RequestCtx) {
+	podParam := ctx.UserValue("pod").(string)
+
+	if server.pods.RemovePod(podParam) == nil {
+		ctx.Response.SetStatusCode(http.StatusNotFound)
+		return
+	}
+
+	fmt.Fprintf(ctx, "ok")
+}
+
+func (server *Server) apiPodTrainHandler(ctx *fasthttp.RequestCtx) {
+	podParam := ctx.UserValue("pod").(string)
+	pod := server.pods.GetPod(podParam)
 
 	if pod == nil {
 		ctx.Response.SetStatusCode(404)
@@ -214,17 +396,38 @@ func apiPodTrainHandler(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	err = aiengine.StartTraining(pod, trainRequest)
+	queued, err := server.aiEngine.QueueTraining(pod, trainRequest)
+	if writeQuotaError(ctx, err) {
+		return
+	}
 	if err != nil {
 		ctx.Response.SetStatusCode(500)
 		ctx.Response.SetBodyString(err.Error())
 		return
 	}
 
+	if queued {
+		ctx.Response.SetStatusCode(http.StatusAccepted)
+		fmt.Fprintf(ctx, "queued")
+		return
+	}
+
 	fmt.Fprintf(ctx, "ok")
 }
 
-func apiRecommendationHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiGetTrainingQueueHandler(ctx *fasthttp.RequestCtx) {
+	response, err := json.Marshal(api.NewTrainingQueue(server.aiEngine.Scheduler().Status()))
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	ctx.Response.Header.SetContentType("application/json")
+	ctx.Response.SetBody(response)
+}
+
+func (server *Server) apiRecommendationHandler(ctx *fasthttp.RequestCtx) {
 	pod := ctx.UserValue("pod").(string)
 	tag := ctx.UserValue("tag")
 
@@ -239,7 +442,7 @@ func apiRecommendationHandler(ctx *fasthttp.RequestCtx) {
 		tag = "latest"
 	}
 
-	inference, err := aiengine.Infer(pod, int64(inferenceTime), tag.(string))
+	inference, err := server.aiEngine.Infer(pod, int64(inferenceTime), tag.(string))
 	if err != nil {
 		ctx.Response.SetStatusCode(500)
 		ctx.Response.SetBodyString(err.Error())
@@ -261,16 +464,16 @@ func apiRecommendationHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetBody(body)
 }
 
-func apiGetFlightsHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiGetFlightsHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 	if pod == nil {
 		ctx.Response.SetStatusCode(404)
 		return
 	}
 
 	data := make([]*api.Flight, 0)
-	for _, f := range *pod.Flights() {
+	for _, f := range pod.Flights() {
 		flight := api.NewFlight(f)
 		data = append(data, flight)
 	}
@@ -286,9 +489,9 @@ func apiGetFlightsHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetBody(response)
 }
 
-func apiGetFlightHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiGetFlightHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 	if pod == nil {
 		ctx.Response.SetStatusCode(404)
 		return
@@ -314,9 +517,9 @@ func apiGetFlightHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetBody(response)
 }
 
-func apiPostFlightEpisodeHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiPostFlightEpisodeHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 	if pod == nil {
 		ctx.Response.SetStatusCode(404)
 		return
@@ -352,9 +555,9 @@ func apiPostFlightEpisodeHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetStatusCode(201)
 }
 
-func apiPostFlightLoggerHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiPostFlightLoggerHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 	if pod == nil {
 		ctx.Response.SetStatusCode(404)
 		return
@@ -368,7 +571,7 @@ func apiPostFlightLoggerHandler(ctx *fasthttp.RequestCtx) {
 	}
 
 	loggerIdParam := ctx.UserValue("loggerId").(string)
-	logger, err := flight.LoadLogger(loggerIdParam)
+	logger, err := flight.LoadLogger(server.rtcontext, loggerIdParam)
 	if err != nil {
 		ctx.Response.SetStatusCode(404)
 		ctx.Response.SetBodyString(err.Error())
@@ -390,28 +593,101 @@ func apiPostFlightLoggerHandler(ctx *fasthttp.RequestCtx) {
 	}
 }
 
-func apiGetInterpretationsHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiGetInterpretationsHandler(ctx *fasthttp.RequestCtx) {
+	podParam := ctx.UserValue("pod").(string)
+	pod := server.pods.GetPod(podParam)
+	if pod == nil {
+		ctx.Response.SetStatusCode(http.StatusNotFound)
+		return
+	}
+
+	start, end, ok := parseInterpretationsRange(ctx, pod)
+	if !ok {
+		return
+	}
+
+	interpretations := pod.Interpretations().Get(start, end)
+	apiInterpretations := api.ApiInterpretations(interpretations)
+
+	response, err := json.Marshal(apiInterpretations)
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	ctx.Response.Header.SetContentType("application/json")
+	ctx.Response.SetBody(response)
+}
+
+// Removes the interpretations that lie within start and end and match the name and tag, when given.
+// Responds with the removed interpretations.
+func (server *Server) apiDeleteInterpretationsHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 	if pod == nil {
 		ctx.Response.SetStatusCode(http.StatusNotFound)
 		return
 	}
 
+	start, end, ok := parseInterpretationsRange(ctx, pod)
+	if !ok {
+		return
+	}
+
+	name := string(ctx.QueryArgs().Peek("name"))
+	tag := string(ctx.QueryArgs().Peek("tag"))
+
+	removed := pod.Interpretations().Remove(func(i *interpretations.Interpretation) bool {
+		if i.Start().Before(start) || i.End().After(end) {
+			return false
+		}
+		if name != "" && i.Name() != name {
+			return false
+		}
+		if tag != "" {
+			for _, t := range i.Tags() {
+				if t == tag {
+					return true
+				}
+			}
+			return false
+		}
+		return true
+	})
+	if len(removed) > 0 {
+		server.pods.NotifyInterpretationsChanged(pod)
+	}
+
+	response, err := json.Marshal(api.ApiInterpretations(removed))
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	ctx.Response.Header.SetContentType("application/json")
+	ctx.Response.SetBody(response)
+}
+
+// Parses the start and end query args, defaulting to the pod's period. Writes a bad request response and returns false if invalid.
+func parseInterpretationsRange(ctx *fasthttp.RequestCtx, pod *pods.Pod) (time.Time, time.Time, bool) {
+	var err error
+
 	start := pod.Epoch()
 	startArg := ctx.QueryArgs().Peek("start")
 	if startArg != nil {
-		start, err := spice_time.ParseTime(string(startArg), "")
+		start, err = spice_time.ParseTime(string(startArg), "")
 		if err != nil {
 			ctx.Response.SetStatusCode(http.StatusBadRequest)
 			ctx.Response.SetBodyString(fmt.Sprintf("invalid start %s", startArg))
-			return
+			return start, start, false
 		}
 
 		if start.Before(pod.Epoch()) {
 			ctx.Response.SetStatusCode(http.StatusBadRequest)
 			ctx.Response.SetBodyString(fmt.Sprintf("start %s cannot be before pod epoch %s", startArg, pod.Epoch().String()))
-			return
+			return start, start, false
 		}
 	}
 
@@ -419,46 +695,41 @@ func apiGetInterpretationsHandler(ctx *fasthttp.RequestCtx) {
 	end := podPeriodEnd
 	endArg := ctx.QueryArgs().Peek("end")
 	if endArg != nil {
-		end, err := spice_time.ParseTime(string(endArg), "")
+		end, err = spice_time.ParseTime(string(endArg), "")
 		if err != nil {
 			ctx.Response.SetStatusCode(http.StatusBadRequest)
 			ctx.Response.SetBodyString(fmt.Sprintf("invalid end %s", endArg))
-			return
+			return start, end, false
 		}
 
 		if end.After(podPeriodEnd) {
 			ctx.Response.SetStatusCode(http.StatusBadRequest)
-			ctx.Response.SetBodyString(fmt.Sprintf("end %s cannot be after pod period %s", startArg, podPeriodEnd.String()))
-			return
+			ctx.Response.SetBodyString(fmt.Sprintf("end %s cannot be after pod period %s", endArg, podPeriodEnd.String()))
+			return start, end, false
 		}
 	}
 
 	if end.Before(start) {
 		ctx.Response.SetStatusCode(http.StatusBadRequest)
 		ctx.Response.SetBodyString(fmt.Sprintf("end %s cannot be before start %s", endArg, startArg))
-		return
-	}
-
-	interpretations := pod.Interpretations().Get(start, end)
-	apiInterpretations := api.ApiInterpretations(interpretations)
-
-	response, err := json.Marshal(apiInterpretations)
-	if err != nil {
-		ctx.Response.Header.SetContentType("application/json")
-		return
+		return start, end, false
 	}
 
-	ctx.Response.SetBody(response)
+	return start, end, true
 }
 
-func apiPostInterpretationsHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiPostInterpretationsHandler(ctx *fasthttp.RequestCtx) {
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 	if pod == nil {
 		ctx.Response.SetStatusCode(http.StatusNotFound)
 		return
 	}
 
+	if writeQuotaError(ctx, pod.Quotas().CheckBodySize(len(ctx.Request.Body()), server.Config().Ingestion.MaxBodyBytes)) {
+		return
+	}
+
 	var apiInterpretations []*api.Interpretation
 	err := json.Unmarshal(ctx.Request.Body(), &apiInterpretations)
 	if err != nil {
@@ -467,6 +738,14 @@ func apiPostInterpretationsHandler(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
+	err = pod.Quotas().CheckInterpretations(len(pod.Interpretations().All()), len(apiInterpretations))
+	if writeQuotaError(ctx, err) {
+		return
+	}
+
+	// Including when only some were added before an invalid one
+	defer server.pods.NotifyInterpretationsChanged(pod)
+
 	for _, i := range apiInterpretations {
 		interpretation, err := api.NewInterpretationFromApi(i)
 		if err != nil {
@@ -486,7 +765,7 @@ func apiPostInterpretationsHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetStatusCode(http.StatusCreated)
 }
 
-func apiPostExportHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiPostExportHandler(ctx *fasthttp.RequestCtx) {
 	tag := ctx.UserValue("tag")
 
 	if tag == nil || tag == "" {
@@ -494,7 +773,7 @@ func apiPostExportHandler(ctx *fasthttp.RequestCtx) {
 	}
 
 	podParam := ctx.UserValue("pod").(string)
-	pod := pods.GetPod(podParam)
+	pod := server.pods.GetPod(podParam)
 	if pod == nil {
 		ctx.Response.SetStatusCode(404)
 		return
@@ -508,7 +787,7 @@ func apiPostExportHandler(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	err = aiengine.ExportPod(pod.Name, tag.(string), &exportRequest)
+	err = server.aiEngine.ExportPod(pod, tag.(string), &exportRequest)
 	if err != nil {
 		ctx.Response.SetStatusCode(400)
 		ctx.Response.SetBodyString(err.Error())
@@ -518,7 +797,7 @@ func apiPostExportHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetStatusCode(200)
 }
 
-func apiPostImportHandler(ctx *fasthttp.RequestCtx) {
+func (server *Server) apiPostImportHandler(ctx *fasthttp.RequestCtx) {
 	tag := ctx.UserValue("tag")
 
 	if tag == nil || tag == "" {
@@ -545,14 +824,19 @@ func apiPostImportHandler(ctx *fasthttp.RequestCtx) {
 		return
 	}
 
-	err = aiengine.ImportPod(pod, &importRequest)
+	server.pods.CreateOrUpdatePod(pod)
+
+	err = server.aiEngine.ImportPod(pod, &importRequest)
 	if err != nil {
 		ctx.Response.SetStatusCode(400)
 		ctx.Response.SetBodyString(err.Error())
 		return
 	}
 
-	err = environment.InitPodDataConnector(pod)
+	// Imported models can include interpretations
+	server.pods.NotifyInterpretationsChanged(pod)
+
+	err = server.environment.InitPodDataConnector(pod)
 	if err != nil {
 		ctx.Response.SetStatusCode(500)
 		ctx.Response.SetBodyString(err.Error())
@@ -562,8 +846,110 @@ func apiPostImportHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetStatusCode(200)
 }
 
-func (server *server) apiGetAlgorithmsHandler(ctx *fasthttp.RequestCtx) {
-	data, err := json.Marshal(aiengine.Algorithms())
+func (server *Server) apiPostSnapshotHandler(ctx *fasthttp.RequestCtx) {
+	exportModel := func(pod *pods.Pod, archivePath string) error {
+		if server.aiEngine == nil || !server.aiEngine.ServerReady() {
+			return snapshot.ErrNoModel
+		}
+		exportRequest := &runtime_pb.ExportModel{
+			Directory: filepath.Dir(archivePath),
+			Filename:  filepath.Base(archivePath),
+		}
+		err := server.aiEngine.ExportPod(pod, "latest", exportRequest)
+		if err != nil {
+			// Pods that haven't been trained have no model to export
+			zaplog.Sugar().Infof("snapshot of pod %s has no model: %s", pod.Name, err.Error())
+			return snapshot.ErrNoModel
+		}
+		return nil
+	}
+
+	var archive bytes.Buffer
+	_, err := snapshot.Create(&archive, server.pods.Pods(), exportModel)
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	ctx.Response.Header.SetContentType("application/zip")
+	ctx.Response.SetBody(archive.Bytes())
+}
+
+func (server *Server) apiPostRestoreHandler(ctx *fasthttp.RequestCtx) {
+	restorePod := func(pod *pods.Pod, modelArchivePath string) error {
+		if server.aiEngine != nil && server.aiEngine.ServerReady() {
+			err := server.aiEngine.InitializePod(pod)
+			if err != nil {
+				return err
+			}
+			err = server.aiEngine.SendData(pod, pod.CachedState()...)
+			if err != nil {
+				return err
+			}
+			if modelArchivePath != "" {
+				err = server.aiEngine.ImportModelArchive(pod, modelArchivePath, "latest")
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if server.environment != nil {
+			return server.environment.InitPodDataConnector(pod)
+		}
+		return nil
+	}
+
+	body := ctx.Request.Body()
+	manifest, err := snapshot.Restore(bytes.NewReader(body), int64(len(body)), server.rtcontext.PodsDir(), server.pods, restorePod)
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusBadRequest)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	response, err := json.Marshal(manifest)
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	ctx.Response.Header.SetContentType("application/json")
+	ctx.Response.SetBody(response)
+}
+
+func (server *Server) apiGetQuotasHandler(ctx *fasthttp.RequestCtx) {
+	podParam := ctx.UserValue("pod").(string)
+	pod := server.pods.GetPod(podParam)
+	if pod == nil {
+		ctx.Response.SetStatusCode(http.StatusNotFound)
+		return
+	}
+
+	response, err := json.Marshal(api.NewPodQuotas(pod))
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	ctx.Response.Header.SetContentType("application/json")
+	ctx.Response.SetBody(response)
+}
+
+func (server *Server) apiGetMetricsHandler(ctx *fasthttp.RequestCtx) {
+	ctx.Response.Header.SetContentType("text/plain; version=0.0.4")
+	err := api.WriteQuotaMetrics(ctx, server.pods.Pods())
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+	}
+}
+
+func (server *Server) apiGetAlgorithmsHandler(ctx *fasthttp.RequestCtx) {
+	data, err := json.Marshal(server.aiEngine.Algorithms())
 	if err != nil {
 		ctx.Response.SetStatusCode(http.StatusInternalServerError)
 		ctx.Response.SetBodyString(err.Error())
@@ -575,8 +961,21 @@ func (server *server) apiGetAlgorithmsHandler(ctx *fasthttp.RequestCtx) {
 	ctx.Response.SetBody(data)
 }
 
-func (server *server) apiGetDiagnosticsHandler(ctx *fasthttp.RequestCtx) {
-	report, err := diagnostics.GenerateReport()
+func (server *Server) apiGetDiagnosticsHandler(ctx *fasthttp.RequestCtx) {
+	if string(ctx.QueryArgs().Peek("format")) == "json" {
+		response, err := json.Marshal(server.diagnosticsReport())
+		if err != nil {
+			ctx.Response.SetStatusCode(http.StatusInternalServerError)
+			ctx.Response.SetBodyString(err.Error())
+			return
+		}
+
+		ctx.Response.Header.SetContentType("application/json")
+		ctx.Response.SetBody(response)
+		return
+	}
+
+	report, err := diagnostics.GenerateReport(server.rtcontext)
 	if err != nil {
 		ctx.Response.SetStatusCode(500)
 		ctx.Response.SetBodyString(err.Error())
@@ -586,51 +985,491 @@ func (server *server) apiGetDiagnosticsHandler(ctx *fasthttp.RequestCtx) {
 	ctx.SetBodyString(report)
 }
 
-func NewServer(port uint) *server {
-	return &server{
-		config: ServerConfig{
-			Port: port,
+// Adds the runtime's recent logs, health, metrics, pods, training runs and algorithms to the diagnostics collected from disk
+func (server *Server) diagnosticsReport() *diagnostics.Report {
+	report := diagnostics.Collect(server.rtcontext)
+	report.Versions.Runtime = version.Version()
+
+	report.Logs = append(report.Logs, &diagnostics.File{
+		Path:    "runtime.log",
+		Content: strings.Join(loggers.RecentRuntimeLogs(), "\n"),
+	})
+
+	if server.aiEngine != nil && server.environment != nil {
+		report.Health = server.health()
+	}
+
+	var metrics bytes.Buffer
+	loadedPods := server.pods.Pods()
+	err := api.WriteQuotaMetrics(&metrics, loadedPods)
+	if err != nil {
+		report.AddError("metrics", err)
+	}
+	report.Metrics = metrics.String()
+
+	now := time.Now()
+	podNames := make([]string, 0, len(loadedPods))
+	for name := range loadedPods {
+		podNames = append(podNames, name)
+	}
+	sort.Strings(podNames)
+
+	report.Pods = make([]*api.PodStatus, 0, len(loadedPods))
+	report.Flights = make(map[string][]*api.Flight, len(loadedPods))
+	for _, name := range podNames {
+		pod := loadedPods[name]
+		engineSync := aiengine.PodNotInitialized
+		if server.aiEngine != nil {
+			engineSync = server.aiEngine.PodSyncState(pod)
+		}
+		report.Pods = append(report.Pods, api.NewPodStatus(pod, engineSync, now))
+
+		podFlights := make([]*api.Flight, 0)
+		for _, f := range pod.Flights() {
+			podFlights = append(podFlights, api.NewFlight(f))
+		}
+		report.Flights[name] = podFlights
+	}
+
+	if server.aiEngine != nil {
+		report.Algorithms = server.aiEngine.Algorithms()
+	}
+
+	return report
+}
+
+// Returns logged lines as JSON lines, oldest first, then keeps streaming new lines if follow=true
+func (server *Server) apiGetLogsHandler(ctx *fasthttp.RequestCtx) {
+	query, err := parseLogQuery(ctx)
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusBadRequest)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	follow := ctx.QueryArgs().GetBool("follow")
+
+	var followed <-chan *loggers.LogLine
+	var stop func()
+	if follow {
+		// Subscribe before reading the logged lines so none are missed in between
+		followed, stop = loggers.FollowLogs(query)
+	}
+
+	var dotSpicePath string
+	if server.rtcontext != nil {
+		dotSpicePath = server.rtcontext.SpiceRuntimeDir()
+	}
+
+	logLines, err := loggers.QueryLogs(query, dotSpicePath)
+	if err != nil {
+		if stop != nil {
+			stop()
+		}
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	ctx.Response.Header.SetContentType("application/x-ndjson")
+
+	if !follow {
+		var body bytes.Buffer
+		encoder := json.NewEncoder(&body)
+		encoder.SetEscapeHTML(false)
+		for _, logLine := range logLines {
+			_ = encoder.Encode(logLine)
+		}
+		ctx.Response.SetBody(body.Bytes())
+		return
+	}
+
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		server.streamLogs(w, logLines, followed, stop)
+	})
+}
+
+// Writes logLines then each followed line until the client goes away or the server shuts down
+func (server *Server) streamLogs(w *bufio.Writer, logLines []*loggers.LogLine, followed <-chan *loggers.LogLine, stop func()) {
+	defer stop()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	written := make(map[string]time.Time)
+	for _, logLine := range logLines {
+		_ = encoder.Encode(logLine)
+		written[logLine.Component] = logLine.Time
+	}
+	if err := w.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(logsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-server.stopping:
+			return
+		case <-keepAlive.C:
+			// Clients skip empty lines, and writing one finds out if the client went away
+			_ = w.WriteByte('\n')
+		case logLine, ok := <-followed:
+			if !ok {
+				return
+			}
+			// Lines logged between subscribing and reading were already written
+			if !logLine.Time.After(written[logLine.Component]) {
+				continue
+			}
+			_ = encoder.Encode(logLine)
+		}
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func parseLogQuery(ctx *fasthttp.RequestCtx) (*loggers.LogQuery, error) {
+	query := &loggers.LogQuery{
+		Pod: string(ctx.QueryArgs().Peek("pod")),
+	}
+
+	if component := string(ctx.QueryArgs().Peek("component")); component != "" {
+		err := loggers.ValidateComponent(component)
+		if err != nil {
+			return nil, err
+		}
+		query.Components = []string{component}
+	}
+
+	since, err := parseTimeArg(ctx, "since")
+	if err != nil {
+		return nil, err
+	}
+	query.Since = since
+
+	if ctx.QueryArgs().Has("tail") {
+		tail, err := ctx.QueryArgs().GetUint("tail")
+		if err != nil {
+			return nil, fmt.Errorf("invalid tail: %w", err)
+		}
+		query.Tail = tail
+	}
+
+	return query, nil
+}
+
+// Returns the quotas limiting the body of a request to an ingestion endpoint, with nil for a pod or dataspace
+// that isn't loaded. Returns false for other endpoints.
+func (server *Server) bodyQuotas(header *fasthttp.RequestHeader) (*quotas.Quotas, bool) {
+	if !header.IsPost() {
+		return nil, false
+	}
+
+	path := strings.SplitN(string(header.RequestURI()), "?", 2)[0]
+	if !strings.HasPrefix(path, apiPodsPath) {
+		return nil, false
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, apiPodsPath), "/")
+	switch {
+	case len(segments) == 2 && (segments[1] == "observations" || segments[1] == "interpretations"):
+		pod := server.pods.GetPod(segments[0])
+		if pod == nil {
+			return nil, true
+		}
+		return pod.Quotas(), true
+	case len(segments) == 5 && segments[1] == "dataspaces" && segments[4] == "data":
+		pod := server.pods.GetPod(segments[0])
+		if pod == nil {
+			return nil, true
+		}
+		for _, dataspace := range pod.Dataspaces() {
+			if dataspace.DataspaceSpec.From == segments[2] && dataspace.DataspaceSpec.Name == segments[3] {
+				return dataspace.Quotas(), true
+			}
+		}
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// Returns the largest body fasthttp reads for a request, before any handler runs. Ingestion endpoints
+// are limited by their pod or dataspace's max_body_bytes, or ingestion.max_body_bytes.
+func (server *Server) maxRequestBodySize(header *fasthttp.RequestHeader) int {
+	if q, ok := server.bodyQuotas(header); ok {
+		limit := q.MaxBodySize(server.Config().Ingestion.MaxBodyBytes)
+		if limit <= 0 {
+			return math.MaxInt32
+		}
+		return limit
+	}
+
+	if header.IsPost() && string(header.RequestURI()) == apiRestorePath {
+		return maxSnapshotBodySize
+	}
+
+	return fasthttp.DefaultMaxRequestBodySize
+}
+
+// Writes the response to a request fasthttp failed to read
+func (server *Server) errorHandler(ctx *fasthttp.RequestCtx, err error) {
+	// Other errors are written like fasthttp's default error handler
+	var smallBufferErr *fasthttp.ErrSmallBuffer
+	var netErr *net.OpError
+	switch {
+	case errors.Is(err, fasthttp.ErrBodyTooLarge):
+	case errors.As(err, &smallBufferErr):
+		ctx.Error("Too big request header", http.StatusRequestHeaderFieldsTooLarge)
+		return
+	case errors.As(err, &netErr) && netErr.Timeout():
+		ctx.Error("Request timeout", http.StatusRequestTimeout)
+		return
+	default:
+		ctx.Error("Error when parsing request", http.StatusBadRequest)
+		return
+	}
+
+	// Counted and reported like the rejections of bodies the handlers read
+	if q, ok := server.bodyQuotas(&ctx.Request.Header); ok && ctx.Request.Header.ContentLength() > 0 {
+		if writeQuotaError(ctx, q.CheckBodySize(ctx.Request.Header.ContentLength(), server.Config().Ingestion.MaxBodyBytes)) {
+			return
+		}
+	}
+
+	ctx.Error(fmt.Sprintf("request body exceeds the limit of %d bytes", server.maxRequestBodySize(&ctx.Request.Header)), http.StatusRequestEntityTooLarge)
+}
+
+// Checks the body size, defaulting to the ingestion limit, then the ingest rate
+func checkIngestQuotas(q *quotas.Quotas, ctx *fasthttp.RequestCtx, ingestionConfig config.IngestionConfiguration) error {
+	if err := q.CheckBodySize(len(ctx.Request.Body()), ingestionConfig.MaxBodyBytes); err != nil {
+		return err
+	}
+	return q.AllowIngest()
+}
+
+// Writes a 413 or 429 response and returns true if err is a quota error
+func writeQuotaError(ctx *fasthttp.RequestCtx, err error) bool {
+	var exceededErr *quotas.ExceededError
+	if !errors.As(err, &exceededErr) {
+		return false
+	}
+
+	if exceededErr.Quota == quotas.BodyBytes {
+		ctx.Response.SetStatusCode(http.StatusRequestEntityTooLarge)
+	} else {
+		ctx.Response.SetStatusCode(http.StatusTooManyRequests)
+	}
+	ctx.Response.SetBodyString(exceededErr.Error())
+	return true
+}
+
+func (server *Server) apiGetAuditHandler(ctx *fasthttp.RequestCtx) {
+	since, err := parseTimeArg(ctx, "since")
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusBadRequest)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	until, err := parseTimeArg(ctx, "until")
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusBadRequest)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	entries, err := server.auditLog.Query(&audit.Query{
+		Since: since,
+		Until: until,
+		Pod:   string(ctx.QueryArgs().Peek("pod")),
+	})
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	response, err := json.Marshal(entries)
+	if err != nil {
+		ctx.Response.SetStatusCode(http.StatusInternalServerError)
+		ctx.Response.SetBodyString(err.Error())
+		return
+	}
+
+	ctx.Response.Header.SetContentType("application/json")
+	ctx.Response.SetBody(response)
+}
+
+// Records every call that can change the runtime's state in the audit log once it is handled
+func (server *Server) auditHandler(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		next(ctx)
+
+		if ctx.IsGet() || ctx.IsHead() || ctx.IsOptions() || !bytes.HasPrefix(ctx.Path(), []byte("/api/")) {
+			return
+		}
+
+		err := server.auditLog.Record(newAuditEntry(ctx))
+		if err != nil {
+			zaplog.Error("error recording API call", zap.ByteString("path", ctx.Path()), zap.Error(err))
+		}
+	}
+}
+
+func newAuditEntry(ctx *fasthttp.RequestCtx) *audit.Entry {
+	entry := &audit.Entry{
+		Source: audit.SourceAPI,
+		Caller: &audit.Caller{
+			Address:   ctx.RemoteIP().String(),
+			UserAgent: string(ctx.UserAgent()),
+			Token:     audit.TokenFingerprint(bearerToken(ctx)),
 		},
+		Action:  string(ctx.Method()),
+		Route:   string(ctx.Path()),
+		Status:  ctx.Response.StatusCode(),
+		Outcome: audit.OutcomeSuccess,
+	}
+
+	// The matched route names which pod and model rather than repeating them
+	if route, ok := ctx.UserValue(router.MatchedRoutePathParam).(string); ok {
+		entry.Route = route
+	}
+	if pod, ok := ctx.UserValue("pod").(string); ok {
+		entry.Pod = pod
+	}
+
+	if entry.Status >= http.StatusBadRequest {
+		entry.Outcome = audit.OutcomeFailure
+		entry.Error = string(ctx.Response.Body())
+		if len(entry.Error) > auditMaxErrorLength {
+			entry.Error = entry.Error[:auditMaxErrorLength]
+		}
+	}
+
+	return entry
+}
+
+func bearerToken(ctx *fasthttp.RequestCtx) string {
+	authorization := string(ctx.Request.Header.Peek("Authorization"))
+	if len(authorization) > len("Bearer ") && strings.EqualFold(authorization[:len("Bearer ")], "Bearer ") {
+		return authorization[len("Bearer "):]
+	}
+	return ""
+}
+
+func (server *Server) corsHandler(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		origin := string(ctx.Request.Header.Peek("Origin"))
+		if origin != "" && isAllowedOrigin(server.Config().CorsOrigins, origin) {
+			ctx.Response.Header.Set("Access-Control-Allow-Origin", origin)
+			ctx.Response.Header.Add("Vary", "Origin")
+
+			if ctx.IsOptions() {
+				ctx.Response.Header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+				ctx.Response.Header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+				ctx.Response.SetStatusCode(http.StatusNoContent)
+				return
+			}
+		}
+
+		next(ctx)
+	}
+}
+
+func isAllowedOrigin(allowedOrigins []string, origin string) bool {
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func NewServer(rtConfig *config.SpiceConfiguration, rtcontext context.RuntimeContext, podRegistry *pods.Registry, aiEngine *aiengine.AIEngine, env *environment.Environment) *Server {
+	server := &Server{
+		config:      rtConfig,
+		rtcontext:   rtcontext,
+		pods:        podRegistry,
+		aiEngine:    aiEngine,
+		environment: env,
+		stopping:    make(chan struct{}),
 	}
+	if rtcontext != nil {
+		server.auditLog = audit.NewLog(audit.LogPath(rtcontext.AppDir()))
+	}
+	return server
+}
+
+func (server *Server) Config() *config.SpiceConfiguration {
+	server.configMutex.RLock()
+	defer server.configMutex.RUnlock()
+
+	return server.config
+}
+
+// Replaces the configuration used by request handlers. The listen address is only read on Start.
+func (server *Server) UpdateConfig(rtConfig *config.SpiceConfiguration) {
+	server.configMutex.Lock()
+	defer server.configMutex.Unlock()
+
+	server.config = rtConfig
 }
 
-func (server *server) Start() error {
+// Returns the handler for every route the server serves
+func (server *Server) Handler() fasthttp.RequestHandler {
 	r := router.New()
-	r.GET("/health", healthHandler)
+	r.SaveMatchedRoutePath = true
+	r.GET("/health", server.healthHandler)
 
 	// Static Dashboard
 	dashboardServer := dashboard.NewDashboardEmbedded()
-	var err error
 
 	api := r.Group("/api/v0.1")
 	{
 		// Pods
-		api.GET("/pods", apiGetPodsHandler)
-		api.GET("/pods/{pod}", apiGetPodHandler)
-		api.POST("/pods/{pod}/train", apiPodTrainHandler)
-		api.GET("/pods/{pod}/observations", apiGetObservationsHandler)
-		api.POST("/pods/{pod}/observations", apiPostObservationsHandler)
-		api.GET("/pods/{pod}/recommendation", apiRecommendationHandler)
-		api.GET("/pods/{pod}/models/{tag}/recommendation", apiRecommendationHandler)
-		api.POST("/pods/{pod}/export", apiPostExportHandler)
-		api.POST("/pods/{pod}/models/{tag}/export", apiPostExportHandler)
-		api.POST("/pods/{pod}/import", apiPostImportHandler)
-		api.POST("/pods/{pod}/models/{tag}/import", apiPostImportHandler)
-		api.POST("/pods/{pod}/dataspaces/{dataspace_from}/{dataspace_name}/data", apiPostDataspaceHandler)
+		api.GET("/pods", server.apiGetPodsHandler)
+		api.GET("/pods/{pod}", server.apiGetPodHandler)
+		api.DELETE("/pods/{pod}", server.apiDeletePodHandler)
+		api.POST("/pods/{pod}/reload", server.apiPodReloadHandler)
+		api.GET("/pods_status", server.apiGetPodsStatusHandler)
+		api.POST("/pods/{pod}/train", server.apiPodTrainHandler)
+		api.GET("/pods/{pod}/observations", server.apiGetObservationsHandler)
+		api.POST("/pods/{pod}/observations", server.apiPostObservationsHandler)
+		api.GET("/pods/{pod}/recommendation", server.apiRecommendationHandler)
+		api.GET("/pods/{pod}/models/{tag}/recommendation", server.apiRecommendationHandler)
+		api.POST("/pods/{pod}/export", server.apiPostExportHandler)
+		api.POST("/pods/{pod}/models/{tag}/export", server.apiPostExportHandler)
+		api.POST("/pods/{pod}/import", server.apiPostImportHandler)
+		api.POST("/pods/{pod}/models/{tag}/import", server.apiPostImportHandler)
+		api.POST("/pods/{pod}/dataspaces/{dataspace_from}/{dataspace_name}/data", server.apiPostDataspaceHandler)
+		api.GET("/pods/{pod}/quotas", server.apiGetQuotasHandler)
 
 		// Flights
-		api.GET("/pods/{pod}/training_runs", apiGetFlightsHandler)
-		api.GET("/pods/{pod}/training_runs/{flight}", apiGetFlightHandler)
-		api.POST("/pods/{pod}/training_runs/{flight}/episodes", apiPostFlightEpisodeHandler)
-		api.POST("/pods/{pod}/training_runs/{flight}/loggers/{loggerId}", apiPostFlightLoggerHandler)
+		api.GET("/pods/{pod}/training_runs", server.apiGetFlightsHandler)
+		api.GET("/pods/{pod}/training_runs/{flight}", server.apiGetFlightHandler)
+		api.POST("/pods/{pod}/training_runs/{flight}/episodes", server.apiPostFlightEpisodeHandler)
+		api.POST("/pods/{pod}/training_runs/{flight}/loggers/{loggerId}", server.apiPostFlightLoggerHandler)
+		api.GET("/training_queue", server.apiGetTrainingQueueHandler)
+
+		// Admin
+		api.POST("/admin/snapshot", server.apiPostSnapshotHandler)
+		api.POST("/admin/restore", server.apiPostRestoreHandler)
+		api.GET("/admin/audit", server.apiGetAuditHandler)
 
 		// Interpretations
-		api.GET("/pods/{pod}/interpretations", apiGetInterpretationsHandler)
-		api.POST("/pods/{pod}/interpretations", apiPostInterpretationsHandler)
+		api.GET("/pods/{pod}/interpretations", server.apiGetInterpretationsHandler)
+		api.POST("/pods/{pod}/interpretations", server.apiPostInterpretationsHandler)
+		api.DELETE("/pods/{pod}/interpretations", server.apiDeleteInterpretationsHandler)
 
 		api.GET("/algorithms", server.apiGetAlgorithmsHandler)
 
 		api.GET("/diagnostics", server.apiGetDiagnosticsHandler)
+		api.GET("/logs", server.apiGetLogsHandler)
+		api.GET("/metrics", server.apiGetMetricsHandler)
 	}
 
 	static := r.Group("/static")
@@ -650,18 +1489,49 @@ func (server *server) Start() error {
 	})
 	r.GET("/", dashboardServer.IndexHandler)
 
+	return server.corsHandler(server.auditHandler(r.Handler))
+}
+
+// Returns the fasthttp server for Handler. Body limits are looked up as each request's headers are read,
+// so changes to ingestion.max_body_bytes and body quotas apply without restarting it.
+func (server *Server) newFastServer() *fasthttp.Server {
+	return &fasthttp.Server{
+		Handler: server.Handler(),
+		HeaderReceived: func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+			// Always set, as fasthttp keeps the previous request's limit for the rest of the connection
+			return fasthttp.RequestConfig{MaxRequestBodySize: server.maxRequestBodySize(header)}
+		},
+		ErrorHandler: server.errorHandler,
+	}
+}
+
+func (server *Server) Start() error {
 	serverLogger, err := zap.NewStdLogAt(zaplog, zap.DebugLevel)
 	if err != nil {
 		return fmt.Errorf("failed to initialize logger: %w", err)
 	}
-	fastServer := &fasthttp.Server{
-		Handler: r.Handler,
-		Logger:  serverLogger,
-	}
+	server.fastServer = server.newFastServer()
+	server.fastServer.Logger = serverLogger
 
+	listenAddress := server.Config().ListenAddress()
 	go func() {
-		log.Fatal(fastServer.ListenAndServe(fmt.Sprintf(":%d", server.config.Port)))
+		err := server.fastServer.ListenAndServe(listenAddress)
+		if err != nil {
+			zaplog.Fatal("error serving HTTP", zap.String("address", listenAddress), zap.Error(err))
+		}
 	}()
 
 	return nil
 }
+
+func (server *Server) Shutdown() error {
+	if server.fastServer == nil {
+		return nil
+	}
+
+	server.stoppingOnce.Do(func() {
+		close(server.stopping)
+	})
+
+	return server.fastServer.Shutdown()
+}
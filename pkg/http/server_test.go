@@ -1,14 +1,34 @@
 package http
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/spiceai/spiceai/pkg/aiengine"
 	"github.com/spiceai/spiceai/pkg/api"
+	"github.com/spiceai/spiceai/pkg/audit"
+	"github.com/spiceai/spiceai/pkg/config"
+	"github.com/spiceai/spiceai/pkg/context"
+	"github.com/spiceai/spiceai/pkg/diagnostics"
 	"github.com/spiceai/spiceai/pkg/interpretations"
+	"github.com/spiceai/spiceai/pkg/loggers"
 	"github.com/spiceai/spiceai/pkg/pods"
+	"github.com/spiceai/spiceai/pkg/proto/common_pb"
+	"github.com/spiceai/spiceai/pkg/quotas"
+	"github.com/spiceai/spiceai/pkg/spec"
 	"github.com/stretchr/testify/assert"
 	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"go.uber.org/zap"
 )
 
 func TestServer(t *testing.T) {
@@ -20,11 +40,792 @@ func TestServer(t *testing.T) {
 		return
 	}
 
-	t.Run("getInterpretations()", testGetInterpretationsHandlerFunc(pod))
-	t.Run("postInterpretations()", testPostInterpretationsHandlerFunc(pod))
+	server := NewServer(config.LoadDefaultConfiguration(), nil, pods.NewRegistry(), nil, nil)
+
+	t.Run("getInterpretations()", testGetInterpretationsHandlerFunc(server, pod))
+	t.Run("postInterpretations()", testPostInterpretationsHandlerFunc(server, pod))
+	t.Run("postObservations() - Body over ingestion limit is rejected", testPostObservationsBodyLimitFunc(pod))
+	t.Run("postObservations() - Body over pod quota is rejected", testPostObservationsBodyQuotaFunc())
+	t.Run("newFastServer() - Bodies over their limit are rejected before they are read", testRequestBodyLimitFunc())
+	t.Run("postObservations() - Cached observations over pod quota are rejected", testPostObservationsCachedQuotaFunc())
+	t.Run("postDataspace() - Requests over ingest rate quota are rejected", testPostDataspaceRateQuotaFunc())
+	t.Run("postInterpretations() - Interpretations over quota are rejected", testPostInterpretationsQuotaFunc())
+	t.Run("podTrain() - Trainings over quota are rejected", testPodTrainQuotaFunc())
+	t.Run("podTrain() - Trainings over the runtime limit are queued", testPodTrainQueuedFunc())
+	t.Run("getMetrics() - Quota rejections are reported", testGetMetricsFunc())
+	t.Run("getObservations() - Observations are filtered by start and end", testGetObservationsBetweenFunc())
+	t.Run("getInterpretations() - Interpretations are filtered by start and end", testGetInterpretationsBetweenFunc())
+	t.Run("deleteInterpretations() - Matching interpretations are removed and reported as changed", testDeleteInterpretationsFunc())
+	t.Run("podReload() - Pods are reloaded from their manifest", testPodReloadFunc())
+	t.Run("deletePod() - Pods are unloaded", testDeletePodFunc())
+	t.Run("getPodsStatus() - Lifecycle, staleness and engine sync are reported", testGetPodsStatusFunc())
+	t.Run("corsHandler() - Only configured origins are allowed", testCorsHandlerFunc())
+	t.Run("getDiagnostics() - The JSON report includes runtime status", testGetDiagnosticsJsonFunc())
+	t.Run("getLogs() - Lines are filtered by component and pod", testGetLogsFunc())
+	t.Run("streamLogs() - Followed lines already written are skipped", testStreamLogsFunc())
+	t.Run("auditHandler() - Mutating calls are recorded and queryable", testAuditHandlerFunc())
+}
+
+func testPostObservationsBodyLimitFunc(pod *pods.Pod) func(t *testing.T) {
+	return func(t *testing.T) {
+		rtConfig := config.LoadDefaultConfiguration()
+		rtConfig.Ingestion.MaxBodyBytes = 10
+
+		podRegistry := pods.NewRegistry()
+		podRegistry.CreateOrUpdatePod(pod)
+		server := NewServer(rtConfig, nil, podRegistry, nil, nil)
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.Request.SetBodyString("time,coinbase.btcusd.close\n1605312000,16339.56\n")
+
+		server.apiPostObservationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusRequestEntityTooLarge, ctx.Response.StatusCode())
+		assert.Empty(t, pod.CachedState())
+	}
+}
+
+// Returns a server with a newly loaded trader-quotas pod
+func newQuotasTestServer(t *testing.T) (*Server, *pods.Pod) {
+	pod, err := pods.LoadPodFromManifest("../../test/assets/pods/manifests/trader-quotas.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	podRegistry := pods.NewRegistry()
+	podRegistry.CreateOrUpdatePod(pod)
+	server := NewServer(config.LoadDefaultConfiguration(), nil, podRegistry, aiengine.NewAIEngine(nil), nil)
+
+	return server, pod
+}
+
+func testPostObservationsBodyQuotaFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		defaultParams := pods.NewPodParams()
+		defaultParams.Quotas = spec.QuotasSpec{MaxBodyBytes: 10}
+		pod, err := pods.LoadPodFromManifestWithDefaults("../../test/assets/pods/manifests/trader.yaml", defaultParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The pod's quota takes precedence over the larger ingestion limit
+		podRegistry := pods.NewRegistry()
+		podRegistry.CreateOrUpdatePod(pod)
+		server := NewServer(config.LoadDefaultConfiguration(), nil, podRegistry, nil, nil)
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.Request.SetBodyString("time,coinbase.btcusd.close\n1605312000,16339.56\n")
+
+		server.apiPostObservationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusRequestEntityTooLarge, ctx.Response.StatusCode())
+		assert.Equal(t, "quota max_body_bytes exceeded: request body of 47 bytes exceeds the limit of 10 bytes", string(ctx.Response.Body()))
+		assert.Equal(t, uint64(1), pod.Quotas().Rejections()[quotas.BodyBytes])
+	}
+}
+
+func testRequestBodyLimitFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		defaultParams := pods.NewPodParams()
+		defaultParams.Quotas = spec.QuotasSpec{MaxBodyBytes: 10}
+		pod, err := pods.LoadPodFromManifestWithDefaults("../../test/assets/pods/manifests/trader.yaml", defaultParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unlimitedPod, err := pods.LoadPodFromManifest("../../test/assets/pods/manifests/trader-quotas.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		podRegistry := pods.NewRegistry()
+		podRegistry.CreateOrUpdatePod(pod)
+		podRegistry.CreateOrUpdatePod(unlimitedPod)
+		server := NewServer(config.LoadDefaultConfiguration(), nil, podRegistry, aiengine.NewAIEngine(nil), nil)
+
+		ln := fasthttputil.NewInmemoryListener()
+		fastServer := server.newFastServer()
+		go func() {
+			_ = fastServer.Serve(ln)
+		}()
+		defer func() {
+			_ = fastServer.Shutdown()
+		}()
+
+		client := &fasthttp.Client{
+			Dial: func(addr string) (net.Conn, error) {
+				return ln.Dial()
+			},
+		}
+		post := func(path string, body string) (int, string) {
+			req := fasthttp.AcquireRequest()
+			defer fasthttp.ReleaseRequest(req)
+			resp := fasthttp.AcquireResponse()
+			defer fasthttp.ReleaseResponse(resp)
+
+			req.SetRequestURI("http://spiced" + path)
+			req.Header.SetMethod(fasthttp.MethodPost)
+			req.SetBodyString(body)
+			if err := client.Do(req, resp); err != nil {
+				t.Fatal(err)
+			}
+			return resp.StatusCode(), string(resp.Body())
+		}
+
+		status, body := post("/api/v0.1/pods/trader/observations", "time,coinbase.btcusd.close\n1605312000,16339.56\n")
+		assert.Equal(t, fasthttp.StatusRequestEntityTooLarge, status)
+		assert.Equal(t, "quota max_body_bytes exceeded: request body of 47 bytes exceeds the limit of 10 bytes", body)
+		assert.Equal(t, uint64(1), pod.Quotas().Rejections()[quotas.BodyBytes])
+
+		status, _ = post("/api/v0.1/pods/trader/interpretations", "[]")
+		assert.Equal(t, fasthttp.StatusCreated, status)
+
+		// Pods without a quota are limited by ingestion.max_body_bytes, which is read for every request
+		status, _ = post("/api/v0.1/pods/trader-quotas/dataspaces/coinbase/btcusd/data", "time,close\n1605312000,1\n")
+		assert.Equal(t, fasthttp.StatusCreated, status)
+
+		rtConfig := config.LoadDefaultConfiguration()
+		rtConfig.Ingestion.MaxBodyBytes = 10
+		server.UpdateConfig(rtConfig)
+
+		status, body = post("/api/v0.1/pods/trader-quotas/dataspaces/coinbase/btcusd/data", "time,close\n1605312001,2\n")
+		assert.Equal(t, fasthttp.StatusRequestEntityTooLarge, status)
+		assert.Contains(t, body, "exceeds the limit of 10 bytes")
+
+		// Other endpoints are limited to fasthttp's default
+		status, body = post("/api/v0.1/pods/trader/reload", strings.Repeat("x", fasthttp.DefaultMaxRequestBodySize+1))
+		assert.Equal(t, fasthttp.StatusRequestEntityTooLarge, status)
+		assert.Equal(t, fmt.Sprintf("request body exceeds the limit of %d bytes", fasthttp.DefaultMaxRequestBodySize), body)
+	}
+}
+
+func testPostObservationsCachedQuotaFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newQuotasTestServer(t)
+
+		post := func(body string) int {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.SetUserValue("pod", pod.Name)
+			ctx.Request.SetBodyString(body)
+			server.apiPostObservationsHandler(ctx)
+			return ctx.Response.StatusCode()
+		}
+
+		assert.Equal(t, fasthttp.StatusCreated, post("time,coinbase.btcusd.close\n1605312000,1\n1605312001,2\n1605312002,3\n"))
+		assert.Equal(t, fasthttp.StatusTooManyRequests, post("time,coinbase.btcusd.close\n1605312003,4\n1605312004,5\n1605312005,6\n"))
+		assert.Equal(t, 3, pod.CachedObservations())
+	}
+}
+
+func testPostDataspaceRateQuotaFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newQuotasTestServer(t)
+
+		post := func(time int) int {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.SetUserValue("pod", pod.Name)
+			ctx.SetUserValue("dataspace_from", "coinbase")
+			ctx.SetUserValue("dataspace_name", "btcusd")
+			ctx.Request.SetBodyString(fmt.Sprintf("time,close\n%d,1\n", time))
+			server.apiPostDataspaceHandler(ctx)
+			return ctx.Response.StatusCode()
+		}
+
+		// max_ingest_rate of 2 allows a burst of two requests
+		assert.Equal(t, fasthttp.StatusCreated, post(1605312000))
+		assert.Equal(t, fasthttp.StatusCreated, post(1605312001))
+		assert.Equal(t, fasthttp.StatusTooManyRequests, post(1605312002))
+
+		ds := pod.Dataspaces()[0]
+		assert.Equal(t, 2, ds.CachedObservations())
+		assert.Equal(t, uint64(1), ds.Quotas().Rejections()[quotas.IngestRate])
+	}
+}
+
+func testPostInterpretationsQuotaFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newQuotasTestServer(t)
+
+		post := func() int {
+			interpretation, err := interpretations.NewInterpretation(pod.Epoch(), pod.Epoch().Add(pod.Interval()), "test interpretation")
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := json.Marshal([]*api.Interpretation{api.NewApiInterpretation(interpretation)})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.SetUserValue("pod", pod.Name)
+			ctx.Request.SetBody(data)
+			server.apiPostInterpretationsHandler(ctx)
+			return ctx.Response.StatusCode()
+		}
+
+		assert.Equal(t, fasthttp.StatusCreated, post())
+		assert.Equal(t, fasthttp.StatusTooManyRequests, post())
+		assert.Equal(t, 1, len(pod.Interpretations().All()))
+	}
+}
+
+func testPodTrainQuotaFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newQuotasTestServer(t)
+
+		// Hold the only training slot, as a training that is starting would
+		assert.NoError(t, pod.ReserveTraining())
+		defer pod.ReleaseTraining()
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.Request.SetBodyString("{}")
+		server.apiPodTrainHandler(ctx)
+
+		assert.Equal(t, fasthttp.StatusTooManyRequests, ctx.Response.StatusCode())
+		assert.Equal(t, "quota max_concurrent_trainings exceeded: 1 trainings already in progress", string(ctx.Response.Body()))
+	}
+}
+
+func testPodTrainQueuedFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newQuotasTestServer(t)
+
+		trainingScheduler := server.aiEngine.Scheduler()
+		trainingScheduler.SetMaxConcurrent(1)
+		running := trainingScheduler.Request("other", 0)
+		running.SetFlight("1")
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.Request.SetBodyString("{}")
+		server.apiPodTrainHandler(ctx)
+
+		assert.Equal(t, fasthttp.StatusAccepted, ctx.Response.StatusCode())
+		assert.Equal(t, "queued", string(ctx.Response.Body()))
+
+		ctx = &fasthttp.RequestCtx{}
+		server.apiGetTrainingQueueHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+		var queue api.TrainingQueue
+		assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &queue))
+		assert.Equal(t, 1, queue.MaxConcurrent)
+		if assert.Len(t, queue.Active, 1) {
+			assert.Equal(t, "other", queue.Active[0].Pod)
+			assert.Equal(t, "1", queue.Active[0].Flight)
+		}
+		if assert.Len(t, queue.Queued, 1) {
+			assert.Equal(t, pod.Name, queue.Queued[0].Pod)
+		}
+
+		// The queued training starts once the slot is free, and fails without learning algorithms
+		running.Release()
+		assert.Eventually(t, func() bool {
+			status := trainingScheduler.Status()
+			return len(status.Active) == 0 && len(status.Queued) == 0 && pod.ActiveTrainings() == 0
+		}, time.Second, 10*time.Millisecond)
+	}
+}
+
+func testGetObservationsBetweenFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newPodsTestServer(t)
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.Request.SetBodyString("time,coinbase.btcusd.close\n1605312000,1\n1605312060,2\n1605312120,3\n")
+		server.apiPostObservationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusCreated, ctx.Response.StatusCode())
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.Request.Header.Set("Accept", "application/json")
+		ctx.QueryArgs().Set("start", "1605312060")
+		ctx.QueryArgs().Set("end", "2020-11-14T00:02:00Z")
+		server.apiGetObservationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+		var observations []*common_pb.Observation
+		assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &observations))
+		if assert.Len(t, observations, 1) {
+			assert.Equal(t, int64(1605312060), observations[0].Time)
+			assert.Equal(t, 2.0, observations[0].Measurements["coinbase.btcusd.close"])
+		}
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.QueryArgs().Set("start", "1605312060")
+		server.apiGetObservationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.NotContains(t, string(ctx.Response.Body()), "1605312000")
+		assert.Contains(t, string(ctx.Response.Body()), "1605312120")
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.QueryArgs().Set("end", "yesterday")
+		server.apiGetObservationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+	}
+}
+
+func testGetInterpretationsBetweenFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newPodsTestServer(t)
+		addTestInterpretations(t, pod)
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.QueryArgs().Set("start", strconv.FormatInt(pod.Epoch().Add(3*pod.Interval()).Unix(), 10))
+		server.apiGetInterpretationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.Equal(t, "application/json", string(ctx.Response.Header.ContentType()))
+
+		var apiInterpretations []*api.Interpretation
+		assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &apiInterpretations))
+		if assert.Len(t, apiInterpretations, 1) {
+			assert.Equal(t, "later", apiInterpretations[0].Name)
+		}
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.QueryArgs().Set("end", pod.Epoch().Add(pod.Period()).Add(time.Second).Format(time.RFC3339))
+		server.apiGetInterpretationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+		assert.Contains(t, string(ctx.Response.Body()), "cannot be after pod period")
+	}
+}
+
+func testDeleteInterpretationsFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newPodsTestServer(t)
+		addTestInterpretations(t, pod)
+
+		changes := 0
+		unwatch := server.pods.Watch(func(event pods.PodEvent) {
+			if event.Type == pods.PodInterpretationsChanged && event.Pod == pod {
+				changes++
+			}
+		})
+		defer unwatch()
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.QueryArgs().Set("tag", "noise")
+		server.apiDeleteInterpretationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.Equal(t, "[]", string(ctx.Response.Body()))
+		assert.Equal(t, 0, changes)
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.QueryArgs().Set("name", "earlier")
+		ctx.QueryArgs().Set("tag", "news")
+		server.apiDeleteInterpretationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+		var removed []*api.Interpretation
+		assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &removed))
+		if assert.Len(t, removed, 1) {
+			assert.Equal(t, "earlier", removed[0].Name)
+		}
+
+		remaining := pod.Interpretations().All()
+		if assert.Len(t, remaining, 1) {
+			assert.Equal(t, "later", remaining[0].Name())
+		}
+		assert.Equal(t, 1, changes)
+
+		// Posted interpretations are also reported, so they can be persisted
+		ctx = &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		ctx.Request.SetBodyString(fmt.Sprintf(`[{"start": %d, "end": %d, "name": "posted"}]`, pod.Epoch().Unix(), pod.Epoch().Add(pod.Interval()).Unix()))
+		server.apiPostInterpretationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusCreated, ctx.Response.StatusCode())
+		assert.Equal(t, 2, changes)
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", "unknown")
+		server.apiDeleteInterpretationsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+	}
+}
+
+func addTestInterpretations(t *testing.T, pod *pods.Pod) {
+	earlier, err := interpretations.NewInterpretation(pod.Epoch(), pod.Epoch().Add(pod.Interval()), "earlier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	earlier.AddTags("news")
+
+	later, err := interpretations.NewInterpretation(pod.Epoch().Add(4*pod.Interval()), pod.Epoch().Add(5*pod.Interval()), "later")
+	if err != nil {
+		t.Fatal(err)
+	}
+	later.AddTags("news")
+
+	for _, i := range []*interpretations.Interpretation{earlier, later} {
+		if err := pod.Interpretations().Add(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Returns a server with the trader pod loaded from a copy of its manifest
+func newPodsTestServer(t *testing.T) (*Server, *pods.Pod) {
+	manifest, err := os.ReadFile("../../test/assets/pods/manifests/trader.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(t.TempDir(), "trader.yaml")
+	err = os.WriteFile(manifestPath, manifest, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	podRegistry := pods.NewRegistry()
+	pod, err := podRegistry.LoadPodFromManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	podRegistry.CreateOrUpdatePod(pod)
+
+	return NewServer(config.LoadDefaultConfiguration(), nil, podRegistry, aiengine.NewAIEngine(nil), nil), pod
+}
+
+func testPodReloadFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newPodsTestServer(t)
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		server.apiPodReloadHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.Same(t, pod, server.pods.GetPod(pod.Name))
+
+		manifest, err := os.ReadFile(pod.ManifestPath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest = []byte(strings.Replace(string(manifest), "granularity: 17s", "granularity: 1m", 1))
+		err = os.WriteFile(pod.ManifestPath(), manifest, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		server.apiPodReloadHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+		reloaded := server.pods.GetPod(pod.Name)
+		assert.NotSame(t, pod, reloaded)
+		assert.Equal(t, time.Minute, reloaded.Granularity())
+
+		var details api.PodDetails
+		assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &details))
+		assert.Equal(t, pod.Name, details.Name)
+		assert.Equal(t, "1m0s", details.Params.Granularity)
+		assert.Equal(t, pods.LifecycleLoaded, details.Lifecycle)
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", "missing")
+		server.apiPodReloadHandler(ctx)
+		assert.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+	}
+}
+
+func testDeletePodFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newPodsTestServer(t)
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		server.apiDeletePodHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.Nil(t, server.pods.GetPod(pod.Name))
+		assert.FileExists(t, pod.ManifestPath())
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.SetUserValue("pod", pod.Name)
+		server.apiDeletePodHandler(ctx)
+		assert.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+	}
+}
+
+func testAuditHandlerFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newPodsTestServer(t)
+		server.auditLog = audit.NewLog(filepath.Join(t.TempDir(), audit.FileName))
+		handler := server.Handler()
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod("DELETE")
+		ctx.Request.SetRequestURI("/api/v0.1/pods/" + pod.Name)
+		ctx.Request.Header.Set("Authorization", "Bearer secret")
+		ctx.Request.Header.SetUserAgent("spice-test")
+		handler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.Request.Header.SetMethod("POST")
+		ctx.Request.SetRequestURI("/api/v0.1/pods/missing/train")
+		handler(ctx)
+		assert.Equal(t, fasthttp.StatusNotFound, ctx.Response.StatusCode())
+
+		// Reads aren't recorded
+		ctx = &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/v0.1/pods")
+		handler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/v0.1/admin/audit")
+		handler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+		var entries []*audit.Entry
+		assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &entries))
+		if !assert.Len(t, entries, 2) {
+			return
+		}
+
+		assert.Equal(t, audit.SourceAPI, entries[0].Source)
+		assert.Equal(t, "DELETE", entries[0].Action)
+		assert.Equal(t, "/api/v0.1/pods/{pod}", entries[0].Route)
+		assert.Equal(t, pod.Name, entries[0].Pod)
+		assert.Equal(t, audit.OutcomeSuccess, entries[0].Outcome)
+		assert.Equal(t, "spice-test", entries[0].Caller.UserAgent)
+		assert.Equal(t, audit.TokenFingerprint("secret"), entries[0].Caller.Token)
+		assert.NotContains(t, string(ctx.Response.Body()), "secret")
+
+		assert.Equal(t, "/api/v0.1/pods/{pod}/train", entries[1].Route)
+		assert.Equal(t, "missing", entries[1].Pod)
+		assert.Equal(t, fasthttp.StatusNotFound, entries[1].Status)
+		assert.Equal(t, audit.OutcomeFailure, entries[1].Outcome)
+		assert.Empty(t, entries[1].Caller.Token)
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/v0.1/admin/audit?pod=missing&since=" + strconv.FormatInt(entries[0].Time.Unix(), 10))
+		handler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+		entries = nil
+		assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &entries))
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, "/api/v0.1/pods/{pod}/train", entries[0].Route)
+		}
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/v0.1/admin/audit?until=invalid")
+		handler(ctx)
+		assert.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+	}
+}
+
+func testGetPodsStatusFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newPodsTestServer(t)
+
+		ctx := &fasthttp.RequestCtx{}
+		server.apiGetPodsStatusHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+
+		var status []*api.PodStatus
+		assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &status))
+		assert.Equal(t, []*api.PodStatus{{
+			Name:       pod.Name,
+			Lifecycle:  pods.LifecycleLoaded,
+			Stale:      true,
+			EngineSync: aiengine.PodNotInitialized,
+		}}, status)
+	}
+}
+
+// A context with an empty app directory, as diagnostics only reads directories
+type diagnosticsTestContext struct {
+	context.RuntimeContext
+	dir string
+}
+
+func (c *diagnosticsTestContext) Name() string {
+	return "test"
+}
+
+func (c *diagnosticsTestContext) AppDir() string {
+	return c.dir
+}
+
+func (c *diagnosticsTestContext) PodsDir() string {
+	return filepath.Join(c.dir, "spicepods")
+}
+
+func (c *diagnosticsTestContext) SpiceRuntimeDir() string {
+	return filepath.Join(c.dir, ".spice")
+}
+
+func (c *diagnosticsTestContext) Version() (string, error) {
+	return "local", nil
+}
+
+func testGetDiagnosticsJsonFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newPodsTestServer(t)
+		server.rtcontext = &diagnosticsTestContext{dir: t.TempDir()}
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/v0.1/diagnostics?format=json")
+		server.apiGetDiagnosticsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.Equal(t, "application/json", string(ctx.Response.Header.ContentType()))
+
+		var report diagnostics.Report
+		assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &report))
+		assert.Equal(t, "test", report.Context.Name)
+		assert.Equal(t, &diagnostics.Versions{Runtime: "local", Context: "local"}, report.Versions)
+		assert.Empty(t, report.Errors)
+		assert.Len(t, report.Pods, 1)
+		assert.Equal(t, pod.Name, report.Pods[0].Name)
+		assert.Equal(t, map[string][]*api.Flight{pod.Name: {}}, report.Flights)
+		assert.Contains(t, report.Metrics, pod.Name)
+		assert.Len(t, report.Logs, 1)
+		assert.Equal(t, "runtime.log", report.Logs[0].Path)
+	}
+}
+
+func testGetLogsFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server := NewServer(config.LoadDefaultConfiguration(), nil, pods.NewRegistry(), nil, nil)
+
+		loggers.ZapLogger().Info("pod loaded", zap.String("pod", "logs-test-pod"))
+		loggers.WriteAIEngineLine("logs-test-pod -> training started")
+		loggers.WriteAIEngineLine("logs-other-pod -> training started")
+
+		getLogs := func(queryString string) []*loggers.LogLine {
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Request.SetRequestURI("/api/v0.1/logs?" + queryString)
+			server.apiGetLogsHandler(ctx)
+			assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+			assert.Equal(t, "application/x-ndjson", string(ctx.Response.Header.ContentType()))
+
+			var logLines []*loggers.LogLine
+			decoder := json.NewDecoder(bytes.NewReader(ctx.Response.Body()))
+			for decoder.More() {
+				var logLine loggers.LogLine
+				assert.NoError(t, decoder.Decode(&logLine))
+				logLines = append(logLines, &logLine)
+			}
+			return logLines
+		}
+
+		logLines := getLogs("pod=logs-test-pod")
+		if assert.Len(t, logLines, 2) {
+			assert.Equal(t, loggers.ComponentRuntime, logLines[0].Component)
+			assert.Contains(t, logLines[0].Line, `"msg":"pod loaded"`)
+			assert.Equal(t, &loggers.LogLine{
+				Time:      logLines[1].Time,
+				Component: loggers.ComponentAIEngine,
+				Pod:       "logs-test-pod",
+				Line:      "logs-test-pod -> training started",
+			}, logLines[1])
+		}
+
+		logLines = getLogs("component=aiengine&tail=1")
+		if assert.Len(t, logLines, 1) {
+			assert.Equal(t, "logs-other-pod", logLines[0].Pod)
+		}
+
+		assert.Empty(t, getLogs(fmt.Sprintf("pod=logs-test-pod&since=%d", time.Now().Add(time.Hour).Unix())))
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI("/api/v0.1/logs?component=dashboard")
+		server.apiGetLogsHandler(ctx)
+		assert.Equal(t, fasthttp.StatusBadRequest, ctx.Response.StatusCode())
+		assert.Equal(t, "invalid component 'dashboard': must be one of runtime or aiengine", string(ctx.Response.Body()))
+	}
+}
+
+func testStreamLogsFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server := NewServer(config.LoadDefaultConfiguration(), nil, pods.NewRegistry(), nil, nil)
+
+		start := time.Now()
+		written := &loggers.LogLine{Time: start, Component: loggers.ComponentAIEngine, Line: "written"}
+		followed := make(chan *loggers.LogLine, 3)
+		followed <- written
+		followed <- &loggers.LogLine{Time: start, Component: loggers.ComponentRuntime, Line: "same time, other component"}
+		followed <- &loggers.LogLine{Time: start.Add(time.Millisecond), Component: loggers.ComponentAIEngine, Line: "new"}
+		close(followed)
+
+		stopped := false
+		var body bytes.Buffer
+		w := bufio.NewWriter(&body)
+		server.streamLogs(w, []*loggers.LogLine{written}, followed, func() {
+			stopped = true
+		})
+		assert.True(t, stopped)
+
+		var lines []string
+		decoder := json.NewDecoder(&body)
+		for decoder.More() {
+			var logLine loggers.LogLine
+			assert.NoError(t, decoder.Decode(&logLine))
+			lines = append(lines, logLine.Line)
+		}
+		assert.Equal(t, []string{"written", "same time, other component", "new"}, lines)
+	}
+}
+
+func testGetMetricsFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		server, pod := newQuotasTestServer(t)
+
+		assert.NoError(t, pod.ReserveTraining())
+		assert.Error(t, server.aiEngine.StartTraining(pod, nil))
+		pod.ReleaseTraining()
+
+		ctx := &fasthttp.RequestCtx{}
+		server.apiGetMetricsHandler(ctx)
+
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.Equal(t, `# TYPE spice_quota_cached_observations gauge
+spice_quota_cached_observations{pod="trader-quotas"} 0
+spice_quota_cached_observations{pod="trader-quotas",dataspace="coinbase.btcusd"} 0
+spice_quota_cached_observations{pod="trader-quotas",dataspace="coinbase.ethusd"} 0
+# TYPE spice_quota_rejections_total counter
+spice_quota_rejections_total{pod="trader-quotas",quota="max_concurrent_trainings"} 1
+`, string(ctx.Response.Body()))
+	}
+}
+
+func testCorsHandlerFunc() func(t *testing.T) {
+	return func(t *testing.T) {
+		rtConfig := config.LoadDefaultConfiguration()
+		rtConfig.CorsOrigins = []string{"http://localhost:3000"}
+		server := NewServer(rtConfig, nil, pods.NewRegistry(), nil, nil)
+
+		handler := server.corsHandler(func(ctx *fasthttp.RequestCtx) {
+			ctx.SetStatusCode(fasthttp.StatusOK)
+		})
+
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.Header.Set("Origin", "http://localhost:3000")
+		handler(ctx)
+		assert.Equal(t, "http://localhost:3000", string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+
+		ctx = &fasthttp.RequestCtx{}
+		ctx.Request.Header.Set("Origin", "http://example.com")
+		handler(ctx)
+		assert.Empty(t, ctx.Response.Header.Peek("Access-Control-Allow-Origin"))
+
+		server.UpdateConfig(&config.SpiceConfiguration{CorsOrigins: []string{"*"}})
+		ctx = &fasthttp.RequestCtx{}
+		ctx.Request.Header.Set("Origin", "http://example.com")
+		handler(ctx)
+		assert.Equal(t, "http://example.com", string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+	}
 }
 
-func testGetInterpretationsHandlerFunc(pod *pods.Pod) func(t *testing.T) {
+func testGetInterpretationsHandlerFunc(server *Server, pod *pods.Pod) func(t *testing.T) {
 	return func(t *testing.T) {
 		interpretation, err := interpretations.NewInterpretation(
 			pod.Epoch(),
@@ -45,7 +846,7 @@ func testGetInterpretationsHandlerFunc(pod *pods.Pod) func(t *testing.T) {
 		}
 		ctx.SetUserValue("pod", "trader")
 
-		apiGetInterpretationsHandler(ctx)
+		server.apiGetInterpretationsHandler(ctx)
 
 		interpretations := pod.Interpretations().All()
 		assert.Equal(t, 1, len(interpretations))
@@ -53,7 +854,7 @@ func testGetInterpretationsHandlerFunc(pod *pods.Pod) func(t *testing.T) {
 	}
 }
 
-func testPostInterpretationsHandlerFunc(pod *pods.Pod) func(t *testing.T) {
+func testPostInterpretationsHandlerFunc(server *Server, pod *pods.Pod) func(t *testing.T) {
 	return func(t *testing.T) {
 		interpretation, err := interpretations.NewInterpretation(
 			pod.Epoch(),
@@ -78,7 +879,7 @@ func testPostInterpretationsHandlerFunc(pod *pods.Pod) func(t *testing.T) {
 
 		ctx.Request.SetBody(data)
 
-		apiPostInterpretationsHandler(ctx)
+		server.apiPostInterpretationsHandler(ctx)
 
 		interpretations := pod.Interpretations().All()
 		assert.Equal(t, 1, len(interpretations))
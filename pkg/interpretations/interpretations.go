@@ -90,6 +90,38 @@ func (store *InterpretationsStore) Add(interpretation *Interpretation) error {
 	return nil
 }
 
+// Removes the interpretations match returns true for and returns them.
+// The time index is rebuilt, so indices handed out before the removal are no longer valid.
+func (store *InterpretationsStore) Remove(match func(i *Interpretation) bool) []Interpretation {
+	store.interpretationsMutex.Lock()
+	defer store.interpretationsMutex.Unlock()
+
+	var removed []Interpretation
+	var kept []Interpretation
+	for i := range store.interpretations {
+		if match(&store.interpretations[i]) {
+			removed = append(removed, store.interpretations[i])
+		} else {
+			kept = append(kept, store.interpretations[i])
+		}
+	}
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	// Replaced rather than modified in place, as All() and IndexedInterpretations() share them with callers
+	store.interpretations = kept
+	store.timeIndex = &common_pb.IndexedInterpretations{
+		Index: make(map[int64]*common_pb.InterpretationIndices),
+	}
+	for i := range kept {
+		store.addToTimeIndex(&kept[i])
+	}
+
+	return removed
+}
+
 func (store *InterpretationsStore) addToTimeIndex(interpretation *Interpretation) {
 	timeIndex := store.timeIndex
 	pbInterpretation := newPbInterpretation(interpretation)
@@ -16,6 +16,7 @@ func TestInterpretations(t *testing.T) {
 	t.Run("Intervals()", testIntervalsFunc())
 	t.Run("Get()", testGetInterpretationsFunc())
 	t.Run("TimeIndex()", testTimeIndexFunc())
+	t.Run("Remove()", testRemoveFunc())
 }
 
 // Tests Intervals()
@@ -163,3 +164,49 @@ func testTimeIndexFunc() func(*testing.T) {
 		snapshotter.SnapshotT(t, string(timeIndexJson))
 	}
 }
+
+// Tests Remove()
+func testRemoveFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		epoch := time.Unix(1631590387, 0)
+		period := 1000 * time.Second
+		granularity := time.Second
+
+		store := interpretations.NewInterpretationsStore(epoch, period, granularity)
+
+		for _, name := range []string{"keep", "remove", "keep too"} {
+			i, err := interpretations.NewInterpretation(epoch.Add(100*time.Second), epoch.Add(102*time.Second), name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Add(i); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		previousIndex := store.IndexedInterpretations()
+
+		removed := store.Remove(func(i *interpretations.Interpretation) bool {
+			return i.Name() == "remove"
+		})
+		if assert.Len(t, removed, 1) {
+			assert.Equal(t, "remove", removed[0].Name())
+		}
+
+		all := store.All()
+		if assert.Len(t, all, 2) {
+			assert.Equal(t, "keep", all[0].Name())
+			assert.Equal(t, "keep too", all[1].Name())
+		}
+
+		timeIndex := store.IndexedInterpretations()
+		assert.Len(t, timeIndex.Interpretations, 2)
+		assert.Equal(t, []uint32{0, 1}, timeIndex.Index[epoch.Add(101*time.Second).Unix()].Indicies)
+
+		// The index handed out before the removal is left untouched
+		assert.Len(t, previousIndex.Interpretations, 3)
+
+		assert.Nil(t, store.Remove(func(i *interpretations.Interpretation) bool { return false }))
+		assert.Len(t, store.All(), 2)
+	}
+}
@@ -1,9 +1,12 @@
 package loggers
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,31 +16,87 @@ import (
 	lumberjack "gopkg.in/natefinch/lumberjack.v2"
 )
 
+// How file loggers rotate their logs
+type FileRotation struct {
+	// Size in megabytes a log grows to before it is rotated
+	MaxSizeMB int
+	// Days rotated logs, and the logs of previous runs, are kept. 0 keeps them regardless of age.
+	MaxAgeDays int
+	// Number of rotated files kept for each log. 0 keeps them all.
+	MaxBackups int
+}
+
 var (
 	createLogDirectoryMutex sync.RWMutex
+
+	DefaultFileRotation = FileRotation{
+		MaxSizeMB:  100,
+		MaxAgeDays: 60,
+		MaxBackups: 3,
+	}
+
+	fileRotationMutex sync.RWMutex
+	fileRotation      = DefaultFileRotation
 )
 
-func NewFileLogger(name string, dotSpicePath string) (*zap.Logger, error) {
+// Sets how logs are rotated, and if podLogsDir is set, also writes the entries about each pod to <podLogsDir>/<pod>.log.
+// Applies to file loggers created afterwards and to the logger returned by ZapLogger.
+func ConfigureFileLogs(rotation FileRotation, podLogsDir string) error {
+	fileRotationMutex.Lock()
+	fileRotation = rotation
+	fileRotationMutex.Unlock()
+
+	zapMutex.Lock()
+	defer zapMutex.Unlock()
+
+	if podLogs != nil && podLogs.dir == podLogsDir && podLogs.rotation == rotation {
+		return nil
+	}
+
+	previous := podLogs
+	podLogs = nil
+	if podLogsDir != "" {
+		if _, err := util.MkDirAllInheritPerm(podLogsDir); err != nil {
+			return fmt.Errorf("failed to create log path '%s'", podLogsDir)
+		}
+		podLogs = newPodLogFiles(podLogsDir, rotation)
+	}
+	storeZapCore()
 
+	if previous != nil {
+		previous.Close()
+	}
+
+	return nil
+}
+
+// Returns a logger writing to <dotSpicePath>/log/<name>-<time>.log, rotated as set by ConfigureFileLogs.
+// Logs with name older than the max age are removed.
+func NewFileLogger(name string, dotSpicePath string) (*zap.Logger, error) {
 	logPath, err := createLogDirectory(dotSpicePath)
 	if err != nil {
 		return nil, err
 	}
 
+	fileRotationMutex.RLock()
+	rotation := fileRotation
+	fileRotationMutex.RUnlock()
+
+	err = removeExpiredLogs(logPath, name, rotation.MaxAgeDays, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	fileName := fmt.Sprintf("%s-%s.log", name, time.Now().UTC().Format("20060102T150405Z"))
 	logFilePath := filepath.Join(logPath, fileName)
 
-	_, err = os.Create(logFilePath)
+	f, err := os.Create(logFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create log file '%s': %w", logFilePath, err)
 	}
+	f.Close()
 
-	w := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   logFilePath,
-		MaxSize:    100, // megabytes
-		MaxBackups: 3,
-		MaxAge:     60, // days
-	})
+	w := zapcore.AddSync(newRotatingFile(logFilePath, rotation))
 	core := zapcore.NewCore(
 		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
 		w,
@@ -47,6 +106,76 @@ func NewFileLogger(name string, dotSpicePath string) (*zap.Logger, error) {
 	return zap.New(core), nil
 }
 
+func newRotatingFile(path string, rotation FileRotation) *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    rotation.MaxSizeMB,
+		MaxBackups: rotation.MaxBackups,
+		MaxAge:     rotation.MaxAgeDays,
+	}
+}
+
+// Removes the logs with name, including rotated ones, last written more than maxAgeDays before now.
+// Each run writes a new log, so rotation alone wouldn't remove the logs of previous runs.
+func removeExpiredLogs(logPath string, name string, maxAgeDays int, now time.Time) error {
+	if maxAgeDays <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(logPath)
+	if err != nil {
+		return err
+	}
+
+	cutoff := now.Add(-time.Duration(maxAgeDays) * 24 * time.Hour)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), name+"-") || filepath.Ext(entry.Name()) != ".log" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		if info.ModTime().Before(cutoff) {
+			err = os.Remove(filepath.Join(logPath, entry.Name()))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove expired log: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the path of the latest log created by NewFileLogger with name, or an empty string if there is none
+func LatestLogFile(dotSpicePath string, name string) (string, error) {
+	logPath := filepath.Join(dotSpicePath, "log")
+	entries, err := os.ReadDir(logPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var logs []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), name+"-") && filepath.Ext(entry.Name()) == ".log" {
+			logs = append(logs, entry.Name())
+		}
+	}
+	if len(logs) == 0 {
+		return "", nil
+	}
+
+	// Log names end with their UTC creation time, so the last name is the latest log
+	sort.Strings(logs)
+
+	return filepath.Join(logPath, logs[len(logs)-1]), nil
+}
+
 func createLogDirectory(dotSpicePath string) (string, error) {
 	createLogDirectoryMutex.Lock()
 	defer createLogDirectoryMutex.Unlock()
@@ -0,0 +1,233 @@
+package loggers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	ComponentRuntime  = "runtime"
+	ComponentAIEngine = "aiengine"
+)
+
+var (
+	Components = []string{ComponentRuntime, ComponentAIEngine}
+
+	// The last lines output by the AI engine
+	aiEngineLogs = NewRecentLines(1000)
+
+	// The AI engine prefixes lines about a pod with "<pod> -> "
+	aiEnginePodPrefix = regexp.MustCompile(`^([A-Za-z0-9_-]+) -> `)
+)
+
+type LogLine struct {
+	Time      time.Time `json:"time"`
+	Component string    `json:"component"`
+	Pod       string    `json:"pod,omitempty"`
+	Line      string    `json:"line"`
+}
+
+type LogQuery struct {
+	// Components to read, all of them if empty
+	Components []string
+	// Only lines attributable to this pod, if set
+	Pod string
+	// Only lines logged at or after this time, if set
+	Since time.Time
+	// Only the last Tail lines, if positive
+	Tail int
+}
+
+// Records a line output by the AI engine
+func WriteAIEngineLine(line string) {
+	aiEngineLogs.WriteLine(line)
+}
+
+func ValidateComponent(component string) error {
+	for _, c := range Components {
+		if component == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid component '%s': must be one of %s or %s", component, ComponentRuntime, ComponentAIEngine)
+}
+
+// Returns the lines matching the query, oldest first. Only the lines kept in memory are read unless Since and
+// dotSpicePath are set, in which case earlier AI engine lines are read from its latest log under dotSpicePath.
+func QueryLogs(query *LogQuery, dotSpicePath string) ([]*LogLine, error) {
+	var logLines []*LogLine
+	for _, component := range query.components() {
+		lines, complete := componentLogs(component).Since(query.Since)
+
+		if !complete && !query.Since.IsZero() && dotSpicePath != "" && component == ComponentAIEngine {
+			var before time.Time
+			if len(lines) > 0 {
+				before = lines[0].Time
+			}
+			fileLines, err := readAIEngineLogFile(dotSpicePath, query.Since, before)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(fileLines, lines...)
+		}
+
+		for _, line := range lines {
+			logLine := newLogLine(component, line)
+			if query.matches(logLine) {
+				logLines = append(logLines, logLine)
+			}
+		}
+	}
+
+	sort.SliceStable(logLines, func(i, j int) bool {
+		return logLines[i].Time.Before(logLines[j].Time)
+	})
+
+	if query.Tail > 0 && len(logLines) > query.Tail {
+		logLines = logLines[len(logLines)-query.Tail:]
+	}
+
+	return logLines, nil
+}
+
+// Returns a channel receiving lines matching the query as they are logged, and a func that stops and closes it.
+// Since and Tail are ignored.
+func FollowLogs(query *LogQuery) (<-chan *LogLine, func()) {
+	components := query.components()
+	logLines := make(chan *LogLine, subscriberBufferSize)
+	done := make(chan struct{})
+	stopped := make(chan struct{}, len(components))
+
+	for _, component := range components {
+		lines, unsubscribe := componentLogs(component).Subscribe()
+		go func(component string) {
+			defer func() { stopped <- struct{}{} }()
+			defer unsubscribe()
+			for {
+				select {
+				case <-done:
+					return
+				case line := <-lines:
+					logLine := newLogLine(component, line)
+					if !query.matches(logLine) {
+						continue
+					}
+					select {
+					case logLines <- logLine:
+					case <-done:
+						return
+					}
+				}
+			}
+		}(component)
+	}
+
+	var once sync.Once
+	return logLines, func() {
+		once.Do(func() {
+			close(done)
+			for range components {
+				<-stopped
+			}
+			close(logLines)
+		})
+	}
+}
+
+func (query *LogQuery) components() []string {
+	if len(query.Components) == 0 {
+		return Components
+	}
+	return query.Components
+}
+
+func (query *LogQuery) matches(logLine *LogLine) bool {
+	return query.Pod == "" || logLine.Pod == query.Pod
+}
+
+func componentLogs(component string) *RecentLines {
+	if component == ComponentAIEngine {
+		return aiEngineLogs
+	}
+	return recentRuntimeLogs
+}
+
+func newLogLine(component string, line Line) *LogLine {
+	return &LogLine{
+		Time:      line.Time,
+		Component: component,
+		Pod:       attributePod(component, line.Text),
+		Line:      line.Text,
+	}
+}
+
+// Splits a line output by the AI engine into the pod it's about, empty if it isn't about one, and its message
+func SplitAIEngineLine(line string) (pod string, message string) {
+	if match := aiEnginePodPrefix.FindStringSubmatch(line); match != nil {
+		return match[1], line[len(match[0]):]
+	}
+	return "", line
+}
+
+// Returns the pod a line is about, or an empty string if it can't be told
+func attributePod(component string, text string) string {
+	if component == ComponentAIEngine {
+		pod, _ := SplitAIEngineLine(text)
+		return pod
+	}
+
+	// Runtime lines are JSON entries, which name their pod in a "pod" field
+	var entry struct {
+		Pod string `json:"pod"`
+	}
+	if err := json.Unmarshal([]byte(text), &entry); err != nil {
+		return ""
+	}
+	return entry.Pod
+}
+
+// Reads the lines in the latest AI engine log logged at or after since and, if before is set, before before
+func readAIEngineLogFile(dotSpicePath string, since time.Time, before time.Time) ([]Line, error) {
+	logPath, err := LatestLogFile(dotSpicePath, ComponentAIEngine)
+	if err != nil || logPath == "" {
+		return nil, err
+	}
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []Line
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry struct {
+			Ts  float64 `json:"ts"`
+			Msg string  `json:"msg"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+
+		sec, frac := math.Modf(entry.Ts)
+		line := Line{Time: time.Unix(int64(sec), int64(frac*1e9)), Text: entry.Msg}
+		if line.Time.Before(since) || (!before.IsZero() && !line.Time.Before(before)) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", logPath, err)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,93 @@
+package loggers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogs(t *testing.T) {
+	t.Run("attributePod() - Lines are attributed to the pod they name", testAttributePodFunc())
+	t.Run("QueryLogs() - Dropped AI engine lines are read from its log", testQueryLogsFileFunc())
+	t.Run("FollowLogs() - New lines matching the query are received", testFollowLogsFunc())
+}
+
+func testAttributePodFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		assert.Equal(t, "trader", attributePod(ComponentAIEngine, "trader -> Episode 1 completed with score of 10"))
+		assert.Equal(t, "", attributePod(ComponentAIEngine, "Starting AI engine"))
+		assert.Equal(t, "trader", attributePod(ComponentRuntime, `{"level":"info","msg":"loaded","pod":"trader"}`))
+		assert.Equal(t, "", attributePod(ComponentRuntime, `{"level":"info","msg":"loaded"}`))
+		assert.Equal(t, "", attributePod(ComponentRuntime, "2021/10/16 10:00:00 trader -> not a runtime pod field"))
+	}
+}
+
+func testQueryLogsFileFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		previous := aiEngineLogs
+		defer func() { aiEngineLogs = previous }()
+		aiEngineLogs = NewRecentLines(1)
+
+		dotSpicePath := t.TempDir()
+		assert.NoError(t, os.MkdirAll(filepath.Join(dotSpicePath, "log"), 0755))
+
+		now := time.Now()
+		ts := func(d time.Duration) float64 {
+			return float64(now.Add(d).UnixNano()) / 1e9
+		}
+		log := fmt.Sprintf(`{"level":"info","ts":%f,"msg":"trader -> too old"}
+{"level":"info","ts":%f,"msg":"trader -> from the log"}
+not json
+{"level":"info","ts":%f,"msg":"trader -> from the log and memory"}
+`, ts(-2*time.Hour), ts(-30*time.Minute), ts(time.Hour))
+		assert.NoError(t, os.WriteFile(filepath.Join(dotSpicePath, "log", "aiengine-20211016T000000Z.log"), []byte(log), 0644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dotSpicePath, "log", "aiengine-20211015T000000Z.log"), []byte("{}\n"), 0644))
+
+		WriteAIEngineLine("trader -> dropped")
+		WriteAIEngineLine("trader -> from memory")
+
+		query := &LogQuery{Components: []string{ComponentAIEngine}, Since: now.Add(-time.Hour)}
+		logLines, err := QueryLogs(query, dotSpicePath)
+		assert.NoError(t, err)
+
+		lines := make([]string, 0, len(logLines))
+		for _, logLine := range logLines {
+			assert.Equal(t, "trader", logLine.Pod)
+			lines = append(lines, logLine.Line)
+		}
+		assert.Equal(t, []string{"trader -> from the log", "trader -> from memory"}, lines)
+
+		// Without since, only the lines kept in memory are read
+		logLines, err = QueryLogs(&LogQuery{Components: []string{ComponentAIEngine}}, dotSpicePath)
+		assert.NoError(t, err)
+		assert.Len(t, logLines, 1)
+	}
+}
+
+func testFollowLogsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		previous := aiEngineLogs
+		defer func() { aiEngineLogs = previous }()
+		aiEngineLogs = NewRecentLines(10)
+
+		logLines, stop := FollowLogs(&LogQuery{Components: []string{ComponentAIEngine}, Pod: "trader"})
+		WriteAIEngineLine("other -> skipped")
+		WriteAIEngineLine("trader -> followed")
+
+		select {
+		case logLine := <-logLines:
+			assert.Equal(t, "trader -> followed", logLine.Line)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no line was received")
+		}
+
+		stop()
+		stop()
+		_, ok := <-logLines
+		assert.False(t, ok)
+	}
+}
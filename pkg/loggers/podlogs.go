@@ -0,0 +1,122 @@
+package loggers
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+)
+
+// A rotating log file for each pod, created on its first entry
+type podLogFiles struct {
+	dir      string
+	rotation FileRotation
+
+	mu    sync.Mutex
+	files map[string]*lumberjack.Logger
+}
+
+func newPodLogFiles(dir string, rotation FileRotation) *podLogFiles {
+	return &podLogFiles{
+		dir:      dir,
+		rotation: rotation,
+		files:    make(map[string]*lumberjack.Logger),
+	}
+}
+
+func (p *podLogFiles) Write(pod string, data []byte) error {
+	// Pod names come from manifest names, but never let one point outside the directory
+	if pod == "" || pod != filepath.Base(pod) || strings.HasPrefix(pod, ".") {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	file, ok := p.files[pod]
+	if !ok {
+		file = newRotatingFile(filepath.Join(p.dir, pod+".log"), p.rotation)
+		p.files[pod] = file
+	}
+
+	_, err := file.Write(data)
+	return err
+}
+
+func (p *podLogFiles) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for pod, file := range p.files {
+		_ = file.Close()
+		delete(p.files, pod)
+	}
+}
+
+// Writes entries with a pod field to that pod's log file
+type podLogsCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	files   *podLogFiles
+	pod     string
+}
+
+func newPodLogsCore(files *podLogFiles, enabler zapcore.LevelEnabler) zapcore.Core {
+	return &podLogsCore{
+		LevelEnabler: enabler,
+		encoder:      zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		files:        files,
+	}
+}
+
+func (c *podLogsCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &podLogsCore{
+		LevelEnabler: c.LevelEnabler,
+		encoder:      c.encoder.Clone(),
+		files:        c.files,
+		pod:          podOf(fields, c.pod),
+	}
+	for _, field := range fields {
+		field.AddTo(clone.encoder)
+	}
+	return clone
+}
+
+func (c *podLogsCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *podLogsCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	pod := podOf(fields, c.pod)
+	if pod == "" {
+		return nil
+	}
+
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	return c.files.Write(pod, buf.Bytes())
+}
+
+func (c *podLogsCore) Sync() error {
+	return nil
+}
+
+// Returns the value of the last pod field, or pod if there is none
+func podOf(fields []zapcore.Field, pod string) string {
+	for _, field := range fields {
+		if field.Key == PodKey && field.Type == zapcore.StringType {
+			pod = field.String
+		}
+	}
+	return pod
+}
@@ -0,0 +1,126 @@
+package loggers
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Lines waiting for a slow subscriber beyond this are dropped rather than blocking the logger
+const subscriberBufferSize = 256
+
+type Line struct {
+	Time time.Time
+	Text string
+}
+
+// Keeps the last lines written to it, so recent logs can be read and followed without a log file
+type RecentLines struct {
+	mu          sync.Mutex
+	lines       []Line
+	next        int
+	full        bool
+	dropped     time.Time
+	subscribers map[chan Line]struct{}
+}
+
+func NewRecentLines(size int) *RecentLines {
+	return &RecentLines{
+		lines:       make([]Line, size),
+		subscribers: make(map[chan Line]struct{}),
+	}
+}
+
+// Adds each non-empty line in p, dropping the oldest lines once full
+func (r *RecentLines) Write(p []byte) (int, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, text := range strings.Split(string(p), "\n") {
+		if text == "" {
+			continue
+		}
+		line := Line{Time: now, Text: text}
+		if r.full {
+			r.dropped = r.lines[r.next].Time
+		}
+		r.lines[r.next] = line
+		r.next = (r.next + 1) % len(r.lines)
+		if r.next == 0 {
+			r.full = true
+		}
+
+		for subscriber := range r.subscribers {
+			select {
+			case subscriber <- line:
+			default:
+			}
+		}
+	}
+
+	return len(p), nil
+}
+
+// Adds a single line without its trailing newline
+func (r *RecentLines) WriteLine(text string) {
+	_, _ = r.Write([]byte(text + "\n"))
+}
+
+func (r *RecentLines) Sync() error {
+	return nil
+}
+
+// Returns the text of the lines kept, oldest first
+func (r *RecentLines) Lines() []string {
+	lines, _ := r.Since(time.Time{})
+	texts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		texts = append(texts, line.Text)
+	}
+	return texts
+}
+
+// Returns the lines kept written at or after since, oldest first. complete is false if
+// lines written at or after since were dropped to make room.
+func (r *RecentLines) Since(since time.Time) (lines []Line, complete bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ordered := r.lines[:r.next]
+	if r.full {
+		ordered = make([]Line, 0, len(r.lines))
+		ordered = append(ordered, r.lines[r.next:]...)
+		ordered = append(ordered, r.lines[:r.next]...)
+	}
+
+	lines = make([]Line, 0, len(ordered))
+	for _, line := range ordered {
+		if !line.Time.Before(since) {
+			lines = append(lines, line)
+		}
+	}
+
+	complete = r.dropped.IsZero() || r.dropped.Before(since)
+	return lines, complete
+}
+
+// Returns a channel receiving lines as they are written, and a func that stops and closes it
+func (r *RecentLines) Subscribe() (<-chan Line, func()) {
+	subscriber := make(chan Line, subscriberBufferSize)
+
+	r.mu.Lock()
+	r.subscribers[subscriber] = struct{}{}
+	r.mu.Unlock()
+
+	var once sync.Once
+	return subscriber, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.subscribers, subscriber)
+			r.mu.Unlock()
+			close(subscriber)
+		})
+	}
+}
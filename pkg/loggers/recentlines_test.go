@@ -0,0 +1,84 @@
+package loggers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentLines(t *testing.T) {
+	t.Run("Lines() - Lines are returned oldest first", testRecentLinesFunc())
+	t.Run("Lines() - The oldest lines are dropped once full", testRecentLinesWrapFunc())
+	t.Run("Since() - Lines before since are skipped and dropped lines are reported", testRecentLinesSinceFunc())
+	t.Run("Subscribe() - Subscribers receive lines until they stop", testRecentLinesSubscribeFunc())
+}
+
+func testRecentLinesFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		r := NewRecentLines(3)
+		assert.Empty(t, r.Lines())
+
+		_, err := r.Write([]byte("one\ntwo\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"one", "two"}, r.Lines())
+	}
+}
+
+func testRecentLinesWrapFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		r := NewRecentLines(3)
+		for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n"} {
+			_, err := r.Write([]byte(line))
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, []string{"three", "four", "five"}, r.Lines())
+	}
+}
+
+func testRecentLinesSinceFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		r := NewRecentLines(2)
+		r.WriteLine("one")
+		since := time.Now()
+		r.WriteLine("two")
+
+		lines, complete := r.Since(since)
+		assert.True(t, complete)
+		if assert.Len(t, lines, 1) {
+			assert.Equal(t, "two", lines[0].Text)
+		}
+
+		r.WriteLine("three")
+		_, complete = r.Since(since)
+		assert.True(t, complete, "only lines before since were dropped")
+
+		r.WriteLine("four")
+		lines, complete = r.Since(since)
+		assert.False(t, complete, "two was dropped")
+		assert.Len(t, lines, 2)
+
+		_, complete = r.Since(time.Now())
+		assert.True(t, complete)
+	}
+}
+
+func testRecentLinesSubscribeFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		r := NewRecentLines(2)
+		r.WriteLine("before")
+
+		lines, stop := r.Subscribe()
+		r.WriteLine("one")
+		r.WriteLine("two")
+		stop()
+		r.WriteLine("after")
+		stop()
+
+		var texts []string
+		for line := range lines {
+			texts = append(texts, line.Text)
+		}
+		assert.Equal(t, []string{"one", "two"}, texts)
+	}
+}
@@ -2,34 +2,90 @@ package loggers
 
 import (
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"sync"
+	"sync/atomic"
 
+	"github.com/logrusorgru/aurora"
 	"github.com/spiceai/spiceai/pkg/util"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Keys of the fields naming what an entry is about, so entries can be filtered and attributed
+const (
+	PodKey       = "pod"
+	DataspaceKey = "dataspace"
+	FlightKey    = "flight"
 )
 
 var (
 	zapLogger *zap.Logger
+	zapLevel  zap.AtomicLevel = zap.NewAtomicLevel()
+	zapCore   atomic.Value
+	zapColor  atomic.Value
+
+	// Guards the settings the zap core is built from
+	zapMutex  sync.Mutex
+	zapFormat = "json"
+	podLogs   *podLogFiles
+
+	// The last entries logged by the runtime, as JSON lines from zap and plain lines from the std logger
+	recentRuntimeLogs = NewRecentLines(1000)
 )
 
 func ZapLogger() *zap.Logger {
+	zapMutex.Lock()
+	defer zapMutex.Unlock()
+
 	if zapLogger != nil {
 		return zapLogger
 	}
 
-	var err error
 	if util.IsDebug() {
-		zapLogger, err = zap.NewDevelopment()
-	} else {
-		zapLogger, err = zap.NewProduction()
+		zapLevel.SetLevel(zap.DebugLevel)
+		zapFormat = "console"
 	}
+
+	storeZapCore()
+	zapLogger = zap.New(&swappableCore{}, zap.AddCaller())
+
+	return zapLogger
+}
+
+// Sets the level and format of the logger returned by ZapLogger, including loggers already handed out
+func ConfigureZapLogger(level string, format string) error {
+	if format != "json" && format != "console" {
+		return fmt.Errorf("invalid log format '%s'", format)
+	}
+
+	err := SetZapLoggerLevel(level)
 	if err != nil {
-		// Fall back to standard logging
-		log.Println(fmt.Errorf("unable to create Zap logger: %w", err))
-		return nil
+		return err
 	}
 
-	return zapLogger
+	ZapLogger()
+
+	zapMutex.Lock()
+	defer zapMutex.Unlock()
+
+	if !util.IsDebug() {
+		zapFormat = format
+	}
+	storeZapCore()
+
+	return nil
+}
+
+// Sets the minimum level logged by the logger returned by ZapLogger
+func SetZapLoggerLevel(level string) error {
+	if util.IsDebug() {
+		// SPICE_DEBUG always logs everything
+		return nil
+	}
+	return zapLevel.UnmarshalText([]byte(level))
 }
 
 func ZapLoggerSync() {
@@ -42,3 +98,129 @@ func ZapLoggerSync() {
 		}
 	}
 }
+
+// Returns colors for console output, which are disabled unless logging to a terminal in the console format
+func Color() aurora.Aurora {
+	if color, ok := zapColor.Load().(aurora.Aurora); ok {
+		return color
+	}
+	return aurora.NewAurora(false)
+}
+
+// Whether stderr is a terminal, rather than a file, pipe or container log
+func IsInteractive() bool {
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func Pod(name string) zap.Field {
+	return zap.String(PodKey, name)
+}
+
+func Dataspace(name string) zap.Field {
+	return zap.String(DataspaceKey, name)
+}
+
+func Flight(id string) zap.Field {
+	return zap.String(FlightKey, id)
+}
+
+// Builds the core from the current settings. Must be called with zapMutex held.
+func storeZapCore() {
+	colored := zapFormat == "console" && IsInteractive()
+	zapColor.Store(aurora.NewAurora(colored))
+
+	var encoder zapcore.Encoder
+	if zapFormat == "console" {
+		encoderConfig := zap.NewDevelopmentEncoderConfig()
+		if colored {
+			encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	} else {
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	}
+
+	cores := []zapcore.Core{
+		zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), zapLevel),
+		&runtimeLogsCore{zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), recentRuntimeLogs, zapLevel)},
+	}
+	if podLogs != nil {
+		cores = append(cores, newPodLogsCore(podLogs, zapLevel))
+	}
+
+	zapCore.Store(zapcore.NewTee(cores...))
+}
+
+// Returns the last entries logged through ZapLogger as JSON lines, oldest first
+func RecentRuntimeLogs() []string {
+	return recentRuntimeLogs.Lines()
+}
+
+// Also records what the std logger writes as runtime logs
+func CaptureStdLog() {
+	log.SetOutput(io.MultiWriter(log.Writer(), recentRuntimeLogs))
+}
+
+// Records entries as runtime logs, except for the AI engine's output, which is kept separately
+type runtimeLogsCore struct {
+	zapcore.Core
+}
+
+func (c *runtimeLogsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &runtimeLogsCore{c.Core.With(fields)}
+}
+
+// The embedded core's Check would add itself rather than c, bypassing the filter in Write
+func (c *runtimeLogsCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.LoggerName != ComponentAIEngine && c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *runtimeLogsCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if entry.LoggerName == ComponentAIEngine {
+		return nil
+	}
+	return c.Core.Write(entry, fields)
+}
+
+// Delegates to the current zapCore so reconfiguring applies to loggers created before it
+type swappableCore struct {
+	fields []zapcore.Field
+}
+
+func (c *swappableCore) current() zapcore.Core {
+	core := zapCore.Load().(zapcore.Core)
+	if len(c.fields) > 0 {
+		return core.With(c.fields)
+	}
+	return core
+}
+
+func (c *swappableCore) Enabled(level zapcore.Level) bool {
+	return zapLevel.Enabled(level)
+}
+
+func (c *swappableCore) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	combined = append(combined, c.fields...)
+	combined = append(combined, fields...)
+	return &swappableCore{fields: combined}
+}
+
+func (c *swappableCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *swappableCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.current().Write(entry, fields)
+}
+
+func (c *swappableCore) Sync() error {
+	return c.current().Sync()
+}
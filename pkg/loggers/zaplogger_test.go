@@ -0,0 +1,117 @@
+package loggers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestZapLogger(t *testing.T) {
+	t.Run("SplitAIEngineLine() - The pod prefix is split from the message", testSplitAIEngineLineFunc())
+	t.Run("runtimeLogsCore.Write() - AI engine entries are not runtime logs", testRuntimeLogsCoreFunc())
+	t.Run("podLogsCore.Write() - Entries with a pod are written to its log file", testPodLogsCoreFunc())
+	t.Run("removeExpiredLogs() - Only logs older than the max age are removed", testRemoveExpiredLogsFunc())
+}
+
+func testSplitAIEngineLineFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		pod, message := SplitAIEngineLine("trader -> Episode 1 completed with score of 10")
+		assert.Equal(t, "trader", pod)
+		assert.Equal(t, "Episode 1 completed with score of 10", message)
+
+		pod, message = SplitAIEngineLine("Starting AI engine")
+		assert.Equal(t, "", pod)
+		assert.Equal(t, "Starting AI engine", message)
+	}
+}
+
+func testRuntimeLogsCoreFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		lines := NewRecentLines(10)
+		encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+		core := &runtimeLogsCore{zapcore.NewCore(encoder, lines, zap.DebugLevel)}
+		logger := zap.New(core)
+
+		logger.Info("from the runtime")
+		logger.Named(ComponentAIEngine).Info("from the AI engine")
+		logger.With(Pod("trader")).Info("about a pod")
+
+		logged := lines.Lines()
+		assert.Len(t, logged, 2)
+		assert.Contains(t, logged[0], `"msg":"from the runtime"`)
+		assert.Contains(t, logged[1], `"msg":"about a pod"`)
+		assert.Contains(t, logged[1], `"pod":"trader"`)
+	}
+}
+
+func testPodLogsCoreFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		dir := t.TempDir()
+		files := newPodLogFiles(dir, DefaultFileRotation)
+		defer files.Close()
+
+		logger := zap.New(newPodLogsCore(files, zap.DebugLevel))
+		logger.Info("loaded", Pod("trader"), Dataspace("coinbase/btcusd"))
+		logger.With(Pod("cartpole")).Info("training", Flight("1"))
+		logger.Info("not about a pod")
+		logger.Info("outside the directory", Pod("../trader"))
+		logger.Info("hidden", Pod(".trader"))
+
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.ElementsMatch(t, []string{"trader.log", "cartpole.log"}, names)
+
+		data, err := os.ReadFile(filepath.Join(dir, "trader.log"))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, strings.Count(string(data), "\n"))
+		assert.Contains(t, string(data), `"msg":"loaded"`)
+		assert.Contains(t, string(data), `"dataspace":"coinbase/btcusd"`)
+
+		data, err = os.ReadFile(filepath.Join(dir, "cartpole.log"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"pod":"cartpole"`)
+		assert.Contains(t, string(data), `"flight":"1"`)
+	}
+}
+
+func testRemoveExpiredLogsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		dir := t.TempDir()
+		now := time.Now()
+
+		write := func(name string, age time.Duration) {
+			path := filepath.Join(dir, name)
+			assert.NoError(t, os.WriteFile(path, []byte("{}\n"), 0644))
+			assert.NoError(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+		}
+		write("aiengine-20211001T000000Z.log", 10*24*time.Hour)
+		write("aiengine-20211001T000000Z-2021-10-02T00-00-00.000.log", 9*24*time.Hour)
+		write("aiengine-20211015T000000Z.log", 24*time.Hour)
+		write("runtime-20211001T000000Z.log", 10*24*time.Hour)
+		write("aiengine-notes.txt", 10*24*time.Hour)
+
+		assert.NoError(t, removeExpiredLogs(dir, "aiengine", 7, now))
+
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.ElementsMatch(t, []string{"aiengine-20211015T000000Z.log", "runtime-20211001T000000Z.log", "aiengine-notes.txt"}, names)
+
+		// A max age of 0 keeps logs forever
+		assert.NoError(t, removeExpiredLogs(dir, "runtime", 0, now))
+		assert.FileExists(t, filepath.Join(dir, "runtime-20211001T000000Z.log"))
+	}
+}
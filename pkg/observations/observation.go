@@ -4,6 +4,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Observation struct {
@@ -14,6 +15,25 @@ type Observation struct {
 	Tags         []string
 }
 
+// Returns the observations from start up to but excluding end. Zero times leave that side unbounded.
+func Between(observations []Observation, start time.Time, end time.Time) []Observation {
+	if start.IsZero() && end.IsZero() {
+		return observations
+	}
+
+	between := make([]Observation, 0, len(observations))
+	for _, o := range observations {
+		if !start.IsZero() && o.Time < start.Unix() {
+			continue
+		}
+		if !end.IsZero() && o.Time >= end.Unix() {
+			continue
+		}
+		between = append(between, o)
+	}
+	return between
+}
+
 func GetCsv(headers []string, tags []string, observations []Observation) string {
 	csv := strings.Builder{}
 	for _, o := range observations {
@@ -0,0 +1,309 @@
+package observations
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Parquet enums and thrift compact protocol types, from parquet.thrift
+const (
+	parquetTypeInt64     = 2
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+
+	parquetRequired = 0
+	parquetOptional = 1
+
+	parquetConvertedUtf8 = 0
+
+	parquetEncodingPlain = 0
+	parquetEncodingRle   = 3
+
+	parquetCodecUncompressed = 0
+	parquetPageData          = 0
+
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+var parquetMagic = []byte("PAR1")
+
+type parquetColumn struct {
+	name          string
+	parquetType   int32
+	repetition    int32
+	convertedUtf8 bool
+	// Definition levels, only for optional columns
+	defined []bool
+	values  bytes.Buffer
+}
+
+// Writes observations as an uncompressed Parquet file with a single row group.
+// Time is a required int64 column of unix seconds, identifiers and categories are optional strings,
+// measurements are optional doubles and tags are an optional space-separated string.
+func WriteParquet(w io.Writer, identifierNames []string, measurementNames []string, categoryNames []string, observations []Observation) error {
+	timeColumn := &parquetColumn{name: "time", parquetType: parquetTypeInt64, repetition: parquetRequired}
+	columns := []*parquetColumn{timeColumn}
+
+	newColumns := func(names []string, parquetType int32, utf8 bool) []*parquetColumn {
+		cols := make([]*parquetColumn, len(names))
+		for i, name := range names {
+			cols[i] = &parquetColumn{name: name, parquetType: parquetType, repetition: parquetOptional, convertedUtf8: utf8}
+		}
+		columns = append(columns, cols...)
+		return cols
+	}
+	identifierColumns := newColumns(identifierNames, parquetTypeByteArray, true)
+	measurementColumns := newColumns(measurementNames, parquetTypeDouble, false)
+	categoryColumns := newColumns(categoryNames, parquetTypeByteArray, true)
+	tagsColumn := newColumns([]string{"tags"}, parquetTypeByteArray, true)[0]
+
+	for _, o := range observations {
+		writeParquetInt64(&timeColumn.values, o.Time)
+
+		for i, name := range identifierNames {
+			value, ok := o.Identifiers[name]
+			identifierColumns[i].addString(value, ok)
+		}
+		for i, name := range measurementNames {
+			value, ok := o.Measurements[name]
+			measurementColumns[i].defined = append(measurementColumns[i].defined, ok)
+			if ok {
+				writeParquetInt64(&measurementColumns[i].values, int64(math.Float64bits(value)))
+			}
+		}
+		for i, name := range categoryNames {
+			value, ok := o.Categories[name]
+			categoryColumns[i].addString(value, ok)
+		}
+
+		tags := append([]string(nil), o.Tags...)
+		sort.Strings(tags)
+		tagsColumn.addString(strings.Join(tags, " "), len(tags) > 0)
+	}
+
+	file := &bytes.Buffer{}
+	file.Write(parquetMagic)
+
+	numRows := int64(len(observations))
+	metadata := &thriftWriter{}
+	metadata.fieldI32(1, 1) // version
+	metadata.fieldListHeader(2, thriftStruct, len(columns)+1)
+	metadata.structBegin()
+	metadata.fieldBinary(4, "schema")
+	metadata.fieldI32(5, int32(len(columns)))
+	metadata.structEnd()
+	for _, c := range columns {
+		metadata.structBegin()
+		metadata.fieldI32(1, c.parquetType)
+		metadata.fieldI32(3, c.repetition)
+		metadata.fieldBinary(4, c.name)
+		if c.convertedUtf8 {
+			metadata.fieldI32(6, parquetConvertedUtf8)
+		}
+		metadata.structEnd()
+	}
+	metadata.fieldI64(3, numRows)
+
+	if numRows > 0 {
+		chunks := &thriftWriter{}
+		var totalBytes int64
+		for _, c := range columns {
+			offset := int64(file.Len())
+			size := c.writePage(file, len(observations))
+			totalBytes += size
+
+			chunks.structBegin()
+			chunks.fieldI64(2, offset)
+			chunks.fieldStructBegin(3)
+			chunks.fieldI32(1, c.parquetType)
+			chunks.fieldListHeader(2, thriftI32, 2)
+			chunks.i32(parquetEncodingPlain)
+			chunks.i32(parquetEncodingRle)
+			chunks.fieldListHeader(3, thriftBinary, 1)
+			chunks.binary(c.name)
+			chunks.fieldI32(4, parquetCodecUncompressed)
+			chunks.fieldI64(5, numRows)
+			chunks.fieldI64(6, size)
+			chunks.fieldI64(7, size)
+			chunks.fieldI64(9, offset)
+			chunks.structEnd()
+			chunks.structEnd()
+		}
+
+		metadata.fieldListHeader(4, thriftStruct, 1)
+		metadata.structBegin()
+		metadata.fieldListHeader(1, thriftStruct, len(columns))
+		metadata.buf.Write(chunks.buf.Bytes())
+		metadata.fieldI64(2, totalBytes)
+		metadata.fieldI64(3, numRows)
+		metadata.structEnd()
+	} else {
+		metadata.fieldListHeader(4, thriftStruct, 0)
+	}
+
+	metadata.fieldBinary(6, "spiceai")
+	metadata.structEnd()
+
+	file.Write(metadata.buf.Bytes())
+	var footerLength [4]byte
+	binary.LittleEndian.PutUint32(footerLength[:], uint32(metadata.buf.Len()))
+	file.Write(footerLength[:])
+	file.Write(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+func (c *parquetColumn) addString(value string, ok bool) {
+	c.defined = append(c.defined, ok)
+	if ok {
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(value)))
+		c.values.Write(length[:])
+		c.values.WriteString(value)
+	}
+}
+
+// Writes the column as a single data page and returns its size including the page header
+func (c *parquetColumn) writePage(file *bytes.Buffer, numValues int) int64 {
+	page := &bytes.Buffer{}
+	if c.repetition == parquetOptional {
+		levels := encodeParquetDefinitionLevels(c.defined)
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(levels)))
+		page.Write(length[:])
+		page.Write(levels)
+	}
+	page.Write(c.values.Bytes())
+
+	header := &thriftWriter{}
+	header.fieldI32(1, parquetPageData)
+	header.fieldI32(2, int32(page.Len()))
+	header.fieldI32(3, int32(page.Len()))
+	header.fieldStructBegin(5)
+	header.fieldI32(1, int32(numValues))
+	header.fieldI32(2, parquetEncodingPlain)
+	header.fieldI32(3, parquetEncodingRle)
+	header.fieldI32(4, parquetEncodingRle)
+	header.structEnd()
+	header.structEnd()
+
+	file.Write(header.buf.Bytes())
+	file.Write(page.Bytes())
+
+	return int64(header.buf.Len() + page.Len())
+}
+
+// Encodes definition levels with a bit width of 1 as RLE runs of the hybrid encoding
+func encodeParquetDefinitionLevels(defined []bool) []byte {
+	levels := &thriftWriter{}
+	for i := 0; i < len(defined); {
+		run := 1
+		for i+run < len(defined) && defined[i+run] == defined[i] {
+			run++
+		}
+		levels.uvarint(uint64(run) << 1)
+		if defined[i] {
+			levels.buf.WriteByte(1)
+		} else {
+			levels.buf.WriteByte(0)
+		}
+		i += run
+	}
+	return levels.buf.Bytes()
+}
+
+func writeParquetInt64(buf *bytes.Buffer, value int64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(value))
+	buf.Write(b[:])
+}
+
+// Writes the subset of the thrift compact protocol needed for parquet metadata
+type thriftWriter struct {
+	buf         bytes.Buffer
+	lastFieldId int16
+	fieldIds    []int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, fieldType byte) {
+	delta := id - t.lastFieldId
+	if delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.buf.WriteByte(fieldType)
+		t.uvarint(zigzag(int64(id)))
+	}
+	t.lastFieldId = id
+}
+
+func (t *thriftWriter) fieldI32(id int16, value int32) {
+	t.fieldHeader(id, thriftI32)
+	t.i32(value)
+}
+
+func (t *thriftWriter) fieldI64(id int16, value int64) {
+	t.fieldHeader(id, thriftI64)
+	t.uvarint(zigzag(value))
+}
+
+func (t *thriftWriter) fieldBinary(id int16, value string) {
+	t.fieldHeader(id, thriftBinary)
+	t.binary(value)
+}
+
+func (t *thriftWriter) fieldListHeader(id int16, elementType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elementType)
+	} else {
+		t.buf.WriteByte(0xf0 | elementType)
+		t.uvarint(uint64(size))
+	}
+}
+
+func (t *thriftWriter) fieldStructBegin(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.structBegin()
+}
+
+// Begins a struct nested in a field or list. Top level structs don't need it.
+func (t *thriftWriter) structBegin() {
+	t.fieldIds = append(t.fieldIds, t.lastFieldId)
+	t.lastFieldId = 0
+}
+
+func (t *thriftWriter) structEnd() {
+	t.buf.WriteByte(0)
+	if len(t.fieldIds) > 0 {
+		t.lastFieldId = t.fieldIds[len(t.fieldIds)-1]
+		t.fieldIds = t.fieldIds[:len(t.fieldIds)-1]
+	}
+}
+
+func (t *thriftWriter) i32(value int32) {
+	t.uvarint(zigzag(int64(value)))
+}
+
+func (t *thriftWriter) binary(value string) {
+	t.uvarint(uint64(len(value)))
+	t.buf.WriteString(value)
+}
+
+func (t *thriftWriter) uvarint(value uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], value)
+	t.buf.Write(b[:n])
+}
+
+func zigzag(value int64) uint64 {
+	return uint64((value << 1) ^ (value >> 63))
+}
@@ -0,0 +1,243 @@
+package observations
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParquet(t *testing.T) {
+	t.Run("WriteParquet() - Footer describes the schema and row group", testWriteParquetFooterFunc())
+	t.Run("WriteParquet() - Column pages hold the values", testWriteParquetValuesFunc())
+	t.Run("WriteParquet() - No observations", testWriteParquetEmptyFunc())
+	t.Run("WriteParquet() - Matches the file read back with pandas by the AI engine tests", testWriteParquetAssetFunc())
+}
+
+// Read back with pandas and pyarrow by ai/src/tests/test_parquet.py, which checks the values below
+const parquetAssetPath = "../../test/assets/data/parquet/observations.parquet"
+
+var parquetAssetObservations = []Observation{
+	{
+		Time:         1605312000,
+		Identifiers:  map[string]string{"event.id": "a"},
+		Measurements: map[string]float64{"coinbase.btcusd.close": 16339.56, "coinbase.btcusd.volume": 1.5},
+		Tags:         []string{"tagB", "tagA"},
+	},
+	{
+		Time:       1605312060,
+		Categories: map[string]string{"event.color": "red"},
+	},
+	{
+		Time:         1605312120,
+		Identifiers:  map[string]string{"event.id": "b"},
+		Measurements: map[string]float64{"coinbase.btcusd.close": -0.25, "coinbase.btcusd.volume": 0},
+		Categories:   map[string]string{"event.color": "grün"},
+		Tags:         []string{"tagA"},
+	},
+	{
+		Time:         1605312180,
+		Identifiers:  map[string]string{"event.id": ""},
+		Measurements: map[string]float64{"coinbase.btcusd.close": 16350},
+	},
+}
+
+var parquetTestObservations = []Observation{
+	{
+		Time:         1605312000,
+		Identifiers:  map[string]string{"event.id": "a"},
+		Measurements: map[string]float64{"coinbase.btcusd.close": 16339.56},
+		Tags:         []string{"tagB", "tagA"},
+	},
+	{
+		Time:       1605312060,
+		Categories: map[string]string{"event.color": "red"},
+	},
+}
+
+func testWriteParquetFooterFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteParquet(&buf, []string{"event.id"}, []string{"coinbase.btcusd.close"}, []string{"event.color"}, parquetTestObservations)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		metadata := readParquetFooter(t, buf.Bytes())
+		assert.Equal(t, int64(1), metadata[1])
+		assert.Equal(t, int64(2), metadata[3])
+
+		schema := metadata[2].([]interface{})
+		names := make([]string, 0, len(schema))
+		for _, element := range schema {
+			names = append(names, element.(map[int16]interface{})[4].(string))
+		}
+		assert.Equal(t, []string{"schema", "time", "event.id", "coinbase.btcusd.close", "event.color", "tags"}, names)
+		assert.Equal(t, int64(5), schema[0].(map[int16]interface{})[5])
+
+		rowGroups := metadata[4].([]interface{})
+		if assert.Len(t, rowGroups, 1) {
+			rowGroup := rowGroups[0].(map[int16]interface{})
+			assert.Equal(t, int64(2), rowGroup[3])
+			assert.Len(t, rowGroup[1], 5)
+		}
+	}
+}
+
+func testWriteParquetValuesFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteParquet(&buf, []string{"event.id"}, []string{"coinbase.btcusd.close"}, []string{"event.color"}, parquetTestObservations)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+
+		metadata := readParquetFooter(t, data)
+		chunks := metadata[4].([]interface{})[0].(map[int16]interface{})[1].([]interface{})
+		page := func(column int) []byte {
+			columnMetadata := chunks[column].(map[int16]interface{})[3].(map[int16]interface{})
+			offset := columnMetadata[9].(int64)
+			reader := &thriftReader{data: data[offset:]}
+			header := reader.readStruct()
+			pageSize := int(header[3].(int64))
+			return data[int(offset)+reader.pos : int(offset)+reader.pos+pageSize]
+		}
+
+		timePage := page(0)
+		assert.Equal(t, uint64(1605312000), binary.LittleEndian.Uint64(timePage[0:8]))
+		assert.Equal(t, uint64(1605312060), binary.LittleEndian.Uint64(timePage[8:16]))
+
+		// Definition levels: length 4, then runs of one defined and one undefined value
+		measurementPage := page(2)
+		assert.Equal(t, []byte{4, 0, 0, 0, 2, 1, 2, 0}, measurementPage[:8])
+		assert.Equal(t, 16339.56, math.Float64frombits(binary.LittleEndian.Uint64(measurementPage[8:16])))
+		assert.Len(t, measurementPage, 16)
+
+		tagsPage := page(4)
+		assert.Equal(t, []byte{4, 0, 0, 0, 2, 1, 2, 0, 9, 0, 0, 0}, tagsPage[:12])
+		assert.Equal(t, "tagA tagB", string(tagsPage[12:]))
+	}
+}
+
+func testWriteParquetEmptyFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteParquet(&buf, nil, []string{"coinbase.btcusd.close"}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		metadata := readParquetFooter(t, buf.Bytes())
+		assert.Equal(t, int64(0), metadata[3])
+		assert.Empty(t, metadata[4])
+	}
+}
+
+func testWriteParquetAssetFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteParquet(&buf, []string{"event.id"}, []string{"coinbase.btcusd.close", "coinbase.btcusd.volume"}, []string{"event.color"}, parquetAssetObservations)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Regenerated with the snapshots, see update-pkg-snapshots
+		if os.Getenv("UPDATE_SNAPSHOTS") != "" {
+			err = os.MkdirAll(filepath.Dir(parquetAssetPath), 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.WriteFile(parquetAssetPath, buf.Bytes(), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		expected, err := os.ReadFile(parquetAssetPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, buf.Bytes(), "%s is out of date, run with UPDATE_SNAPSHOTS=true and the AI engine tests", parquetAssetPath)
+	}
+}
+
+func readParquetFooter(t *testing.T, data []byte) map[int16]interface{} {
+	if !assert.Equal(t, parquetMagic, data[:4]) || !assert.Equal(t, parquetMagic, data[len(data)-4:]) {
+		t.FailNow()
+	}
+	footerLength := int(binary.LittleEndian.Uint32(data[len(data)-8 : len(data)-4]))
+	footer := data[len(data)-8-footerLength : len(data)-8]
+
+	reader := &thriftReader{data: footer}
+	metadata := reader.readStruct()
+	assert.Equal(t, footerLength, reader.pos)
+	return metadata
+}
+
+// Reads the thrift compact protocol types written by thriftWriter. Integers are returned as int64.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var lastFieldId int16
+	for {
+		header := r.data[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		fieldType := header & 0x0f
+		if delta := int16(header >> 4); delta != 0 {
+			lastFieldId += delta
+		} else {
+			lastFieldId = int16(r.readVarint())
+		}
+		fields[lastFieldId] = r.readValue(fieldType)
+	}
+}
+
+func (r *thriftReader) readValue(valueType byte) interface{} {
+	switch valueType {
+	case thriftI32, thriftI64:
+		return r.readVarint()
+	case thriftBinary:
+		length := int(r.readUvarint())
+		value := string(r.data[r.pos : r.pos+length])
+		r.pos += length
+		return value
+	case thriftList:
+		header := r.data[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.readUvarint())
+		}
+		list := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			list = append(list, r.readValue(header&0x0f))
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic("unsupported thrift type")
+}
+
+func (r *thriftReader) readUvarint() uint64 {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) readVarint() int64 {
+	value := r.readUvarint()
+	return int64(value>>1) ^ -int64(value&1)
+}
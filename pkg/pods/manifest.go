@@ -0,0 +1,296 @@
+package pods
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spiceai/spiceai/pkg/spec"
+	"github.com/spiceai/spiceai/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// A pod manifest as a YAML document, for edits that keep the manifest's comments, key order
+// and values such as env var placeholders that unmarshalling into a PodSpec would lose
+type Manifest struct {
+	path string
+	root *yaml.Node
+}
+
+func LoadManifest(manifestPath string) (*Manifest, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := ParseManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", manifestPath, err)
+	}
+	manifest.path = manifestPath
+
+	return manifest, nil
+}
+
+func ParseManifest(data []byte) (*Manifest, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if document.Kind != yaml.DocumentNode || document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("pod manifest must be a mapping")
+	}
+
+	return &Manifest{root: document.Content[0]}, nil
+}
+
+func (m *Manifest) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	err := encoder.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{m.root}})
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Writes the manifest back to the file it was loaded from
+func (m *Manifest) Save() error {
+	data, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+	return util.WriteToExistingFile(m.path, data)
+}
+
+func (m *Manifest) HasAction(name string) bool {
+	return findSequenceItem(mappingValue(m.root, "actions"), "name", name) >= 0
+}
+
+// Adds the action, replacing any existing action with the same name in place
+func (m *Manifest) SetAction(action spec.PodActionSpec) error {
+	node, err := encodeNode(action)
+	if err != nil {
+		return err
+	}
+
+	actions, err := ensureMappingValue(m.root, "actions", yaml.SequenceNode)
+	if err != nil {
+		return err
+	}
+
+	if i := findSequenceItem(actions, "name", action.Name); i >= 0 {
+		replaceNode(actions.Content[i], node)
+	} else {
+		actions.Content = append(actions.Content, node)
+	}
+
+	return nil
+}
+
+func (m *Manifest) HasDataspace(from string, name string) bool {
+	return m.dataspace(from, name) != nil
+}
+
+// Appends the dataspace to the manifest's dataspaces
+func (m *Manifest) AddDataspace(dataspace spec.DataspaceSpec) error {
+	if m.HasDataspace(dataspace.From, dataspace.Name) {
+		return fmt.Errorf("dataspace %s/%s already exists", dataspace.From, dataspace.Name)
+	}
+
+	node, err := encodeNode(dataspace)
+	if err != nil {
+		return err
+	}
+
+	dataspaces, err := ensureMappingValue(m.root, "dataspaces", yaml.SequenceNode)
+	if err != nil {
+		return err
+	}
+	dataspaces.Content = append(dataspaces.Content, node)
+
+	return nil
+}
+
+func (m *Manifest) HasDataspaceAction(from string, name string, action string) bool {
+	dataspace := m.dataspace(from, name)
+	return dataspace != nil && mappingValue(mappingValue(dataspace, "actions"), action) != nil
+}
+
+// Adds the action to the dataspace's actions, replacing any existing action with the same name
+func (m *Manifest) SetDataspaceAction(from string, name string, action string, script string) error {
+	dataspace := m.dataspace(from, name)
+	if dataspace == nil {
+		return fmt.Errorf("dataspace %s/%s not found", from, name)
+	}
+
+	actions, err := ensureMappingValue(dataspace, "actions", yaml.MappingNode)
+	if err != nil {
+		return err
+	}
+
+	node, err := encodeNode(script)
+	if err != nil {
+		return err
+	}
+	setMappingValue(actions, action, node)
+
+	return nil
+}
+
+// Returns the names of the actions with rewards. A 'uniform' rewards value has none.
+func (m *Manifest) RewardNames() ([]string, error) {
+	rewards := mappingValue(mappingValue(m.root, "training"), "rewards")
+	if rewards == nil {
+		return nil, nil
+	}
+
+	switch rewards.Kind {
+	case yaml.ScalarNode:
+		if rewards.Value == "uniform" {
+			return nil, nil
+		}
+	case yaml.SequenceNode:
+		names := make([]string, 0, len(rewards.Content))
+		for _, reward := range rewards.Content {
+			name := mappingValue(reward, "reward")
+			if name == nil {
+				return nil, errors.New("rewards section malformed: each reward must name an action with 'reward'")
+			}
+			names = append(names, name.Value)
+		}
+		return names, nil
+	}
+
+	return nil, errors.New("rewards section malformed: 'rewards' must be either 'uniform' or an array of rewards")
+}
+
+// Appends the rewards to the manifest's rewards, replacing a 'uniform' rewards value
+func (m *Manifest) AddRewards(rewardSpecs ...spec.RewardSpec) error {
+	if _, err := m.RewardNames(); err != nil {
+		return err
+	}
+
+	training, err := ensureMappingValue(m.root, "training", yaml.MappingNode)
+	if err != nil {
+		return err
+	}
+
+	rewards := mappingValue(training, "rewards")
+	if rewards == nil || rewards.Kind != yaml.SequenceNode {
+		rewards = setMappingValue(training, "rewards", &yaml.Node{Kind: yaml.SequenceNode})
+	}
+
+	for _, reward := range rewardSpecs {
+		node, err := encodeNode(reward)
+		if err != nil {
+			return err
+		}
+		rewards.Content = append(rewards.Content, node)
+	}
+
+	return nil
+}
+
+func (m *Manifest) dataspace(from string, name string) *yaml.Node {
+	dataspaces := mappingValue(m.root, "dataspaces")
+	if dataspaces == nil || dataspaces.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for _, dataspace := range dataspaces.Content {
+		dsFrom, dsName := mappingValue(dataspace, "from"), mappingValue(dataspace, "name")
+		if dsFrom != nil && dsName != nil && dsFrom.Value == from && dsName.Value == name {
+			return dataspace
+		}
+	}
+	return nil
+}
+
+// Encodes v as a node, with multi-line strings as literal blocks like hand-written manifests
+func encodeNode(v interface{}) (*yaml.Node, error) {
+	var node yaml.Node
+	err := node.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	setLiteralStyle(&node)
+	return &node, nil
+}
+
+func setLiteralStyle(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		setLiteralStyle(child)
+	}
+}
+
+// Returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Sets the value of key in a mapping node and returns the node now in the mapping
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			replaceNode(node.Content[i+1], value)
+			return node.Content[i+1]
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// Replaces the node's content in place, keeping the comments attached to it
+func replaceNode(node *yaml.Node, replacement *yaml.Node) {
+	headComment, lineComment, footComment := node.HeadComment, node.LineComment, node.FootComment
+	*node = *replacement
+	node.HeadComment, node.LineComment, node.FootComment = headComment, lineComment, footComment
+}
+
+// Returns the value of key, adding an empty node of kind if it is missing or null
+func ensureMappingValue(node *yaml.Node, key string, kind yaml.Kind) (*yaml.Node, error) {
+	value := mappingValue(node, key)
+	if value == nil || value.Tag == "!!null" {
+		value = setMappingValue(node, key, &yaml.Node{Kind: kind})
+	}
+	if value.Kind != kind {
+		return nil, fmt.Errorf("'%s' section malformed", key)
+	}
+	return value, nil
+}
+
+// Returns the index of the sequence item whose key is value, or -1
+func findSequenceItem(sequence *yaml.Node, key string, value string) int {
+	if sequence == nil || sequence.Kind != yaml.SequenceNode {
+		return -1
+	}
+	for i, item := range sequence.Content {
+		if itemValue := mappingValue(item, key); itemValue != nil && itemValue.Value == value {
+			return i
+		}
+	}
+	return -1
+}
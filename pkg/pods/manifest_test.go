@@ -0,0 +1,238 @@
+package pods
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiceai/spiceai/pkg/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+const manifestTestYaml = `# Trades bitcoin
+name: trader
+params:
+  epoch_time: ${TRADER_EPOCH} # set by the environment
+dataspaces:
+  - from: local
+    name: portfolio
+    measurements:
+      - name: usd_balance
+actions:
+  # Actions are listed in the order they were added
+  - name: buy
+  - name: sell
+training:
+  rewards: uniform
+`
+
+func TestManifest(t *testing.T) {
+	t.Run("SetAction() - Actions are added and replaced keeping comments", testManifestSetActionFunc())
+	t.Run("AddDataspace() - Dataspaces are appended", testManifestAddDataspaceFunc())
+	t.Run("SetDataspaceAction() - Dataspace actions are added", testManifestSetDataspaceActionFunc())
+	t.Run("AddRewards() - Uniform rewards are replaced", testManifestAddRewardsFunc())
+	t.Run("RewardNames() - Malformed rewards are rejected", testManifestRewardNamesMalformedFunc())
+	t.Run("Save() - Manifest is written back to its file", testManifestSaveFunc())
+}
+
+func testManifestSetActionFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		manifest, err := ParseManifest([]byte(manifestTestYaml))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.True(t, manifest.HasAction("buy"))
+		assert.False(t, manifest.HasAction("hold"))
+
+		err = manifest.SetAction(spec.PodActionSpec{Name: "hold"})
+		assert.NoError(t, err)
+		err = manifest.SetAction(spec.PodActionSpec{Name: "buy", Do: &spec.DoSpec{Name: "local.portfolio.buy", Args: map[string]string{"price": "coinbase.btcusd.close"}}})
+		assert.NoError(t, err)
+
+		data, err := manifest.Bytes()
+		assert.NoError(t, err)
+		assert.Equal(t, `# Trades bitcoin
+name: trader
+params:
+  epoch_time: ${TRADER_EPOCH} # set by the environment
+dataspaces:
+  - from: local
+    name: portfolio
+    measurements:
+      - name: usd_balance
+actions:
+  # Actions are listed in the order they were added
+  - name: buy
+    do:
+      name: local.portfolio.buy
+      args:
+        price: coinbase.btcusd.close
+  - name: sell
+  - name: hold
+training:
+  rewards: uniform
+`, string(data))
+	}
+}
+
+func testManifestAddDataspaceFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		manifest, err := ParseManifest([]byte(manifestTestYaml))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.True(t, manifest.HasDataspace("local", "portfolio"))
+		assert.False(t, manifest.HasDataspace("coinbase", "btcusd"))
+
+		err = manifest.AddDataspace(spec.DataspaceSpec{
+			From: "coinbase",
+			Name: "btcusd",
+			Data: &spec.DataSpec{
+				Connector: spec.DataConnectorSpec{Name: "coinbase", Params: map[string]string{"product_ids": "BTC-USD"}},
+				Processor: spec.DataProcessorSpec{Name: "json"},
+			},
+			Measurements: []spec.MeasurementSpec{{Name: "close", Selector: "price", Fill: "none"}},
+		})
+		assert.NoError(t, err)
+		assert.True(t, manifest.HasDataspace("coinbase", "btcusd"))
+
+		err = manifest.AddDataspace(spec.DataspaceSpec{From: "local", Name: "portfolio"})
+		assert.EqualError(t, err, "dataspace local/portfolio already exists")
+
+		data, err := manifest.Bytes()
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `dataspaces:
+  - from: local
+    name: portfolio
+    measurements:
+      - name: usd_balance
+  - from: coinbase
+    name: btcusd
+    data:
+      connector:
+        name: coinbase
+        params:
+          product_ids: BTC-USD
+      processor:
+        name: json
+    measurements:
+      - name: close
+        selector: price
+        fill: none
+actions:
+  # Actions are listed in the order they were added
+`)
+
+		manifest, err = ParseManifest([]byte("name: trader\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, manifest.AddDataspace(spec.DataspaceSpec{From: "local", Name: "portfolio"}))
+		data, err = manifest.Bytes()
+		assert.NoError(t, err)
+		assert.Equal(t, "name: trader\ndataspaces:\n  - from: local\n    name: portfolio\n", string(data))
+	}
+}
+
+func testManifestSetDataspaceActionFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		manifest, err := ParseManifest([]byte(manifestTestYaml))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = manifest.SetDataspaceAction("local", "portfolio", "buy", "usd_balance -= args.price\nbtc_balance += 1\n")
+		assert.NoError(t, err)
+		assert.True(t, manifest.HasDataspaceAction("local", "portfolio", "buy"))
+		assert.False(t, manifest.HasDataspaceAction("local", "portfolio", "sell"))
+
+		err = manifest.SetDataspaceAction("coinbase", "btcusd", "buy", "")
+		assert.EqualError(t, err, "dataspace coinbase/btcusd not found")
+
+		data, err := manifest.Bytes()
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `    measurements:
+      - name: usd_balance
+    actions:
+      buy: |
+        usd_balance -= args.price
+        btc_balance += 1
+actions:`)
+	}
+}
+
+func testManifestAddRewardsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		manifest, err := ParseManifest([]byte(manifestTestYaml))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names, err := manifest.RewardNames()
+		assert.NoError(t, err)
+		assert.Empty(t, names)
+
+		err = manifest.AddRewards(spec.RewardSpec{Reward: "buy", With: "reward = 1"})
+		assert.NoError(t, err)
+		err = manifest.AddRewards(spec.RewardSpec{Reward: "sell", With: "a = 2\nreward = a\n"})
+		assert.NoError(t, err)
+
+		names, err = manifest.RewardNames()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"buy", "sell"}, names)
+
+		data, err := manifest.Bytes()
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `training:
+  rewards:
+    - reward: buy
+      with: reward = 1
+    - reward: sell
+      with: |
+        a = 2
+        reward = a
+`)
+	}
+}
+
+func testManifestRewardNamesMalformedFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		manifest, err := ParseManifest([]byte("name: trader\ntraining:\n  rewards: random\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = manifest.RewardNames()
+		assert.Error(t, err)
+		assert.Error(t, manifest.AddRewards(spec.RewardSpec{Reward: "buy", With: "reward = 1"}))
+
+		_, err = ParseManifest([]byte("- name: trader\n"))
+		assert.Error(t, err)
+	}
+}
+
+func testManifestSaveFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		manifestPath := filepath.Join(t.TempDir(), "trader.yaml")
+		err := os.WriteFile(manifestPath, []byte(manifestTestYaml), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		manifest, err := LoadManifest(manifestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = manifest.SetAction(spec.PodActionSpec{Name: "hold"})
+		assert.NoError(t, err)
+		assert.NoError(t, manifest.Save())
+
+		data, err := os.ReadFile(manifestPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "# Trades bitcoin\n")
+		assert.Contains(t, string(data), "  - name: hold\n")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/spiceai/spiceai/pkg/flights"
 	"github.com/spiceai/spiceai/pkg/interpretations"
 	"github.com/spiceai/spiceai/pkg/observations"
+	"github.com/spiceai/spiceai/pkg/quotas"
 	"github.com/spiceai/spiceai/pkg/spec"
 	"github.com/spiceai/spiceai/pkg/state"
 	"github.com/spiceai/spiceai/pkg/tempdir"
@@ -28,6 +29,15 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+const (
+	// Loaded but never trained
+	LifecycleLoaded = "loaded"
+	// Has a training run queued or in progress
+	LifecycleTraining = "training"
+	// Has completed at least one training run
+	LifecycleTrained = "trained"
+)
+
 type Pod struct {
 	spec.PodSpec
 	viper        *viper.Viper
@@ -48,15 +58,24 @@ type Pod struct {
 	tags                []string
 	externalRewardFuncs string
 
-	flights map[string]*flights.Flight
-	logDir  string
+	flightsMutex     sync.RWMutex
+	flights          map[string]*flights.Flight
+	pendingTrainings int
+	logDir           string
 
 	podLocalStateMutex    sync.RWMutex
 	podLocalState         []*state.State
 	podLocalStateHandlers []state.StateHandler
 
+	// Counts observations cached by the pod and its dataspaces for the max_cached_observations quota
+	observationsMutex     sync.Mutex
+	podLocalObservations  int
+	dataspaceObservations int
+
 	interpretations *interpretations.InterpretationsStore
 
+	quotas *quotas.Quotas
+
 	fqCsvHeaders string
 }
 
@@ -99,6 +118,13 @@ func (pod *Pod) TrainingLoggers() []string {
 	return nil
 }
 
+func (pod *Pod) TrainingPriority() int {
+	if pod.PodSpec.Training != nil {
+		return pod.PodSpec.Training.Priority
+	}
+	return 0
+}
+
 func (pod *Pod) TimeCategories() map[string][]spice_time.TimeCategoryInfo {
 	return pod.timeCategories
 }
@@ -136,18 +162,21 @@ func (pod *Pod) CachedState() []*state.State {
 		}
 	}
 
-	if pod.podLocalState != nil {
-		pod.podLocalStateMutex.RLock()
-		defer pod.podLocalStateMutex.RUnlock()
-		if pod.podLocalState != nil && len(pod.podLocalState) > 0 {
-			cachedState = append(cachedState, pod.podLocalState...)
-		}
+	pod.podLocalStateMutex.RLock()
+	defer pod.podLocalStateMutex.RUnlock()
+	if len(pod.podLocalState) > 0 {
+		cachedState = append(cachedState, pod.podLocalState...)
 	}
 
 	return cachedState
 }
 
 func (pod *Pod) CachedCsv() string {
+	return pod.CachedCsvBetween(time.Time{}, time.Time{})
+}
+
+// Returns cached observations from start up to but excluding end. Zero times leave that side unbounded.
+func (pod *Pod) CachedCsvBetween(start time.Time, end time.Time) string {
 	csv := strings.Builder{}
 
 	csv.WriteString(pod.csvHeaders())
@@ -202,7 +231,8 @@ func (pod *Pod) CachedCsv() string {
 			}
 		}
 
-		stateCsv := observations.GetCsv(validHeaders, pod.Tags(), state.Observations())
+		stateObservations := observations.Between(state.Observations(), start, end)
+		stateCsv := observations.GetCsv(validHeaders, pod.Tags(), stateObservations)
 		csv.WriteString(stateCsv)
 	}
 	return csv.String()
@@ -212,8 +242,16 @@ func (pod *Pod) Dataspaces() []*dataspace.Dataspace {
 	return pod.dataspaces
 }
 
-func (pod *Pod) Flights() *map[string]*flights.Flight {
-	return &pod.flights
+// Returns a copy of the pod's flights
+func (pod *Pod) Flights() map[string]*flights.Flight {
+	pod.flightsMutex.RLock()
+	defer pod.flightsMutex.RUnlock()
+
+	podFlights := make(map[string]*flights.Flight, len(pod.flights))
+	for id, flight := range pod.flights {
+		podFlights[id] = flight
+	}
+	return podFlights
 }
 
 func (pod *Pod) Interpretations() *interpretations.InterpretationsStore {
@@ -225,6 +263,9 @@ func (pod *Pod) GetDataspace(path string) *dataspace.Dataspace {
 }
 
 func (pod *Pod) GetFlight(flight string) *flights.Flight {
+	pod.flightsMutex.RLock()
+	defer pod.flightsMutex.RUnlock()
+
 	f, ok := pod.flights[flight]
 	if ok {
 		return f
@@ -233,9 +274,78 @@ func (pod *Pod) GetFlight(flight string) *flights.Flight {
 }
 
 func (pod *Pod) AddFlight(flightId string, flight *flights.Flight) {
+	pod.flightsMutex.Lock()
+	defer pod.flightsMutex.Unlock()
+
 	pod.flights[flightId] = flight
 }
 
+// Returns the most recently started flight, or nil if the pod has never trained
+func (pod *Pod) LatestFlight() *flights.Flight {
+	pod.flightsMutex.RLock()
+	defer pod.flightsMutex.RUnlock()
+
+	var latest *flights.Flight
+	for _, flight := range pod.flights {
+		if latest == nil || flight.Start().After(latest.Start()) {
+			latest = flight
+		}
+	}
+	return latest
+}
+
+func (pod *Pod) Lifecycle() string {
+	pod.flightsMutex.RLock()
+	defer pod.flightsMutex.RUnlock()
+
+	if pod.activeTrainings() > 0 {
+		return LifecycleTraining
+	}
+	if len(pod.flights) > 0 {
+		return LifecycleTrained
+	}
+	return LifecycleLoaded
+}
+
+// Returns the time of the newest cached observation, or the zero time if there are none
+func (pod *Pod) LatestObservationTime() time.Time {
+	var latest int64
+	for _, s := range pod.CachedState() {
+		for _, o := range s.Observations() {
+			if o.Time > latest {
+				latest = o.Time
+			}
+		}
+	}
+	if latest == 0 {
+		return time.Time{}
+	}
+	return time.Unix(latest, 0)
+}
+
+// Reserves a training slot, unless it would exceed the pod's max_concurrent_trainings quota.
+// The slot is held until ReleaseTraining is called, by which time the flight should have been added.
+func (pod *Pod) ReserveTraining() error {
+	pod.flightsMutex.Lock()
+	defer pod.flightsMutex.Unlock()
+
+	if err := pod.quotas.CheckConcurrentTrainings(pod.activeTrainings()); err != nil {
+		return err
+	}
+
+	pod.pendingTrainings++
+	return nil
+}
+
+func (pod *Pod) ReleaseTraining() {
+	pod.flightsMutex.Lock()
+	defer pod.flightsMutex.Unlock()
+
+	if pod.pendingTrainings > 0 {
+		pod.pendingTrainings--
+	}
+}
+
 func (pod *Pod) Actions() map[string]string {
 	return pod.actions
 }
@@ -263,6 +373,33 @@ func (pod *Pod) ExternalRewardFuncs() string {
 	return pod.externalRewardFuncs
 }
 
+// Returns the files the manifest refers to, as written in the manifest: the external reward functions and the
+// paths of file connectors, without duplicates
+func (pod *Pod) ReferencedFiles() []string {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	if pod.PodSpec.Training != nil {
+		add(pod.PodSpec.Training.RewardFuncs)
+	}
+
+	for _, ds := range pod.PodSpec.Dataspaces {
+		for _, dataSpec := range []*spec.DataSpec{ds.SeedData, ds.Data} {
+			if dataSpec != nil && dataSpec.Connector.Name == "file" {
+				add(dataSpec.Connector.Params["path"])
+			}
+		}
+	}
+
+	return files
+}
+
 func (pod *Pod) Rewards() map[string]string {
 	rewards := make(map[string]string)
 
@@ -314,6 +451,16 @@ func (pod *Pod) Tags() []string {
 
 func (pod *Pod) GetLogDir() (string, error) {
 	if pod.logDir == "" {
+		if pod.podParams.DataDir != "" {
+			// Flight ids restart with the runtime, so each start gets its own directory
+			logDir := filepath.Join(pod.podParams.DataDir, "pods", pod.Name, time.Now().Format("20060102T150405"))
+			if err := os.MkdirAll(logDir, 0755); err != nil {
+				return "", err
+			}
+			pod.logDir = logDir
+			return pod.logDir, nil
+		}
+
 		logDir, err := tempdir.CreateTempDir(pod.Name)
 		if err != nil {
 			return "", err
@@ -393,15 +540,79 @@ func (pod *Pod) ValidateForTraining() error {
 	return nil
 }
 
-func (pod *Pod) AddLocalState(newState ...*state.State) {
+// Adds state posted to the pod, unless it would exceed the pod's max_cached_observations quota
+func (pod *Pod) AddLocalState(newState ...*state.State) error {
+	numObservations := 0
+	for _, s := range newState {
+		numObservations += len(s.Observations())
+	}
+
 	pod.podLocalStateMutex.Lock()
 	defer pod.podLocalStateMutex.Unlock()
 
+	pod.observationsMutex.Lock()
+	defer pod.observationsMutex.Unlock()
+
+	cached := pod.podLocalObservations + pod.dataspaceObservations
+	if err := pod.quotas.CheckCachedObservations(cached, numObservations); err != nil {
+		return err
+	}
+
 	pod.podLocalState = append(pod.podLocalState, newState...)
+	pod.podLocalObservations += numObservations
+
+	return nil
+}
+
+// Counts observations added to a dataspace towards the pod's max_cached_observations quota
+func (pod *Pod) reserveDataspaceObservations(numObservations int) error {
+	pod.observationsMutex.Lock()
+	defer pod.observationsMutex.Unlock()
+
+	cached := pod.podLocalObservations + pod.dataspaceObservations
+	if err := pod.quotas.CheckCachedObservations(cached, numObservations); err != nil {
+		return err
+	}
+
+	pod.dataspaceObservations += numObservations
+	return nil
+}
+
+// Returns the number of observations cached by the pod and its dataspaces
+func (pod *Pod) CachedObservations() int {
+	pod.observationsMutex.Lock()
+	defer pod.observationsMutex.Unlock()
+
+	return pod.podLocalObservations + pod.dataspaceObservations
+}
+
+func (pod *Pod) Quotas() *quotas.Quotas {
+	return pod.quotas
+}
+
+// Returns the number of training runs that have not completed, including those still starting
+func (pod *Pod) ActiveTrainings() int {
+	pod.flightsMutex.RLock()
+	defer pod.flightsMutex.RUnlock()
+
+	return pod.activeTrainings()
+}
+
+func (pod *Pod) activeTrainings() int {
+	active := pod.pendingTrainings
+	for _, flight := range pod.flights {
+		if !flight.IsComplete() {
+			active++
+		}
+	}
+	return active
 }
 
 func (pod *Pod) State() []*state.State {
-	return pod.podLocalState
+	pod.podLocalStateMutex.RLock()
+	defer pod.podLocalStateMutex.RUnlock()
+
+	return pod.podLocalState[:len(pod.podLocalState):len(pod.podLocalState)]
 }
 
 func (pod *Pod) LearningAlgorithm() string {
@@ -457,13 +668,13 @@ func unmarshalPod(podPath string) (*Pod, error) {
 	return pod, nil
 }
 
-func loadPod(podPath string, hash string) (*Pod, error) {
+func loadPod(podPath string, hash string, defaultParams *PodParams) (*Pod, error) {
 	pod, err := unmarshalPod(podPath)
 	if err != nil {
 		return nil, err
 	}
 
-	err = pod.loadParams()
+	err = pod.loadParams(defaultParams)
 	if err != nil {
 		return nil, fmt.Errorf("error loading pod params: %s", err.Error())
 	}
@@ -495,11 +706,16 @@ func loadPod(podPath string, hash string) (*Pod, error) {
 	measurements := make(map[string]*dataspace.MeasurementInfo)
 	dataspaceMap := make(map[string]*dataspace.Dataspace, len(pod.PodSpec.Dataspaces))
 
+	podQuotas := quotas.Merge(pod.podParams.Quotas, pod.PodSpec.Quotas)
+	pod.quotas = quotas.NewQuotas(podQuotas)
+
 	for _, dsSpec := range pod.PodSpec.Dataspaces {
 		ds, err := dataspace.NewDataspace(dsSpec)
 		if err != nil {
 			return nil, err
 		}
+		dsQuotas := quotas.Merge(quotas.DataspaceLimits(podQuotas), dsSpec.Quotas)
+		ds.SetQuotas(quotas.NewQuotas(quotas.DataspaceLimits(dsQuotas)), pod.reserveDataspaceObservations)
 		pod.dataspaces = append(pod.dataspaces, ds)
 		dataspaceMap[ds.Path()] = ds
 
@@ -572,8 +788,8 @@ func (pod *Pod) IsSame(otherPod *Pod) bool {
 	return otherPod != nil && pod.manifestPath == otherPod.manifestPath && pod.Hash() == otherPod.Hash()
 }
 
-func (pod *Pod) loadParams() error {
-	podParams := NewPodParams()
+func (pod *Pod) loadParams(defaultParams *PodParams) error {
+	podParams := *defaultParams
 
 	if pod.PodSpec.Params != nil {
 		str, ok := pod.PodSpec.Params["epoch_time"]
@@ -622,7 +838,7 @@ func (pod *Pod) loadParams() error {
 		}
 	}
 
-	pod.podParams = podParams
+	pod.podParams = &podParams
 
 	return nil
 }
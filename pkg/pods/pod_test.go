@@ -9,6 +9,8 @@ import (
 
 	"github.com/bradleyjkemp/cupaloy"
 	"github.com/spiceai/data-components-contrib/dataconnectors/file"
+	"github.com/spiceai/spiceai/pkg/observations"
+	"github.com/spiceai/spiceai/pkg/quotas"
 	"github.com/spiceai/spiceai/pkg/spec"
 	"github.com/spiceai/spiceai/pkg/state"
 	"github.com/stretchr/testify/assert"
@@ -363,7 +365,8 @@ func testAddLocalStateFunc(pod *Pod) func(*testing.T) {
 			t.Error(err)
 		}
 
-		pod.AddLocalState(newState...)
+		err = pod.AddLocalState(newState...)
+		assert.NoError(t, err)
 	}
 }
 
@@ -424,6 +427,24 @@ func testAddLocalStateCachedCsvFunc(pod *Pod) func(*testing.T) {
 	}
 }
 
+// Tests ReferencedFiles()
+func TestReferencedFiles(t *testing.T) {
+	t.Run("ReferencedFiles() - seed and data files", testReferencedFilesFunc("trader-seed.yaml", []string{"../../test/assets/data/csv/COINBASE_BTCUSD, 30.csv"}))
+	t.Run("ReferencedFiles() - reward functions", testReferencedFilesFunc("trader-external-reward.yaml", []string{"../../test/assets/rewards/trader_rewards.py", "../../test/assets/data/btcusd.csv"}))
+	t.Run("ReferencedFiles() - non-file connectors", testReferencedFilesFunc("logpruner.yaml", nil))
+}
+
+func testReferencedFilesFunc(manifest string, expected []string) func(*testing.T) {
+	return func(t *testing.T) {
+		pod, err := LoadPodFromManifest(filepath.Join("../../test/assets/pods/manifests", manifest))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, expected, pod.ReferencedFiles())
+	}
+}
+
 // Tests loadParams()
 func TestLoadParams(t *testing.T) {
 	t.Run("loadParams() - defaults", testLoadParamsDefaultsFunc())
@@ -435,7 +456,7 @@ func testLoadParamsDefaultsFunc() func(*testing.T) {
 		pod := &Pod{
 			PodSpec: spec.PodSpec{},
 		}
-		err := pod.loadParams()
+		err := pod.loadParams(NewPodParams())
 		assert.NoError(t, err)
 
 		assert.Equal(t, time.Now().Add(-pod.Period()).Unix()/10, pod.Epoch().Unix()/10)
@@ -457,7 +478,7 @@ func testLoadParamsFunc() func(*testing.T) {
 				},
 			},
 		}
-		err := pod.loadParams()
+		err := pod.loadParams(NewPodParams())
 		assert.NoError(t, err)
 
 		assert.Equal(t, int64(123456789), pod.Epoch().Unix())
@@ -466,3 +487,93 @@ func testLoadParamsFunc() func(*testing.T) {
 		assert.Equal(t, 124*time.Second, pod.Granularity())
 	}
 }
+
+// Tests quotas loaded from the manifest
+func TestPodQuotas(t *testing.T) {
+	pod, err := LoadPodFromManifest("../../test/assets/pods/manifests/trader-quotas.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Quotas() - manifest limits", testQuotasLimitsFunc(pod))
+	t.Run("AddLocalState() - max_cached_observations", testAddLocalStateQuotaFunc(pod))
+	t.Run("Dataspace AddNewState() - pod-wide max_cached_observations", testDataspaceAddNewStateQuotaFunc(pod))
+	t.Run("ReserveTraining() - max_concurrent_trainings", testReserveTrainingFunc(pod))
+}
+
+func testQuotasLimitsFunc(pod *Pod) func(*testing.T) {
+	return func(t *testing.T) {
+		assert.Equal(t, spec.QuotasSpec{
+			MaxCachedObservations:  5,
+			MaxInterpretations:     1,
+			MaxConcurrentTrainings: 1,
+		}, pod.Quotas().Limits())
+
+		// Dataspaces only take the pod's dataspace-level limits
+		ds := pod.Dataspaces()[0]
+		assert.Equal(t, spec.QuotasSpec{
+			MaxCachedObservations: 3,
+			MaxIngestRate:         2,
+		}, ds.Quotas().Limits())
+
+		ds = pod.Dataspaces()[1]
+		assert.Equal(t, spec.QuotasSpec{
+			MaxCachedObservations: 5,
+		}, ds.Quotas().Limits())
+	}
+}
+
+func testAddLocalStateQuotaFunc(pod *Pod) func(*testing.T) {
+	return func(t *testing.T) {
+		newObservations := make([]observations.Observation, 3)
+		for i := range newObservations {
+			newObservations[i] = observations.Observation{
+				Time:         int64(1605312000 + i),
+				Measurements: map[string]float64{"usd_balance": float64(i)},
+			}
+		}
+		newState := state.NewState("local.portfolio", nil, []string{"usd_balance"}, nil, nil, newObservations)
+
+		assert.NoError(t, pod.AddLocalState(newState))
+		assert.Equal(t, 3, pod.CachedObservations())
+
+		err := pod.AddLocalState(newState)
+		assert.EqualError(t, err, "quota max_cached_observations exceeded: adding 3 observations to 3 cached would exceed the limit of 5")
+		assert.Equal(t, 3, pod.CachedObservations())
+		assert.Equal(t, uint64(1), pod.Quotas().Rejections()[quotas.CachedObservations])
+	}
+}
+
+func testDataspaceAddNewStateQuotaFunc(pod *Pod) func(*testing.T) {
+	return func(t *testing.T) {
+		newObservations := []observations.Observation{
+			{Time: 1605312000, Measurements: map[string]float64{"close": 1}},
+			{Time: 1605312001, Measurements: map[string]float64{"close": 2}},
+		}
+		newState := state.NewState("coinbase.btcusd", nil, []string{"close"}, nil, nil, newObservations)
+
+		// Within the dataspace's own limit of 3, but the pod already caches 3 of its 5
+		ds := pod.Dataspaces()[0]
+		assert.NoError(t, ds.AddNewState(newState, nil))
+		assert.Equal(t, 5, pod.CachedObservations())
+
+		otherState := state.NewState("coinbase.ethusd", nil, []string{"close"}, nil, nil, newObservations)
+		err := pod.Dataspaces()[1].AddNewState(otherState, nil)
+		assert.EqualError(t, err, "quota max_cached_observations exceeded: adding 2 observations to 5 cached would exceed the limit of 5")
+		assert.Equal(t, 0, pod.Dataspaces()[1].CachedObservations())
+		assert.Equal(t, 5, pod.CachedObservations())
+	}
+}
+
+func testReserveTrainingFunc(pod *Pod) func(*testing.T) {
+	return func(t *testing.T) {
+		assert.NoError(t, pod.ReserveTraining())
+		assert.Equal(t, 1, pod.ActiveTrainings())
+		assert.Error(t, pod.ReserveTraining())
+
+		pod.ReleaseTraining()
+		assert.Equal(t, 0, pod.ActiveTrainings())
+		assert.NoError(t, pod.ReserveTraining())
+		pod.ReleaseTraining()
+	}
+}
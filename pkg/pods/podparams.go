@@ -1,6 +1,10 @@
 package pods
 
-import "time"
+import (
+	"time"
+
+	"github.com/spiceai/spiceai/pkg/spec"
+)
 
 type PodParams struct {
 	Epoch             time.Time
@@ -9,6 +13,10 @@ type PodParams struct {
 	Granularity       time.Duration
 	LearningAlgorithm string
 	Interpolation     bool
+	// Default quotas, overridden by the manifest
+	Quotas spec.QuotasSpec
+	// When set, training logs are kept here instead of a temp directory
+	DataDir string
 }
 
 func NewPodParams() *PodParams {
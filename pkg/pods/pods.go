@@ -7,44 +7,247 @@ import (
 	"path/filepath"
 	"sync"
 
-	"github.com/logrusorgru/aurora"
-	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/tempdir"
 	"github.com/spiceai/spiceai/pkg/util"
 )
 
-var (
-	podsMutex sync.RWMutex
-	pods      = make(map[string]*Pod)
+type PodEventType int
+
+const (
+	PodAdded PodEventType = iota
+	PodUpdated
+	PodRemoved
+	PodInterpretationsChanged
 )
 
-func CreateOrUpdatePod(pod *Pod) {
-	podsMutex.Lock()
-	defer podsMutex.Unlock()
+func (t PodEventType) String() string {
+	switch t {
+	case PodAdded:
+		return "added"
+	case PodUpdated:
+		return "updated"
+	case PodRemoved:
+		return "removed"
+	case PodInterpretationsChanged:
+		return "interpretations changed"
+	}
+	return "unknown"
+}
+
+// PodEvent describes a change to the set of pods in a Registry, or to the interpretations of a loaded pod.
+// Previous is the pod that was replaced or removed, and is nil for PodAdded and PodInterpretationsChanged.
+type PodEvent struct {
+	Type     PodEventType
+	Pod      *Pod
+	Previous *Pod
+}
+
+type PodWatcher func(event PodEvent)
+
+// Registry holds the set of pods loaded into a runtime.
+// The set is copy-on-write: every change replaces the internal map, so snapshots
+// returned by Pods() are never mutated and can be iterated without locking.
+type Registry struct {
+	podsMutex     sync.RWMutex
+	pods          map[string]*Pod
+	defaultParams *PodParams
+
+	// Serializes notifications so watchers observe events in the order they were applied
+	notifyMutex   sync.Mutex
+	watchersMutex sync.RWMutex
+	watchers      map[int]PodWatcher
+	nextWatcherId int
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		pods:          make(map[string]*Pod),
+		defaultParams: NewPodParams(),
+		watchers:      make(map[int]PodWatcher),
+	}
+}
+
+// Sets the params used by pods loaded through the registry for values their manifest doesn't set
+func (r *Registry) SetDefaultParams(params *PodParams) {
+	r.podsMutex.Lock()
+	defer r.podsMutex.Unlock()
+
+	r.defaultParams = params
+}
+
+// Adds or replaces the pod with the same name
+func (r *Registry) CreateOrUpdatePod(pod *Pod) {
+	r.notifyMutex.Lock()
+	defer r.notifyMutex.Unlock()
+
+	r.podsMutex.Lock()
+	previous := r.pods[pod.Name]
+	r.replace(func(pods map[string]*Pod) {
+		pods[pod.Name] = pod
+	})
+	r.podsMutex.Unlock()
+
+	if previous == nil {
+		r.notify(PodEvent{Type: PodAdded, Pod: pod})
+	} else if previous != pod {
+		r.notify(PodEvent{Type: PodUpdated, Pod: pod, Previous: previous})
+	}
+}
+
+// Atomically replaces the loaded pod with the same name unless it was loaded from an identical manifest.
+// Returns the pod now in the registry and whether it changed.
+func (r *Registry) ReplacePod(pod *Pod) (*Pod, bool) {
+	r.notifyMutex.Lock()
+	defer r.notifyMutex.Unlock()
+
+	r.podsMutex.Lock()
+	previous := r.pods[pod.Name]
+	if previous != nil && previous.IsSame(pod) {
+		r.podsMutex.Unlock()
+		return previous, false
+	}
+	r.replace(func(pods map[string]*Pod) {
+		pods[pod.Name] = pod
+	})
+	r.podsMutex.Unlock()
+
+	if previous == nil {
+		r.notify(PodEvent{Type: PodAdded, Pod: pod})
+	} else {
+		r.notify(PodEvent{Type: PodUpdated, Pod: pod, Previous: previous})
+	}
+
+	return pod, true
+}
+
+// Returns a snapshot of the loaded pods keyed by name. The snapshot must not be modified.
+func (r *Registry) Pods() map[string]*Pod {
+	r.podsMutex.RLock()
+	defer r.podsMutex.RUnlock()
+
+	return r.pods
+}
+
+func (r *Registry) GetPod(name string) *Pod {
+	r.podsMutex.RLock()
+	defer r.podsMutex.RUnlock()
+
+	return r.pods[name]
+}
+
+// Removes the pod with the given name, returning it or nil if no such pod is loaded
+func (r *Registry) RemovePod(name string) *Pod {
+	return r.removeWhere(func(pod *Pod) bool {
+		return pod.Name == name
+	})
+}
+
+// Removes the pod loaded from manifestPath, returning it or nil if no such pod is loaded
+func (r *Registry) RemovePodByManifestPath(manifestPath string) *Pod {
+	return r.removeWhere(func(pod *Pod) bool {
+		return pod.ManifestPath() == manifestPath
+	})
+}
+
+// Registers a watcher called after every add, update or remove.
+// Watchers are called synchronously and in order, so they must not modify the registry.
+// Returns a function that unregisters the watcher.
+func (r *Registry) Watch(watcher PodWatcher) func() {
+	r.watchersMutex.Lock()
+	defer r.watchersMutex.Unlock()
 
-	pods[pod.Name] = pod
+	id := r.nextWatcherId
+	r.nextWatcherId++
+	r.watchers[id] = watcher
+
+	return func() {
+		r.watchersMutex.Lock()
+		defer r.watchersMutex.Unlock()
+
+		delete(r.watchers, id)
+	}
+}
+
+// Loads the pod at manifestPath, returning the already loaded pod if the manifest is unchanged
+func (r *Registry) LoadPodFromManifest(manifestPath string) (*Pod, error) {
+	r.podsMutex.RLock()
+	defaultParams := r.defaultParams
+	r.podsMutex.RUnlock()
+
+	pod, err := LoadPodFromManifestWithDefaults(manifestPath, defaultParams)
+	if err != nil {
+		return nil, err
+	}
+
+	existingPod := r.GetPod(pod.Name)
+	if existingPod != nil && existingPod.IsSame(pod) {
+		// Pods are the same, ignore new pod
+		return existingPod, nil
+	}
+
+	return pod, nil
+}
+
+func (r *Registry) removeWhere(match func(pod *Pod) bool) *Pod {
+	r.notifyMutex.Lock()
+	defer r.notifyMutex.Unlock()
+
+	r.podsMutex.Lock()
+	var removed *Pod
+	for _, pod := range r.pods {
+		if match(pod) {
+			removed = pod
+			break
+		}
+	}
+	if removed != nil {
+		r.replace(func(pods map[string]*Pod) {
+			delete(pods, removed.Name)
+		})
+	}
+	r.podsMutex.Unlock()
+
+	if removed != nil {
+		r.notify(PodEvent{Type: PodRemoved, Pod: removed, Previous: removed})
+	}
+
+	return removed
 }
 
-func Pods() map[string]*Pod {
-	return pods
+// Applies mutate to a copy of the pods map and swaps it in. Must be called with podsMutex held.
+func (r *Registry) replace(mutate func(pods map[string]*Pod)) {
+	newPods := make(map[string]*Pod, len(r.pods)+1)
+	for name, pod := range r.pods {
+		newPods[name] = pod
+	}
+	mutate(newPods)
+	r.pods = newPods
 }
 
-func GetPod(name string) *Pod {
-	podsMutex.RLock()
-	defer podsMutex.RUnlock()
+// Notifies watchers that interpretations were added to or removed from pod, so they can be persisted
+func (r *Registry) NotifyInterpretationsChanged(pod *Pod) {
+	r.notifyMutex.Lock()
+	defer r.notifyMutex.Unlock()
 
-	return pods[name]
+	r.notify(PodEvent{Type: PodInterpretationsChanged, Pod: pod})
 }
 
-func RemovePod(name string) {
-	podsMutex.Lock()
-	defer podsMutex.Unlock()
+// Must be called with notifyMutex held
+func (r *Registry) notify(event PodEvent) {
+	r.watchersMutex.RLock()
+	watchers := make([]PodWatcher, 0, len(r.watchers))
+	for _, watcher := range r.watchers {
+		watchers = append(watchers, watcher)
+	}
+	r.watchersMutex.RUnlock()
 
-	delete(pods, name)
+	for _, watcher := range watchers {
+		watcher(event)
+	}
 }
 
-func FindPod(podName string) (*Pod, error) {
-	manifests := FindAllManifestPaths()
+func FindPod(podsDir string, podName string) (*Pod, error) {
+	manifests := FindAllManifestPaths(podsDir)
 
 	if len(manifests) == 0 {
 		return nil, fmt.Errorf("no pods detected")
@@ -70,23 +273,8 @@ func FindPod(podName string) (*Pod, error) {
 	return selectedPod, nil
 }
 
-func RemovePodByManifestPath(manifestPath string) {
-	relativePath := context.CurrentContext().GetSpiceAppRelativePath(manifestPath)
-	var podToDelete *Pod
-	for _, pod := range pods {
-		if pod.ManifestPath() == manifestPath {
-			podToDelete = pod
-			break
-		}
-	}
-
-	log.Printf("Removing pod %s: %s\n", aurora.Bold(podToDelete.Name), aurora.Gray(12, relativePath))
-	RemovePod(podToDelete.Name)
-}
-
-func FindAllManifestPaths() []string {
-	podsPath := context.CurrentContext().PodsDir()
-	files, err := os.ReadDir(podsPath)
+func FindAllManifestPaths(podsDir string) []string {
+	files, err := os.ReadDir(podsDir)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
@@ -96,7 +284,7 @@ func FindAllManifestPaths() []string {
 	for _, file := range files {
 		extension := filepath.Ext(file.Name())
 		if extension == ".yml" || extension == ".yaml" {
-			manifestPaths = append(manifestPaths, filepath.Join(podsPath, file.Name()))
+			manifestPaths = append(manifestPaths, filepath.Join(podsDir, file.Name()))
 		}
 	}
 
@@ -104,29 +292,26 @@ func FindAllManifestPaths() []string {
 }
 
 func LoadPodFromManifest(manifestPath string) (*Pod, error) {
+	return LoadPodFromManifestWithDefaults(manifestPath, NewPodParams())
+}
+
+// Loads the pod at manifestPath, using defaultParams for any params the manifest doesn't set
+func LoadPodFromManifestWithDefaults(manifestPath string, defaultParams *PodParams) (*Pod, error) {
 	manifestHash, err := util.ComputeFileHash(manifestPath)
 	if err != nil {
-		log.Printf("Error: Failed to compute hash for manifest '%s: %s\n", manifestPath, err)
 		return nil, err
 	}
 
-	pod, err := loadPod(manifestPath, manifestHash)
+	pod, err := loadPod(manifestPath, manifestHash, defaultParams)
 	if err != nil {
-		log.Printf("Error: Failed to load manifest '%s': %s\n", manifestPath, err)
 		return nil, err
 	}
 
-	existingPod, ok := pods[pod.Name]
-	if ok {
-		if existingPod.IsSame(pod) {
-			// Pods are the same, ignore new pod
-			return existingPod, nil
-		}
-	}
-
 	return pod, nil
 }
 
+// Extracts a pod archive to a temporary directory and loads its manifest.
+// The returned pod is not added to any registry.
 func ImportPod(podName string, archivePath string) (*Pod, error) {
 	tempDir, err := tempdir.CreateTempDir("import")
 	if err != nil {
@@ -143,7 +328,5 @@ func ImportPod(podName string, archivePath string) (*Pod, error) {
 		return nil, err
 	}
 
-	CreateOrUpdatePod(pod)
-
 	return pod, nil
 }
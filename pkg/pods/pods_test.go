@@ -0,0 +1,157 @@
+package pods
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("Pods() - Snapshots are not affected by later changes", testRegistrySnapshotFunc())
+	t.Run("ReplacePod() - Identical manifest is not replaced", testRegistryReplacePodFunc())
+	t.Run("Watch() - Watchers receive add, update and remove events", testRegistryWatchFunc())
+	t.Run("Concurrent reloads and reads", testRegistryConcurrencyFunc())
+}
+
+func testRegistrySnapshotFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		registry := NewRegistry()
+
+		trader := loadTestPod(t, "trader.yaml")
+		registry.CreateOrUpdatePod(trader)
+
+		snapshot := registry.Pods()
+		assert.Len(t, snapshot, 1)
+
+		registry.CreateOrUpdatePod(loadTestPod(t, "event-tags.yaml"))
+		registry.RemovePod(trader.Name)
+
+		assert.Len(t, snapshot, 1)
+		assert.Equal(t, trader, snapshot[trader.Name])
+
+		current := registry.Pods()
+		assert.Len(t, current, 1)
+		assert.Nil(t, current[trader.Name])
+		assert.NotNil(t, current["event-tags"])
+	}
+}
+
+func testRegistryReplacePodFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		registry := NewRegistry()
+
+		original := loadTestPod(t, "trader.yaml")
+		pod, changed := registry.ReplacePod(original)
+		assert.True(t, changed)
+		assert.Equal(t, original, pod)
+
+		pod, changed = registry.ReplacePod(loadTestPod(t, "trader.yaml"))
+		assert.False(t, changed)
+		assert.Same(t, original, pod)
+		assert.Same(t, original, registry.GetPod(original.Name))
+	}
+}
+
+func testRegistryWatchFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		registry := NewRegistry()
+
+		var events []PodEvent
+		unwatch := registry.Watch(func(event PodEvent) {
+			events = append(events, event)
+		})
+
+		first := loadTestPod(t, "trader.yaml")
+		second := loadTestPod(t, "trader.yaml")
+
+		registry.CreateOrUpdatePod(first)
+		registry.CreateOrUpdatePod(second)
+		removed := registry.RemovePodByManifestPath(first.ManifestPath())
+		assert.Same(t, second, removed)
+		assert.Nil(t, registry.RemovePod(first.Name))
+
+		if assert.Len(t, events, 3) {
+			assert.Equal(t, PodEvent{Type: PodAdded, Pod: first}, events[0])
+			assert.Equal(t, PodEvent{Type: PodUpdated, Pod: second, Previous: first}, events[1])
+			assert.Equal(t, PodEvent{Type: PodRemoved, Pod: second, Previous: second}, events[2])
+		}
+
+		unwatch()
+		registry.CreateOrUpdatePod(first)
+		assert.Len(t, events, 3)
+	}
+}
+
+func testRegistryConcurrencyFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		registry := NewRegistry()
+
+		manifests := []string{"trader.yaml", "event-tags.yaml", "event-categories.yaml"}
+		loadedPods := make([]*Pod, 0, len(manifests)*2)
+		for _, manifest := range manifests {
+			// Two distinct instances of each pod so reloads are real replacements
+			loadedPods = append(loadedPods, loadTestPod(t, manifest), loadTestPod(t, manifest))
+		}
+
+		var eventsMutex sync.Mutex
+		eventCounts := make(map[PodEventType]int)
+		registry.Watch(func(event PodEvent) {
+			eventsMutex.Lock()
+			defer eventsMutex.Unlock()
+			eventCounts[event.Type]++
+		})
+
+		const iterations = 200
+		var wg sync.WaitGroup
+
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < iterations; i++ {
+					pod := loadedPods[(w+i)%len(loadedPods)]
+					switch i % 3 {
+					case 0:
+						registry.CreateOrUpdatePod(pod)
+					case 1:
+						registry.ReplacePod(pod)
+					case 2:
+						registry.RemovePod(pod.Name)
+					}
+				}
+			}(w)
+		}
+
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < iterations; i++ {
+					for name, pod := range registry.Pods() {
+						assert.Equal(t, name, pod.Name)
+						_ = pod.ManifestPath()
+					}
+					_ = registry.GetPod(loadedPods[i%len(loadedPods)].Name)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		eventsMutex.Lock()
+		defer eventsMutex.Unlock()
+		assert.Equal(t, len(registry.Pods()), eventCounts[PodAdded]-eventCounts[PodRemoved])
+	}
+}
+
+func loadTestPod(t *testing.T, manifest string) *Pod {
+	manifestPath := filepath.Join("../../test/assets/pods/manifests", manifest)
+	pod, err := LoadPodFromManifest(manifestPath)
+	if err != nil {
+		t.Fatal(fmt.Errorf("failed to load %s: %w", manifest, err))
+	}
+	return pod
+}
@@ -0,0 +1,192 @@
+package quotas
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/spiceai/spiceai/pkg/spec"
+)
+
+const (
+	CachedObservations  = "max_cached_observations"
+	IngestRate          = "max_ingest_rate"
+	BodyBytes           = "max_body_bytes"
+	Interpretations     = "max_interpretations"
+	ConcurrentTrainings = "max_concurrent_trainings"
+)
+
+// Returned when an operation would exceed a quota
+type ExceededError struct {
+	Quota   string
+	Message string
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("quota %s exceeded: %s", e.Quota, e.Message)
+}
+
+// Quotas enforces the limits of a single pod or dataspace and counts rejections.
+// A nil *Quotas allows everything.
+type Quotas struct {
+	limits spec.QuotasSpec
+
+	limiterMutex sync.Mutex
+	tokens       float64
+	lastRefill   time.Time
+	now          func() time.Time
+
+	rejectionsMutex sync.Mutex
+	rejections      map[string]uint64
+}
+
+func NewQuotas(limits spec.QuotasSpec) *Quotas {
+	q := &Quotas{
+		limits:     limits,
+		now:        time.Now,
+		rejections: make(map[string]uint64),
+	}
+	q.tokens = q.burst()
+	q.lastRefill = q.now()
+	return q
+}
+
+// Returns base with every limit set in override replacing the base limit
+func Merge(base spec.QuotasSpec, override *spec.QuotasSpec) spec.QuotasSpec {
+	if override == nil {
+		return base
+	}
+
+	merged := base
+	if override.MaxCachedObservations != 0 {
+		merged.MaxCachedObservations = override.MaxCachedObservations
+	}
+	if override.MaxIngestRate != 0 {
+		merged.MaxIngestRate = override.MaxIngestRate
+	}
+	if override.MaxBodyBytes != 0 {
+		merged.MaxBodyBytes = override.MaxBodyBytes
+	}
+	if override.MaxInterpretations != 0 {
+		merged.MaxInterpretations = override.MaxInterpretations
+	}
+	if override.MaxConcurrentTrainings != 0 {
+		merged.MaxConcurrentTrainings = override.MaxConcurrentTrainings
+	}
+	return merged
+}
+
+// Returns only the limits that apply to a dataspace. Interpretations and trainings are pod-wide.
+func DataspaceLimits(limits spec.QuotasSpec) spec.QuotasSpec {
+	return spec.QuotasSpec{
+		MaxCachedObservations: limits.MaxCachedObservations,
+		MaxIngestRate:         limits.MaxIngestRate,
+		MaxBodyBytes:          limits.MaxBodyBytes,
+	}
+}
+
+func (q *Quotas) Limits() spec.QuotasSpec {
+	if q == nil {
+		return spec.QuotasSpec{}
+	}
+	return q.limits
+}
+
+// Returns the number of rejections per quota name
+func (q *Quotas) Rejections() map[string]uint64 {
+	rejections := make(map[string]uint64)
+	if q == nil {
+		return rejections
+	}
+
+	q.rejectionsMutex.Lock()
+	defer q.rejectionsMutex.Unlock()
+
+	for quota, count := range q.rejections {
+		rejections[quota] = count
+	}
+	return rejections
+}
+
+// Returns max_body_bytes, or defaultLimit if the quota doesn't set one. 0 is unlimited.
+func (q *Quotas) MaxBodySize(defaultLimit int) int {
+	if q != nil && q.limits.MaxBodyBytes > 0 {
+		return q.limits.MaxBodyBytes
+	}
+	return defaultLimit
+}
+
+// Checks size against max_body_bytes, or defaultLimit if the quota doesn't set one
+func (q *Quotas) CheckBodySize(size int, defaultLimit int) error {
+	limit := q.MaxBodySize(defaultLimit)
+	if limit <= 0 || size <= limit {
+		return nil
+	}
+	if q == nil {
+		return &ExceededError{
+			Quota:   BodyBytes,
+			Message: fmt.Sprintf("request body of %d bytes exceeds the limit of %d bytes", size, limit),
+		}
+	}
+	return q.reject(BodyBytes, "request body of %d bytes exceeds the limit of %d bytes", size, limit)
+}
+
+// Takes a token from the ingest rate limiter, which allows max_ingest_rate requests per second
+func (q *Quotas) AllowIngest() error {
+	if q == nil || q.limits.MaxIngestRate <= 0 {
+		return nil
+	}
+
+	q.limiterMutex.Lock()
+	now := q.now()
+	elapsed := now.Sub(q.lastRefill).Seconds()
+	q.lastRefill = now
+	q.tokens = math.Min(q.burst(), q.tokens+elapsed*q.limits.MaxIngestRate)
+	allowed := q.tokens >= 1
+	if allowed {
+		q.tokens--
+	}
+	q.limiterMutex.Unlock()
+
+	if allowed {
+		return nil
+	}
+	return q.reject(IngestRate, "more than %g requests per second", q.limits.MaxIngestRate)
+}
+
+func (q *Quotas) CheckCachedObservations(cached int, adding int) error {
+	if q == nil || q.limits.MaxCachedObservations <= 0 || cached+adding <= q.limits.MaxCachedObservations {
+		return nil
+	}
+	return q.reject(CachedObservations, "adding %d observations to %d cached would exceed the limit of %d", adding, cached, q.limits.MaxCachedObservations)
+}
+
+func (q *Quotas) CheckInterpretations(count int, adding int) error {
+	if q == nil || q.limits.MaxInterpretations <= 0 || count+adding <= q.limits.MaxInterpretations {
+		return nil
+	}
+	return q.reject(Interpretations, "adding %d interpretations to %d would exceed the limit of %d", adding, count, q.limits.MaxInterpretations)
+}
+
+func (q *Quotas) CheckConcurrentTrainings(active int) error {
+	if q == nil || q.limits.MaxConcurrentTrainings <= 0 || active < q.limits.MaxConcurrentTrainings {
+		return nil
+	}
+	return q.reject(ConcurrentTrainings, "%d trainings already in progress", active)
+}
+
+func (q *Quotas) reject(quota string, format string, args ...interface{}) error {
+	q.rejectionsMutex.Lock()
+	q.rejections[quota]++
+	q.rejectionsMutex.Unlock()
+
+	return &ExceededError{
+		Quota:   quota,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (q *Quotas) burst() float64 {
+	return math.Max(1, math.Ceil(q.limits.MaxIngestRate))
+}
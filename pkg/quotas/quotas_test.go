@@ -0,0 +1,129 @@
+package quotas
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spiceai/spiceai/pkg/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotas(t *testing.T) {
+	t.Run("Merge() - Override replaces only the limits it sets", testMergeFunc())
+	t.Run("DataspaceLimits() - Only dataspace limits are kept", testDataspaceLimitsFunc())
+	t.Run("Check*() - Limits are enforced and rejections counted", testChecksFunc())
+	t.Run("AllowIngest() - Requests are limited per second", testAllowIngestFunc())
+	t.Run("nil Quotas - Everything is allowed", testNilQuotasFunc())
+}
+
+func testMergeFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		base := spec.QuotasSpec{
+			MaxCachedObservations: 100,
+			MaxBodyBytes:          1024,
+		}
+
+		assert.Equal(t, base, Merge(base, nil))
+
+		merged := Merge(base, &spec.QuotasSpec{
+			MaxBodyBytes:       10,
+			MaxInterpretations: 5,
+		})
+		assert.Equal(t, spec.QuotasSpec{
+			MaxCachedObservations: 100,
+			MaxBodyBytes:          10,
+			MaxInterpretations:    5,
+		}, merged)
+	}
+}
+
+func testDataspaceLimitsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		limits := DataspaceLimits(spec.QuotasSpec{
+			MaxCachedObservations:  100,
+			MaxIngestRate:          2,
+			MaxBodyBytes:           1024,
+			MaxInterpretations:     5,
+			MaxConcurrentTrainings: 1,
+		})
+		assert.Equal(t, spec.QuotasSpec{
+			MaxCachedObservations: 100,
+			MaxIngestRate:         2,
+			MaxBodyBytes:          1024,
+		}, limits)
+	}
+}
+
+func testChecksFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		q := NewQuotas(spec.QuotasSpec{
+			MaxCachedObservations:  10,
+			MaxBodyBytes:           100,
+			MaxInterpretations:     2,
+			MaxConcurrentTrainings: 1,
+		})
+
+		assert.NoError(t, q.CheckBodySize(100, 10))
+		assert.Equal(t, 100, q.MaxBodySize(10))
+		assert.NoError(t, q.CheckCachedObservations(5, 5))
+		assert.NoError(t, q.CheckInterpretations(1, 1))
+		assert.NoError(t, q.CheckConcurrentTrainings(0))
+
+		err := q.CheckBodySize(101, 0)
+		var exceededErr *ExceededError
+		if assert.True(t, errors.As(err, &exceededErr)) {
+			assert.Equal(t, BodyBytes, exceededErr.Quota)
+		}
+		assert.EqualError(t, q.CheckCachedObservations(5, 6), "quota max_cached_observations exceeded: adding 6 observations to 5 cached would exceed the limit of 10")
+		assert.Error(t, q.CheckInterpretations(2, 1))
+		assert.Error(t, q.CheckConcurrentTrainings(1))
+		assert.Error(t, q.CheckConcurrentTrainings(1))
+
+		assert.Equal(t, map[string]uint64{
+			BodyBytes:           1,
+			CachedObservations:  1,
+			Interpretations:     1,
+			ConcurrentTrainings: 2,
+		}, q.Rejections())
+	}
+}
+
+func testAllowIngestFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		now := time.Unix(1000, 0)
+		q := NewQuotas(spec.QuotasSpec{MaxIngestRate: 2})
+		q.now = func() time.Time { return now }
+		q.lastRefill = now
+
+		assert.NoError(t, q.AllowIngest())
+		assert.NoError(t, q.AllowIngest())
+		assert.Error(t, q.AllowIngest())
+
+		now = now.Add(500 * time.Millisecond)
+		assert.NoError(t, q.AllowIngest())
+		assert.Error(t, q.AllowIngest())
+
+		now = now.Add(10 * time.Second)
+		assert.NoError(t, q.AllowIngest())
+		assert.NoError(t, q.AllowIngest())
+		assert.Error(t, q.AllowIngest())
+
+		assert.Equal(t, uint64(3), q.Rejections()[IngestRate])
+	}
+}
+
+func testNilQuotasFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		var q *Quotas
+		assert.NoError(t, q.CheckBodySize(1<<30, 0))
+		assert.Error(t, q.CheckBodySize(11, 10))
+		assert.Equal(t, 10, q.MaxBodySize(10))
+		assert.NoError(t, q.AllowIngest())
+		assert.NoError(t, q.CheckCachedObservations(1<<30, 1))
+		assert.NoError(t, q.CheckInterpretations(1<<30, 1))
+		assert.NoError(t, q.CheckConcurrentTrainings(1<<30))
+		assert.Empty(t, q.Rejections())
+		assert.Equal(t, spec.QuotasSpec{}, q.Limits())
+	}
+}
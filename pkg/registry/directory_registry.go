@@ -0,0 +1,197 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spiceai/spiceai/pkg/util"
+	"golang.org/x/mod/semver"
+)
+
+// DirectoryRegistry is a registry kept in a local directory, such as a shared drive, selected with a file://
+// registry URL. Each published version of a pod is a zip at <dir>/<pod>/<version>.zip, where <pod> is the
+// pod's path such as samples/LogPruner. Versions are ordered as semantic versions.
+type DirectoryRegistry struct {
+	podsDir string
+	dir     string
+}
+
+func NewDirectoryRegistry(podsDir string, dir string) *DirectoryRegistry {
+	return &DirectoryRegistry{
+		podsDir: podsDir,
+		dir:     dir,
+	}
+}
+
+func (r *DirectoryRegistry) GetPod(podPath string, podVersion string) (*FetchedPod, error) {
+	if podVersion == "" {
+		latest, err := r.latestVersion(podPath)
+		if err != nil {
+			return nil, err
+		}
+		podVersion = latest.Version
+	}
+
+	zipPath := r.versionPath(podPath, podVersion)
+	if _, err := os.Stat(zipPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, NewRegistryItemNotFound(fmt.Errorf("pod %s version %s not found", podPath, podVersion))
+		}
+		return nil, fmt.Errorf("an error occurred fetching pod '%s': %w", podPath, err)
+	}
+
+	fetched, err := extractPodZip(zipPath, r.podsDir, filepath.Base(podPath))
+	if err != nil {
+		return nil, err
+	}
+
+	fetched.Source = "file://" + r.dir
+	fetched.Version = podVersion
+
+	return fetched, nil
+}
+
+func (r *DirectoryRegistry) Search(query string) ([]*PodSummary, error) {
+	summaries := make([]*PodSummary, 0)
+
+	podPaths := make(map[string]bool)
+	err := filepath.WalkDir(r.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".zip" {
+			podPath, err := filepath.Rel(r.dir, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+			podPaths[filepath.ToSlash(podPath)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return summaries, nil
+		}
+		return nil, fmt.Errorf("failed to search %s: %w", r.dir, err)
+	}
+
+	for podPath := range podPaths {
+		if !strings.Contains(strings.ToLower(podPath), strings.ToLower(query)) {
+			continue
+		}
+		latest, err := r.latestVersion(podPath)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, &PodSummary{Name: podPath, LatestVersion: latest.Version})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+
+	return summaries, nil
+}
+
+func (r *DirectoryRegistry) ListVersions(podPath string) ([]*PodVersion, error) {
+	entries, err := os.ReadDir(filepath.Join(r.dir, filepath.FromSlash(podPath)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list the versions of pod %s: %w", podPath, err)
+	}
+
+	versions := make([]*PodVersion, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".zip" {
+			continue
+		}
+
+		version := strings.TrimSuffix(entry.Name(), ".zip")
+		data, err := os.ReadFile(r.versionPath(podPath, version))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the versions of pod %s: %w", podPath, err)
+		}
+
+		podVersion := &PodVersion{Version: version, Checksum: Checksum(data)}
+		if info, err := entry.Info(); err == nil {
+			podVersion.PublishedAt = info.ModTime().UTC()
+		}
+		versions = append(versions, podVersion)
+	}
+
+	if len(versions) == 0 {
+		return nil, NewRegistryItemNotFound(fmt.Errorf("pod %s not found", podPath))
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return semver.Compare("v"+versions[i].Version, "v"+versions[j].Version) < 0
+	})
+
+	return versions, nil
+}
+
+func (r *DirectoryRegistry) Publish(podPath string, podVersion string, pkg io.Reader) (*PodVersion, error) {
+	if podVersion == "" || strings.ContainsAny(podVersion, `/\`) {
+		return nil, fmt.Errorf("invalid version '%s' of pod %s", podVersion, podPath)
+	}
+
+	zipPath := r.versionPath(podPath, podVersion)
+	if err := util.SanitizeExtractPath(filepath.FromSlash(podPath), r.dir); err != nil {
+		return nil, fmt.Errorf("invalid pod path '%s': %w", podPath, err)
+	}
+
+	if _, err := os.Stat(zipPath); err == nil {
+		return nil, fmt.Errorf("pod %s version %s is already published to %s", podPath, podVersion, r.dir)
+	}
+
+	data, err := ioutil.ReadAll(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish pod %s to %s: %w", podPath, r.dir, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(zipPath), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish pod %s to %s: %w", podPath, r.dir, err)
+	}
+
+	// Written to a temporary file first so a failed publish doesn't leave a partial version behind
+	tmpFile, err := ioutil.TempFile(filepath.Dir(zipPath), ".publish-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish pod %s to %s: %w", podPath, r.dir, err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), zipPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish pod %s to %s: %w", podPath, r.dir, err)
+	}
+
+	published := &PodVersion{Version: podVersion, Checksum: Checksum(data)}
+	if info, err := os.Stat(zipPath); err == nil {
+		published.PublishedAt = info.ModTime().UTC()
+	}
+
+	return published, nil
+}
+
+func (r *DirectoryRegistry) versionPath(podPath string, podVersion string) string {
+	return filepath.Join(r.dir, filepath.FromSlash(podPath), fmt.Sprintf("%s.zip", podVersion))
+}
+
+func (r *DirectoryRegistry) latestVersion(podPath string) (*PodVersion, error) {
+	versions, err := r.ListVersions(podPath)
+	if err != nil {
+		return nil, err
+	}
+	return versions[len(versions)-1], nil
+}
@@ -0,0 +1,168 @@
+package registry_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiceai/spiceai/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectoryRegistry(t *testing.T) {
+	t.Run("Package() - The manifest, referenced files and README are packaged with checksums", testPackageFunc())
+	t.Run("Package() - Files outside the pod's directory are rejected", testPackageOutsideFunc())
+	t.Run("Publish() - Published packages can be fetched, searched and listed", testDirectoryPublishFunc())
+	t.Run("GetPod() - Files not matching the package checksums are rejected", testDirectoryGetPodChecksumFunc())
+	t.Run("GetRegistry() - file:// registry URLs use a directory registry", testGetRegistryDirectoryFunc())
+}
+
+func testPackageFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		podDir := newPodDir(t)
+
+		pkg, metadata, err := registry.Package("trader", "1.0.0", filepath.Join(podDir, "trader.yaml"), []string{
+			filepath.Join(podDir, "rewards", "trader.py"),
+			filepath.Join(podDir, "data", "trader.csv"),
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "trader", metadata.Name)
+		assert.Equal(t, "1.0.0", metadata.Version)
+		assert.Equal(t, map[string]string{
+			"trader.yaml":       registry.Checksum([]byte("name: trader\n")),
+			"rewards/trader.py": registry.Checksum([]byte("reward = 1\n")),
+			"data/trader.csv":   registry.Checksum([]byte("time\n")),
+			"README.md":         registry.Checksum([]byte("# Trader\n")),
+		}, metadata.Files)
+
+		zipReader, err := zip.NewReader(bytes.NewReader(pkg), int64(len(pkg)))
+		if assert.NoError(t, err) {
+			var names []string
+			for _, f := range zipReader.File {
+				names = append(names, f.Name)
+			}
+			assert.ElementsMatch(t, []string{"trader.yaml", "rewards/trader.py", "data/trader.csv", "README.md", "trader.package.json"}, names)
+		}
+
+		// Packaging the same files gives the same package
+		again, _, err := registry.Package("trader", "1.0.0", filepath.Join(podDir, "trader.yaml"), []string{
+			filepath.Join(podDir, "data", "trader.csv"),
+			filepath.Join(podDir, "rewards", "trader.py"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, registry.Checksum(pkg), registry.Checksum(again))
+	}
+}
+
+func testPackageOutsideFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		podDir := newPodDir(t)
+		outside := filepath.Join(filepath.Dir(podDir), "outside.csv")
+		writeFile(t, outside, "time\n")
+
+		_, _, err := registry.Package("trader", "1.0.0", filepath.Join(podDir, "trader.yaml"), []string{outside})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "outside the pod's directory")
+	}
+}
+
+func testDirectoryPublishFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		podDir := newPodDir(t)
+		r := registry.NewDirectoryRegistry(filepath.Join(t.TempDir(), "spicepods"), t.TempDir())
+
+		for _, version := range []string{"1.0.0", "1.10.0", "1.2.0"} {
+			pkg, _, err := registry.Package("trader", version, filepath.Join(podDir, "trader.yaml"), []string{filepath.Join(podDir, "data", "trader.csv")})
+			assert.NoError(t, err)
+
+			published, err := r.Publish("samples/trader", version, bytes.NewReader(pkg))
+			assert.NoError(t, err)
+			if assert.NotNil(t, published) {
+				assert.Equal(t, version, published.Version)
+				assert.Equal(t, registry.Checksum(pkg), published.Checksum)
+			}
+		}
+
+		_, err := r.Publish("samples/trader", "1.0.0", bytes.NewReader(nil))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already published")
+
+		versions, err := r.ListVersions("samples/trader")
+		assert.NoError(t, err)
+		if assert.Len(t, versions, 3) {
+			assert.Equal(t, "1.0.0", versions[0].Version)
+			assert.Equal(t, "1.2.0", versions[1].Version)
+			assert.Equal(t, "1.10.0", versions[2].Version)
+		}
+
+		summaries, err := r.Search("TRAD")
+		assert.NoError(t, err)
+		assert.Equal(t, []*registry.PodSummary{{Name: "samples/trader", LatestVersion: "1.10.0"}}, summaries)
+
+		fetched, err := r.GetPod("samples/trader", "")
+		assert.NoError(t, err)
+		assertFileContains(t, fetched.ManifestPath, "name: trader")
+		assert.Equal(t, "1.10.0", fetched.Version)
+		assert.Equal(t, map[string]string{
+			"trader.yaml":     registry.Checksum([]byte("name: trader\n")),
+			"data/trader.csv": registry.Checksum([]byte("time\n")),
+			"README.md":       registry.Checksum([]byte("# Trader\n")),
+		}, fetched.Files)
+		assert.Equal(t, registry.ContentHash(fetched.Files), fetched.Hash)
+		assertFileContains(t, filepath.Join(filepath.Dir(fetched.ManifestPath), "data", "trader.csv"), "time")
+		assert.NoFileExists(t, filepath.Join(filepath.Dir(fetched.ManifestPath), "trader.package.json"))
+
+		var itemNotFound *registry.RegistryItemNotFound
+		_, err = r.GetPod("samples/trader", "2.0.0")
+		assert.True(t, errors.As(err, &itemNotFound))
+		_, err = r.GetPod("samples/missing", "")
+		assert.True(t, errors.As(err, &itemNotFound))
+	}
+}
+
+func testDirectoryGetPodChecksumFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		r := registry.NewDirectoryRegistry(filepath.Join(t.TempDir(), "spicepods"), t.TempDir())
+
+		pkg := zipFiles(t, map[string]string{
+			"trader.yaml":         "name: trader\n",
+			"trader.package.json": `{"name": "trader", "version": "1.0.0", "files": {"trader.yaml": "sha256:0"}}`,
+		})
+		_, err := r.Publish("samples/trader", "1.0.0", bytes.NewReader(pkg))
+		assert.NoError(t, err)
+
+		_, err = r.GetPod("samples/trader", "1.0.0")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum")
+	}
+}
+
+func testGetRegistryDirectoryFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		r := registry.GetRegistry(t.TempDir(), "samples/trader", registry.WithUrl("file://"+t.TempDir()))
+		assert.IsType(t, &registry.DirectoryRegistry{}, r)
+	}
+}
+
+// Returns a pod directory with a manifest, reward functions, data and a README
+func newPodDir(t *testing.T) string {
+	podDir := filepath.Join(t.TempDir(), "trader")
+	writeFile(t, filepath.Join(podDir, "trader.yaml"), "name: trader\n")
+	writeFile(t, filepath.Join(podDir, "rewards", "trader.py"), "reward = 1\n")
+	writeFile(t, filepath.Join(podDir, "data", "trader.csv"), "time\n")
+	writeFile(t, filepath.Join(podDir, "README.md"), "# Trader\n")
+	return podDir
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
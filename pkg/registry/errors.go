@@ -1,5 +1,7 @@
 package registry
 
+import "fmt"
+
 type RegistryItemNotFound struct {
 	Err error
 }
@@ -11,3 +13,13 @@ func NewRegistryItemNotFound(err error) *RegistryItemNotFound {
 		Err: err,
 	}
 }
+
+// Returned by registries that can't perform an operation, such as searching local files
+type RegistryOperationNotSupported struct {
+	Registry  string
+	Operation string
+}
+
+func (e *RegistryOperationNotSupported) Error() string {
+	return fmt.Sprintf("the %s registry does not support %s", e.Registry, e.Operation)
+}
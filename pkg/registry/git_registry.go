@@ -0,0 +1,145 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+const gitPrefix = "git+"
+
+// GitRegistry adds pods from git repositories, referenced as git+<repository URL>[#<path>] where the repository
+// URL is a file:// or https:// URL and <path> is the pod's directory within the repository, such as
+// git+https://github.com/spiceai/pods.git#trader. A version is a git ref, such as a tag, branch or commit, and
+// defaults to the repository's default branch. The fetched pod's version is the commit the ref resolved to, so
+// a locked pod is fetched at the same commit even if the ref moves.
+type GitRegistry struct {
+	podsDir string
+}
+
+func NewGitRegistry(podsDir string) *GitRegistry {
+	return &GitRegistry{podsDir: podsDir}
+}
+
+func (r *GitRegistry) GetPod(podPath string, version string) (*FetchedPod, error) {
+	repoUrl, subpath, err := ParseGitReference(podPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(version, "-") {
+		return nil, fmt.Errorf("invalid git ref '%s'", version)
+	}
+
+	cloneDir, err := os.MkdirTemp("", "spicepod-git-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(cloneDir)
+
+	_, err = runGit("", "clone", "--quiet", "--", repoUrl, cloneDir)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching pod %s: %w", podPath, err)
+	}
+
+	if version != "" {
+		_, err = runGit(cloneDir, "-c", "advice.detachedHead=false", "checkout", "--quiet", version, "--")
+		if err != nil {
+			return nil, NewRegistryItemNotFound(fmt.Errorf("pod %s version %s not found: %w", podPath, version, err))
+		}
+	}
+
+	commit, err := runGit(cloneDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("error fetching pod %s: %w", podPath, err)
+	}
+
+	podDir := cloneDir
+	podName := strings.TrimSuffix(path.Base(strings.TrimSuffix(repoUrl, "/")), ".git")
+	if subpath != "" {
+		err = util.SanitizeExtractPath(filepath.FromSlash(subpath), cloneDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pod path in %s: %w", podPath, err)
+		}
+		podDir = filepath.Join(cloneDir, filepath.FromSlash(subpath))
+		podName = path.Base(subpath)
+	}
+
+	if stat, err := os.Stat(podDir); err != nil || !stat.IsDir() {
+		return nil, NewRegistryItemNotFound(fmt.Errorf("pod %s not found: the repository has no directory '%s'", podPath, subpath))
+	}
+
+	fetched, err := copyPodDir(podDir, r.podsDir, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	fetched.Source = podPath
+	fetched.Version = commit
+
+	return fetched, nil
+}
+
+func (r *GitRegistry) Search(query string) ([]*PodSummary, error) {
+	return nil, &RegistryOperationNotSupported{Registry: "git", Operation: "search"}
+}
+
+func (r *GitRegistry) ListVersions(podPath string) ([]*PodVersion, error) {
+	return nil, &RegistryOperationNotSupported{Registry: "git", Operation: "versions"}
+}
+
+func (r *GitRegistry) Publish(podPath string, version string, pkg io.Reader) (*PodVersion, error) {
+	return nil, &RegistryOperationNotSupported{Registry: "git", Operation: "publishing"}
+}
+
+// Splits a git pod reference, git+<repository URL>[#<path>], into the repository's URL and the pod's path
+// within it
+func ParseGitReference(podPath string) (repoUrl string, subpath string, err error) {
+	if !strings.HasPrefix(podPath, gitPrefix) {
+		return "", "", fmt.Errorf("'%s' isn't a git pod reference, expected git+<repository URL>", podPath)
+	}
+
+	repoUrl = strings.TrimPrefix(podPath, gitPrefix)
+	if i := strings.Index(repoUrl, "#"); i >= 0 {
+		repoUrl, subpath = repoUrl[:i], strings.Trim(repoUrl[i+1:], "/")
+	}
+
+	u, err := url.Parse(repoUrl)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid git repository URL '%s': %w", repoUrl, err)
+	}
+	if u.Scheme != "file" && u.Scheme != "https" {
+		return "", "", fmt.Errorf("unsupported git repository URL '%s': only file:// and https:// repositories are supported", repoUrl)
+	}
+
+	return repoUrl, subpath, nil
+}
+
+// Runs git in dir, returning its trimmed output
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	// Fail rather than wait for credentials nobody will enter
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", errors.New("git is required to add pods from git repositories, but wasn't found")
+		}
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return "", fmt.Errorf("git %s failed: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
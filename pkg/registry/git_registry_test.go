@@ -0,0 +1,188 @@
+package registry_test
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/spiceai/spiceai/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitRegistry(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Run("GetPod() - The pod is fetched at the default branch or a ref", testGitGetPodFunc())
+	t.Run("GetPod() - Pods at the root of the repository are fetched", testGitGetPodRootFunc())
+	t.Run("GetPod() - Missing refs and paths are not found", testGitGetPodNotFoundFunc())
+	t.Run("GetPod() - Paths outside the repository are rejected", testGitGetPodSanitizesFunc())
+	t.Run("ParseGitReference() - Only file:// and https:// repositories are supported", testParseGitReferenceFunc())
+	t.Run("GetRegistry() - git+ references use a git registry", testGetRegistryGitFunc())
+}
+
+func testGitGetPodFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		repo := newBareRepo(t, map[string]string{
+			"pods/trader/trader.yaml":     "name: trader\n# 1.0.0\n",
+			"pods/trader/data/trader.csv": "time\n",
+		}, map[string]string{
+			"pods/trader/trader.yaml": "name: trader\n# 1.1.0\n",
+		})
+
+		podsDir := filepath.Join(t.TempDir(), "spicepods")
+		r := registry.NewGitRegistry(podsDir)
+		podPath := "git+file://" + repo.dir + "#pods/trader"
+
+		fetched, err := r.GetPod(podPath, "")
+		assert.NoError(t, err)
+		if assert.NotNil(t, fetched) {
+			assert.Equal(t, filepath.Join(podsDir, "trader.yaml"), fetched.ManifestPath)
+			assertFileContains(t, fetched.ManifestPath, "# 1.1.0")
+			assert.Equal(t, podPath, fetched.Source)
+			assert.Equal(t, repo.commits[1], fetched.Version)
+			assert.Equal(t, registry.ContentHash(fetched.Files), fetched.Hash)
+		}
+
+		fetched, err = r.GetPod(podPath, "v1.0.0")
+		assert.NoError(t, err)
+		if assert.NotNil(t, fetched) {
+			assertFileContains(t, fetched.ManifestPath, "# 1.0.0")
+			assert.Equal(t, repo.commits[0], fetched.Version)
+			assert.Equal(t, []string{"data/trader.csv", "trader.yaml"}, sortedKeys(fetched.Files))
+		}
+		assert.FileExists(t, filepath.Join(podsDir, "data", "trader.csv"))
+
+		// Locked pods are fetched by commit
+		fetched, err = r.GetPod(podPath, repo.commits[0])
+		assert.NoError(t, err)
+		if assert.NotNil(t, fetched) {
+			assertFileContains(t, fetched.ManifestPath, "# 1.0.0")
+		}
+	}
+}
+
+func testGitGetPodRootFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		repo := newBareRepo(t, map[string]string{"logpruner.yaml": "name: logpruner\n"})
+
+		podsDir := t.TempDir()
+		fetched, err := registry.NewGitRegistry(podsDir).GetPod("git+file://"+repo.dir, "")
+		assert.NoError(t, err)
+		if assert.NotNil(t, fetched) {
+			assert.Equal(t, filepath.Join(podsDir, "logpruner.yaml"), fetched.ManifestPath)
+			assert.Equal(t, []string{"logpruner.yaml"}, sortedKeys(fetched.Files))
+		}
+		assert.NoDirExists(t, filepath.Join(podsDir, ".git"))
+	}
+}
+
+func testGitGetPodNotFoundFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		repo := newBareRepo(t, map[string]string{"trader/trader.yaml": "name: trader\n"})
+		r := registry.NewGitRegistry(t.TempDir())
+
+		var itemNotFound *registry.RegistryItemNotFound
+		_, err := r.GetPod("git+file://"+repo.dir+"#trader", "v9.9.9")
+		assert.True(t, errors.As(err, &itemNotFound))
+		_, err = r.GetPod("git+file://"+repo.dir+"#missing", "")
+		assert.True(t, errors.As(err, &itemNotFound))
+		_, err = r.GetPod("git+file://"+filepath.Join(t.TempDir(), "missing.git"), "")
+		assert.Error(t, err)
+	}
+}
+
+func testGitGetPodSanitizesFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		repo := newBareRepo(t, map[string]string{"trader/trader.yaml": "name: trader\n"})
+
+		_, err := registry.NewGitRegistry(t.TempDir()).GetPod("git+file://"+repo.dir+"#../..", "")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "illegal file path")
+		}
+
+		_, err = registry.NewGitRegistry(t.TempDir()).GetPod("git+file://"+repo.dir+"#trader", "--upload-pack=touch")
+		assert.Error(t, err)
+	}
+}
+
+func testParseGitReferenceFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		repoUrl, subpath, err := registry.ParseGitReference("git+https://github.com/example/pods.git#pods/trader/")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://github.com/example/pods.git", repoUrl)
+		assert.Equal(t, "pods/trader", subpath)
+
+		_, _, err = registry.ParseGitReference("git+ssh://git@github.com/example/pods.git")
+		assert.Error(t, err)
+		_, _, err = registry.ParseGitReference("git+http://github.com/example/pods.git")
+		assert.Error(t, err)
+
+		podPath, version := registry.ParsePodReference("git+https://github.com/example/pods.git#trader@v1.0.0")
+		assert.Equal(t, "git+https://github.com/example/pods.git#trader", podPath)
+		assert.Equal(t, "v1.0.0", version)
+
+		podPath, version = registry.ParsePodReference("git+https://user@github.com/example/pods.git")
+		assert.Equal(t, "git+https://user@github.com/example/pods.git", podPath)
+		assert.Equal(t, "", version)
+	}
+}
+
+func testGetRegistryGitFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		r := registry.GetRegistry(t.TempDir(), "git+https://github.com/example/pods.git#trader")
+		assert.IsType(t, &registry.GitRegistry{}, r)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type bareRepo struct {
+	dir string
+	// The commit of each revision, oldest first
+	commits []string
+}
+
+// Creates a bare repository with a commit of each revision's files. The first commit is tagged v1.0.0.
+func newBareRepo(t *testing.T, revisions ...map[string]string) *bareRepo {
+	workDir := filepath.Join(t.TempDir(), "work")
+	repo := &bareRepo{dir: filepath.Join(t.TempDir(), "pods.git")}
+
+	git(t, "", "init", "--quiet", workDir)
+	for i, files := range revisions {
+		for name, content := range files {
+			writeFile(t, filepath.Join(workDir, filepath.FromSlash(name)), content)
+		}
+		git(t, workDir, "add", "--all")
+		git(t, workDir, "commit", "--quiet", "--message", "revision")
+		repo.commits = append(repo.commits, git(t, workDir, "rev-parse", "HEAD"))
+		if i == 0 {
+			git(t, workDir, "tag", "v1.0.0")
+		}
+	}
+	git(t, "", "clone", "--quiet", "--bare", workDir, repo.dir)
+
+	return repo
+}
+
+func git(t *testing.T, dir string, args ...string) string {
+	args = append([]string{"-c", "user.name=spice", "-c", "user.email=spice@example.com", "-c", "commit.gpgsign=false"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s: %s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
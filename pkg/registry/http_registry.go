@@ -0,0 +1,326 @@
+package registry
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	spice_http "github.com/spiceai/spiceai/pkg/http"
+
+	"github.com/spiceai/spiceai/pkg/loggers"
+	"github.com/spiceai/spiceai/pkg/util"
+	"go.uber.org/zap"
+)
+
+var (
+	zaplog *zap.Logger = loggers.ZapLogger()
+)
+
+// HttpRegistry fetches and publishes pods with a registry serving this protocol under its base URL, as
+// spicerack.org does:
+//
+//	GET /pods?q=<query>         JSON array of the PodSummary of each pod matching the query
+//	GET /pods/<pod>             the latest version of the pod as a zip
+//	GET /pods/<pod>/<version>   the version of the pod as a zip
+//	GET /pods/<pod>/versions    JSON array of the PodVersion of each published version
+//	PUT /pods/<pod>/<version>   publishes the application/zip body as the version, responding with its
+//	                            PodVersion as JSON, or 409 Conflict if the version is already published
+//
+// <pod> is the pod's path, such as samples/LogPruner, so "versions" can't be used as a version. Unknown pods
+// and versions are 404 Not Found. Requests have an Authorization: Bearer header when the registry has a token.
+//
+// A pod's zip has its manifest, <name>.yaml, at its root along with any files the manifest refers to, and may
+// have the PackageMetadata written by Package to verify them.
+type HttpRegistry struct {
+	podsDir string
+	baseUrl string
+	token   string
+}
+
+func NewHttpRegistry(podsDir string, baseUrl string, token string) *HttpRegistry {
+	return &HttpRegistry{
+		podsDir: podsDir,
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+		token:   token,
+	}
+}
+
+func (r *HttpRegistry) GetPod(podPath string, podVersion string) (*FetchedPod, error) {
+	podName := filepath.Base(podPath)
+
+	podUrl := fmt.Sprintf("%s/pods/%s", r.baseUrl, podPath)
+	if podVersion != "" {
+		podUrl = fmt.Sprintf("%s/%s", podUrl, url.PathEscape(podVersion))
+	}
+	failureMessage := fmt.Sprintf("An error occurred while fetching pod '%s' from %s", podPath, r.host())
+
+	response, err := spice_http.Do(http.MethodGet, podUrl, r.header("application/zip"), nil)
+	if err != nil {
+		zaplog.Sugar().Debugf("%s: %s", failureMessage, err.Error())
+		return nil, errors.New(failureMessage)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		if podVersion != "" {
+			return nil, NewRegistryItemNotFound(fmt.Errorf("pod %s version %s not found", podPath, podVersion))
+		}
+		return nil, NewRegistryItemNotFound(fmt.Errorf("pod %s not found", podPath))
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("an error occurred fetching pod '%s': %s", podPath, response.Status)
+	}
+
+	tmpFile, err := ioutil.TempFile(os.TempDir(), "spice-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	_, err = io.Copy(tmpFile, response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	fetched, err := extractPodZip(tmpFile.Name(), r.podsDir, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	fetched.Source = r.baseUrl
+	if fetched.Version == "" {
+		fetched.Version = podVersion
+	}
+
+	return fetched, nil
+}
+
+func (r *HttpRegistry) Search(query string) ([]*PodSummary, error) {
+	searchUrl := fmt.Sprintf("%s/pods?q=%s", r.baseUrl, url.QueryEscape(query))
+
+	var summaries []*PodSummary
+	err := r.getJson(searchUrl, &summaries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", r.host(), err)
+	}
+
+	return summaries, nil
+}
+
+func (r *HttpRegistry) ListVersions(podPath string) ([]*PodVersion, error) {
+	versionsUrl := fmt.Sprintf("%s/pods/%s/versions", r.baseUrl, podPath)
+
+	var versions []*PodVersion
+	err := r.getJson(versionsUrl, &versions)
+	if err != nil {
+		var itemNotFound *RegistryItemNotFound
+		if errors.As(err, &itemNotFound) {
+			return nil, NewRegistryItemNotFound(fmt.Errorf("pod %s not found", podPath))
+		}
+		return nil, fmt.Errorf("failed to list the versions of pod %s: %w", podPath, err)
+	}
+
+	return versions, nil
+}
+
+func (r *HttpRegistry) Publish(podPath string, podVersion string, pkg io.Reader) (*PodVersion, error) {
+	publishUrl := fmt.Sprintf("%s/pods/%s/%s", r.baseUrl, podPath, url.PathEscape(podVersion))
+
+	header := r.header("application/json")
+	header.Set("Content-Type", "application/zip")
+
+	response, err := spice_http.Do(http.MethodPut, publishUrl, header, pkg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish pod %s to %s: %w", podPath, r.host(), err)
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+	case http.StatusConflict:
+		return nil, fmt.Errorf("pod %s version %s is already published to %s", podPath, podVersion, r.host())
+	default:
+		return nil, fmt.Errorf("failed to publish pod %s to %s: %s", podPath, r.host(), responseError(response))
+	}
+
+	var published PodVersion
+	err = json.NewDecoder(response.Body).Decode(&published)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the published version of pod %s: %w", podPath, err)
+	}
+
+	return &published, nil
+}
+
+func (r *HttpRegistry) getJson(url string, v interface{}) error {
+	response, err := spice_http.Do(http.MethodGet, url, r.header("application/json"), nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return NewRegistryItemNotFound(errors.New(response.Status))
+	}
+	if response.StatusCode != http.StatusOK {
+		return errors.New(responseError(response))
+	}
+
+	return json.NewDecoder(response.Body).Decode(v)
+}
+
+func (r *HttpRegistry) header(accept string) http.Header {
+	header := http.Header{}
+	header.Set("Accept", accept)
+	if r.token != "" {
+		header.Set("Authorization", "Bearer "+r.token)
+	}
+	return header
+}
+
+// The registry's host for messages, such as api.spicerack.org
+func (r *HttpRegistry) host() string {
+	if u, err := url.Parse(r.baseUrl); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return r.baseUrl
+}
+
+// Returns the response's status and any message in its body
+func responseError(response *http.Response) string {
+	body, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+	if message := strings.TrimSpace(string(body)); message != "" {
+		return fmt.Sprintf("%s: %s", response.Status, message)
+	}
+	return response.Status
+}
+
+// Extracts the pod zip at zipPath into podsDir as the pod named podName. Files are verified against the
+// package's metadata if it has any, and the version is read from it.
+func extractPodZip(zipPath string, podsDir string, podName string) (*FetchedPod, error) {
+	podsPerm, err := util.MkDirAllInheritPerm(podsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	zipReader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zipReader.Close()
+
+	metadata, err := readPackageMetadata(&zipReader.Reader, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	fetched := &FetchedPod{Files: make(map[string]string)}
+	if metadata != nil {
+		fetched.Version = metadata.Version
+	}
+
+	for _, f := range zipReader.File {
+		if strings.EqualFold(f.Name, PackageMetadataName(podName)) {
+			continue
+		}
+
+		err = util.SanitizeExtractPath(f.Name, podsDir)
+		if err != nil {
+			return nil, err
+		}
+
+		fpath := filepath.Join(podsDir, f.Name)
+		extractDir := filepath.Dir(fpath)
+
+		if f.FileInfo().IsDir() {
+			err := os.MkdirAll(fpath, podsPerm)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		err = os.MkdirAll(extractDir, podsPerm)
+		if err != nil {
+			return nil, err
+		}
+
+		checksum, err := extractZipFile(f, fpath)
+		if err != nil {
+			return nil, err
+		}
+
+		if metadata != nil && metadata.Files[f.Name] != checksum {
+			return nil, fmt.Errorf("the checksum of '%s' doesn't match the package's metadata", f.Name)
+		}
+		fetched.Files[f.Name] = checksum
+
+		if strings.EqualFold(filepath.Base(fpath), fmt.Sprintf("%s.yaml", podName)) {
+			fetched.ManifestPath = fpath
+		}
+	}
+
+	fetched.Hash = ContentHash(fetched.Files)
+
+	return fetched, nil
+}
+
+// Returns the metadata of the package of the pod named podName, or nil if the package has none
+func readPackageMetadata(zipReader *zip.Reader, podName string) (*PackageMetadata, error) {
+	for _, f := range zipReader.File {
+		if !strings.EqualFold(f.Name, PackageMetadataName(podName)) {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		var metadata PackageMetadata
+		err = json.NewDecoder(r).Decode(&metadata)
+		if err != nil {
+			return nil, fmt.Errorf("invalid package metadata: %w", err)
+		}
+
+		return &metadata, nil
+	}
+
+	return nil, nil
+}
+
+// Extracts f to fpath, returning the checksum of its contents
+func extractZipFile(f *zip.File, fpath string) (string, error) {
+	outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+	if err != nil {
+		return "", err
+	}
+	defer outFile.Close()
+
+	zipFile, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer zipFile.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(outFile, hash), zipFile)
+	if err != nil {
+		return "", err
+	}
+
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,310 @@
+package registry_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spiceai/spiceai/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpRegistry(t *testing.T) {
+	t.Run("GetPod() - The latest or requested version is extracted", testHttpGetPodFunc())
+	t.Run("GetPod() - Files outside the pods directory are rejected", testHttpGetPodSanitizesFunc())
+	t.Run("Search() - Pods matching the query are returned", testHttpSearchFunc())
+	t.Run("ListVersions() - Published versions are returned", testHttpListVersionsFunc())
+	t.Run("Publish() - Packages are uploaded with the registry token", testHttpPublishFunc())
+	t.Run("GetRegistry() - Registry pods use the configured URL", testGetRegistryUrlFunc())
+}
+
+func testHttpGetPodFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		stub := newStubRegistry(t, "")
+		stub.add("samples/trader", "1.0.0", map[string]string{"trader.yaml": "name: trader\n# 1.0.0\n", "data/trader.csv": "time\n"})
+		stub.add("samples/trader", "1.1.0", map[string]string{"trader.yaml": "name: trader\n# 1.1.0\n"})
+
+		podsDir := filepath.Join(t.TempDir(), "spicepods")
+		r := registry.NewHttpRegistry(podsDir, stub.server.URL, "")
+
+		fetched, err := r.GetPod("samples/trader", "")
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(podsDir, "trader.yaml"), fetched.ManifestPath)
+		assertFileContains(t, fetched.ManifestPath, "# 1.1.0")
+		assert.Equal(t, stub.server.URL, fetched.Source)
+		assert.Equal(t, "", fetched.Version)
+
+		fetched, err = r.GetPod("samples/trader", "1.0.0")
+		assert.NoError(t, err)
+		assertFileContains(t, fetched.ManifestPath, "# 1.0.0")
+		assert.Equal(t, "1.0.0", fetched.Version)
+		assert.Equal(t, registry.ContentHash(fetched.Files), fetched.Hash)
+		assert.FileExists(t, filepath.Join(podsDir, "data", "trader.csv"))
+
+		var itemNotFound *registry.RegistryItemNotFound
+		_, err = r.GetPod("samples/trader", "2.0.0")
+		assert.True(t, errors.As(err, &itemNotFound))
+		_, err = r.GetPod("samples/missing", "")
+		assert.True(t, errors.As(err, &itemNotFound))
+	}
+}
+
+func testHttpGetPodSanitizesFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		stub := newStubRegistry(t, "")
+		stub.add("samples/evil", "1.0.0", map[string]string{"evil.yaml": "name: evil\n", "../outside.txt": "oops"})
+
+		dir := t.TempDir()
+		r := registry.NewHttpRegistry(filepath.Join(dir, "spicepods"), stub.server.URL, "")
+
+		_, err := r.GetPod("samples/evil", "")
+		assert.Error(t, err)
+		assert.NoFileExists(t, filepath.Join(dir, "outside.txt"))
+	}
+}
+
+func testHttpSearchFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		stub := newStubRegistry(t, "")
+		stub.add("samples/trader", "1.0.0", map[string]string{"trader.yaml": "name: trader\n"})
+		stub.add("samples/cartpole", "0.1.0", map[string]string{"cartpole.yaml": "name: cartpole\n"})
+
+		r := registry.NewHttpRegistry(t.TempDir(), stub.server.URL, "")
+
+		summaries, err := r.Search("trad")
+		assert.NoError(t, err)
+		assert.Equal(t, []*registry.PodSummary{{Name: "samples/trader", LatestVersion: "1.0.0"}}, summaries)
+
+		summaries, err = r.Search("")
+		assert.NoError(t, err)
+		assert.Len(t, summaries, 2)
+	}
+}
+
+func testHttpListVersionsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		stub := newStubRegistry(t, "")
+		stub.add("samples/trader", "1.0.0", map[string]string{"trader.yaml": "name: trader\n"})
+		stub.add("samples/trader", "1.1.0", map[string]string{"trader.yaml": "name: trader\n"})
+
+		r := registry.NewHttpRegistry(t.TempDir(), stub.server.URL, "")
+
+		versions, err := r.ListVersions("samples/trader")
+		assert.NoError(t, err)
+		if assert.Len(t, versions, 2) {
+			assert.Equal(t, "1.0.0", versions[0].Version)
+			assert.Equal(t, "1.1.0", versions[1].Version)
+			assert.True(t, strings.HasPrefix(versions[0].Checksum, "sha256:"))
+		}
+
+		var itemNotFound *registry.RegistryItemNotFound
+		_, err = r.ListVersions("samples/missing")
+		assert.True(t, errors.As(err, &itemNotFound))
+	}
+}
+
+func testHttpPublishFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		stub := newStubRegistry(t, "secret")
+
+		pkg := zipFiles(t, map[string]string{"trader.yaml": "name: trader\n"})
+
+		_, err := registry.NewHttpRegistry(t.TempDir(), stub.server.URL, "").Publish("samples/trader", "1.0.0", bytes.NewReader(pkg))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "401")
+
+		r := registry.NewHttpRegistry(t.TempDir(), stub.server.URL, "secret")
+		published, err := r.Publish("samples/trader", "1.0.0", bytes.NewReader(pkg))
+		assert.NoError(t, err)
+		if assert.NotNil(t, published) {
+			assert.Equal(t, "1.0.0", published.Version)
+			assert.Equal(t, stub.checksum(pkg), published.Checksum)
+		}
+
+		_, err = r.Publish("samples/trader", "1.0.0", bytes.NewReader(pkg))
+		assert.EqualError(t, err, "pod samples/trader version 1.0.0 is already published to "+strings.TrimPrefix(stub.server.URL, "http://"))
+
+		fetched, err := r.GetPod("samples/trader", "1.0.0")
+		assert.NoError(t, err)
+		assertFileContains(t, fetched.ManifestPath, "name: trader")
+	}
+}
+
+func testGetRegistryUrlFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		stub := newStubRegistry(t, "")
+		stub.add("samples/trader", "1.0.0", map[string]string{"trader.yaml": "name: trader\n"})
+
+		podsDir := t.TempDir()
+		r := registry.GetRegistry(podsDir, "samples/trader", registry.WithUrl(stub.server.URL+"/"))
+		assert.IsType(t, &registry.HttpRegistry{}, r)
+
+		fetched, err := r.GetPod("samples/trader", "")
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(podsDir, "trader.yaml"), fetched.ManifestPath)
+
+		podPath, version := registry.ParsePodReference("samples/trader@1.0.0")
+		assert.Equal(t, "samples/trader", podPath)
+		assert.Equal(t, "1.0.0", version)
+
+		podPath, version = registry.ParsePodReference("samples/trader")
+		assert.Equal(t, "samples/trader", podPath)
+		assert.Equal(t, "", version)
+	}
+}
+
+// An in-memory registry serving the HttpRegistry protocol
+type stubRegistry struct {
+	server *httptest.Server
+	token  string
+
+	mutex sync.Mutex
+	pods  map[string]map[string][]byte
+}
+
+func newStubRegistry(t *testing.T, token string) *stubRegistry {
+	stub := &stubRegistry{token: token, pods: make(map[string]map[string][]byte)}
+	stub.server = httptest.NewServer(http.HandlerFunc(stub.serveHTTP))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func (s *stubRegistry) add(podPath string, version string, files map[string]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.pods[podPath] == nil {
+		s.pods[podPath] = make(map[string][]byte)
+	}
+	s.pods[podPath][version] = zipFiles(nil, files)
+}
+
+func (s *stubRegistry) checksum(pkg []byte) string {
+	return registry.Checksum(pkg)
+}
+
+func (s *stubRegistry) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/pods")
+
+	if path == "" && r.Method == http.MethodGet {
+		summaries := make([]*registry.PodSummary, 0)
+		for podPath := range s.pods {
+			if strings.Contains(podPath, r.URL.Query().Get("q")) {
+				summaries = append(summaries, &registry.PodSummary{Name: podPath, LatestVersion: s.latest(podPath)})
+			}
+		}
+		writeJson(w, http.StatusOK, summaries)
+		return
+	}
+
+	// Pod paths have two parts, so a third is a version
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	podPath := strings.Join(parts[:2], "/")
+	version := ""
+	if len(parts) > 2 {
+		version = parts[2]
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		versions, ok := s.pods[podPath]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if version == "versions" {
+			list := make([]*registry.PodVersion, 0, len(versions))
+			for v, pkg := range versions {
+				list = append(list, &registry.PodVersion{Version: v, Checksum: s.checksum(pkg)})
+			}
+			sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+			writeJson(w, http.StatusOK, list)
+			return
+		}
+		if version == "" {
+			version = s.latest(podPath)
+		}
+		pkg, ok := versions[version]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		_, _ = w.Write(pkg)
+	case http.MethodPut:
+		if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if _, ok := s.pods[podPath][version]; ok {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		pkg, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if s.pods[podPath] == nil {
+			s.pods[podPath] = make(map[string][]byte)
+		}
+		s.pods[podPath][version] = pkg
+		writeJson(w, http.StatusCreated, &registry.PodVersion{Version: version, Checksum: s.checksum(pkg)})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *stubRegistry) latest(podPath string) string {
+	latest := ""
+	for version := range s.pods[podPath] {
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zipWriter.Create(name)
+		if err == nil {
+			_, err = w.Write([]byte(content))
+		}
+		if err != nil && t != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil && t != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func assertFileContains(t *testing.T, path string, content string) {
+	data, err := os.ReadFile(path)
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(data), content)
+	}
+}
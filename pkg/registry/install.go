@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+// Fetches the version of the pod at podPath, or its latest version if version is empty, with the registry
+// GetRegistry selects. The pod is fetched into a staging directory and only copied into podsDir if accept,
+// when set, returns nil for it, so a pod that isn't accepted leaves podsDir unchanged.
+func Install(podsDir string, podPath string, version string, accept func(staged *FetchedPod) error, opts ...Option) (*FetchedPod, error) {
+	stagingDir, err := os.MkdirTemp("", "spicepods-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	staged, err := GetRegistry(stagingDir, podPath, opts...).GetPod(podPath, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if staged.ManifestPath == "" {
+		return nil, fmt.Errorf("pod %s has no manifest", podPath)
+	}
+
+	if accept != nil {
+		err = accept(staged)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	manifestPath, err := filepath.Rel(stagingDir, staged.ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for path := range staged.Files {
+		src := filepath.Join(stagingDir, filepath.FromSlash(path))
+		err = util.CopyFile(src, filepath.Join(podsDir, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, fmt.Errorf("error copying pod %s to %s: %w", podPath, podsDir, err)
+		}
+	}
+
+	installed := *staged
+	installed.ManifestPath = filepath.Join(podsDir, manifestPath)
+
+	return &installed, nil
+}
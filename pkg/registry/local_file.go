@@ -3,92 +3,230 @@ package registry
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/util"
 )
 
-type LocalFileRegistry struct{}
+// The archive formats local pods can be added from
+var archiveExtensions = []string{".zip", ".tar.gz", ".tgz"}
+
+// LocalFileRegistry adds pods from a local directory, or from a .zip or .tar.gz archive of one. An archive has
+// the pod's files either at its root or in a single top-level directory.
+type LocalFileRegistry struct {
+	podsDir string
+}
+
+func (r *LocalFileRegistry) GetPod(podPath string, version string) (*FetchedPod, error) {
+	if version != "" {
+		return nil, fmt.Errorf("local pods don't have versions, but version %s of '%s' was requested", version, podPath)
+	}
+
+	podPath = strings.TrimPrefix(podPath, "file://")
 
-func (r *LocalFileRegistry) GetPod(podPath string) (string, error) {
 	stat, err := os.Stat(podPath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return "", fmt.Errorf("the pod directory '%s' does not exist.", podPath)
+			return nil, fmt.Errorf("the pod directory '%s' does not exist.", podPath)
 		}
-		return "", fmt.Errorf("pod not found at %s: %w", podPath, err)
+		return nil, fmt.Errorf("pod not found at %s: %w", podPath, err)
 	}
 
-	if !stat.IsDir() {
-		return "", fmt.Errorf("expected '%s' to be a directory", podPath)
+	if !stat.IsDir() && archiveExtension(podPath) == "" {
+		return nil, fmt.Errorf("expected '%s' to be a directory, or a .zip or .tar.gz archive", podPath)
 	}
 
 	if !filepath.IsAbs(podPath) {
 		podPath, err = filepath.Abs(podPath)
 		if err != nil {
-			return "", fmt.Errorf("error fetching pod'%s': %w", podPath, err)
+			return nil, fmt.Errorf("error fetching pod'%s': %w", podPath, err)
 		}
 	}
 
-	// Validate source
-	podManifestFileName := fmt.Sprintf("%s.yaml", strings.ToLower(filepath.Base(podPath)))
-	podManifestPath := filepath.Join(context.CurrentContext().PodsDir(), podManifestFileName)
+	var fetched *FetchedPod
+	if stat.IsDir() {
+		fetched, err = copyPodDir(podPath, r.podsDir, filepath.Base(podPath))
+	} else {
+		fetched, err = r.getArchivedPod(podPath)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	if _, err := os.Stat(filepath.Join(podPath, podManifestFileName)); err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return "", fmt.Errorf("the directory '%s' does not contain a manifest. Is it a valid pod?", podPath)
+	fetched.Source = podPath
+
+	return fetched, nil
+}
+
+func (r *LocalFileRegistry) Search(query string) ([]*PodSummary, error) {
+	return nil, &RegistryOperationNotSupported{Registry: "local file", Operation: "search"}
+}
+
+func (r *LocalFileRegistry) ListVersions(podPath string) ([]*PodVersion, error) {
+	return nil, &RegistryOperationNotSupported{Registry: "local file", Operation: "versions"}
+}
+
+func (r *LocalFileRegistry) Publish(podPath string, version string, pkg io.Reader) (*PodVersion, error) {
+	return nil, &RegistryOperationNotSupported{Registry: "local file", Operation: "publishing"}
+}
+
+func (r *LocalFileRegistry) getArchivedPod(archivePath string) (*FetchedPod, error) {
+	extension := archiveExtension(archivePath)
+
+	extractDir, err := os.MkdirTemp("", "spicepod-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(extractDir)
+
+	if extension == ".zip" {
+		err = util.ExtractZipFileToDir(archivePath, extractDir)
+	} else {
+		err = untarFile(archivePath, extractDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error extracting pod archive '%s': %w", archivePath, err)
+	}
+
+	podDir, podName := extractDir, strings.TrimSuffix(filepath.Base(archivePath), extension)
+	if entries, err := os.ReadDir(extractDir); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		podDir, podName = filepath.Join(extractDir, entries[0].Name()), entries[0].Name()
+	}
+
+	return copyPodDir(podDir, r.podsDir, podName)
+}
+
+func untarFile(archivePath string, dir string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return util.Untar(f, dir, true)
+}
+
+// Returns the archive extension of path, or "" if it isn't an archive
+func archiveExtension(path string) string {
+	for _, extension := range archiveExtensions {
+		if strings.HasSuffix(strings.ToLower(path), extension) {
+			return extension
 		}
-		return "", fmt.Errorf("error fetching pod %s: %w", podPath, err)
+	}
+	return ""
+}
+
+// Copies the pod in podDir into podsDir. The pod's manifest is <podName>.yaml, or the only manifest in podDir.
+// Git metadata isn't copied.
+func copyPodDir(podDir string, podsDir string, podName string) (*FetchedPod, error) {
+	stat, err := os.Stat(podDir)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching pod %s: %w", podName, err)
+	}
+
+	// Validate source
+	podManifestFileName, err := findPodManifest(podDir, podName)
+	if err != nil {
+		return nil, err
 	}
 
 	// Prepare destination
-	podsDir := context.CurrentContext().PodsDir()
 	if _, err = os.Stat(podsDir); err != nil {
 		if !errors.Is(err, os.ErrNotExist) {
-			return "", fmt.Errorf("error fetching pod %s: %w", podPath, err)
+			return nil, fmt.Errorf("error fetching pod %s: %w", podName, err)
 		}
 		_, err = util.MkDirAllInheritPerm(podsDir)
 		if err != nil {
-			return "", fmt.Errorf("error fetching pod %s: %w", podPath, err)
+			return nil, fmt.Errorf("error fetching pod %s: %w", podName, err)
 		}
 	}
 
 	// Copy files
 	fileList := make(map[string]fs.DirEntry)
-	err = filepath.WalkDir(podPath, func(path string, d fs.DirEntry, err error) error {
-		if path != podPath {
+	err = filepath.WalkDir(podDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if path != podDir {
 			fileList[path] = d
 		}
-		return err
+		return nil
 	})
 	if err != nil {
-		return "", fmt.Errorf("error fetching pod %s: %w", podPath, err)
+		return nil, fmt.Errorf("error fetching pod %s: %w", podName, err)
 	}
 
 	if len(fileList) == 0 {
-		return "", fmt.Errorf("the directory '%s' is empty", podPath)
+		return nil, fmt.Errorf("the directory '%s' is empty", podDir)
+	}
+
+	fetched := &FetchedPod{
+		ManifestPath: filepath.Join(podsDir, podManifestFileName),
+		Files:        make(map[string]string),
 	}
 
 	for path, d := range fileList {
-		dst := filepath.Join(podsDir, strings.TrimPrefix(path, podPath))
+		relPath, err := filepath.Rel(podDir, path)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching pod %s: %w", podName, err)
+		}
+
+		dst := filepath.Join(podsDir, relPath)
 		if d.IsDir() {
-			if err := os.Mkdir(dst, stat.Mode().Perm()); err != nil {
-				if !errors.Is(err, os.ErrExist) {
-					return "", fmt.Errorf("error creating directory '%s'", dst)
-				}
+			if err := os.MkdirAll(dst, stat.Mode().Perm()); err != nil {
+				return nil, fmt.Errorf("error creating directory '%s'", dst)
 			}
 			continue
 		}
 
-		err := util.CopyFile(path, dst)
+		err = util.CopyFile(path, dst)
+		if err != nil {
+			return nil, fmt.Errorf("error copying file '%s' to '%s'", path, dst)
+		}
+
+		data, err := os.ReadFile(dst)
 		if err != nil {
-			return "", fmt.Errorf("error copying file '%s' to '%s'", path, dst)
+			return nil, fmt.Errorf("error fetching pod %s: %w", podName, err)
 		}
+		fetched.Files[filepath.ToSlash(relPath)] = Checksum(data)
+	}
+
+	fetched.Hash = ContentHash(fetched.Files)
+
+	return fetched, nil
+}
+
+// Returns the file name of the manifest of the pod named podName in podDir
+func findPodManifest(podDir string, podName string) (string, error) {
+	podManifestFileName := fmt.Sprintf("%s.yaml", strings.ToLower(podName))
+	if _, err := os.Stat(filepath.Join(podDir, podManifestFileName)); err == nil {
+		return podManifestFileName, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("error fetching pod %s: %w", podName, err)
+	}
+
+	var manifests []string
+	entries, err := os.ReadDir(podDir)
+	if err != nil {
+		return "", fmt.Errorf("error fetching pod %s: %w", podName, err)
+	}
+	for _, entry := range entries {
+		extension := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (extension == ".yaml" || extension == ".yml") {
+			manifests = append(manifests, entry.Name())
+		}
+	}
+
+	if len(manifests) != 1 {
+		return "", fmt.Errorf("the directory '%s' does not contain a manifest. Is it a valid pod?", podDir)
 	}
 
-	return podManifestPath, nil
+	return manifests[0], nil
 }
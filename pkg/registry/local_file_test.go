@@ -1,10 +1,15 @@
 package registry_test
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spiceai/spiceai/pkg/constants"
+	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/pods"
 	"github.com/spiceai/spiceai/pkg/registry"
 	"github.com/spiceai/spiceai/pkg/testutils"
@@ -14,17 +19,31 @@ import (
 func TestLocalFileRegistry(t *testing.T) {
 	testutils.EnsureTestSpiceDirectory(t)
 	t.Run("testGetPod() -- Local registry should fetch pod", testGetPod())
+	t.Run("GetPod() - Pods are fetched from .zip and .tar.gz archives", testGetArchivedPodFunc())
+	t.Run("GetPod() - Archive files outside the pods directory are rejected", testGetArchivedPodSanitizesFunc())
 	t.Cleanup(testutils.CleanupTestSpiceDirectory)
 }
 
 func testGetPod() func(*testing.T) {
 	return func(t *testing.T) {
+		rtcontext, err := context.NewDefaultContext()
+		if err != nil {
+			t.Fatal(err)
+		}
+
 		manifestPath := "../../test/assets/pods/trader"
-		r := registry.GetRegistry(manifestPath)
-		_, err := r.GetPod(manifestPath)
+		r := registry.GetRegistry(rtcontext.PodsDir(), manifestPath)
+		fetched, err := r.GetPod(manifestPath, "")
 		assert.NoError(t, err)
 		defer os.RemoveAll(constants.SpicePodsDirectoryName)
 
+		if assert.NotNil(t, fetched) {
+			assert.Equal(t, "", fetched.Version)
+			assert.Contains(t, fetched.Files, "trader.yaml")
+			assert.Contains(t, fetched.Files, "data/fake-data.json")
+			assert.Equal(t, registry.ContentHash(fetched.Files), fetched.Hash)
+		}
+
 		_, err = os.Stat("spicepods/data")
 		assert.NoError(t, err)
 
@@ -37,3 +56,78 @@ func testGetPod() func(*testing.T) {
 		}
 	}
 }
+
+func testGetArchivedPodFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		files := map[string]string{"trader.yaml": "name: trader\n", "rewards/trader.py": "reward = 1\n", "data/trader.csv": "time\n"}
+
+		archivesDir := t.TempDir()
+		zipPath := filepath.Join(archivesDir, "trader.zip")
+		writeFile(t, zipPath, string(zipFiles(t, files)))
+
+		// A single top-level directory, as in GitHub's archives, is the pod's directory
+		tarPath := filepath.Join(archivesDir, "trader-1.0.0.tar.gz")
+		nested := make(map[string]string)
+		for name, content := range files {
+			nested["trader-1.0.0/"+name] = content
+		}
+		writeFile(t, tarPath, string(tarGzFiles(t, nested)))
+
+		for _, archivePath := range []string{zipPath, tarPath} {
+			podsDir := filepath.Join(t.TempDir(), "spicepods")
+			r := registry.GetRegistry(podsDir, archivePath)
+
+			fetched, err := r.GetPod(archivePath, "")
+			if assert.NoError(t, err, archivePath) {
+				assert.Equal(t, filepath.Join(podsDir, "trader.yaml"), fetched.ManifestPath)
+				assert.Equal(t, archivePath, fetched.Source)
+				assert.Equal(t, []string{"data/trader.csv", "rewards/trader.py", "trader.yaml"}, sortedKeys(fetched.Files))
+				assertFileContains(t, filepath.Join(podsDir, "rewards", "trader.py"), "reward = 1")
+			}
+		}
+
+		_, err := registry.GetRegistry(t.TempDir(), zipPath).GetPod("file://"+zipPath, "")
+		assert.NoError(t, err)
+	}
+}
+
+func testGetArchivedPodSanitizesFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		dir := t.TempDir()
+
+		zipPath := filepath.Join(dir, "evil.zip")
+		writeFile(t, zipPath, string(zipFiles(t, map[string]string{"evil.yaml": "name: evil\n", "../../outside.txt": "oops"})))
+		tarPath := filepath.Join(dir, "evil.tar.gz")
+		writeFile(t, tarPath, string(tarGzFiles(t, map[string]string{"evil.yaml": "name: evil\n", "../../outside.txt": "oops"})))
+
+		for _, archivePath := range []string{zipPath, tarPath} {
+			podsDir := filepath.Join(t.TempDir(), "spicepods")
+			_, err := registry.GetRegistry(podsDir, archivePath).GetPod(archivePath, "")
+			assert.Error(t, err, archivePath)
+			assert.NoDirExists(t, podsDir)
+		}
+		assert.NoFileExists(t, filepath.Join(os.TempDir(), "outside.txt"))
+	}
+}
+
+func tarGzFiles(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err == nil {
+			_, err = tarWriter.Write([]byte(content))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const LockfileName = "spicepods.lock"
+
+const lockfileHeader = "# Written by spice add and spice update to pin the pods added to this app. Don't edit by hand.\n"
+
+// The source, version and content hash a pod was added at
+type LockedPod struct {
+	// The registry URL, or the local pod's absolute path
+	Source  string `json:"source" yaml:"source"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// The ContentHash of the pod's files
+	Hash string `json:"hash" yaml:"hash"`
+}
+
+// The pods added to an app, by the path they were added with, such as samples/LogPruner
+type Lockfile struct {
+	Pods map[string]*LockedPod `yaml:"pods"`
+
+	path string
+}
+
+// Loads the lockfile at path. A missing file has no pods.
+func LoadLockfile(path string) (*Lockfile, error) {
+	l := &Lockfile{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err == nil {
+		err = yaml.Unmarshal(data, l)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+	}
+
+	if l.Pods == nil {
+		l.Pods = make(map[string]*LockedPod)
+	}
+
+	return l, nil
+}
+
+func (l *Lockfile) Save() error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(l.path, append([]byte(lockfileHeader), data...), 0644)
+}
+
+func (l *Lockfile) Path() string {
+	return l.path
+}
+
+// Returns the locked pod added with podPath, or nil if it isn't locked
+func (l *Lockfile) Get(podPath string) *LockedPod {
+	return l.Pods[podPath]
+}
+
+// Locks the pod added with podPath at the source, version and hash it was fetched with
+func (l *Lockfile) Set(podPath string, fetched *FetchedPod) {
+	l.Pods[podPath] = &LockedPod{
+		Source:  fetched.Source,
+		Version: fetched.Version,
+		Hash:    fetched.Hash,
+	}
+}
+
+// Returns an error if the fetched pod doesn't have the locked version and content
+func (locked *LockedPod) Verify(podPath string, fetched *FetchedPod) error {
+	if locked.Version != fetched.Version {
+		return fmt.Errorf("pod %s is locked at version %s in %s, but version %s was fetched", podPath, locked.Version, LockfileName, fetched.Version)
+	}
+	if locked.Hash != fetched.Hash {
+		return fmt.Errorf("pod %s doesn't match %s: its content hash is %s, but %s is locked", podPath, LockfileName, fetched.Hash, locked.Hash)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package registry_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiceai/spiceai/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockfile(t *testing.T) {
+	t.Run("LoadLockfile() - A missing lockfile has no pods", testLoadMissingLockfileFunc())
+	t.Run("Save() - Locked pods are written and read back", testSaveLockfileFunc())
+	t.Run("Verify() - Pods not matching the lockfile are rejected", testVerifyLockedPodFunc())
+	t.Run("Install() - Pods are only installed once accepted", testInstallFunc())
+}
+
+func testLoadMissingLockfileFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		l, err := registry.LoadLockfile(filepath.Join(t.TempDir(), registry.LockfileName))
+		assert.NoError(t, err)
+		assert.Empty(t, l.Pods)
+		assert.Nil(t, l.Get("samples/trader"))
+	}
+}
+
+func testSaveLockfileFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), registry.LockfileName)
+
+		l, err := registry.LoadLockfile(path)
+		assert.NoError(t, err)
+		l.Set("samples/trader", &registry.FetchedPod{Source: registry.DefaultRegistryUrl, Version: "1.0.0", Hash: "sha256:1"})
+		l.Set("../pods/cartpole", &registry.FetchedPod{Source: "/home/pods/cartpole", Hash: "sha256:2"})
+		assert.NoError(t, l.Save())
+
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "# Written by spice add")
+
+		l, err = registry.LoadLockfile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*registry.LockedPod{
+			"samples/trader":   {Source: registry.DefaultRegistryUrl, Version: "1.0.0", Hash: "sha256:1"},
+			"../pods/cartpole": {Source: "/home/pods/cartpole", Hash: "sha256:2"},
+		}, l.Pods)
+	}
+}
+
+func testVerifyLockedPodFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		locked := &registry.LockedPod{Source: registry.DefaultRegistryUrl, Version: "1.0.0", Hash: "sha256:1"}
+
+		assert.NoError(t, locked.Verify("samples/trader", &registry.FetchedPod{Version: "1.0.0", Hash: "sha256:1"}))
+		assert.EqualError(t, locked.Verify("samples/trader", &registry.FetchedPod{Version: "1.1.0", Hash: "sha256:1"}),
+			"pod samples/trader is locked at version 1.0.0 in spicepods.lock, but version 1.1.0 was fetched")
+		assert.EqualError(t, locked.Verify("samples/trader", &registry.FetchedPod{Version: "1.0.0", Hash: "sha256:2"}),
+			"pod samples/trader doesn't match spicepods.lock: its content hash is sha256:2, but sha256:1 is locked")
+	}
+}
+
+func testInstallFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		registryDir := t.TempDir()
+		pkg := zipFiles(t, map[string]string{"trader.yaml": "name: trader\n", "data/trader.csv": "time\n"})
+		_, err := registry.NewDirectoryRegistry("", registryDir).Publish("samples/trader", "1.0.0", bytes.NewReader(pkg))
+		assert.NoError(t, err)
+
+		podsDir := filepath.Join(t.TempDir(), "spicepods")
+		rejected := errors.New("rejected")
+
+		_, err = registry.Install(podsDir, "samples/trader", "", func(staged *registry.FetchedPod) error {
+			assertFileContains(t, staged.ManifestPath, "name: trader")
+			return rejected
+		}, registry.WithUrl("file://"+registryDir))
+		assert.Equal(t, rejected, err)
+		assert.NoDirExists(t, podsDir)
+
+		installed, err := registry.Install(podsDir, "samples/trader", "", nil, registry.WithUrl("file://"+registryDir))
+		assert.NoError(t, err)
+		if assert.NotNil(t, installed) {
+			assert.Equal(t, filepath.Join(podsDir, "trader.yaml"), installed.ManifestPath)
+			assert.Equal(t, "file://"+registryDir, installed.Source)
+			assert.Equal(t, "1.0.0", installed.Version)
+			assert.Equal(t, registry.ContentHash(installed.Files), installed.Hash)
+			assertFileContains(t, installed.ManifestPath, "name: trader")
+			assertFileContains(t, filepath.Join(podsDir, "data", "trader.csv"), "time")
+		}
+	}
+}
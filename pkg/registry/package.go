@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spiceai/spiceai/pkg/util"
+)
+
+const (
+	ReadmeFileName = "README.md"
+)
+
+// PackageMetadata describes a pod package. It is written to the package as <name>.package.json, which isn't
+// extracted, and the files in the package are verified against it when the package is extracted.
+type PackageMetadata struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// sha256:<hex> of each file in the package by its path in the package
+	Files map[string]string `json:"files"`
+}
+
+// Returns the name of the metadata file in the package of the pod named podName
+func PackageMetadataName(podName string) string {
+	return fmt.Sprintf("%s.package.json", podName)
+}
+
+// Packages the version of the pod named podName with the manifest at manifestPath and the files it refers to,
+// returning the package as a zip in the layout GetPod extracts. The manifest is written as <podName>.yaml at
+// the root of the zip. Referenced files are read relative to the working directory, as the runtime reads them,
+// and must be within the manifest's directory. They are written at their path relative to that directory,
+// along with the README.md next to the manifest if there is one.
+func Package(podName string, version string, manifestPath string, files []string) ([]byte, *PackageMetadata, error) {
+	podDir, err := filepath.Abs(filepath.Dir(manifestPath))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Package path to path on disk
+	contents := map[string]string{
+		fmt.Sprintf("%s.yaml", podName): manifestPath,
+	}
+
+	for _, file := range files {
+		absPath, err := filepath.Abs(file)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		relPath, err := filepath.Rel(podDir, absPath)
+		if err == nil {
+			err = util.SanitizeExtractPath(relPath, podDir)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("'%s' is outside the pod's directory %s, so can't be packaged", file, podDir)
+		}
+
+		contents[filepath.ToSlash(relPath)] = file
+	}
+
+	readmePath := filepath.Join(podDir, ReadmeFileName)
+	if _, err := os.Stat(readmePath); err == nil {
+		contents[ReadmeFileName] = readmePath
+	}
+
+	names := make([]string, 0, len(contents))
+	for name := range contents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	metadata := &PackageMetadata{
+		Name:    podName,
+		Version: version,
+		Files:   make(map[string]string, len(names)),
+	}
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+
+	for _, name := range names {
+		data, err := os.ReadFile(contents[name])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to package pod %s: %w", podName, err)
+		}
+
+		err = writeZipFile(zipWriter, name, data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to package pod %s: %w", podName, err)
+		}
+
+		metadata.Files[name] = Checksum(data)
+	}
+
+	metadataBytes, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = writeZipFile(zipWriter, PackageMetadataName(podName), metadataBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to package pod %s: %w", podName, err)
+	}
+
+	err = zipWriter.Close()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to package pod %s: %w", podName, err)
+	}
+
+	return buf.Bytes(), metadata, nil
+}
+
+// Files are written without modification times so packaging the same files gives the same checksum
+func writeZipFile(zipWriter *zip.Writer, name string, data []byte) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	header.SetMode(0644)
+	w, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
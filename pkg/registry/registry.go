@@ -1,22 +1,139 @@
 package registry
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+)
+
+const (
+	DefaultRegistryUrl = "https://api.spicerack.org/api/v0.1"
 )
 
 type SpiceRegistry interface {
-	GetPod(podPath string) (string, error)
+	// Fetches the version of the pod at podPath, or its latest version if version is empty, into the pods
+	// directory
+	GetPod(podPath string, version string) (*FetchedPod, error)
+	// Returns the pods matching query, or every pod if query is empty
+	Search(query string) ([]*PodSummary, error)
+	// Returns the published versions of the pod at podPath
+	ListVersions(podPath string) ([]*PodVersion, error)
+	// Publishes the zipped pod read from pkg as the version of the pod at podPath
+	Publish(podPath string, version string, pkg io.Reader) (*PodVersion, error)
+}
+
+// A pod fetched into the pods directory
+type FetchedPod struct {
+	ManifestPath string
+	// Where the pod was fetched from: a registry's URL, or a local pod's absolute path
+	Source string
+	// The version fetched, if known. Local pods don't have versions.
+	Version string
+	// The ContentHash of the fetched files
+	Hash string
+	// The checksum of each fetched file by its path relative to the pods directory
+	Files map[string]string
+}
+
+type PodSummary struct {
+	Name          string `json:"name" yaml:"name"`
+	Description   string `json:"description,omitempty" yaml:"description,omitempty"`
+	LatestVersion string `json:"latest_version,omitempty" yaml:"latest_version,omitempty"`
+}
+
+type PodVersion struct {
+	Version string `json:"version" yaml:"version"`
+	// sha256:<hex> of the version's zip
+	Checksum    string    `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+	PublishedAt time.Time `json:"published_at,omitempty" yaml:"published_at,omitempty"`
+}
+
+type options struct {
+	url   string
+	token string
+}
+
+type Option func(*options)
+
+// Sets the base URL of the registry pods that aren't local are fetched from. Defaults to DefaultRegistryUrl.
+// A file:// URL selects a DirectoryRegistry in that directory.
+func WithUrl(url string) Option {
+	return func(o *options) {
+		if url != "" {
+			o.url = url
+		}
+	}
+}
+
+// Sets the bearer token sent to the registry
+func WithToken(token string) Option {
+	return func(o *options) {
+		o.token = token
+	}
 }
 
-func GetRegistry(path string) SpiceRegistry {
+// Returns the registry able to fetch the pod at path into podsDir: a GitRegistry for git+ references, a
+// LocalFileRegistry for local directories and archives, or the registry at the configured URL
+func GetRegistry(podsDir string, path string, opts ...Option) SpiceRegistry {
+	o := &options{url: DefaultRegistryUrl}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if strings.HasPrefix(path, gitPrefix) {
+		return NewGitRegistry(podsDir)
+	}
+
 	if strings.HasPrefix(path, "/") || strings.HasPrefix(path, "../") || strings.HasPrefix(path, "file://") {
-		return &LocalFileRegistry{}
+		return &LocalFileRegistry{podsDir: podsDir}
 	}
 
 	if _, err := os.Stat(path); err == nil {
-		return &LocalFileRegistry{}
+		return &LocalFileRegistry{podsDir: podsDir}
+	}
+
+	if strings.HasPrefix(o.url, "file://") {
+		return NewDirectoryRegistry(podsDir, strings.TrimPrefix(o.url, "file://"))
+	}
+
+	return NewHttpRegistry(podsDir, o.url, o.token)
+}
+
+// Splits a pod reference such as samples/LogPruner@1.0.0 into the pod's path and version, which is empty if
+// the reference has none. The version follows the last "/", so URLs with a user, such as
+// git+https://user@host/pods.git, aren't split.
+func ParsePodReference(ref string) (podPath string, version string) {
+	if i := strings.LastIndex(ref, "@"); i > 0 && i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// Returns the checksum registries identify a version's zip by, sha256:<hex>
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Returns a hash of a pod's files from the checksum of each by its path. It depends only on the files'
+// paths and contents, so it is the same however the pod was packaged or fetched.
+func ContentHash(files map[string]string) string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	for _, path := range paths {
+		fmt.Fprintf(&b, "%s %s\n", filepath.ToSlash(path), files[path])
 	}
 
-	return &SpiceRackRegistry{}
+	return Checksum([]byte(b.String()))
 }
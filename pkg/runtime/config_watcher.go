@@ -0,0 +1,123 @@
+package runtime
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"github.com/spiceai/spiceai/pkg/config"
+	"github.com/spiceai/spiceai/pkg/constants"
+	"go.uber.org/zap"
+)
+
+// Reloads the configuration when the config file in the app directory changes.
+// SIGHUP is process-wide, so it is handled by the spiced command which calls ReloadConfig.
+func (r *Runtime) watchConfig() error {
+	appDir := r.rtcontext.AppDir()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error starting '%s' watcher: %w", appDir, err)
+	}
+
+	// Watch the directory rather than the file so configs created or replaced by editors are seen
+	if err := watcher.Add(appDir); err != nil {
+		// Not fatal, the configuration can still be reloaded with ReloadConfig
+		zaplog.Error("error starting config watcher", zap.String("dir", appDir), zap.Error(err))
+		watcher.Close()
+		return nil
+	}
+
+	r.configWatcher = watcher
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if isConfigFile(event.Name) && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					r.reloadConfigAndLog()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				zaplog.Error("error from config watcher", zap.String("dir", appDir), zap.Error(err))
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (r *Runtime) stopWatchingConfig() {
+	if r.configWatcher != nil {
+		r.configWatcher.Close()
+		r.configWatcher = nil
+	}
+}
+
+// Reloads the configuration from the config file, env vars and bound flags.
+// Changes to log level, CORS origins, ingestion limits and training concurrency are applied immediately,
+// changes to other fields are reported and take effect on restart.
+// Safe to call from a signal handler goroutine.
+func (r *Runtime) ReloadConfig() error {
+	v := viper.New()
+	if r.flags != nil {
+		err := config.BindFlags(v, r.flags)
+		if err != nil {
+			return err
+		}
+	}
+
+	newConfig, err := config.LoadRuntimeConfiguration(v, r.rtcontext.AppDir())
+	if err != nil {
+		return err
+	}
+
+	currentConfig := r.Config()
+	for _, key := range currentConfig.RestartRequiredChanges(newConfig) {
+		zaplog.Warn("configuration changed, restart the runtime to apply it", zap.String("key", key))
+	}
+
+	updatedConfig := currentConfig.WithReloadableFields(newConfig)
+
+	if r.configHook != nil {
+		err = r.configHook(updatedConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.aiEngine.Scheduler().SetMaxConcurrent(updatedConfig.Training.MaxConcurrent)
+
+	r.configMutex.Lock()
+	r.config = updatedConfig
+	r.viper = v
+	r.configMutex.Unlock()
+
+	if r.server != nil {
+		r.server.UpdateConfig(updatedConfig)
+	}
+
+	return nil
+}
+
+func (r *Runtime) reloadConfigAndLog() {
+	err := r.ReloadConfig()
+	if err != nil {
+		zaplog.Error("error reloading configuration", zap.Error(err))
+		return
+	}
+	zaplog.Info("reloaded configuration")
+}
+
+func isConfigFile(path string) bool {
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) == constants.SpiceConfigBaseName && (ext == ".yaml" || ext == ".yml")
+}
@@ -2,14 +2,14 @@ package runtime
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"sync"
 
-	"github.com/logrusorgru/aurora"
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/spiceai/spiceai/pkg/aiengine"
+	"github.com/spiceai/spiceai/pkg/audit"
 	"github.com/spiceai/spiceai/pkg/config"
 	"github.com/spiceai/spiceai/pkg/context"
 	"github.com/spiceai/spiceai/pkg/environment"
@@ -21,123 +21,222 @@ import (
 	"go.uber.org/zap"
 )
 
-type SpiceRuntime struct {
-	config *config.SpiceConfiguration
-	viper  *viper.Viper
+// Runtime owns everything a Spice runtime needs: its context, configuration,
+// pod registry, AI engine, environment and HTTP server.
+// Multiple runtimes can exist in the same process.
+type Runtime struct {
+	configMutex sync.RWMutex
+	config      *config.SpiceConfiguration
+	viper       *viper.Viper
+	flags       *pflag.FlagSet
+	rtcontext   context.RuntimeContext
+	pods        *pods.Registry
+	aiEngine    *aiengine.AIEngine
+	environment *environment.Environment
+	server      *spice_http.Server
+	auditLog    *audit.Log
+
+	configWatcher *fsnotify.Watcher
+	configHook    func(*config.SpiceConfiguration) error
+}
+
+type Option func(*Runtime)
+
+// Sets the runtime context. Defaults to an initialized "metal" context.
+func WithContext(rtcontext context.RuntimeContext) Option {
+	return func(r *Runtime) {
+		r.rtcontext = rtcontext
+	}
+}
+
+// Sets the runtime configuration, skipping loading it from the app directory
+func WithConfig(config *config.SpiceConfiguration) Option {
+	return func(r *Runtime) {
+		r.config = config
+	}
+}
+
+// Sets the viper instance used to load configuration and bind flags
+func WithViper(v *viper.Viper) Option {
+	return func(r *Runtime) {
+		r.viper = v
+	}
+}
+
+// Sets a function called with the configuration when it is loaded and after every reload.
+// Process-wide settings like the logger belong here rather than in the runtime,
+// as multiple runtimes can exist in the same process.
+func WithConfigHook(hook func(*config.SpiceConfiguration) error) Option {
+	return func(r *Runtime) {
+		r.configHook = hook
+	}
 }
 
 var (
-	runtime *SpiceRuntime
-	zaplog  *zap.Logger = loggers.ZapLogger()
+	zaplog *zap.Logger = loggers.ZapLogger()
 )
 
-func GetSpiceRuntime() *SpiceRuntime {
-	if runtime == nil {
-		runtime = &SpiceRuntime{
-			viper: viper.New(),
+func NewRuntime(opts ...Option) (*Runtime, error) {
+	r := &Runtime{}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.rtcontext == nil {
+		rtcontext, err := context.NewDefaultContext()
+		if err != nil {
+			return nil, err
 		}
+		r.rtcontext = rtcontext
+	}
+
+	if r.viper == nil {
+		r.viper = viper.New()
 	}
-	return runtime
+
+	r.auditLog = audit.NewLog(audit.LogPath(r.rtcontext.AppDir()))
+	r.pods = pods.NewRegistry()
+	r.aiEngine = aiengine.NewAIEngine(r.rtcontext)
+	r.environment = environment.NewEnvironment(r.aiEngine, r.pods)
+	r.pods.Watch(r.onPodEvent)
+
+	return r, nil
 }
 
-func (r *SpiceRuntime) LoadConfig() error {
-	var err error
-	if r.config == nil {
-		appDir := context.CurrentContext().AppDir()
-		r.config, err = config.LoadRuntimeConfiguration(r.viper, appDir)
+func (r *Runtime) Context() context.RuntimeContext {
+	return r.rtcontext
+}
+
+func (r *Runtime) Config() *config.SpiceConfiguration {
+	r.configMutex.RLock()
+	defer r.configMutex.RUnlock()
+
+	return r.config
+}
+
+func (r *Runtime) Pods() *pods.Registry {
+	return r.pods
+}
+
+func (r *Runtime) AIEngine() *aiengine.AIEngine {
+	return r.aiEngine
+}
+
+func (r *Runtime) Environment() *environment.Environment {
+	return r.environment
+}
+
+func (r *Runtime) LoadConfig() error {
+	if r.Config() != nil {
+		return nil
+	}
+
+	rtConfig, err := config.LoadRuntimeConfiguration(r.viper, r.rtcontext.AppDir())
+	if err != nil {
+		return err
 	}
 
-	return err
+	r.configMutex.Lock()
+	r.config = rtConfig
+	r.configMutex.Unlock()
+
+	return nil
 }
 
-func (r *SpiceRuntime) SingleRun(manifestPath string) error {
+func (r *Runtime) SingleRun(manifestPath string) error {
 	err := r.startRuntime()
 	if err != nil {
 		return err
 	}
 
-	err = aiengine.Init()
+	err = r.aiEngine.Init()
 	if err != nil {
 		return err
 	}
 
 	aiEngineReady := make(chan bool, 1)
-	err = aiengine.StartServer(aiEngineReady, true)
+	err = r.aiEngine.StartServer(aiEngineReady, true)
 	if err != nil {
 		return err
 	}
 
-	err = spice_http.NewServer(runtime.config.HttpPort).Start()
+	err = r.startServer()
 	if err != nil {
 		return err
 	}
 
 	<-aiEngineReady
 
-	runtime.printStartupBanner("Single training run")
+	r.printStartupBanner("Single training run")
 
-	pod, err := initializePod(manifestPath)
+	pod, err := r.initializePod(manifestPath)
 	if err != nil {
 		return err
 	}
 
-	err = environment.InitDataConnectors()
+	err = r.environment.InitDataConnectors()
 	if err != nil {
 		return err
 	}
 
 	// Pass nil trainModel to use pod's default
-	err = aiengine.StartTraining(pod, nil)
+	err = r.aiEngine.StartTraining(pod, nil)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(aurora.Green("Exiting after single training run."))
+	zaplog.Info(loggers.Color().Green("Exiting after single training run").String(), loggers.Pod(pod.Name))
 
 	return nil
 }
 
-func (r *SpiceRuntime) Run() error {
+func (r *Runtime) Run() error {
 	err := r.startRuntime()
 	if err != nil {
 		return err
 	}
 
-	err = aiengine.Init()
+	err = r.aiEngine.Init()
 	if err != nil {
 		return err
 	}
 
 	aiEngineReady := make(chan bool)
-	err = aiengine.StartServer(aiEngineReady, false)
+	err = r.aiEngine.StartServer(aiEngineReady, false)
 	if err != nil {
 		return err
 	}
 
-	err = spice_http.NewServer(runtime.config.HttpPort).Start()
+	err = r.startServer()
 	if err != nil {
 		return err
 	}
 
 	<-aiEngineReady
 
-	runtime.printStartupBanner("")
+	r.printStartupBanner("")
 
-	err = runtime.scanForPods()
+	err = r.scanForPods()
 	if err != nil {
-		log.Printf("error scanning for pods: %s", err.Error())
+		zaplog.Error("error scanning for pods", zap.Error(err))
 		return err
 	}
 
-	if runtime.config.DevelopmentMode {
-		err = watchPods()
+	if r.Config().DevelopmentMode {
+		err = r.watchPods()
 		if err != nil {
 			zaplog.Sugar().Errorf("error watching for pods: %s", err.Error())
 			return err
 		}
 	}
 
-	err = environment.InitDataConnectors()
+	err = r.watchConfig()
+	if err != nil {
+		zaplog.Sugar().Errorf("error watching for configuration changes: %s", err.Error())
+		return err
+	}
+
+	err = r.environment.InitDataConnectors()
 	if err != nil {
 		return err
 	}
@@ -145,23 +244,45 @@ func (r *SpiceRuntime) Run() error {
 	return nil
 }
 
-func (r *SpiceRuntime) BindFlags(developmentFlag *pflag.Flag) error {
-	err := r.viper.BindPFlag("development_mode", developmentFlag)
+// Binds the configuration flags registered by config.AddFlags, so they override the config file and env vars
+func (r *Runtime) BindFlags(flags *pflag.FlagSet) error {
+	err := config.BindFlags(r.viper, flags)
 	if err != nil {
 		return err
 	}
+	r.flags = flags
 	return nil
 }
 
-func (r *SpiceRuntime) Shutdown() {
-	log.Println("Shutting down...")
+func (r *Runtime) Shutdown() {
+	zaplog.Info("shutting down")
+
+	r.stopWatchingConfig()
+
+	if r.Config().Persistence.Enabled {
+		r.savePodsState()
+	}
 
 	wg := new(sync.WaitGroup)
 	wg.Add(1)
 
 	go func() {
 		defer wg.Done()
-		err := aiengine.StopServer()
+		err := r.aiEngine.StopServer()
+		if err != nil {
+			zaplog.Sugar().Debug(err.Error())
+			return
+		}
+	}()
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		if r.server == nil {
+			return
+		}
+		err := r.server.Shutdown()
 		if err != nil {
 			zaplog.Sugar().Debug(err.Error())
 			return
@@ -183,41 +304,53 @@ func (r *SpiceRuntime) Shutdown() {
 	wg.Wait()
 }
 
-func (r *SpiceRuntime) printStartupBanner(runMode string) {
-	fmt.Printf("- Runtime version: %s\n", version.Version())
+func (r *Runtime) printStartupBanner(runMode string) {
+	color := loggers.Color()
+	rtConfig := r.Config()
+
+	host := rtConfig.BindAddress
+	if host == "" {
+		host = "localhost"
+	}
+	address := fmt.Sprintf("http://%s:%d", host, rtConfig.HttpPort)
+
+	fields := []zap.Field{
+		zap.String("version", version.Version()),
+		zap.String("address", address),
+		zap.Bool("development_mode", rtConfig.DevelopmentMode),
+	}
 	if runMode != "" {
-		fmt.Printf("- %s\n", runMode)
+		fields = append(fields, zap.String("mode", runMode))
+	}
+	zaplog.Info(color.Green("Listening on "+address).String(), fields...)
+
+	if rtConfig.DevelopmentMode {
+		zaplog.Info(color.Yellow("Development mode").String())
 	}
-	if r.config.DevelopmentMode {
-		fmt.Print("- ")
-		fmt.Println(aurora.Yellow("Development mode"))
+	if loggers.IsInteractive() {
+		zaplog.Info("Use Ctrl-C to stop")
 	}
-	fmt.Print("- ")
-	fmt.Println(aurora.Green(fmt.Sprintf("Listening on http://localhost:%d", runtime.config.HttpPort)))
-	fmt.Println()
-	fmt.Println("Use Ctrl-C to stop")
 }
 
-func (r *SpiceRuntime) scanForPods() error {
-	_, err := os.Stat(context.CurrentContext().AppDir())
+func (r *Runtime) scanForPods() error {
+	_, err := os.Stat(r.rtcontext.AppDir())
 	if err != nil {
 		// No app directory means no pods
 		return nil
 	}
 
-	podsManifestDir := context.CurrentContext().PodsDir()
+	podsManifestDir := r.rtcontext.PodsDir()
 	_, err = os.Stat(podsManifestDir)
 	if err != nil {
 		// No spicepods directory means no pods
 		return nil
 	}
 
-	manifestPaths := pods.FindAllManifestPaths()
+	manifestPaths := pods.FindAllManifestPaths(podsManifestDir)
 
 	for _, manifestPath := range manifestPaths {
-		_, err = initializePod(manifestPath)
+		_, err = r.initializePod(manifestPath)
 		if err != nil {
-			log.Println(fmt.Errorf("error loading pod manifest %s: %w", manifestPath, err))
 			continue
 		}
 	}
@@ -225,34 +358,73 @@ func (r *SpiceRuntime) scanForPods() error {
 	return nil
 }
 
-func (r *SpiceRuntime) startRuntime() error {
-	err := runtime.LoadConfig()
+func (r *Runtime) startRuntime() error {
+	err := r.LoadConfig()
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("Loading Spice runtime ...")
+	zaplog.Info("loading Spice runtime")
+
+	rtConfig := r.Config()
+
+	if r.configHook != nil {
+		err = r.configHook(rtConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.aiEngine.Configure(rtConfig.AIEngine)
+	r.aiEngine.Scheduler().SetMaxConcurrent(rtConfig.Training.MaxConcurrent)
+
+	r.setDefaultPodParams(rtConfig)
+
+	if rtConfig.Persistence.Enabled {
+		for _, dir := range []string{rtConfig.DataDir, rtConfig.StateDir} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("error creating %s: %w", dir, err)
+			}
+		}
+	}
 
 	return nil
 }
 
-func initializePod(manifestPath string) (*pods.Pod, error) {
-	newPod, err := pods.LoadPodFromManifest(manifestPath)
+func (r *Runtime) setDefaultPodParams(rtConfig *config.SpiceConfiguration) {
+	defaultParams := pods.NewPodParams()
+	defaultParams.Period = rtConfig.Retention.Period
+	defaultParams.Interval = rtConfig.Retention.Interval
+	defaultParams.Granularity = rtConfig.Retention.Granularity
+	defaultParams.Quotas = rtConfig.Quotas
+	if rtConfig.Persistence.Enabled {
+		defaultParams.DataDir = rtConfig.DataDir
+	}
+	r.pods.SetDefaultParams(defaultParams)
+}
+
+func (r *Runtime) startServer() error {
+	r.server = spice_http.NewServer(r.Config(), r.rtcontext, r.pods, r.aiEngine, r.environment)
+	return r.server.Start()
+}
+
+func (r *Runtime) initializePod(manifestPath string) (*pods.Pod, error) {
+	newPod, err := r.pods.LoadPodFromManifest(manifestPath)
 	if err != nil {
-		log.Println(fmt.Errorf("error loading pod manifest %s: %w", manifestPath, err))
+		zaplog.Error("error loading pod manifest", zap.String("manifest", manifestPath), zap.Error(err))
 		return nil, err
 	}
 
-	pods.CreateOrUpdatePod(newPod)
+	r.pods.CreateOrUpdatePod(newPod)
 
-	err = aiengine.InitializePod(newPod)
+	err = r.aiEngine.InitializePod(newPod)
 	if err != nil {
-		log.Println(fmt.Errorf("error initializing pod %s: %w", newPod.Name, err))
+		zaplog.Error("error initializing pod", loggers.Pod(newPod.Name), zap.Error(err))
 		return nil, err
 	}
 
 	for _, ds := range newPod.Dataspaces() {
-		fmt.Printf("Loaded dataspace %s\n", aurora.BrightCyan(ds.Name()))
+		zaplog.Info("loaded dataspace "+loggers.Color().BrightCyan(ds.Name()).String(), loggers.Pod(newPod.Name), loggers.Dataspace(ds.Name()))
 	}
 
 	return newPod, nil
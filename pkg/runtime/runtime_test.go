@@ -0,0 +1,367 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spiceai/spiceai/pkg/aiengine"
+	"github.com/spiceai/spiceai/pkg/audit"
+	"github.com/spiceai/spiceai/pkg/config"
+	spice_context "github.com/spiceai/spiceai/pkg/context"
+	spice_http "github.com/spiceai/spiceai/pkg/http"
+	"github.com/spiceai/spiceai/pkg/interpretations"
+	"github.com/spiceai/spiceai/pkg/proto/aiengine_pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+)
+
+func TestRuntime(t *testing.T) {
+	t.Run("processPodManifestEvent() - Reloads are safe with concurrent API reads", testConcurrentReloadsFunc())
+	t.Run("processPodManifestEvent() - Manifest changes are recorded in the audit log", testAuditManifestChangesFunc())
+	t.Run("onPodEvent() - Interpretations are persisted across pod reloads", testPersistInterpretationsFunc())
+	t.Run("onPodEvent() - Interpretations are saved when they change", testSaveChangedInterpretationsFunc())
+	t.Run("Validate() - Valid pods print their init request", testValidateFunc())
+	t.Run("Validate() - Invalid pods fail validation", testValidateInvalidFunc())
+}
+
+func testValidateFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		r, err := NewRuntime(WithConfig(config.LoadDefaultConfiguration()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var output bytes.Buffer
+		err = r.Validate(&output, "../../test/assets/pods/manifests/trader.yaml")
+		assert.NoError(t, err)
+		assert.Contains(t, output.String(), "trader.yaml")
+		// protojson randomizes whitespace, so don't match on it
+		assert.Regexp(t, `"pod":\s+"trader"`, output.String())
+		assert.Contains(t, output.String(), `"local_portfolio_usd_balance"`)
+		// Tests don't run in a terminal, so the output isn't colored
+		assert.NotContains(t, output.String(), "\x1b[")
+
+		// Validating doesn't load pods into the runtime
+		assert.Empty(t, r.pods.Pods())
+	}
+}
+
+func testValidateInvalidFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		r, err := NewRuntime(WithConfig(config.LoadDefaultConfiguration()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		manifestPath := filepath.Join(t.TempDir(), "invalid.yaml")
+		manifest := "name: invalid\nparams:\n  interval: 1m\n  granularity: 5m\ndataspaces:\n  - from: a\n    name: b\n    measurements:\n      - name: c\n"
+		if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var output bytes.Buffer
+		err = r.Validate(&output, "../../test/assets/pods/manifests/trader.yaml", manifestPath)
+		assert.EqualError(t, err, "1 of 2 pods are invalid")
+		assert.Contains(t, output.String(), "granularity must be less than or equal to interval")
+	}
+}
+
+func testPersistInterpretationsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		rtcontext, err := spice_context.NewContext("metal")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rtConfig := config.LoadDefaultConfiguration()
+		rtConfig.Persistence.Enabled = true
+		rtConfig.StateDir = t.TempDir()
+
+		r, err := NewRuntime(WithContext(rtcontext), WithConfig(rtConfig))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		manifestPath := "../../test/assets/pods/manifests/trader.yaml"
+		pod, err := r.pods.LoadPodFromManifest(manifestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.pods.CreateOrUpdatePod(pod)
+
+		interpretation, err := interpretations.NewInterpretation(pod.Epoch(), pod.Epoch().Add(pod.Interval()), "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		interpretation.AddActions("buy")
+		assert.NoError(t, pod.Interpretations().Add(interpretation))
+
+		r.pods.RemovePodByManifestPath(manifestPath)
+		assert.FileExists(t, filepath.Join(rtConfig.StateDir, "pods", "trader", "interpretations.json"))
+
+		reloadedPod, err := r.pods.LoadPodFromManifest(manifestPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.pods.CreateOrUpdatePod(reloadedPod)
+
+		restored := reloadedPod.Interpretations().All()
+		if assert.Len(t, restored, 1) {
+			assert.Equal(t, "test", restored[0].Name())
+			assert.Equal(t, pod.Epoch(), restored[0].Start())
+			assert.Equal(t, []string{"buy"}, restored[0].Actions())
+		}
+	}
+}
+
+func testSaveChangedInterpretationsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		rtcontext, err := spice_context.NewContext("metal")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rtConfig := config.LoadDefaultConfiguration()
+		rtConfig.Persistence.Enabled = true
+		rtConfig.StateDir = t.TempDir()
+
+		r, err := NewRuntime(WithContext(rtcontext), WithConfig(rtConfig))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pod, err := r.pods.LoadPodFromManifest("../../test/assets/pods/manifests/trader.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.pods.CreateOrUpdatePod(pod)
+
+		interpretation, err := interpretations.NewInterpretation(pod.Epoch(), pod.Epoch().Add(pod.Interval()), "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, pod.Interpretations().Add(interpretation))
+		r.pods.NotifyInterpretationsChanged(pod)
+
+		// Saved without the pod being reloaded or the runtime shut down
+		statePath := filepath.Join(rtConfig.StateDir, "pods", "trader", "interpretations.json")
+		data, err := os.ReadFile(statePath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"Name":"test"`)
+
+		pod.Interpretations().Remove(func(i *interpretations.Interpretation) bool { return true })
+		r.pods.NotifyInterpretationsChanged(pod)
+
+		data, err = os.ReadFile(statePath)
+		assert.NoError(t, err)
+		assert.Equal(t, "[]", string(data))
+
+		// Temp files are renamed over the state file, not left behind
+		entries, err := os.ReadDir(filepath.Dir(statePath))
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+	}
+}
+
+func testAuditManifestChangesFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		rtcontext, err := spice_context.NewContext("metal")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := NewRuntime(WithContext(rtcontext), WithConfig(config.LoadDefaultConfiguration()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.auditLog = audit.NewLog(filepath.Join(t.TempDir(), audit.FileName))
+		r.aiEngine.SetAIEngineClient(newHealthyAIEngineClient())
+
+		manifest, err := os.ReadFile("../../test/assets/pods/manifests/trader.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifestPath := filepath.Join(t.TempDir(), "trader.yaml")
+
+		write := func(content string) {
+			if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			// Training may fail without data, which is recorded as the change's outcome
+			_ = r.processPodManifestEvent(fsnotify.Event{Name: manifestPath, Op: fsnotify.Write})
+		}
+
+		write(string(manifest))
+		firstHash := r.pods.GetPod("trader").Hash()
+		write(strings.Replace(string(manifest), "params:\n", "params:\n  episodes: 5\n", 1))
+		secondHash := r.pods.GetPod("trader").Hash()
+		write("name: [")
+		assert.NoError(t, r.processPodManifestEvent(fsnotify.Event{Name: manifestPath, Op: fsnotify.Remove}))
+
+		entries, err := r.auditLog.Query(&audit.Query{Pod: "trader"})
+		assert.NoError(t, err)
+		if !assert.Len(t, entries, 4) {
+			return
+		}
+
+		for _, entry := range entries {
+			assert.Equal(t, audit.SourceWatcher, entry.Source)
+		}
+
+		assert.Equal(t, "manifest changed", entries[0].Action)
+		assert.Empty(t, entries[0].OldHash)
+		assert.Equal(t, firstHash, entries[0].NewHash)
+
+		assert.Equal(t, firstHash, entries[1].OldHash)
+		assert.Equal(t, secondHash, entries[1].NewHash)
+
+		// The invalid manifest isn't loaded, so the pod keeps its previous hash
+		assert.Equal(t, audit.OutcomeFailure, entries[2].Outcome)
+		assert.Equal(t, secondHash, entries[2].OldHash)
+		assert.NotEmpty(t, entries[2].NewHash)
+		assert.NotEqual(t, secondHash, entries[2].NewHash)
+
+		assert.Equal(t, "manifest removed", entries[3].Action)
+		assert.Equal(t, secondHash, entries[3].OldHash)
+		assert.Empty(t, entries[3].NewHash)
+	}
+}
+
+// Run with -race to detect unsynchronized access between pod reloads and request handlers
+func testConcurrentReloadsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		rtcontext, err := spice_context.NewContext("metal")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := NewRuntime(WithContext(rtcontext), WithConfig(config.LoadDefaultConfiguration()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.auditLog = audit.NewLog(filepath.Join(t.TempDir(), audit.FileName))
+
+		r.aiEngine.SetAIEngineClient(newHealthyAIEngineClient())
+		server := spice_http.NewServer(r.Config(), r.rtcontext, r.pods, r.aiEngine, r.environment)
+		handler := server.Handler()
+
+		manifest, err := os.ReadFile("../../test/assets/pods/manifests/trader.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dataPath, err := filepath.Abs("../../test/assets/data/csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest = []byte(strings.ReplaceAll(string(manifest), "../../test/assets/data/csv", dataPath))
+		manifestPath := filepath.Join(t.TempDir(), "trader.yaml")
+
+		const reloads = 20
+		var wg sync.WaitGroup
+		done := make(chan bool)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done)
+
+			for i := 0; i < reloads; i++ {
+				// A different episodes param changes the manifest hash so each write replaces the pod
+				content := strings.Replace(string(manifest), "params:\n", fmt.Sprintf("params:\n  episodes: %d\n", i+1), 1)
+				if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+					t.Error(err)
+					return
+				}
+
+				// Training fails without learning algorithms, after the pod has been replaced
+				err := r.processPodManifestEvent(fsnotify.Event{Name: manifestPath, Op: fsnotify.Write})
+				if err != nil && !strings.Contains(err.Error(), "Learning algorithm") {
+					t.Error(err)
+				}
+
+				if i%5 == 2 {
+					assert.NoError(t, r.processPodManifestEvent(fsnotify.Event{Name: manifestPath, Op: fsnotify.Remove}))
+				}
+			}
+		}()
+
+		paths := []string{
+			"/api/v0.1/pods",
+			"/api/v0.1/pods/trader",
+			"/api/v0.1/pods/trader/observations",
+			"/api/v0.1/pods/trader/quotas",
+			"/api/v0.1/pods/trader/training_runs",
+			"/api/v0.1/pods/trader/interpretations",
+			"/api/v0.1/metrics",
+		}
+		for _, path := range paths {
+			wg.Add(1)
+			go func(path string) {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+					}
+
+					ctx := &fasthttp.RequestCtx{}
+					ctx.Request.SetRequestURI(path)
+					handler(ctx)
+					status := ctx.Response.StatusCode()
+					assert.True(t, status == fasthttp.StatusOK || status == fasthttp.StatusNotFound, "%s returned %d", path, status)
+				}
+			}(path)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				assert.NoError(t, r.environment.InitDataConnectors())
+			}
+		}()
+
+		wg.Wait()
+
+		pod := r.pods.GetPod("trader")
+		if assert.NotNil(t, pod) {
+			assert.Equal(t, manifestPath, pod.ManifestPath())
+			assert.Equal(t, int64(reloads), pod.Episodes())
+		}
+	}
+}
+
+// Returns an AI engine client that accepts pods and their data
+func newHealthyAIEngineClient() *aiengine.MockAIEngineClient {
+	ok := func(context.Context, ...grpc.CallOption) (*aiengine_pb.Response, error) {
+		return &aiengine_pb.Response{Result: "ok"}, nil
+	}
+	return &aiengine.MockAIEngineClient{
+		GetHealthHandler: func(c context.Context, hr *aiengine_pb.HealthRequest, co ...grpc.CallOption) (*aiengine_pb.Response, error) {
+			return ok(c, co...)
+		},
+		InitHandler: func(c context.Context, ir *aiengine_pb.InitRequest, co ...grpc.CallOption) (*aiengine_pb.Response, error) {
+			return ok(c, co...)
+		},
+		AddDataHandler: func(c context.Context, adr *aiengine_pb.AddDataRequest, co ...grpc.CallOption) (*aiengine_pb.Response, error) {
+			return ok(c, co...)
+		},
+		AddInterpretationsHandler: func(c context.Context, air *aiengine_pb.AddInterpretationsRequest, co ...grpc.CallOption) (*aiengine_pb.Response, error) {
+			return ok(c, co...)
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package runtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spiceai/spiceai/pkg/api"
+	"github.com/spiceai/spiceai/pkg/loggers"
+	"github.com/spiceai/spiceai/pkg/pods"
+	"go.uber.org/zap"
+)
+
+// Interpretations can't be recovered from manifests or data connectors, so with persistence
+// enabled they are saved to the state directory whenever they change and restored when the pod is loaded again.
+func (r *Runtime) podStatePath(podName string) string {
+	return filepath.Join(r.Config().StateDir, "pods", podName, "interpretations.json")
+}
+
+func (r *Runtime) loadPodState(pod *pods.Pod) error {
+	statePath := r.podStatePath(pod.Name)
+	stateBytes, err := os.ReadFile(statePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var apiInterpretations []api.Interpretation
+	err = json.Unmarshal(stateBytes, &apiInterpretations)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", statePath, err)
+	}
+
+	for i := range apiInterpretations {
+		interpretation, err := api.NewInterpretationFromApi(&apiInterpretations[i])
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", statePath, err)
+		}
+		// Interpretations outside a changed period are dropped rather than failing the load
+		err = pod.Interpretations().Add(interpretation)
+		if err != nil {
+			zaplog.Warn("skipping interpretation", loggers.Pod(pod.Name), zap.String("interpretation", interpretation.Name()), zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+func (r *Runtime) savePodState(pod *pods.Pod) error {
+	statePath := r.podStatePath(pod.Name)
+	err := os.MkdirAll(filepath.Dir(statePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	stateBytes, err := json.Marshal(api.ApiInterpretations(pod.Interpretations().All()))
+	if err != nil {
+		return err
+	}
+
+	// Write then rename so a crash mid-save leaves the previous file rather than a truncated one
+	tmpFile, err := os.CreateTemp(filepath.Dir(statePath), filepath.Base(statePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	err = tmpFile.Chmod(0644)
+	if err == nil {
+		_, err = tmpFile.Write(stateBytes)
+	}
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), statePath)
+}
+
+func (r *Runtime) savePodsState() {
+	for _, pod := range r.pods.Pods() {
+		if err := r.savePodState(pod); err != nil {
+			zaplog.Error("error saving pod state", loggers.Pod(pod.Name), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spiceai/spiceai/pkg/aiengine"
+	"github.com/spiceai/spiceai/pkg/loggers"
+	"github.com/spiceai/spiceai/pkg/pods"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Loads the given pod manifests, or every manifest in the pods directory, as Run would
+// without reading data, starting the AI engine or training. The AI engine init request
+// of each pod is written to w. Returns an error if any pod is invalid.
+func (r *Runtime) Validate(w io.Writer, manifestPaths ...string) error {
+	err := r.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if r.configHook != nil {
+		err = r.configHook(r.Config())
+		if err != nil {
+			return err
+		}
+	}
+
+	r.setDefaultPodParams(r.Config())
+
+	if len(manifestPaths) == 0 {
+		podsDir := r.rtcontext.PodsDir()
+		if _, err := os.Stat(podsDir); err == nil {
+			manifestPaths = pods.FindAllManifestPaths(podsDir)
+		}
+	}
+
+	if len(manifestPaths) == 0 {
+		return errors.New("no pod manifests found")
+	}
+
+	// Learning algorithms are only checked when the AI engine is installed
+	color := loggers.Color()
+	checkAlgorithms := true
+	if err := r.aiEngine.Init(); err != nil {
+		checkAlgorithms = false
+		fmt.Fprintf(w, "%s skipping learning algorithm checks: %s\n", color.Yellow("warning:"), err.Error())
+	}
+
+	marshalOptions := protojson.MarshalOptions{Multiline: true, Indent: "  "}
+	numInvalid := 0
+	for _, manifestPath := range manifestPaths {
+		initJson, err := r.validatePod(manifestPath, checkAlgorithms, marshalOptions)
+		if err != nil {
+			numInvalid++
+			fmt.Fprintf(w, "%s %s: %s\n", color.Red("✗"), manifestPath, err.Error())
+			continue
+		}
+
+		fmt.Fprintf(w, "%s %s\n%s\n", color.Green("✓"), manifestPath, initJson)
+	}
+
+	if numInvalid > 0 {
+		return fmt.Errorf("%d of %d pods are invalid", numInvalid, len(manifestPaths))
+	}
+
+	return nil
+}
+
+func (r *Runtime) validatePod(manifestPath string, checkAlgorithms bool, marshalOptions protojson.MarshalOptions) ([]byte, error) {
+	// Loading a pod constructs its dataspaces, processors and connectors. Connectors only read once initialized.
+	pod, err := r.pods.LoadPodFromManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pod.ValidateForTraining()
+	if err != nil {
+		return nil, err
+	}
+
+	if checkAlgorithms && r.aiEngine.GetAlgorithm(pod.LearningAlgorithm()) == nil {
+		return nil, fmt.Errorf("learning algorithm %s not found", pod.LearningAlgorithm())
+	}
+
+	return marshalOptions.Marshal(aiengine.PodInitForTraining(pod))
+}
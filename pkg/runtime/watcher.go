@@ -2,20 +2,20 @@ package runtime
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/spiceai/spiceai/pkg/aiengine"
-	"github.com/spiceai/spiceai/pkg/context"
-	"github.com/spiceai/spiceai/pkg/environment"
+	"github.com/spiceai/spiceai/pkg/audit"
+	"github.com/spiceai/spiceai/pkg/loggers"
 	"github.com/spiceai/spiceai/pkg/pods"
+	"github.com/spiceai/spiceai/pkg/util"
+	"go.uber.org/zap"
 )
 
-func ensurePodsPathExists() error {
-	podsDir := context.CurrentContext().PodsDir()
+func (r *Runtime) ensurePodsPathExists() error {
+	podsDir := r.rtcontext.PodsDir()
 	if _, err := os.Stat(podsDir); os.IsNotExist(err) {
 		err := os.MkdirAll(podsDir, os.ModePerm)
 		if err != nil {
@@ -25,9 +25,9 @@ func ensurePodsPathExists() error {
 	return nil
 }
 
-func watchPods() error {
-	podsDir := context.CurrentContext().PodsDir()
-	if err := ensurePodsPathExists(); err != nil {
+func (r *Runtime) watchPods() error {
+	podsDir := r.rtcontext.PodsDir()
+	if err := r.ensurePodsPathExists(); err != nil {
 		// Ignore this error, just don't watch
 		return nil
 	}
@@ -35,22 +35,23 @@ func watchPods() error {
 	go func() {
 		watcher, err := fsnotify.NewWatcher()
 		if err != nil {
-			log.Println(fmt.Errorf("error starting '%s' watcher: %w", podsDir, err))
+			zaplog.Error("error starting pods watcher", zap.String("dir", podsDir), zap.Error(err))
+			return
 		}
 		defer watcher.Close()
 
 		if err := watcher.Add(podsDir); err != nil {
-			log.Println(fmt.Errorf("error starting '%s' watcher: %w", podsDir, err))
+			zaplog.Error("error starting pods watcher", zap.String("dir", podsDir), zap.Error(err))
 		}
 		for {
 			select {
 			case event := <-watcher.Events:
-				err := processNotifyEvent(event)
+				err := r.processNotifyEvent(event)
 				if err != nil {
-					log.Println(err)
+					zaplog.Error("error processing pods change", zap.String("path", event.Name), zap.Error(err))
 				}
 			case err := <-watcher.Errors:
-				log.Println(fmt.Errorf("error from '%s' watcher: %w", podsDir, err))
+				zaplog.Error("error from pods watcher", zap.String("dir", podsDir), zap.Error(err))
 			}
 		}
 	}()
@@ -58,7 +59,7 @@ func watchPods() error {
 	return nil
 }
 
-func processNotifyEvent(event fsnotify.Event) error {
+func (r *Runtime) processNotifyEvent(event fsnotify.Event) error {
 	manifestPath := event.Name
 	ext := filepath.Ext(manifestPath)
 
@@ -66,22 +67,22 @@ func processNotifyEvent(event fsnotify.Event) error {
 	case ".yml":
 		fallthrough
 	case ".yaml":
-		return processPodManifestEvent(event)
+		return r.processPodManifestEvent(event)
 	case ".py":
-		return processRewardFuncEvent(event)
+		return r.processRewardFuncEvent(event)
 	}
 
 	return nil
 }
 
-func processRewardFuncEvent(event fsnotify.Event) error {
+func (r *Runtime) processRewardFuncEvent(event fsnotify.Event) error {
 	rewardFuncPath := event.Name
-	fmt.Println(rewardFuncPath)
+	zaplog.Debug("reward function changed", zap.String("path", rewardFuncPath))
 
 	var pod *pods.Pod
 
 	// Find the pod that this reward func is mapped to
-	for _, p := range pods.Pods() {
+	for _, p := range r.pods.Pods() {
 		if strings.Contains(rewardFuncPath, p.Training.RewardFuncs) {
 			pod = p
 		}
@@ -91,7 +92,7 @@ func processRewardFuncEvent(event fsnotify.Event) error {
 		return nil
 	}
 
-	err := startNewPodTraining(pod)
+	err := r.startNewPodTraining(pod)
 	if err != nil {
 		return err
 	}
@@ -99,66 +100,137 @@ func processRewardFuncEvent(event fsnotify.Event) error {
 	return nil
 }
 
-func processPodManifestEvent(event fsnotify.Event) error {
+func (r *Runtime) processPodManifestEvent(event fsnotify.Event) error {
 	manifestPath := event.Name
 
 	switch event.Op {
 	case fsnotify.Create:
-		pod, err := pods.LoadPodFromManifest(manifestPath)
-		if err != nil {
-			return err
-		}
-		err = startNewPodTraining(pod)
-		if err != nil {
-			return err
-		}
+		fallthrough
 	case fsnotify.Write:
-		newPod, err := pods.LoadPodFromManifest(manifestPath)
+		newPod, err := r.pods.LoadPodFromManifest(manifestPath)
 		if err != nil {
+			r.recordManifestChange("manifest changed", manifestPath, r.podWithManifest(manifestPath), nil, err)
 			return err
 		}
-		existingPod := pods.GetPod(newPod.Name)
-		if newPod.IsSame(existingPod) {
+		previous := r.pods.GetPod(newPod.Name)
+		pod, changed := r.pods.ReplacePod(newPod)
+		if !changed {
 			// Nothing changed, ignore
 			break
 		}
 		// TODO: Check if datasources have actually changed
-		err = startNewPodTraining(newPod)
+		err = r.startNewPodTraining(pod)
+		r.recordManifestChange("manifest changed", manifestPath, previous, pod, err)
 		if err != nil {
 			return err
 		}
 	case fsnotify.Remove:
-		pods.RemovePodByManifestPath(manifestPath)
+		removed := r.pods.RemovePodByManifestPath(manifestPath)
+		if removed != nil {
+			r.recordManifestChange("manifest removed", manifestPath, removed, nil, nil)
+		}
 		return nil
 	}
 
 	return nil
 }
 
-func startNewPodTraining(pod *pods.Pod) error {
-	pods.CreateOrUpdatePod(pod)
+// Records a manifest change detected by the watcher in the audit log
+func (r *Runtime) recordManifestChange(action string, manifestPath string, previous *pods.Pod, pod *pods.Pod, err error) {
+	entry := &audit.Entry{
+		Source:   audit.SourceWatcher,
+		Action:   action,
+		Manifest: r.rtcontext.GetSpiceAppRelativePath(manifestPath),
+	}
+	if previous != nil {
+		entry.Pod = previous.Name
+		entry.OldHash = previous.Hash()
+	}
+	if pod != nil {
+		entry.Pod = pod.Name
+		entry.NewHash = pod.Hash()
+	} else if err != nil {
+		// The manifest failed to load, so its hash is the only record of what it changed to
+		entry.NewHash, _ = util.ComputeFileHash(manifestPath)
+	}
+
+	if err := r.auditLog.Record(entry.WithError(err)); err != nil {
+		zaplog.Error("error recording manifest change", zap.String("manifest", entry.Manifest), zap.Error(err))
+	}
+}
+
+// Called by the pod registry after every change
+func (r *Runtime) onPodEvent(event pods.PodEvent) {
+	if r.Config().Persistence.Enabled {
+		r.persistPodEvent(event)
+	}
+
+	if event.Type != pods.PodRemoved {
+		return
+	}
+
+	r.aiEngine.RemovePod(event.Pod.Name)
 
-	err := aiengine.InitializePod(pod)
+	relativePath := r.rtcontext.GetSpiceAppRelativePath(event.Pod.ManifestPath())
+	color := loggers.Color()
+	zaplog.Info(fmt.Sprintf("removed pod %s: %s", color.Bold(event.Pod.Name), color.Gray(12, relativePath)), loggers.Pod(event.Pod.Name), zap.String("manifest", relativePath))
+}
+
+// Saves interpretations when they change, carries them over to replacement pods and saves them for removed pods
+func (r *Runtime) persistPodEvent(event pods.PodEvent) {
+	if event.Type == pods.PodInterpretationsChanged {
+		if err := r.savePodState(event.Pod); err != nil {
+			zaplog.Error("error saving pod state", loggers.Pod(event.Pod.Name), zap.Error(err))
+		}
+		return
+	}
+
+	if event.Previous != nil {
+		if err := r.savePodState(event.Previous); err != nil {
+			zaplog.Error("error saving pod state", loggers.Pod(event.Previous.Name), zap.Error(err))
+		}
+	}
+
+	if event.Type == pods.PodRemoved {
+		return
+	}
+
+	if err := r.loadPodState(event.Pod); err != nil {
+		zaplog.Error("error loading pod state", loggers.Pod(event.Pod.Name), zap.Error(err))
+	}
+}
+
+func (r *Runtime) startNewPodTraining(pod *pods.Pod) error {
+	err := r.aiEngine.InitializePod(pod)
 	if err != nil {
 		return err
 	}
 
-	err = environment.InitPodDataConnector(pod)
+	err = r.environment.InitPodDataConnector(pod)
 	if err != nil {
 		return err
 	}
 
 	podState := pod.CachedState()
-	err = aiengine.SendData(pod, podState...)
+	err = r.aiEngine.SendData(pod, podState...)
 	if err != nil {
 		return err
 	}
 
-	// Pass empty algorithm and negative episode number string to use pod's default
-	err = aiengine.StartTraining(pod, nil)
+	// Pass nil trainModel to use pod's default. Queued trainings start when the scheduler has a slot.
+	_, err = r.aiEngine.QueueTraining(pod, nil)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (r *Runtime) podWithManifest(manifestPath string) *pods.Pod {
+	for _, pod := range r.pods.Pods() {
+		if pod.ManifestPath() == manifestPath {
+			return pod
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package scheduler
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Scheduler limits how many trainings run at once across every pod in a runtime.
+// Queued requests are granted by priority, then to the pod with the fewest running
+// trainings, then to the pod granted least recently, then in request order, so a pod
+// that queues many trainings can't starve the others.
+type Scheduler struct {
+	mutex         sync.Mutex
+	maxConcurrent int
+	active        []*Ticket
+	queued        []*Ticket
+	nextSequence  uint64
+	grants        uint64
+	lastGranted   map[string]uint64
+}
+
+// A request for a training slot, held until the training completes
+type Ticket struct {
+	scheduler   *Scheduler
+	pod         string
+	priority    int
+	sequence    uint64
+	requestedAt time.Time
+	granted     chan struct{}
+
+	// Guarded by the scheduler mutex
+	grantedAt time.Time
+	flight    string
+	released  bool
+}
+
+type TicketStatus struct {
+	Pod         string
+	Priority    int
+	Flight      string
+	RequestedAt time.Time
+	GrantedAt   time.Time
+}
+
+type Status struct {
+	MaxConcurrent int
+	Active        []TicketStatus
+	// In the order they will be granted
+	Queued []TicketStatus
+}
+
+// Creates a scheduler running at most maxConcurrent trainings, or one per CPU if maxConcurrent <= 0
+func NewScheduler(maxConcurrent int) *Scheduler {
+	s := &Scheduler{
+		lastGranted: make(map[string]uint64),
+	}
+	s.maxConcurrent = normalizeMaxConcurrent(maxConcurrent)
+	return s
+}
+
+func (s *Scheduler) MaxConcurrent() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.maxConcurrent
+}
+
+// Changes the number of concurrent trainings, granting queued requests if it grew.
+// Lowering it doesn't stop running trainings.
+func (s *Scheduler) SetMaxConcurrent(maxConcurrent int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.maxConcurrent = normalizeMaxConcurrent(maxConcurrent)
+	s.grantQueued()
+}
+
+// Queues a request for a training slot. Wait on Granted() before training and
+// call Release() when the training completes or fails to start.
+func (s *Scheduler) Request(pod string, priority int) *Ticket {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t := &Ticket{
+		scheduler:   s,
+		pod:         pod,
+		priority:    priority,
+		sequence:    s.nextSequence,
+		requestedAt: time.Now(),
+		granted:     make(chan struct{}),
+	}
+	s.nextSequence++
+
+	s.queued = append(s.queued, t)
+	s.grantQueued()
+
+	return t
+}
+
+func (s *Scheduler) Status() Status {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	status := Status{
+		MaxConcurrent: s.maxConcurrent,
+		Active:        make([]TicketStatus, 0, len(s.active)),
+		Queued:        make([]TicketStatus, 0, len(s.queued)),
+	}
+	for _, t := range s.active {
+		status.Active = append(status.Active, t.status())
+	}
+
+	s.sortQueued()
+	for _, t := range s.queued {
+		status.Queued = append(status.Queued, t.status())
+	}
+
+	return status
+}
+
+// Closed once the ticket is granted a slot
+func (t *Ticket) Granted() <-chan struct{} {
+	return t.granted
+}
+
+func (t *Ticket) Pod() string {
+	return t.pod
+}
+
+// Records the flight training in this slot, for Status
+func (t *Ticket) SetFlight(flight string) {
+	t.scheduler.mutex.Lock()
+	defer t.scheduler.mutex.Unlock()
+
+	t.flight = flight
+}
+
+// Frees the slot, or leaves the queue if not yet granted. Safe to call more than once.
+func (t *Ticket) Release() {
+	s := t.scheduler
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if t.released {
+		return
+	}
+	t.released = true
+
+	s.active = removeTicket(s.active, t)
+	s.queued = removeTicket(s.queued, t)
+	s.grantQueued()
+}
+
+func (t *Ticket) status() TicketStatus {
+	return TicketStatus{
+		Pod:         t.pod,
+		Priority:    t.priority,
+		Flight:      t.flight,
+		RequestedAt: t.requestedAt,
+		GrantedAt:   t.grantedAt,
+	}
+}
+
+// Must be called with the mutex held
+func (s *Scheduler) grantQueued() {
+	for len(s.active) < s.maxConcurrent && len(s.queued) > 0 {
+		s.sortQueued()
+		t := s.queued[0]
+		s.queued = s.queued[1:]
+
+		s.grants++
+		s.lastGranted[t.pod] = s.grants
+		t.grantedAt = time.Now()
+		s.active = append(s.active, t)
+		close(t.granted)
+	}
+}
+
+// Must be called with the mutex held
+func (s *Scheduler) sortQueued() {
+	activeByPod := make(map[string]int, len(s.active))
+	for _, t := range s.active {
+		activeByPod[t.pod]++
+	}
+
+	sort.SliceStable(s.queued, func(i, j int) bool {
+		a, b := s.queued[i], s.queued[j]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		if activeByPod[a.pod] != activeByPod[b.pod] {
+			return activeByPod[a.pod] < activeByPod[b.pod]
+		}
+		if s.lastGranted[a.pod] != s.lastGranted[b.pod] {
+			return s.lastGranted[a.pod] < s.lastGranted[b.pod]
+		}
+		return a.sequence < b.sequence
+	})
+}
+
+func removeTicket(tickets []*Ticket, t *Ticket) []*Ticket {
+	for i, ticket := range tickets {
+		if ticket == t {
+			return append(tickets[:i:i], tickets[i+1:]...)
+		}
+	}
+	return tickets
+}
+
+func normalizeMaxConcurrent(maxConcurrent int) int {
+	if maxConcurrent <= 0 {
+		return runtime.NumCPU()
+	}
+	return maxConcurrent
+}
@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Run("Request() - Grants up to the concurrency limit", testRequestLimitFunc())
+	t.Run("Request() - Grants higher priorities first", testRequestPriorityFunc())
+	t.Run("Request() - Grants fairly across pods", testRequestFairnessFunc())
+	t.Run("Release() - Leaves the queue if not yet granted", testReleaseQueuedFunc())
+	t.Run("SetMaxConcurrent() - Grants queued requests when raised", testSetMaxConcurrentFunc())
+	t.Run("Status() - Lists active and queued requests in grant order", testStatusFunc())
+}
+
+func testRequestLimitFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		s := NewScheduler(2)
+
+		first := s.Request("a", 0)
+		second := s.Request("b", 0)
+		third := s.Request("c", 0)
+
+		assert.True(t, isGranted(first))
+		assert.True(t, isGranted(second))
+		assert.False(t, isGranted(third))
+
+		first.Release()
+		assert.True(t, isGranted(third))
+
+		// Releasing twice doesn't free a second slot
+		first.Release()
+		fourth := s.Request("d", 0)
+		assert.False(t, isGranted(fourth))
+	}
+}
+
+func testRequestPriorityFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		s := NewScheduler(1)
+
+		running := s.Request("a", 0)
+		low := s.Request("b", 0)
+		high := s.Request("c", 10)
+
+		running.Release()
+		assert.True(t, isGranted(high))
+		assert.False(t, isGranted(low))
+
+		high.Release()
+		assert.True(t, isGranted(low))
+	}
+}
+
+func testRequestFairnessFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		s := NewScheduler(1)
+
+		running := s.Request("busy", 0)
+		busy1 := s.Request("busy", 0)
+		busy2 := s.Request("busy", 0)
+		quiet := s.Request("quiet", 0)
+
+		// "busy" was granted most recently, so "quiet" goes next despite requesting last
+		running.Release()
+		assert.True(t, isGranted(quiet))
+		assert.False(t, isGranted(busy1))
+
+		quiet.Release()
+		assert.True(t, isGranted(busy1))
+		assert.False(t, isGranted(busy2))
+	}
+}
+
+func testReleaseQueuedFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		s := NewScheduler(1)
+
+		running := s.Request("a", 0)
+		cancelled := s.Request("b", 0)
+		waiting := s.Request("c", 0)
+
+		cancelled.Release()
+		assert.Len(t, s.Status().Queued, 1)
+
+		running.Release()
+		assert.True(t, isGranted(waiting))
+		assert.False(t, isGranted(cancelled))
+	}
+}
+
+func testSetMaxConcurrentFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		s := NewScheduler(1)
+
+		s.Request("a", 0)
+		queued := s.Request("b", 0)
+		assert.False(t, isGranted(queued))
+
+		s.SetMaxConcurrent(2)
+		assert.Equal(t, 2, s.MaxConcurrent())
+		assert.True(t, isGranted(queued))
+
+		s.SetMaxConcurrent(0)
+		assert.Greater(t, s.MaxConcurrent(), 0)
+	}
+}
+
+func testStatusFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		s := NewScheduler(1)
+
+		running := s.Request("a", 0)
+		running.SetFlight("1")
+		s.Request("b", 0)
+		s.Request("c", 5)
+
+		status := s.Status()
+		assert.Equal(t, 1, status.MaxConcurrent)
+		if assert.Len(t, status.Active, 1) {
+			assert.Equal(t, "a", status.Active[0].Pod)
+			assert.Equal(t, "1", status.Active[0].Flight)
+			assert.False(t, status.Active[0].GrantedAt.IsZero())
+		}
+		if assert.Len(t, status.Queued, 2) {
+			assert.Equal(t, "c", status.Queued[0].Pod)
+			assert.Equal(t, 5, status.Queued[0].Priority)
+			assert.Equal(t, "b", status.Queued[1].Pod)
+			assert.True(t, status.Queued[1].GrantedAt.IsZero())
+		}
+	}
+}
+
+func isGranted(ticket *Ticket) bool {
+	select {
+	case <-ticket.Granted():
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,432 @@
+package snapshot
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spiceai/spiceai/pkg/api"
+	"github.com/spiceai/spiceai/pkg/flights"
+	"github.com/spiceai/spiceai/pkg/interpretations"
+	"github.com/spiceai/spiceai/pkg/observations"
+	"github.com/spiceai/spiceai/pkg/pods"
+	"github.com/spiceai/spiceai/pkg/state"
+	"github.com/spiceai/spiceai/pkg/tempdir"
+	"github.com/spiceai/spiceai/pkg/version"
+)
+
+const (
+	FileExtension = ".spicesnapshot"
+
+	manifestFileName        = "snapshot.json"
+	observationsFileName    = "observations.json"
+	interpretationsFileName = "interpretations.json"
+	flightsFileName         = "training_runs.json"
+	modelFileName           = "model.spicepod"
+)
+
+// Describes the contents of a snapshot archive
+type Manifest struct {
+	RuntimeVersion string         `json:"runtime_version"`
+	CreatedAt      int64          `json:"created_at"`
+	Pods           []*PodManifest `json:"pods"`
+}
+
+// Cached state is stored as its observations rather than CSV, which can't represent tags
+type stateRecord struct {
+	Path         string              `json:"path"`
+	Identifiers  []string            `json:"identifiers,omitempty"`
+	Measurements []string            `json:"measurements"`
+	Categories   []string            `json:"categories,omitempty"`
+	Tags         []string            `json:"tags,omitempty"`
+	Observations []observationRecord `json:"observations"`
+}
+
+type observationRecord struct {
+	Time         int64              `json:"time"`
+	Identifiers  map[string]string  `json:"identifiers,omitempty"`
+	Measurements map[string]float64 `json:"measurements"`
+	Categories   map[string]string  `json:"categories,omitempty"`
+	Tags         []string           `json:"tags,omitempty"`
+}
+
+type PodManifest struct {
+	Name            string `json:"name" csv:"name"`
+	ManifestFile    string `json:"manifest_file" csv:"manifest"`
+	Observations    int    `json:"observations" csv:"observations"`
+	Interpretations int    `json:"interpretations" csv:"interpretations"`
+	TrainingRuns    int    `json:"training_runs" csv:"training_runs"`
+	Model           bool   `json:"model" csv:"model"`
+}
+
+// Exports the trained model of a pod to an archive at archivePath.
+// Return ErrNoModel if the pod has no model to export.
+type ModelExporter func(pod *pods.Pod, archivePath string) error
+
+// Called for every restored pod, after it is added to the registry.
+// modelArchivePath is empty if the snapshot has no model for the pod.
+type PodRestorer func(pod *pods.Pod, modelArchivePath string) error
+
+var ErrNoModel = errors.New("no model to export")
+
+// Writes a snapshot of every pod's manifest, cached observations, interpretations,
+// training runs and, if exportModel is not nil, trained model to w as a zip archive
+func Create(w io.Writer, loadedPods map[string]*pods.Pod, exportModel ModelExporter) (*Manifest, error) {
+	manifest := &Manifest{
+		RuntimeVersion: version.Version(),
+		CreatedAt:      time.Now().Unix(),
+		Pods:           make([]*PodManifest, 0, len(loadedPods)),
+	}
+
+	podNames := make([]string, 0, len(loadedPods))
+	for name := range loadedPods {
+		podNames = append(podNames, name)
+	}
+	sort.Strings(podNames)
+
+	zipWriter := zip.NewWriter(w)
+	for _, name := range podNames {
+		podManifest, err := addPod(zipWriter, loadedPods[name], exportModel)
+		if err != nil {
+			return nil, fmt.Errorf("error adding pod %s to snapshot: %w", name, err)
+		}
+		manifest.Pods = append(manifest.Pods, podManifest)
+	}
+
+	err := addJson(zipWriter, manifestFileName, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, zipWriter.Close()
+}
+
+// Restores every pod in the snapshot archive, writing its manifest to podsDir and adding it to registry
+func Restore(r io.ReaderAt, size int64, podsDir string, registry *pods.Registry, restorePod PodRestorer) (*Manifest, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zipReader.File))
+	for _, f := range zipReader.File {
+		files[f.Name] = f
+	}
+
+	var manifest Manifest
+	err = readJson(files, manifestFileName, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+
+	err = os.MkdirAll(podsDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, podManifest := range manifest.Pods {
+		err = restorePodFromSnapshot(files, &manifest, podManifest, podsDir, registry, restorePod)
+		if err != nil {
+			return nil, fmt.Errorf("error restoring pod %s: %w", podManifest.Name, err)
+		}
+	}
+
+	return &manifest, nil
+}
+
+func addPod(zipWriter *zip.Writer, pod *pods.Pod, exportModel ModelExporter) (*PodManifest, error) {
+	podManifest := &PodManifest{
+		Name:         pod.Name,
+		ManifestFile: filepath.Base(pod.ManifestPath()),
+	}
+	podDir := path.Join("pods", pod.Name)
+
+	manifestBytes, err := os.ReadFile(pod.ManifestPath())
+	if err != nil {
+		return nil, err
+	}
+	err = addBytes(zipWriter, path.Join(podDir, podManifest.ManifestFile), manifestBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedState := pod.CachedState()
+	stateRecords := make([]*stateRecord, 0, len(cachedState))
+	for _, s := range cachedState {
+		stateRecords = append(stateRecords, newStateRecord(s))
+		podManifest.Observations += len(s.Observations())
+	}
+	err = addJson(zipWriter, path.Join(podDir, observationsFileName), stateRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	podInterpretations := api.ApiInterpretations(pod.Interpretations().All())
+	podManifest.Interpretations = len(podInterpretations)
+	err = addJson(zipWriter, path.Join(podDir, interpretationsFileName), podInterpretations)
+	if err != nil {
+		return nil, err
+	}
+
+	podFlights := make([]*api.Flight, 0)
+	for _, f := range pod.Flights() {
+		podFlights = append(podFlights, api.NewFlight(f))
+	}
+	sort.Slice(podFlights, func(i, j int) bool {
+		return podFlights[i].Start < podFlights[j].Start
+	})
+	podManifest.TrainingRuns = len(podFlights)
+	err = addJson(zipWriter, path.Join(podDir, flightsFileName), podFlights)
+	if err != nil {
+		return nil, err
+	}
+
+	if exportModel != nil {
+		modelBytes, err := exportPodModel(pod, exportModel)
+		if err != nil {
+			return nil, err
+		}
+		if modelBytes != nil {
+			podManifest.Model = true
+			err = addBytes(zipWriter, path.Join(podDir, modelFileName), modelBytes)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return podManifest, nil
+}
+
+func exportPodModel(pod *pods.Pod, exportModel ModelExporter) ([]byte, error) {
+	exportDir, err := tempdir.CreateTempDir("snapshot")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(exportDir)
+
+	archivePath := filepath.Join(exportDir, modelFileName)
+	err = exportModel(pod, archivePath)
+	if err != nil {
+		if errors.Is(err, ErrNoModel) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return os.ReadFile(archivePath)
+}
+
+func restorePodFromSnapshot(files map[string]*zip.File, manifest *Manifest, podManifest *PodManifest, podsDir string, registry *pods.Registry, restorePod PodRestorer) error {
+	podDir := path.Join("pods", podManifest.Name)
+
+	// Only the base name is used so an archive can't write outside podsDir
+	manifestPath := filepath.Join(podsDir, filepath.Base(podManifest.ManifestFile))
+	manifestBytes, err := readBytes(files, path.Join(podDir, podManifest.ManifestFile))
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(manifestPath, manifestBytes, 0644)
+	if err != nil {
+		return err
+	}
+
+	pod, err := registry.LoadPodFromManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	if pod.Name != podManifest.Name {
+		return fmt.Errorf("manifest %s is for pod %s", podManifest.ManifestFile, pod.Name)
+	}
+
+	// Added before restoring its data so registry watchers, such as persistence, run first
+	registry.CreateOrUpdatePod(pod)
+
+	var stateRecords []*stateRecord
+	err = readJson(files, path.Join(podDir, observationsFileName), &stateRecords)
+	if err != nil {
+		return err
+	}
+	podState := make([]*state.State, 0, len(stateRecords))
+	for _, record := range stateRecords {
+		podState = append(podState, record.newState())
+	}
+	// Observations from dataspaces are restored as pod observations, as their connectors may not exist here
+	err = pod.AddLocalState(podState...)
+	if err != nil {
+		return err
+	}
+
+	var apiInterpretations []*api.Interpretation
+	err = readJson(files, path.Join(podDir, interpretationsFileName), &apiInterpretations)
+	if err != nil {
+		return err
+	}
+	for _, apiInterpretation := range apiInterpretations {
+		interpretation, err := api.NewInterpretationFromApi(apiInterpretation)
+		if err != nil {
+			return err
+		}
+		// The pod may already have it, such as when restoring over a runtime with persistence enabled
+		if hasInterpretation(pod, interpretation) {
+			continue
+		}
+		err = pod.Interpretations().Add(interpretation)
+		if err != nil {
+			return err
+		}
+	}
+	if len(apiInterpretations) > 0 {
+		registry.NotifyInterpretationsChanged(pod)
+	}
+
+	var apiFlights []*api.Flight
+	err = readJson(files, path.Join(podDir, flightsFileName), &apiFlights)
+	if err != nil {
+		return err
+	}
+	for _, apiFlight := range apiFlights {
+		pod.AddFlight(apiFlight.Id, newFlightFromApi(apiFlight, time.Unix(manifest.CreatedAt, 0)))
+	}
+
+	if restorePod == nil {
+		return nil
+	}
+
+	modelArchivePath := ""
+	if podManifest.Model {
+		modelBytes, err := readBytes(files, path.Join(podDir, modelFileName))
+		if err != nil {
+			return err
+		}
+		modelDir, err := tempdir.CreateTempDir("snapshot")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(modelDir)
+
+		modelArchivePath = filepath.Join(modelDir, modelFileName)
+		err = os.WriteFile(modelArchivePath, modelBytes, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return restorePod(pod, modelArchivePath)
+}
+
+func newStateRecord(s *state.State) *stateRecord {
+	record := &stateRecord{
+		Path:         s.Path(),
+		Identifiers:  sortedKeys(s.IdentifiersNamesMap()),
+		Measurements: s.MeasurementsNames(),
+		Categories:   sortedKeys(s.CategoryNamesMap()),
+		Tags:         s.Tags(),
+		Observations: make([]observationRecord, 0, len(s.Observations())),
+	}
+	for _, o := range s.Observations() {
+		record.Observations = append(record.Observations, observationRecord(o))
+	}
+	return record
+}
+
+func (record *stateRecord) newState() *state.State {
+	stateObservations := make([]observations.Observation, 0, len(record.Observations))
+	for _, o := range record.Observations {
+		stateObservations = append(stateObservations, observations.Observation(o))
+	}
+	return state.NewState(record.Path, record.Identifiers, record.Measurements, record.Categories, record.Tags, stateObservations)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hasInterpretation(pod *pods.Pod, interpretation *interpretations.Interpretation) bool {
+	for _, existing := range pod.Interpretations().Get(interpretation.Start(), interpretation.End()) {
+		if existing.Name() == interpretation.Name() && existing.Start().Equal(interpretation.Start()) && existing.End().Equal(interpretation.End()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flights still training when the snapshot was taken are restored as ending when it was taken
+func newFlightFromApi(apiFlight *api.Flight, snapshotTime time.Time) *flights.Flight {
+	episodes := make([]*flights.Episode, 0, len(apiFlight.Episodes))
+	for _, ep := range apiFlight.Episodes {
+		episodes = append(episodes, &flights.Episode{
+			EpisodeId:    ep.Episode,
+			Start:        time.Unix(ep.Start, 0),
+			End:          time.Unix(ep.End, 0),
+			Score:        ep.Score,
+			ActionsTaken: ep.ActionsTaken,
+			Error:        ep.Error,
+			ErrorMessage: ep.ErrorMessage,
+		})
+	}
+
+	end := time.Unix(apiFlight.End, 0)
+	if end.IsZero() {
+		end = snapshotTime
+	}
+
+	return flights.RestoreFlight(apiFlight.Id, apiFlight.Algorithm, apiFlight.Loggers, time.Unix(apiFlight.Start, 0), end, episodes)
+}
+
+func addJson(zipWriter *zip.Writer, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return addBytes(zipWriter, name, data)
+}
+
+func addBytes(zipWriter *zip.Writer, name string, data []byte) error {
+	writer, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, bytes.NewReader(data))
+	return err
+}
+
+func readJson(files map[string]*zip.File, name string, v interface{}) error {
+	data, err := readBytes(files, name)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", name, err)
+	}
+	return nil
+}
+
+func readBytes(files map[string]*zip.File, name string) ([]byte, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+
+	reader, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
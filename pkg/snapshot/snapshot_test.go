@@ -0,0 +1,113 @@
+package snapshot
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spiceai/spiceai/pkg/flights"
+	"github.com/spiceai/spiceai/pkg/interpretations"
+	"github.com/spiceai/spiceai/pkg/pods"
+	"github.com/spiceai/spiceai/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Run("Create() - Snapshot can be restored into another registry", testCreateAndRestoreFunc())
+	t.Run("Restore() - Invalid archives are rejected", testRestoreInvalidFunc())
+}
+
+func testCreateAndRestoreFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		pod, err := pods.LoadPodFromManifest("../../test/assets/pods/manifests/trader.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		csv := "time,local.portfolio.usd_balance,local.portfolio.btc_balance\n1605312000,100,1\n1605313000,90,2\n"
+		podState, err := state.GetStateFromCsv(pod.IdentifierNames(), pod.MeasurementNames(), pod.CategoryNames(), []byte(csv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, pod.AddLocalState(podState...))
+
+		interpretation, err := interpretations.NewInterpretation(pod.Epoch(), pod.Epoch().Add(pod.Interval()), "dip")
+		if err != nil {
+			t.Fatal(err)
+		}
+		interpretation.AddActions("buy")
+		assert.NoError(t, pod.Interpretations().Add(interpretation))
+
+		start := time.Unix(1605312000, 0)
+		episodes := []*flights.Episode{{EpisodeId: 1, Start: start, End: start.Add(time.Minute), Score: 42}}
+		pod.AddFlight("1", flights.RestoreFlight("1", "dql", nil, start, start.Add(time.Minute), episodes))
+
+		exportModel := func(p *pods.Pod, archivePath string) error {
+			return os.WriteFile(archivePath, []byte("model"), 0644)
+		}
+
+		var archive bytes.Buffer
+		manifest, err := Create(&archive, map[string]*pods.Pod{pod.Name: pod}, exportModel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if assert.Len(t, manifest.Pods, 1) {
+			assert.Equal(t, &PodManifest{
+				Name:            "trader",
+				ManifestFile:    "trader.yaml",
+				Observations:    2,
+				Interpretations: 1,
+				TrainingRuns:    1,
+				Model:           true,
+			}, manifest.Pods[0])
+		}
+
+		podsDir := t.TempDir()
+		registry := pods.NewRegistry()
+		var restoredModel []byte
+		restorePod := func(p *pods.Pod, modelArchivePath string) error {
+			restoredModel, err = os.ReadFile(modelArchivePath)
+			return err
+		}
+
+		restoredManifest, err := Restore(bytes.NewReader(archive.Bytes()), int64(archive.Len()), podsDir, registry, restorePod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, manifest, restoredManifest)
+		assert.Equal(t, []byte("model"), restoredModel)
+		assert.FileExists(t, filepath.Join(podsDir, "trader.yaml"))
+
+		restored := registry.GetPod("trader")
+		if !assert.NotNil(t, restored) {
+			return
+		}
+		assert.Equal(t, pod.CachedCsv(), restored.CachedCsv())
+
+		restoredInterpretations := restored.Interpretations().All()
+		if assert.Len(t, restoredInterpretations, 1) {
+			assert.Equal(t, "dip", restoredInterpretations[0].Name())
+			assert.Equal(t, []string{"buy"}, restoredInterpretations[0].Actions())
+		}
+
+		restoredFlight := restored.GetFlight("1")
+		if assert.NotNil(t, restoredFlight) {
+			assert.True(t, restoredFlight.IsComplete())
+			assert.Equal(t, "dql", restoredFlight.Algorithm())
+			assert.Equal(t, start.Add(time.Minute), restoredFlight.End())
+			if assert.Len(t, restoredFlight.Episodes(), 1) {
+				assert.Equal(t, 42.0, restoredFlight.Episodes()[0].Score)
+			}
+		}
+	}
+}
+
+func testRestoreInvalidFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		data := []byte("not a snapshot")
+		_, err := Restore(bytes.NewReader(data), int64(len(data)), t.TempDir(), pods.NewRegistry(), nil)
+		assert.Error(t, err)
+	}
+}
@@ -11,6 +11,7 @@ type DataspaceSpec struct {
 	Tags         *TagsSpec         `json:"tags,omitempty" yaml:"tags,omitempty" mapstructure:"tags,omitempty"`
 	Actions      map[string]string `json:"actions,omitempty" yaml:"actions,omitempty" mapstructure:"actions,omitempty"`
 	Laws         []string          `json:"laws,omitempty" yaml:"laws,omitempty" mapstructure:"laws,omitempty"`
+	Quotas       *QuotasSpec       `json:"quotas,omitempty" yaml:"quotas,omitempty" mapstructure:"quotas,omitempty"`
 }
 
 type DataSpec struct {
@@ -7,6 +7,16 @@ type PodSpec struct {
 	Dataspaces []DataspaceSpec   `json:"dataspaces,omitempty" yaml:"dataspaces,omitempty" mapstructure:"dataspaces,omitempty"`
 	Actions    []PodActionSpec   `json:"actions,omitempty" yaml:"actions,omitempty" mapstructure:"actions,omitempty"`
 	Training   *TrainingSpec     `json:"training,omitempty" yaml:"training,omitempty" mapstructure:"training,omitempty"`
+	Quotas     *QuotasSpec       `json:"quotas,omitempty" yaml:"quotas,omitempty" mapstructure:"quotas,omitempty"`
+}
+
+// Resource limits for a pod or dataspace. Zero values are unlimited.
+type QuotasSpec struct {
+	MaxCachedObservations  int     `json:"max_cached_observations,omitempty" yaml:"max_cached_observations,omitempty" mapstructure:"max_cached_observations,omitempty"`
+	MaxIngestRate          float64 `json:"max_ingest_rate,omitempty" yaml:"max_ingest_rate,omitempty" mapstructure:"max_ingest_rate,omitempty"`
+	MaxBodyBytes           int     `json:"max_body_bytes,omitempty" yaml:"max_body_bytes,omitempty" mapstructure:"max_body_bytes,omitempty"`
+	MaxInterpretations     int     `json:"max_interpretations,omitempty" yaml:"max_interpretations,omitempty" mapstructure:"max_interpretations,omitempty"`
+	MaxConcurrentTrainings int     `json:"max_concurrent_trainings,omitempty" yaml:"max_concurrent_trainings,omitempty" mapstructure:"max_concurrent_trainings,omitempty"`
 }
 
 type TimeSpec struct {
@@ -30,6 +40,8 @@ type TrainingSpec struct {
 	RewardInit  string            `json:"reward_init,omitempty" yaml:"reward_init,omitempty" mapstructure:"reward_init,omitempty"`
 	RewardArgs  map[string]string `json:"reward_args,omitempty" yaml:"reward_args,omitempty" mapstructure:"reward_args,omitempty"`
 	Rewards     interface{}       `json:"rewards,omitempty" yaml:"rewards,omitempty" mapstructure:"rewards,omitempty"`
+	// Trainings with a higher priority are started first when the runtime is at its training limit
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty" mapstructure:"priority,omitempty"`
 }
 
 type RewardSpec struct {
@@ -6,15 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/spiceai/spiceai/pkg/loggers"
 	"github.com/spiceai/spiceai/pkg/observations"
 	spice_time "github.com/spiceai/spiceai/pkg/time"
+	"go.uber.org/zap"
+)
+
+var (
+	zaplog *zap.Logger = loggers.ZapLogger()
 )
 
 type State struct {
@@ -98,7 +103,7 @@ func GetStateFromCsv(validIdentifierNames []string, validMeasurementNames []stri
 	for line, record := range lines {
 		ts, err := spice_time.ParseTime(record[0], "")
 		if err != nil {
-			log.Printf("ignoring invalid line %d - %v: %v", line+1, record, err)
+			zaplog.Warn("ignoring invalid line", zap.Int("line", line+1), zap.Strings("record", record), zap.Error(err))
 			continue
 		}
 
@@ -138,7 +143,7 @@ func GetStateFromCsv(validIdentifierNames []string, validMeasurementNames []stri
 			if measurementName != "" {
 				val, err := strconv.ParseFloat(fieldValue, 64)
 				if err != nil {
-					log.Printf("ignoring invalid measurement field %d - %v: %v", line+1, fieldValue, err)
+					zaplog.Warn("ignoring invalid measurement field", zap.Int("line", line+1), zap.String("value", fieldValue), zap.Error(err))
 					continue
 				}
 				if lineData.measurements == nil {
@@ -0,0 +1,14 @@
+def price_change(current_state: dict, next_state: dict):
+    return next_state["coinbase_btcusd_close"] - current_state["coinbase_btcusd_close"]
+
+
+def buy(current_state: dict, current_interpretations, next_state: dict, next_interpretations):
+    return price_change(current_state, next_state)
+
+
+def sell(current_state: dict, current_interpretations, next_state: dict, next_interpretations):
+    return -price_change(current_state, next_state)
+
+
+def hold(current_state: dict, current_interpretations, next_state: dict, next_interpretations):
+    return -0.1
@@ -0,0 +1,233 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spiceai/spiceai/pkg/validator"
+	"gopkg.in/yaml.v3"
+)
+
+// Describes the template and its variables, and is not copied into the app
+const TemplateFileName = "template.yaml"
+
+// Files with this extension are rendered with the template's variables and written without it.
+// Other files are copied as they are.
+const renderedFileExt = ".tmpl"
+
+// Built-in templates are directories under builtin, each with a template.yaml
+//go:embed builtin
+var builtinFS embed.FS
+
+type Variable struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Default     string `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// An app template. {<variable>} in a file's path is replaced with the variable's value.
+type Template struct {
+	Name        string     `json:"name" yaml:"-"`
+	Description string     `json:"description,omitempty" yaml:"description,omitempty"`
+	Variables   []Variable `json:"variables,omitempty" yaml:"variables,omitempty"`
+
+	files fs.FS
+}
+
+// A file rendered from a template, with a slash-separated path relative to the app directory
+type File struct {
+	Path string
+	Data []byte
+}
+
+func Builtin() ([]*Template, error) {
+	entries, err := fs.ReadDir(builtinFS, "builtin")
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []*Template
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		t, err := loadBuiltin(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	sort.SliceStable(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates, nil
+}
+
+// Loads the template from a local directory if nameOrDir is one, otherwise the built-in template with that name
+func Load(nameOrDir string) (*Template, error) {
+	if info, err := os.Stat(nameOrDir); err == nil && info.IsDir() {
+		return LoadDir(nameOrDir)
+	}
+
+	if strings.ContainsAny(nameOrDir, `/\`) {
+		return nil, fmt.Errorf("template directory %s not found", nameOrDir)
+	}
+
+	t, err := loadBuiltin(nameOrDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		names, err := builtinNames()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unknown template '%s', expected a template directory or one of: %s", nameOrDir, strings.Join(names, ", "))
+	}
+
+	return t, err
+}
+
+func LoadDir(dir string) (*Template, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return load(filepath.Base(absDir), os.DirFS(absDir))
+}
+
+// Returns the template's variables with defaults applied to those not given
+func (t *Template) Vars(given map[string]string) (map[string]string, error) {
+	vars := make(map[string]string, len(t.Variables))
+	for _, v := range t.Variables {
+		vars[v.Name] = v.Default
+	}
+
+	for name, value := range given {
+		if _, ok := vars[name]; !ok {
+			return nil, fmt.Errorf("template %s has no variable '%s'", t.Name, name)
+		}
+		vars[name] = value
+	}
+
+	for _, v := range t.Variables {
+		if strings.TrimSpace(vars[v.Name]) == "" {
+			return nil, fmt.Errorf("template %s requires a value for '%s'", t.Name, v.Name)
+		}
+	}
+
+	return vars, nil
+}
+
+// Renders the template's files with vars, which must have a value for each of the template's variables
+func (t *Template) Render(vars map[string]string) ([]File, error) {
+	var files []File
+	err := fs.WalkDir(t.files, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || filePath == TemplateFileName {
+			return nil
+		}
+
+		data, err := fs.ReadFile(t.files, filePath)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(filePath, renderedFileExt) {
+			filePath = strings.TrimSuffix(filePath, renderedFileExt)
+			data, err = renderFile(filePath, data, vars)
+			if err != nil {
+				return err
+			}
+		}
+
+		renderedPath := path.Clean(renderPath(filePath, vars))
+		if path.IsAbs(renderedPath) || renderedPath == ".." || strings.HasPrefix(renderedPath, "../") {
+			return fmt.Errorf("%s renders outside the app directory as %s", filePath, renderedPath)
+		}
+
+		files = append(files, File{Path: renderedPath, Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", t.Name, err)
+	}
+
+	return files, nil
+}
+
+func loadBuiltin(name string) (*Template, error) {
+	files, err := fs.Sub(builtinFS, path.Join("builtin", name))
+	if err != nil {
+		return nil, err
+	}
+	return load(name, files)
+}
+
+func load(name string, files fs.FS) (*Template, error) {
+	data, err := fs.ReadFile(files, TemplateFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Template{}
+	err = yaml.Unmarshal(data, t)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s of template %s: %w", TemplateFileName, name, err)
+	}
+
+	for _, v := range t.Variables {
+		if !validator.ValidateDataspaceName(v.Name) {
+			return nil, fmt.Errorf("template %s has an invalid variable name '%s': it should only contain A-Za-z0-9_", name, v.Name)
+		}
+	}
+
+	t.Name = name
+	t.files = files
+
+	return t, nil
+}
+
+func builtinNames() ([]string, error) {
+	templates, err := Builtin()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(templates))
+	for i, t := range templates {
+		names[i] = t.Name
+	}
+	return names, nil
+}
+
+func renderFile(filePath string, data []byte, vars map[string]string) ([]byte, error) {
+	tmpl, err := template.New(filePath).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func renderPath(filePath string, vars map[string]string) string {
+	for name, value := range vars {
+		filePath = strings.ReplaceAll(filePath, "{"+name+"}", value)
+	}
+	return filePath
+}
@@ -0,0 +1,145 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiceai/spiceai/pkg/pods"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplates(t *testing.T) {
+	t.Run("Builtin() - Built-in templates render valid pods", testBuiltinFunc())
+	t.Run("Load() - Unknown templates are rejected", testLoadUnknownFunc())
+	t.Run("LoadDir() - Local templates are rendered with variables", testLoadDirFunc())
+	t.Run("Vars() - Unknown and missing variables are rejected", testVarsFunc())
+}
+
+func testBuiltinFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		builtins, err := Builtin()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := make([]string, len(builtins))
+		for i, builtin := range builtins {
+			names[i] = builtin.Name
+		}
+		assert.Equal(t, []string{"event-stream", "trader"}, names)
+
+		for _, builtin := range builtins {
+			vars, err := builtin.Vars(map[string]string{"name": "mypod"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files, err := builtin.Render(vars)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			appDir := t.TempDir()
+			writeFiles(t, appDir, files)
+			assert.FileExists(t, filepath.Join(appDir, "spice.config.yaml"))
+
+			// Paths in the manifests are relative to the app directory
+			cwd, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = os.Chdir(appDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pod, err := pods.LoadPodFromManifest(filepath.Join("spicepods", "mypod.yaml"))
+			if assert.NoError(t, err, builtin.Name) {
+				assert.Equal(t, "mypod", pod.Name)
+				assert.NoError(t, pod.ValidateForTraining(), builtin.Name)
+			}
+
+			err = os.Chdir(cwd)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func testLoadUnknownFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		_, err := Load("does-not-exist")
+		assert.EqualError(t, err, "unknown template 'does-not-exist', expected a template directory or one of: event-stream, trader")
+
+		_, err = Load("./does-not-exist")
+		assert.EqualError(t, err, "template directory ./does-not-exist not found")
+	}
+}
+
+func testLoadDirFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		templateDir := filepath.Join(t.TempDir(), "house")
+		writeFiles(t, templateDir, []File{
+			{Path: TemplateFileName, Data: []byte("description: House pod\nvariables:\n  - name: name\n  - name: owner\n    default: platform\n")},
+			{Path: "spicepods/{name}.yaml.tmpl", Data: []byte("name: {{ .name }} # owned by {{ .owner }}\n")},
+			{Path: "data/{name}.csv", Data: []byte("time,{{ .name }}\n")},
+		})
+
+		template, err := Load(templateDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "house", template.Name)
+		assert.Equal(t, "House pod", template.Description)
+
+		vars, err := template.Vars(map[string]string{"name": "trader"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files, err := template.Render(vars)
+		assert.NoError(t, err)
+		assert.Equal(t, []File{
+			{Path: "data/trader.csv", Data: []byte("time,{{ .name }}\n")},
+			{Path: "spicepods/trader.yaml", Data: []byte("name: trader # owned by platform\n")},
+		}, files)
+
+		_, err = template.Render(map[string]string{"name": "../trader"})
+		assert.Error(t, err)
+	}
+}
+
+func testVarsFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		template, err := Load("trader")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vars, err := template.Vars(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"name": "trader", "starting_balance": "1000000", "http_port": "8000"}, vars)
+
+		_, err = template.Vars(map[string]string{"owner": "platform"})
+		assert.EqualError(t, err, "template trader has no variable 'owner'")
+
+		_, err = template.Vars(map[string]string{"name": " "})
+		assert.EqualError(t, err, "template trader requires a value for 'name'")
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files []File) {
+	for _, file := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(file.Path))
+		err := os.MkdirAll(filepath.Dir(filePath), 0766)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filePath, file.Data, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -85,6 +85,64 @@ func ParseTime(str string, format string) (time.Time, error) {
 	return t.UTC(), nil
 }
 
+// Parses times typed by people as well as the formats ParseTime detects: "now", "today" and "yesterday",
+// offsets from now such as "-2h", "+30m" or "3d ago", and dates with optional times such as "2021-11-14 15:04".
+// Dates without a zone are UTC.
+func ParseHumanTime(str string, now time.Time) (time.Time, error) {
+	str = strings.TrimSpace(str)
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	switch strings.ToLower(str) {
+	case "now":
+		return now.UTC(), nil
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.Add(-24 * time.Hour), nil
+	}
+
+	if strings.HasSuffix(str, " ago") {
+		d, err := parseHumanDuration(strings.TrimSuffix(str, " ago"))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("incorrectly formatted time '%s': %s", str, err.Error())
+		}
+		return now.UTC().Add(-d), nil
+	}
+
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		d, err := parseHumanDuration(str[1:])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("incorrectly formatted time '%s': %s", str, err.Error())
+		}
+		if str[0] == '-' {
+			d = -d
+		}
+		return now.UTC().Add(d), nil
+	}
+
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, nil
+		}
+	}
+
+	t, err := ParseTime(str, "")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("incorrectly formatted time '%s', expected a date, an offset like -2h, unix timestamp or rfc3339", str)
+	}
+	return t, nil
+}
+
+// Parses a duration, also accepting whole days such as "3d"
+func parseHumanDuration(str string) (time.Duration, error) {
+	if strings.HasSuffix(str, "d") {
+		if n, err := strconv.ParseInt(strings.TrimSuffix(str, "d"), 10, 64); err == nil {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	return time.ParseDuration(str)
+}
+
 func NumIntervals(period time.Duration, granularity time.Duration) int64 {
 	return int64(math.Ceil(float64(period) / float64(granularity)))
 }
@@ -20,6 +20,20 @@ func TestTime(t *testing.T) {
 	t.Run("ParseTime() - detected hex by prefix", testParseTimeFunc("0x618C6F9F", "", time.Date(2021, time.November, 11, 1, 19, 27, 0, time.UTC)))
 }
 
+func TestParseHumanTime(t *testing.T) {
+	now := time.Date(2021, 11, 14, 15, 30, 0, 0, time.UTC)
+	t.Run("ParseHumanTime() - now", testParseHumanTimeFunc("now", now, now))
+	t.Run("ParseHumanTime() - today", testParseHumanTimeFunc("today", now, time.Date(2021, 11, 14, 0, 0, 0, 0, time.UTC)))
+	t.Run("ParseHumanTime() - yesterday", testParseHumanTimeFunc("yesterday", now, time.Date(2021, 11, 13, 0, 0, 0, 0, time.UTC)))
+	t.Run("ParseHumanTime() - negative offset", testParseHumanTimeFunc("-2h", now, now.Add(-2*time.Hour)))
+	t.Run("ParseHumanTime() - positive offset", testParseHumanTimeFunc("+30m", now, now.Add(30*time.Minute)))
+	t.Run("ParseHumanTime() - days ago", testParseHumanTimeFunc("3d ago", now, now.Add(-72*time.Hour)))
+	t.Run("ParseHumanTime() - date", testParseHumanTimeFunc("2021-11-01", now, time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)))
+	t.Run("ParseHumanTime() - date and time", testParseHumanTimeFunc("2021-11-01 09:15", now, time.Date(2021, 11, 1, 9, 15, 0, 0, time.UTC)))
+	t.Run("ParseHumanTime() - unix", testParseHumanTimeFunc("1605312000", now, time.Unix(1605312000, 0)))
+	t.Run("ParseHumanTime() - rfc3339", testParseHumanTimeFunc("2009-01-01T12:59:59Z", now, time.Date(2009, 01, 01, 12, 59, 59, 0, time.UTC)))
+}
+
 func TestNumIntervals(t *testing.T) {
 	t.Run("NumIntervals()", testNumIntervalsFunc())
 }
@@ -46,6 +60,19 @@ func testParseTimeFunc(str string, format string, expected time.Time) func(*test
 	}
 }
 
+// Tests "ParseHumanTime() success"
+func testParseHumanTimeFunc(str string, now time.Time, expected time.Time) func(*testing.T) {
+	return func(t *testing.T) {
+		actualTime, err := ParseHumanTime(str, now)
+
+		assert.NoError(t, err, "ParseHumanTime() failed")
+		assert.Equal(t, expected.UTC(), actualTime, "ParseHumanTime() was incorrect")
+
+		_, err = ParseHumanTime("3 fortnights ago", now)
+		assert.Error(t, err, "ParseHumanTime() did not return err")
+	}
+}
+
 // Benchmarks "ParseTime()"
 func benchParseTimeFunc(str string, format string) func(*testing.B) {
 	return func(b *testing.B) {
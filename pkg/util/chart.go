@@ -0,0 +1,44 @@
+package util
+
+import (
+	"math"
+	"strings"
+)
+
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Renders values as a line of block characters scaled between their min and max
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		level := 0
+		if max > min {
+			level = int((v - min) / (max - min) * float64(len(sparklineBlocks)-1))
+		}
+		sb.WriteRune(sparklineBlocks[level])
+	}
+	return sb.String()
+}
+
+// Renders value as a bar of up to width characters, relative to max
+func Bar(value float64, max float64, width int) string {
+	if max <= 0 || value <= 0 {
+		return ""
+	}
+	length := int(math.Round(value / max * float64(width)))
+	if length == 0 {
+		// Non-zero values are always visible
+		length = 1
+	}
+	return strings.Repeat("█", length)
+}
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChart(t *testing.T) {
+	t.Run("Sparkline() - Values are scaled between min and max", testSparklineFunc())
+	t.Run("Sparkline() - Equal values are flat", testSparklineFlatFunc())
+	t.Run("Bar() - Values are scaled to width", testBarFunc())
+}
+
+func testSparklineFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		assert.Equal(t, "▁▄█▁", Sparkline([]float64{-7, 0, 7, -7}))
+		assert.Equal(t, "", Sparkline(nil))
+	}
+}
+
+func testSparklineFlatFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		assert.Equal(t, "▁▁▁", Sparkline([]float64{3, 3, 3}))
+	}
+}
+
+func testBarFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		assert.Equal(t, "█████", Bar(50, 100, 10))
+		assert.Equal(t, "██████████", Bar(100, 100, 10))
+		assert.Equal(t, "█", Bar(1, 100, 10))
+		assert.Equal(t, "", Bar(0, 100, 10))
+		assert.Equal(t, "", Bar(1, 0, 10))
+	}
+}
@@ -0,0 +1,79 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	OutputTable = "table"
+	OutputJson  = "json"
+	OutputYaml  = "yaml"
+)
+
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case OutputTable, OutputJson, OutputYaml:
+		return nil
+	}
+	return fmt.Errorf("invalid output format '%s', must be one of %s, %s or %s", format, OutputTable, OutputJson, OutputYaml)
+}
+
+// Prints in as JSON or YAML, or as a table using printTable
+func MarshalAndPrint(writer io.Writer, format string, in interface{}, printTable func(writer io.Writer) error) error {
+	switch format {
+	case OutputJson:
+		return MarshalAndPrintJson(writer, in)
+	case OutputYaml:
+		return MarshalAndPrintYaml(writer, in)
+	case OutputTable:
+		return printTable(writer)
+	}
+	return ValidateOutputFormat(format)
+}
+
+func MarshalAndPrintJson(writer io.Writer, in interface{}) error {
+	data, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(writer, string(data))
+	return err
+}
+
+// Prints in as YAML using its JSON field names, in the order they are declared
+func MarshalAndPrintYaml(writer io.Writer, in interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	// JSON is valid YAML. Decoding objects into a MapSlice keeps their fields in order.
+	var out interface{}
+	switch {
+	case bytes.HasPrefix(data, []byte("{")):
+		var object yaml.MapSlice
+		err = yaml.Unmarshal(data, &object)
+		out = object
+	case bytes.HasPrefix(data, []byte("[{")):
+		var objects []yaml.MapSlice
+		err = yaml.Unmarshal(data, &objects)
+		out = objects
+	default:
+		err = yaml.Unmarshal(data, &out)
+	}
+	if err != nil {
+		return err
+	}
+
+	yamlData, err := yaml.Marshal(out)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(yamlData)
+	return err
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type outputTestItem struct {
+	Name    string            `json:"name"`
+	Count   int               `json:"count"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Ignored string            `json:"-"`
+}
+
+func TestOutput(t *testing.T) {
+	t.Run("MarshalAndPrintYaml() - Objects keep their JSON field names and order", testMarshalAndPrintYamlObjectFunc())
+	t.Run("MarshalAndPrintYaml() - Arrays of objects are printed", testMarshalAndPrintYamlArrayFunc())
+	t.Run("MarshalAndPrint() - Invalid formats are rejected", testMarshalAndPrintInvalidFunc())
+}
+
+func testMarshalAndPrintYamlObjectFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		var output bytes.Buffer
+		err := MarshalAndPrintYaml(&output, &outputTestItem{Name: "trader", Count: 2, Labels: map[string]string{"b": "2", "a": "1"}, Ignored: "x"})
+		assert.NoError(t, err)
+		assert.Equal(t, "name: trader\ncount: 2\nlabels:\n  a: \"1\"\n  b: \"2\"\n", output.String())
+	}
+}
+
+func testMarshalAndPrintYamlArrayFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		var output bytes.Buffer
+		err := MarshalAndPrintYaml(&output, []*outputTestItem{{Name: "a", Count: 1}, {Name: "b"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "- name: a\n  count: 1\n- name: b\n  count: 0\n", output.String())
+	}
+}
+
+func testMarshalAndPrintInvalidFunc() func(*testing.T) {
+	return func(t *testing.T) {
+		var output bytes.Buffer
+		err := MarshalAndPrint(&output, "xml", "a", nil)
+		assert.Error(t, err)
+		assert.Empty(t, output.String())
+	}
+}
@@ -144,7 +144,7 @@ func untar(r io.Reader, dir string, isGzipped bool) (err error) {
 }
 
 func validRelPath(p string) bool {
-	if p == "" || strings.Contains(p, `\`) || strings.HasPrefix(p, "/") || strings.Contains(p, "../") {
+	if p == "" || strings.Contains(p, `\`) || strings.HasPrefix(p, "/") || strings.Contains(p, "../") || p == ".." || strings.HasSuffix(p, "/..") {
 		return false
 	}
 	return true
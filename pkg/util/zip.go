@@ -42,6 +42,7 @@ func ProcessAFileInZipArchive(zipArchive string, filename string, processFunc Pr
 	return nil
 }
 
+// Extracts the zip archive into targetDirectory, rejecting files that would be extracted outside of it
 func ExtractZipFileToDir(zipArchive string, targetDirectory string) error {
 	r, err := zip.OpenReader(zipArchive)
 	if err != nil {
@@ -49,38 +50,34 @@ func ExtractZipFileToDir(zipArchive string, targetDirectory string) error {
 	}
 	defer r.Close()
 
-	for _, f := range r.File {
-		reader, err := f.Open()
-		if err != nil {
-			return err
-		}
-		defer reader.Close()
+	// Copy file mask from target directory
+	stat, err := os.Stat(targetDirectory)
+	if err != nil {
+		return err
+	}
 
+	for _, f := range r.File {
 		if err := SanitizeExtractPath(f.Name, targetDirectory); err != nil {
 			return err
 		}
 
-		if f.FileInfo().IsDir() {
-			// Copy file mask from target directory
-			stat, err := os.Stat(targetDirectory)
-			if err != nil {
-				return err
-			}
+		path := filepath.Join(targetDirectory, f.Name)
 
-			err = os.Mkdir(filepath.Join(targetDirectory, f.Name), stat.Mode())
+		if f.FileInfo().IsDir() {
+			err = os.MkdirAll(path, stat.Mode().Perm())
 			if err != nil {
 				return err
 			}
 			continue
 		}
 
-		fileHandle, err := os.Create(filepath.Join(targetDirectory, f.Name))
+		// Archives don't always have entries for the directories of their files
+		err = os.MkdirAll(filepath.Dir(path), stat.Mode().Perm())
 		if err != nil {
 			return err
 		}
-		defer fileHandle.Close()
 
-		_, err = io.Copy(fileHandle, reader)
+		err = extractZipFile(f, path)
 		if err != nil {
 			return err
 		}
@@ -89,6 +86,23 @@ func ExtractZipFileToDir(zipArchive string, targetDirectory string) error {
 	return nil
 }
 
+func extractZipFile(f *zip.File, path string) error {
+	reader, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	fileHandle, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer fileHandle.Close()
+
+	_, err = io.Copy(fileHandle, reader)
+	return err
+}
+
 func SanitizeExtractPath(filePath string, destination string) error {
 	destpath := filepath.Join(destination, filePath)
 	if !strings.HasPrefix(destpath, filepath.Clean(destination)+string(os.PathSeparator)) {